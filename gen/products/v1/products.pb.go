@@ -7,8 +7,11 @@
 package productsv1
 
 import (
+	_ "github.com/yaninyzwitty/go-fx-v1/gen/validate/v1"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -22,23 +25,351 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ReservationStatus int32
+
+const (
+	ReservationStatus_RESERVATION_STATUS_UNSPECIFIED ReservationStatus = 0
+	// Stock is held and will be returned when expires_at passes.
+	ReservationStatus_RESERVATION_STATUS_PENDING ReservationStatus = 1
+	// The hold was converted into a sale; stock stays decremented.
+	ReservationStatus_RESERVATION_STATUS_COMMITTED ReservationStatus = 2
+	// The hold was cancelled and its stock returned.
+	ReservationStatus_RESERVATION_STATUS_RELEASED ReservationStatus = 3
+	// The hold timed out and its stock was returned.
+	ReservationStatus_RESERVATION_STATUS_EXPIRED ReservationStatus = 4
+)
+
+// Enum value maps for ReservationStatus.
+var (
+	ReservationStatus_name = map[int32]string{
+		0: "RESERVATION_STATUS_UNSPECIFIED",
+		1: "RESERVATION_STATUS_PENDING",
+		2: "RESERVATION_STATUS_COMMITTED",
+		3: "RESERVATION_STATUS_RELEASED",
+		4: "RESERVATION_STATUS_EXPIRED",
+	}
+	ReservationStatus_value = map[string]int32{
+		"RESERVATION_STATUS_UNSPECIFIED": 0,
+		"RESERVATION_STATUS_PENDING":     1,
+		"RESERVATION_STATUS_COMMITTED":   2,
+		"RESERVATION_STATUS_RELEASED":    3,
+		"RESERVATION_STATUS_EXPIRED":     4,
+	}
+)
+
+func (x ReservationStatus) Enum() *ReservationStatus {
+	p := new(ReservationStatus)
+	*p = x
+	return p
+}
+
+func (x ReservationStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReservationStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_products_v1_products_proto_enumTypes[0].Descriptor()
+}
+
+func (ReservationStatus) Type() protoreflect.EnumType {
+	return &file_products_v1_products_proto_enumTypes[0]
+}
+
+func (x ReservationStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReservationStatus.Descriptor instead.
+func (ReservationStatus) EnumDescriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{0}
+}
+
+type StockMovementReason int32
+
+const (
+	StockMovementReason_STOCK_MOVEMENT_REASON_UNSPECIFIED StockMovementReason = 0
+	// Goods received into inventory.
+	StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT StockMovementReason = 1
+	// Goods sold to a customer.
+	StockMovementReason_STOCK_MOVEMENT_REASON_SALE StockMovementReason = 2
+	// Goods returned by a customer.
+	StockMovementReason_STOCK_MOVEMENT_REASON_RETURN StockMovementReason = 3
+	// Goods lost, damaged or stolen.
+	StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE StockMovementReason = 4
+	// Manual correction after a stock count.
+	StockMovementReason_STOCK_MOVEMENT_REASON_CORRECTION StockMovementReason = 5
+)
+
+// Enum value maps for StockMovementReason.
+var (
+	StockMovementReason_name = map[int32]string{
+		0: "STOCK_MOVEMENT_REASON_UNSPECIFIED",
+		1: "STOCK_MOVEMENT_REASON_RECEIPT",
+		2: "STOCK_MOVEMENT_REASON_SALE",
+		3: "STOCK_MOVEMENT_REASON_RETURN",
+		4: "STOCK_MOVEMENT_REASON_SHRINKAGE",
+		5: "STOCK_MOVEMENT_REASON_CORRECTION",
+	}
+	StockMovementReason_value = map[string]int32{
+		"STOCK_MOVEMENT_REASON_UNSPECIFIED": 0,
+		"STOCK_MOVEMENT_REASON_RECEIPT":     1,
+		"STOCK_MOVEMENT_REASON_SALE":        2,
+		"STOCK_MOVEMENT_REASON_RETURN":      3,
+		"STOCK_MOVEMENT_REASON_SHRINKAGE":   4,
+		"STOCK_MOVEMENT_REASON_CORRECTION":  5,
+	}
+)
+
+func (x StockMovementReason) Enum() *StockMovementReason {
+	p := new(StockMovementReason)
+	*p = x
+	return p
+}
+
+func (x StockMovementReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StockMovementReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_products_v1_products_proto_enumTypes[1].Descriptor()
+}
+
+func (StockMovementReason) Type() protoreflect.EnumType {
+	return &file_products_v1_products_proto_enumTypes[1]
+}
+
+func (x StockMovementReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StockMovementReason.Descriptor instead.
+func (StockMovementReason) EnumDescriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{1}
+}
+
+type ProductEventType int32
+
+const (
+	ProductEventType_PRODUCT_EVENT_TYPE_UNSPECIFIED ProductEventType = 0
+	ProductEventType_PRODUCT_EVENT_TYPE_CREATED     ProductEventType = 1
+	ProductEventType_PRODUCT_EVENT_TYPE_UPDATED     ProductEventType = 2
+	ProductEventType_PRODUCT_EVENT_TYPE_DELETED     ProductEventType = 3
+	// The product was permanently removed after its soft delete; the
+	// event carries its state when it was purged.
+	ProductEventType_PRODUCT_EVENT_TYPE_PURGED ProductEventType = 4
+)
+
+// Enum value maps for ProductEventType.
+var (
+	ProductEventType_name = map[int32]string{
+		0: "PRODUCT_EVENT_TYPE_UNSPECIFIED",
+		1: "PRODUCT_EVENT_TYPE_CREATED",
+		2: "PRODUCT_EVENT_TYPE_UPDATED",
+		3: "PRODUCT_EVENT_TYPE_DELETED",
+		4: "PRODUCT_EVENT_TYPE_PURGED",
+	}
+	ProductEventType_value = map[string]int32{
+		"PRODUCT_EVENT_TYPE_UNSPECIFIED": 0,
+		"PRODUCT_EVENT_TYPE_CREATED":     1,
+		"PRODUCT_EVENT_TYPE_UPDATED":     2,
+		"PRODUCT_EVENT_TYPE_DELETED":     3,
+		"PRODUCT_EVENT_TYPE_PURGED":      4,
+	}
+)
+
+func (x ProductEventType) Enum() *ProductEventType {
+	p := new(ProductEventType)
+	*p = x
+	return p
+}
+
+func (x ProductEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProductEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_products_v1_products_proto_enumTypes[2].Descriptor()
+}
+
+func (ProductEventType) Type() protoreflect.EnumType {
+	return &file_products_v1_products_proto_enumTypes[2]
+}
+
+func (x ProductEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProductEventType.Descriptor instead.
+func (ProductEventType) EnumDescriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{2}
+}
+
+type RevisionAction int32
+
+const (
+	RevisionAction_REVISION_ACTION_UNSPECIFIED RevisionAction = 0
+	RevisionAction_REVISION_ACTION_CREATE      RevisionAction = 1
+	RevisionAction_REVISION_ACTION_UPDATE      RevisionAction = 2
+	RevisionAction_REVISION_ACTION_DELETE      RevisionAction = 3
+	RevisionAction_REVISION_ACTION_UNDELETE    RevisionAction = 4
+	// State of a product that existed before revisions were recorded.
+	RevisionAction_REVISION_ACTION_BASELINE RevisionAction = 5
+	// Recorded by the purge job when it removes a soft deleted product.
+	RevisionAction_REVISION_ACTION_PURGE RevisionAction = 6
+	// Stock changed through AdjustStock or a reservation.
+	RevisionAction_REVISION_ACTION_STOCK RevisionAction = 7
+)
+
+// Enum value maps for RevisionAction.
+var (
+	RevisionAction_name = map[int32]string{
+		0: "REVISION_ACTION_UNSPECIFIED",
+		1: "REVISION_ACTION_CREATE",
+		2: "REVISION_ACTION_UPDATE",
+		3: "REVISION_ACTION_DELETE",
+		4: "REVISION_ACTION_UNDELETE",
+		5: "REVISION_ACTION_BASELINE",
+		6: "REVISION_ACTION_PURGE",
+		7: "REVISION_ACTION_STOCK",
+	}
+	RevisionAction_value = map[string]int32{
+		"REVISION_ACTION_UNSPECIFIED": 0,
+		"REVISION_ACTION_CREATE":      1,
+		"REVISION_ACTION_UPDATE":      2,
+		"REVISION_ACTION_DELETE":      3,
+		"REVISION_ACTION_UNDELETE":    4,
+		"REVISION_ACTION_BASELINE":    5,
+		"REVISION_ACTION_PURGE":       6,
+		"REVISION_ACTION_STOCK":       7,
+	}
+)
+
+func (x RevisionAction) Enum() *RevisionAction {
+	p := new(RevisionAction)
+	*p = x
+	return p
+}
+
+func (x RevisionAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RevisionAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_products_v1_products_proto_enumTypes[3].Descriptor()
+}
+
+func (RevisionAction) Type() protoreflect.EnumType {
+	return &file_products_v1_products_proto_enumTypes[3]
+}
+
+func (x RevisionAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RevisionAction.Descriptor instead.
+func (RevisionAction) EnumDescriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{3}
+}
+
+// An exact amount of money, laid out like google.type.Money. units and
+// nanos must have the same sign, and nanos may not be more precise than the
+// currency's ISO 4217 minor unit (e.g. whole cents for USD, whole yen for JPY).
+type Money struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ISO 4217 currency code, e.g. "USD".
+	CurrencyCode string `protobuf:"bytes,1,opt,name=currency_code,json=currencyCode,proto3" json:"currency_code,omitempty"`
+	// Whole units of the amount.
+	Units int64 `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	// Fractional units in billionths, in the range -999,999,999..999,999,999.
+	Nanos         int32 `protobuf:"varint,3,opt,name=nanos,proto3" json:"nanos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Money) Reset() {
+	*x = Money{}
+	mi := &file_products_v1_products_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Money) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Money) ProtoMessage() {}
+
+func (x *Money) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Money.ProtoReflect.Descriptor instead.
+func (*Money) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Money) GetCurrencyCode() string {
+	if x != nil {
+		return x.CurrencyCode
+	}
+	return ""
+}
+
+func (x *Money) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *Money) GetNanos() int32 {
+	if x != nil {
+		return x.Nanos
+	}
+	return 0
+}
+
 type Product struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
-	Currency      string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Price         *Money                 `protobuf:"bytes,11,opt,name=price,proto3" json:"price,omitempty"`
 	StockQuantity uint32                 `protobuf:"varint,6,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
 	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Opaque version tag that changes on every mutation. Send it back on
+	// UpdateProduct/DeleteProduct to guard against concurrent modification.
+	Etag string `protobuf:"bytes,9,opt,name=etag,proto3" json:"etag,omitempty"`
+	// Set while the product is soft deleted.
+	DeletedAt *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// price converted into the display_currency of the request; unset when
+	// no display currency was requested.
+	DisplayPrice *ConvertedPrice `protobuf:"bytes,12,opt,name=display_price,json=displayPrice,proto3" json:"display_price,omitempty"`
+	// Categories the product is filed under, in ascending id order.
+	CategoryIds []uint64 `protobuf:"varint,13,rep,packed,name=category_ids,json=categoryIds,proto3" json:"category_ids,omitempty"`
+	// Free-form lower-case labels in ascending order.
+	Tags []string `protobuf:"bytes,14,rep,name=tags,proto3" json:"tags,omitempty"`
+	// The product's variants in creation order. Only GetProduct fills this
+	// in.
+	Variants []*ProductVariant `protobuf:"bytes,15,rep,name=variants,proto3" json:"variants,omitempty"`
+	// The product's images in display order.
+	Media         []*ProductMedia `protobuf:"bytes,16,rep,name=media,proto3" json:"media,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Product) Reset() {
 	*x = Product{}
-	mi := &file_products_v1_products_proto_msgTypes[0]
+	mi := &file_products_v1_products_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -50,7 +381,7 @@ func (x *Product) String() string {
 func (*Product) ProtoMessage() {}
 
 func (x *Product) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[0]
+	mi := &file_products_v1_products_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -63,7 +394,7 @@ func (x *Product) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Product.ProtoReflect.Descriptor instead.
 func (*Product) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{0}
+	return file_products_v1_products_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *Product) GetId() uint64 {
@@ -87,18 +418,11 @@ func (x *Product) GetDescription() string {
 	return ""
 }
 
-func (x *Product) GetPrice() float64 {
+func (x *Product) GetPrice() *Money {
 	if x != nil {
 		return x.Price
 	}
-	return 0
-}
-
-func (x *Product) GetCurrency() string {
-	if x != nil {
-		return x.Currency
-	}
-	return ""
+	return nil
 }
 
 func (x *Product) GetStockQuantity() uint32 {
@@ -122,28 +446,4378 @@ func (x *Product) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
-type GetProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+func (x *Product) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Product) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Product) GetDisplayPrice() *ConvertedPrice {
+	if x != nil {
+		return x.DisplayPrice
+	}
+	return nil
+}
+
+func (x *Product) GetCategoryIds() []uint64 {
+	if x != nil {
+		return x.CategoryIds
+	}
+	return nil
+}
+
+func (x *Product) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Product) GetVariants() []*ProductVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *Product) GetMedia() []*ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+// An image of a product. The blob is kept in the gateway's blob storage;
+// this is its metadata.
+type ProductMedia struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Where the image can be downloaded.
+	Url string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	// Key of the blob in blob storage.
+	StorageKey string `protobuf:"bytes,4,opt,name=storage_key,json=storageKey,proto3" json:"storage_key,omitempty"`
+	// e.g. "image/png".
+	ContentType string `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Lower-case hex SHA-256 of the blob.
+	ChecksumSha256 string `protobuf:"bytes,6,opt,name=checksum_sha256,json=checksumSha256,proto3" json:"checksum_sha256,omitempty"`
+	SizeBytes      uint64 `protobuf:"varint,7,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	// Pixel dimensions of the image.
+	Width   uint32 `protobuf:"varint,8,opt,name=width,proto3" json:"width,omitempty"`
+	Height  uint32 `protobuf:"varint,9,opt,name=height,proto3" json:"height,omitempty"`
+	AltText string `protobuf:"bytes,10,opt,name=alt_text,json=altText,proto3" json:"alt_text,omitempty"`
+	// Zero-based display position among the product's media.
+	Position      uint32                 `protobuf:"varint,11,opt,name=position,proto3" json:"position,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductRequest) Reset() {
-	*x = GetProductRequest{}
-	mi := &file_products_v1_products_proto_msgTypes[1]
+func (x *ProductMedia) Reset() {
+	*x = ProductMedia{}
+	mi := &file_products_v1_products_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductRequest) String() string {
+func (x *ProductMedia) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductMedia) ProtoMessage() {}
+
+func (x *ProductMedia) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductMedia.ProtoReflect.Descriptor instead.
+func (*ProductMedia) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProductMedia) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ProductMedia) GetStorageKey() string {
+	if x != nil {
+		return x.StorageKey
+	}
+	return ""
+}
+
+func (x *ProductMedia) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ProductMedia) GetChecksumSha256() string {
+	if x != nil {
+		return x.ChecksumSha256
+	}
+	return ""
+}
+
+func (x *ProductMedia) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetAltText() string {
+	if x != nil {
+		return x.AltText
+	}
+	return ""
+}
+
+func (x *ProductMedia) GetPosition() uint32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *ProductMedia) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// A purchasable version of a product, such as one size and colour, with its
+// own SKU and stock.
+type ProductVariant struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Unique across all products: 1 to 64 letters, digits, '.', '_' or '-',
+	// starting with a letter or digit.
+	Sku string `protobuf:"bytes,3,opt,name=sku,proto3" json:"sku,omitempty"`
+	// Option values that tell the variant apart, e.g. {"size": "M"}. No two
+	// variants of a product may have the same options.
+	Options map[string]string `protobuf:"bytes,4,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Overrides the product's price when set.
+	Price         *Money                 `protobuf:"bytes,5,opt,name=price,proto3" json:"price,omitempty"`
+	StockQuantity uint32                 `protobuf:"varint,6,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductVariant) Reset() {
+	*x = ProductVariant{}
+	mi := &file_products_v1_products_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductVariant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductVariant) ProtoMessage() {}
+
+func (x *ProductVariant) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductVariant.ProtoReflect.Descriptor instead.
+func (*ProductVariant) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ProductVariant) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductVariant) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductVariant) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ProductVariant) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ProductVariant) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ProductVariant) GetStockQuantity() uint32 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+func (x *ProductVariant) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ProductVariant) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// A node in the category tree.
+type Category struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Unset for root categories.
+	ParentId uint64 `protobuf:"varint,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Name     string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Materialized path of ids from the root down to this category, e.g.
+	// "/12/34/".
+	Path          string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Category) Reset() {
+	*x = Category{}
+	mi := &file_products_v1_products_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Category) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Category) ProtoMessage() {}
+
+func (x *Category) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Category.ProtoReflect.Descriptor instead.
+func (*Category) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Category) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Category) GetParentId() uint64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Category) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Category) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Category) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Category) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// A rate for converting amounts in base_currency into quote_currency.
+type ExchangeRate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BaseCurrency  string                 `protobuf:"bytes,1,opt,name=base_currency,json=baseCurrency,proto3" json:"base_currency,omitempty"`
+	QuoteCurrency string                 `protobuf:"bytes,2,opt,name=quote_currency,json=quoteCurrency,proto3" json:"quote_currency,omitempty"`
+	// Units of quote_currency per unit of base_currency as an exact decimal
+	// string with at most 12 decimal places, e.g. "0.9215".
+	Rate string `protobuf:"bytes,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	// When the rate takes effect. It stays in force until a rate with a later
+	// effective_at does.
+	EffectiveAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=effective_at,json=effectiveAt,proto3" json:"effective_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeRate) Reset() {
+	*x = ExchangeRate{}
+	mi := &file_products_v1_products_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeRate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeRate) ProtoMessage() {}
+
+func (x *ExchangeRate) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeRate.ProtoReflect.Descriptor instead.
+func (*ExchangeRate) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ExchangeRate) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *ExchangeRate) GetQuoteCurrency() string {
+	if x != nil {
+		return x.QuoteCurrency
+	}
+	return ""
+}
+
+func (x *ExchangeRate) GetRate() string {
+	if x != nil {
+		return x.Rate
+	}
+	return ""
+}
+
+func (x *ExchangeRate) GetEffectiveAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EffectiveAt
+	}
+	return nil
+}
+
+type ConvertedPrice struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Price *Money                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	// The rate applied and when it took effect. When only the opposite
+	// direction is on record its inverse is used. Unset when the product is
+	// already priced in the display currency.
+	Rate          *ExchangeRate `protobuf:"bytes,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertedPrice) Reset() {
+	*x = ConvertedPrice{}
+	mi := &file_products_v1_products_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertedPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertedPrice) ProtoMessage() {}
+
+func (x *ConvertedPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertedPrice.ProtoReflect.Descriptor instead.
+func (*ConvertedPrice) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ConvertedPrice) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *ConvertedPrice) GetRate() *ExchangeRate {
+	if x != nil {
+		return x.Rate
+	}
+	return nil
+}
+
+type GetProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Optional ISO 4217 code to also show the price in; see
+	// Product.display_price.
+	DisplayCurrency string `protobuf:"bytes,2,opt,name=display_currency,json=displayCurrency,proto3" json:"display_currency,omitempty"`
+	// Returns the product as it was at this time, rebuilt from its
+	// revision history. Variants and media are not versioned and are left
+	// empty.
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductRequest) Reset() {
+	*x = GetProductRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductRequest) ProtoMessage() {}
+
+func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
+func (*GetProductRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GetProductRequest) GetDisplayCurrency() string {
+	if x != nil {
+		return x.DisplayCurrency
+	}
+	return ""
+}
+
+func (x *GetProductRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type GetProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductResponse) Reset() {
+	*x = GetProductResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductResponse) ProtoMessage() {}
+
+func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
+func (*GetProductResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type ProductFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Exact ISO 4217 currency code, e.g. "USD".
+	Currency string `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`
+	// Inclusive price bounds. Prices are only comparable within a currency,
+	// so a bound restricts results to its currency; it must agree with
+	// currency when both are set.
+	MinPrice *Money `protobuf:"bytes,6,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MaxPrice *Money `protobuf:"bytes,7,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	// Case-sensitive prefix the product name must start with.
+	NamePrefix string `protobuf:"bytes,4,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix,omitempty"`
+	// Only return products with stock_quantity > 0.
+	InStockOnly bool `protobuf:"varint,5,opt,name=in_stock_only,json=inStockOnly,proto3" json:"in_stock_only,omitempty"`
+	// Only return products filed under this category or any category below
+	// it.
+	CategoryId uint64 `protobuf:"varint,8,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	// Only return products carrying this tag. Matching is case-insensitive.
+	Tag           string `protobuf:"bytes,9,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductFilter) Reset() {
+	*x = ProductFilter{}
+	mi := &file_products_v1_products_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductFilter) ProtoMessage() {}
+
+func (x *ProductFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductFilter.ProtoReflect.Descriptor instead.
+func (*ProductFilter) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProductFilter) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ProductFilter) GetMinPrice() *Money {
+	if x != nil {
+		return x.MinPrice
+	}
+	return nil
+}
+
+func (x *ProductFilter) GetMaxPrice() *Money {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return nil
+}
+
+func (x *ProductFilter) GetNamePrefix() string {
+	if x != nil {
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *ProductFilter) GetInStockOnly() bool {
+	if x != nil {
+		return x.InStockOnly
+	}
+	return false
+}
+
+func (x *ProductFilter) GetCategoryId() uint64 {
+	if x != nil {
+		return x.CategoryId
+	}
+	return 0
+}
+
+func (x *ProductFilter) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ListProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to 10. Values above 100 are rejected.
+	PageSize uint32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque token from a previous ListProductsResponse.next_page_token.
+	// It is only valid with the same filter and order_by.
+	PageToken string         `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Filter    *ProductFilter `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Sort order: one of "id", "price", "name" or "created_at", optionally
+	// followed by "asc" or "desc". Defaults to "id asc". Ordering by price
+	// compares minor units and is only meaningful within one currency.
+	OrderBy string `protobuf:"bytes,5,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// Include soft deleted products.
+	ShowDeleted bool `protobuf:"varint,6,opt,name=show_deleted,json=showDeleted,proto3" json:"show_deleted,omitempty"`
+	// Optional ISO 4217 code to also show prices in; see
+	// Product.display_price.
+	DisplayCurrency string `protobuf:"bytes,7,opt,name=display_currency,json=displayCurrency,proto3" json:"display_currency,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsRequest) ProtoMessage() {}
+
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListProductsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListProductsRequest) GetFilter() *ProductFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListProductsRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListProductsRequest) GetShowDeleted() bool {
+	if x != nil {
+		return x.ShowDeleted
+	}
+	return false
+}
+
+func (x *ListProductsRequest) GetDisplayCurrency() string {
+	if x != nil {
+		return x.DisplayCurrency
+	}
+	return ""
+}
+
+type ListProductsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Products []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// Token for the next page; empty when there are no more results.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *ListProductsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type SearchProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Free-text query matched against name and description.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Defaults to 10. Values above 100 are rejected.
+	PageSize uint32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque token from a previous SearchProductsResponse.next_page_token.
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchProductsRequest) Reset() {
+	*x = SearchProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchProductsRequest) ProtoMessage() {}
+
+func (x *SearchProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchProductsRequest.ProtoReflect.Descriptor instead.
+func (*SearchProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SearchProductsRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchProductsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchProductsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Product *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// Relevance score; higher is better.
+	Score float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	// Name and description excerpts with matches wrapped in <mark></mark>.
+	// The surrounding text is HTML-escaped.
+	NameSnippet        string `protobuf:"bytes,3,opt,name=name_snippet,json=nameSnippet,proto3" json:"name_snippet,omitempty"`
+	DescriptionSnippet string `protobuf:"bytes,4,opt,name=description_snippet,json=descriptionSnippet,proto3" json:"description_snippet,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_products_v1_products_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SearchResult) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *SearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchResult) GetNameSnippet() string {
+	if x != nil {
+		return x.NameSnippet
+	}
+	return ""
+}
+
+func (x *SearchResult) GetDescriptionSnippet() string {
+	if x != nil {
+		return x.DescriptionSnippet
+	}
+	return ""
+}
+
+type SearchProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchProductsResponse) Reset() {
+	*x = SearchProductsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchProductsResponse) ProtoMessage() {}
+
+func (x *SearchProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchProductsResponse.ProtoReflect.Descriptor instead.
+func (*SearchProductsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchProductsResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchProductsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type SuggestProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Case-insensitive prefix of the product name.
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Maximum number of suggestions; capped by server configuration.
+	MaxResults    uint32 `protobuf:"varint,2,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestProductsRequest) Reset() {
+	*x = SuggestProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestProductsRequest) ProtoMessage() {}
+
+func (x *SuggestProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestProductsRequest.ProtoReflect.Descriptor instead.
+func (*SuggestProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SuggestProductsRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SuggestProductsRequest) GetMaxResults() uint32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+type ProductSuggestion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductSuggestion) Reset() {
+	*x = ProductSuggestion{}
+	mi := &file_products_v1_products_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductSuggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductSuggestion) ProtoMessage() {}
+
+func (x *ProductSuggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductSuggestion.ProtoReflect.Descriptor instead.
+func (*ProductSuggestion) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ProductSuggestion) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductSuggestion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type SuggestProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions   []*ProductSuggestion   `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestProductsResponse) Reset() {
+	*x = SuggestProductsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestProductsResponse) ProtoMessage() {}
+
+func (x *SuggestProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestProductsResponse.ProtoReflect.Descriptor instead.
+func (*SuggestProductsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SuggestProductsResponse) GetSuggestions() []*ProductSuggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+type CreateProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Price         *Money                 `protobuf:"bytes,6,opt,name=price,proto3" json:"price,omitempty"`
+	StockQuantity uint32                 `protobuf:"varint,5,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	// Categories to file the product under; each must exist.
+	CategoryIds []uint64 `protobuf:"varint,7,rep,packed,name=category_ids,json=categoryIds,proto3" json:"category_ids,omitempty"`
+	// Tags are trimmed and lower-cased; duplicates are dropped.
+	Tags          []string `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProductRequest) Reset() {
+	*x = CreateProductRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProductRequest) ProtoMessage() {}
+
+func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
+func (*CreateProductRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CreateProductRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProductRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateProductRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *CreateProductRequest) GetStockQuantity() uint32 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+func (x *CreateProductRequest) GetCategoryIds() []uint64 {
+	if x != nil {
+		return x.CategoryIds
+	}
+	return nil
+}
+
+func (x *CreateProductRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type CreateProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProductResponse) Reset() {
+	*x = CreateProductResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProductResponse) ProtoMessage() {}
+
+func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProductResponse.ProtoReflect.Descriptor instead.
+func (*CreateProductResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CreateProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type BatchGetProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetProductsRequest) Reset() {
+	*x = BatchGetProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetProductsRequest) ProtoMessage() {}
+
+func (x *BatchGetProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetProductsRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BatchGetProductsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetProductsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Found products in the order their ids were requested.
+	Products []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	// Requested ids that matched no product.
+	MissingIds    []int64 `protobuf:"varint,2,rep,packed,name=missing_ids,json=missingIds,proto3" json:"missing_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetProductsResponse) Reset() {
+	*x = BatchGetProductsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetProductsResponse) ProtoMessage() {}
+
+func (x *BatchGetProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetProductsResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetProductsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BatchGetProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *BatchGetProductsResponse) GetMissingIds() []int64 {
+	if x != nil {
+		return x.MissingIds
+	}
+	return nil
+}
+
+type BatchCreateProductsRequest struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Requests      []*CreateProductRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateProductsRequest) Reset() {
+	*x = BatchCreateProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateProductsRequest) ProtoMessage() {}
+
+func (x *BatchCreateProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateProductsRequest.ProtoReflect.Descriptor instead.
+func (*BatchCreateProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BatchCreateProductsRequest) GetRequests() []*CreateProductRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchCreateProductsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Created products in request order.
+	Products      []*Product `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCreateProductsResponse) Reset() {
+	*x = BatchCreateProductsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCreateProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCreateProductsResponse) ProtoMessage() {}
+
+func (x *BatchCreateProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCreateProductsResponse.ProtoReflect.Descriptor instead.
+func (*BatchCreateProductsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BatchCreateProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type UpdateProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Product carrying the new values; id selects the product to update.
+	// When product.etag is set the update fails with ABORTED if it is stale.
+	Product *Product `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	// Fields of product to overwrite. An empty mask updates every mutable field.
+	// The "price" path replaces amount and currency together. The
+	// "category_ids" and "tags" paths replace the whole list and are only
+	// updated when named explicitly.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductRequest) Reset() {
+	*x = UpdateProductRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductRequest) ProtoMessage() {}
+
+func (x *UpdateProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateProductRequest) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+func (x *UpdateProductRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductResponse) Reset() {
+	*x = UpdateProductResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductResponse) ProtoMessage() {}
+
+func (x *UpdateProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProductResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UpdateProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type DeleteProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Optional etag; when set the delete fails with ABORTED if it is stale.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteProductRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type DeleteProductResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	// The product as it was soft deleted.
+	Product       *Product `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteProductResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type UndeleteProductRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Optional etag; when set the undelete fails with ABORTED if it is stale.
+	Etag          string `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteProductRequest) Reset() {
+	*x = UndeleteProductRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteProductRequest) ProtoMessage() {}
+
+func (x *UndeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UndeleteProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UndeleteProductRequest) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+type UndeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteProductResponse) Reset() {
+	*x = UndeleteProductResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteProductResponse) ProtoMessage() {}
+
+func (x *UndeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UndeleteProductResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type UpsertExchangeRateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Rate to store. effective_at defaults to now; a rate with the same
+	// currencies and effective_at is replaced.
+	Rate          *ExchangeRate `protobuf:"bytes,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertExchangeRateRequest) Reset() {
+	*x = UpsertExchangeRateRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertExchangeRateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertExchangeRateRequest) ProtoMessage() {}
+
+func (x *UpsertExchangeRateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertExchangeRateRequest.ProtoReflect.Descriptor instead.
+func (*UpsertExchangeRateRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UpsertExchangeRateRequest) GetRate() *ExchangeRate {
+	if x != nil {
+		return x.Rate
+	}
+	return nil
+}
+
+type UpsertExchangeRateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rate          *ExchangeRate          `protobuf:"bytes,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertExchangeRateResponse) Reset() {
+	*x = UpsertExchangeRateResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertExchangeRateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertExchangeRateResponse) ProtoMessage() {}
+
+func (x *UpsertExchangeRateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertExchangeRateResponse.ProtoReflect.Descriptor instead.
+func (*UpsertExchangeRateResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpsertExchangeRateResponse) GetRate() *ExchangeRate {
+	if x != nil {
+		return x.Rate
+	}
+	return nil
+}
+
+type CreateVariantRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Sku       string                 `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	Options   map[string]string      `protobuf:"bytes,3,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Optional price override.
+	Price *Money `protobuf:"bytes,4,opt,name=price,proto3" json:"price,omitempty"`
+	// Opening stock, booked in the ledger as a receipt.
+	StockQuantity uint32 `protobuf:"varint,5,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVariantRequest) Reset() {
+	*x = CreateVariantRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVariantRequest) ProtoMessage() {}
+
+func (x *CreateVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVariantRequest.ProtoReflect.Descriptor instead.
+func (*CreateVariantRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CreateVariantRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *CreateVariantRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *CreateVariantRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *CreateVariantRequest) GetPrice() *Money {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *CreateVariantRequest) GetStockQuantity() uint32 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+type CreateVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *ProductVariant        `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateVariantResponse) Reset() {
+	*x = CreateVariantResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVariantResponse) ProtoMessage() {}
+
+func (x *CreateVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVariantResponse.ProtoReflect.Descriptor instead.
+func (*CreateVariantResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *CreateVariantResponse) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type ListVariantsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVariantsRequest) Reset() {
+	*x = ListVariantsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVariantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVariantsRequest) ProtoMessage() {}
+
+func (x *ListVariantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVariantsRequest.ProtoReflect.Descriptor instead.
+func (*ListVariantsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListVariantsRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+type ListVariantsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In creation order.
+	Variants      []*ProductVariant `protobuf:"bytes,1,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVariantsResponse) Reset() {
+	*x = ListVariantsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVariantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVariantsResponse) ProtoMessage() {}
+
+func (x *ListVariantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVariantsResponse.ProtoReflect.Descriptor instead.
+func (*ListVariantsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListVariantsResponse) GetVariants() []*ProductVariant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type GetVariantBySkuRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVariantBySkuRequest) Reset() {
+	*x = GetVariantBySkuRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVariantBySkuRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVariantBySkuRequest) ProtoMessage() {}
+
+func (x *GetVariantBySkuRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVariantBySkuRequest.ProtoReflect.Descriptor instead.
+func (*GetVariantBySkuRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetVariantBySkuRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+type GetVariantBySkuResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Variant *ProductVariant        `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	// The product the variant belongs to.
+	Product       *Product `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVariantBySkuResponse) Reset() {
+	*x = GetVariantBySkuResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVariantBySkuResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVariantBySkuResponse) ProtoMessage() {}
+
+func (x *GetVariantBySkuResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVariantBySkuResponse.ProtoReflect.Descriptor instead.
+func (*GetVariantBySkuResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetVariantBySkuResponse) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+func (x *GetVariantBySkuResponse) GetProduct() *Product {
+	if x != nil {
+		return x.Product
+	}
+	return nil
+}
+
+type UpdateVariantRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Variant carrying the new values; id selects the variant to update.
+	Variant *ProductVariant `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	// Any of "sku", "options" and "price". An empty mask updates all three;
+	// an unset price removes the override. Stock changes go through
+	// AdjustStock.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateVariantRequest) Reset() {
+	*x = UpdateVariantRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVariantRequest) ProtoMessage() {}
+
+func (x *UpdateVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVariantRequest.ProtoReflect.Descriptor instead.
+func (*UpdateVariantRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *UpdateVariantRequest) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+func (x *UpdateVariantRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *ProductVariant        `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateVariantResponse) Reset() {
+	*x = UpdateVariantResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVariantResponse) ProtoMessage() {}
+
+func (x *UpdateVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVariantResponse.ProtoReflect.Descriptor instead.
+func (*UpdateVariantResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *UpdateVariantResponse) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type DeleteVariantRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The variant must have no stock and no pending reservations. Its ledger
+	// entries and settled reservations are kept without the variant.
+	Id            uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVariantRequest) Reset() {
+	*x = DeleteVariantRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVariantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVariantRequest) ProtoMessage() {}
+
+func (x *DeleteVariantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVariantRequest.ProtoReflect.Descriptor instead.
+func (*DeleteVariantRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *DeleteVariantRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteVariantResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Variant       *ProductVariant        `protobuf:"bytes,1,opt,name=variant,proto3" json:"variant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteVariantResponse) Reset() {
+	*x = DeleteVariantResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteVariantResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteVariantResponse) ProtoMessage() {}
+
+func (x *DeleteVariantResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteVariantResponse.ProtoReflect.Descriptor instead.
+func (*DeleteVariantResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DeleteVariantResponse) GetVariant() *ProductVariant {
+	if x != nil {
+		return x.Variant
+	}
+	return nil
+}
+
+type AddProductMediaRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// The blob must already be in blob storage.
+	StorageKey     string `protobuf:"bytes,2,opt,name=storage_key,json=storageKey,proto3" json:"storage_key,omitempty"`
+	ContentType    string `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	ChecksumSha256 string `protobuf:"bytes,4,opt,name=checksum_sha256,json=checksumSha256,proto3" json:"checksum_sha256,omitempty"`
+	SizeBytes      uint64 `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Width          uint32 `protobuf:"varint,6,opt,name=width,proto3" json:"width,omitempty"`
+	Height         uint32 `protobuf:"varint,7,opt,name=height,proto3" json:"height,omitempty"`
+	AltText        string `protobuf:"bytes,8,opt,name=alt_text,json=altText,proto3" json:"alt_text,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AddProductMediaRequest) Reset() {
+	*x = AddProductMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProductMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProductMediaRequest) ProtoMessage() {}
+
+func (x *AddProductMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProductMediaRequest.ProtoReflect.Descriptor instead.
+func (*AddProductMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *AddProductMediaRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AddProductMediaRequest) GetStorageKey() string {
+	if x != nil {
+		return x.StorageKey
+	}
+	return ""
+}
+
+func (x *AddProductMediaRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *AddProductMediaRequest) GetChecksumSha256() string {
+	if x != nil {
+		return x.ChecksumSha256
+	}
+	return ""
+}
+
+func (x *AddProductMediaRequest) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *AddProductMediaRequest) GetWidth() uint32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *AddProductMediaRequest) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *AddProductMediaRequest) GetAltText() string {
+	if x != nil {
+		return x.AltText
+	}
+	return ""
+}
+
+type AddProductMediaResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Placed after the product's existing media.
+	Media         *ProductMedia `protobuf:"bytes,1,opt,name=media,proto3" json:"media,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddProductMediaResponse) Reset() {
+	*x = AddProductMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddProductMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddProductMediaResponse) ProtoMessage() {}
+
+func (x *AddProductMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddProductMediaResponse.ProtoReflect.Descriptor instead.
+func (*AddProductMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *AddProductMediaResponse) GetMedia() *ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+type UpdateProductMediaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Media carrying the new values; id and product_id select the media to
+	// update.
+	Media *ProductMedia `protobuf:"bytes,1,opt,name=media,proto3" json:"media,omitempty"`
+	// Only "alt_text" can be updated. An empty mask updates it.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductMediaRequest) Reset() {
+	*x = UpdateProductMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductMediaRequest) ProtoMessage() {}
+
+func (x *UpdateProductMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductMediaRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProductMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UpdateProductMediaRequest) GetMedia() *ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+func (x *UpdateProductMediaRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateProductMediaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Media         *ProductMedia          `protobuf:"bytes,1,opt,name=media,proto3" json:"media,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProductMediaResponse) Reset() {
+	*x = UpdateProductMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProductMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProductMediaResponse) ProtoMessage() {}
+
+func (x *UpdateProductMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProductMediaResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProductMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *UpdateProductMediaResponse) GetMedia() *ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+type ReorderProductMediaRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Every media id of the product, in the new display order.
+	MediaIds      []uint64 `protobuf:"varint,2,rep,packed,name=media_ids,json=mediaIds,proto3" json:"media_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReorderProductMediaRequest) Reset() {
+	*x = ReorderProductMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReorderProductMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReorderProductMediaRequest) ProtoMessage() {}
+
+func (x *ReorderProductMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReorderProductMediaRequest.ProtoReflect.Descriptor instead.
+func (*ReorderProductMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ReorderProductMediaRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ReorderProductMediaRequest) GetMediaIds() []uint64 {
+	if x != nil {
+		return x.MediaIds
+	}
+	return nil
+}
+
+type ReorderProductMediaResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In the new display order.
+	Media         []*ProductMedia `protobuf:"bytes,1,rep,name=media,proto3" json:"media,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReorderProductMediaResponse) Reset() {
+	*x = ReorderProductMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReorderProductMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReorderProductMediaResponse) ProtoMessage() {}
+
+func (x *ReorderProductMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReorderProductMediaResponse.ProtoReflect.Descriptor instead.
+func (*ReorderProductMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ReorderProductMediaResponse) GetMedia() []*ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+type DeleteProductMediaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	MediaId       uint64                 `protobuf:"varint,2,opt,name=media_id,json=mediaId,proto3" json:"media_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductMediaRequest) Reset() {
+	*x = DeleteProductMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductMediaRequest) ProtoMessage() {}
+
+func (x *DeleteProductMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductMediaRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DeleteProductMediaRequest) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *DeleteProductMediaRequest) GetMediaId() uint64 {
+	if x != nil {
+		return x.MediaId
+	}
+	return 0
+}
+
+type DeleteProductMediaResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The caller removes the blob from blob storage.
+	Media         *ProductMedia `protobuf:"bytes,1,opt,name=media,proto3" json:"media,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductMediaResponse) Reset() {
+	*x = DeleteProductMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductMediaResponse) ProtoMessage() {}
+
+func (x *DeleteProductMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductMediaResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DeleteProductMediaResponse) GetMedia() *ProductMedia {
+	if x != nil {
+		return x.Media
+	}
+	return nil
+}
+
+type ListPurgedMediaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Defaults to 100. Values above 1000 are rejected.
+	PageSize      uint32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPurgedMediaRequest) Reset() {
+	*x = ListPurgedMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPurgedMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPurgedMediaRequest) ProtoMessage() {}
+
+func (x *ListPurgedMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPurgedMediaRequest.ProtoReflect.Descriptor instead.
+func (*ListPurgedMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListPurgedMediaRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListPurgedMediaResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Storage keys of the blobs of media that went with a purged product,
+	// oldest first. They stay listed until ConfirmPurgedMedia removes them.
+	StorageKeys   []string `protobuf:"bytes,1,rep,name=storage_keys,json=storageKeys,proto3" json:"storage_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPurgedMediaResponse) Reset() {
+	*x = ListPurgedMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPurgedMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPurgedMediaResponse) ProtoMessage() {}
+
+func (x *ListPurgedMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPurgedMediaResponse.ProtoReflect.Descriptor instead.
+func (*ListPurgedMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ListPurgedMediaResponse) GetStorageKeys() []string {
+	if x != nil {
+		return x.StorageKeys
+	}
+	return nil
+}
+
+type ConfirmPurgedMediaRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Keys from ListPurgedMediaResponse whose blobs have been deleted.
+	StorageKeys   []string `protobuf:"bytes,1,rep,name=storage_keys,json=storageKeys,proto3" json:"storage_keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmPurgedMediaRequest) Reset() {
+	*x = ConfirmPurgedMediaRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmPurgedMediaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmPurgedMediaRequest) ProtoMessage() {}
+
+func (x *ConfirmPurgedMediaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmPurgedMediaRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmPurgedMediaRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ConfirmPurgedMediaRequest) GetStorageKeys() []string {
+	if x != nil {
+		return x.StorageKeys
+	}
+	return nil
+}
+
+type ConfirmPurgedMediaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Confirmed     uint64                 `protobuf:"varint,1,opt,name=confirmed,proto3" json:"confirmed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmPurgedMediaResponse) Reset() {
+	*x = ConfirmPurgedMediaResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmPurgedMediaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmPurgedMediaResponse) ProtoMessage() {}
+
+func (x *ConfirmPurgedMediaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmPurgedMediaResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmPurgedMediaResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ConfirmPurgedMediaResponse) GetConfirmed() uint64 {
+	if x != nil {
+		return x.Confirmed
+	}
+	return 0
+}
+
+type CreateCategoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional parent; unset creates a root category.
+	ParentId      uint64 `protobuf:"varint,1,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCategoryRequest) Reset() {
+	*x = CreateCategoryRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCategoryRequest) ProtoMessage() {}
+
+func (x *CreateCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCategoryRequest.ProtoReflect.Descriptor instead.
+func (*CreateCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *CreateCategoryRequest) GetParentId() uint64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateCategoryRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CreateCategoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *Category              `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCategoryResponse) Reset() {
+	*x = CreateCategoryResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCategoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCategoryResponse) ProtoMessage() {}
+
+func (x *CreateCategoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCategoryResponse.ProtoReflect.Descriptor instead.
+func (*CreateCategoryResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *CreateCategoryResponse) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+type GetCategoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCategoryRequest) Reset() {
+	*x = GetCategoryRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCategoryRequest) ProtoMessage() {}
+
+func (x *GetCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCategoryRequest.ProtoReflect.Descriptor instead.
+func (*GetCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *GetCategoryRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetCategoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *Category              `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCategoryResponse) Reset() {
+	*x = GetCategoryResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCategoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCategoryResponse) ProtoMessage() {}
+
+func (x *GetCategoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCategoryResponse.ProtoReflect.Descriptor instead.
+func (*GetCategoryResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *GetCategoryResponse) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+type ListCategoriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Lists the children of this category; unset lists the roots.
+	ParentId uint64 `protobuf:"varint,1,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// Defaults to 10. Values above 100 are rejected.
+	PageSize uint32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Opaque token from a previous ListCategoriesResponse.next_page_token.
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesRequest) Reset() {
+	*x = ListCategoriesRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesRequest) ProtoMessage() {}
+
+func (x *ListCategoriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesRequest.ProtoReflect.Descriptor instead.
+func (*ListCategoriesRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ListCategoriesRequest) GetParentId() uint64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *ListCategoriesRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCategoriesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListCategoriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Categories ordered by name, then id.
+	Categories    []*Category `protobuf:"bytes,1,rep,name=categories,proto3" json:"categories,omitempty"`
+	NextPageToken string      `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCategoriesResponse) Reset() {
+	*x = ListCategoriesResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCategoriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCategoriesResponse) ProtoMessage() {}
+
+func (x *ListCategoriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCategoriesResponse.ProtoReflect.Descriptor instead.
+func (*ListCategoriesResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *ListCategoriesResponse) GetCategories() []*Category {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *ListCategoriesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateCategoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Category carrying the new values; id selects the category to update.
+	Category *Category `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	// "name" and/or "parent_id". An empty mask updates both. Moving a
+	// category moves its whole subtree; it cannot move below itself.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCategoryRequest) Reset() {
+	*x = UpdateCategoryRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCategoryRequest) ProtoMessage() {}
+
+func (x *UpdateCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCategoryRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *UpdateCategoryRequest) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+func (x *UpdateCategoryRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateCategoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *Category              `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCategoryResponse) Reset() {
+	*x = UpdateCategoryResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCategoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCategoryResponse) ProtoMessage() {}
+
+func (x *UpdateCategoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCategoryResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCategoryResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *UpdateCategoryResponse) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+type DeleteCategoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The category must have no children. Products filed under it are
+	// unlinked, not deleted.
+	Id            uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCategoryRequest) Reset() {
+	*x = DeleteCategoryRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCategoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCategoryRequest) ProtoMessage() {}
+
+func (x *DeleteCategoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCategoryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCategoryRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *DeleteCategoryRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteCategoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      *Category              `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCategoryResponse) Reset() {
+	*x = DeleteCategoryResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCategoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCategoryResponse) ProtoMessage() {}
+
+func (x *DeleteCategoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCategoryResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCategoryResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *DeleteCategoryResponse) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+type StockReservation struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Set when the stock is held on a variant of the product.
+	VariantId     uint64                 `protobuf:"varint,8,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	Quantity      uint32                 `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Status        ReservationStatus      `protobuf:"varint,4,opt,name=status,proto3,enum=products.v1.ReservationStatus" json:"status,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockReservation) Reset() {
+	*x = StockReservation{}
+	mi := &file_products_v1_products_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockReservation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockReservation) ProtoMessage() {}
+
+func (x *StockReservation) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockReservation.ProtoReflect.Descriptor instead.
+func (*StockReservation) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *StockReservation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StockReservation) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *StockReservation) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+func (x *StockReservation) GetQuantity() uint32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StockReservation) GetStatus() ReservationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ReservationStatus_RESERVATION_STATUS_UNSPECIFIED
+}
+
+func (x *StockReservation) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *StockReservation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *StockReservation) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ReserveStockRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity  uint32                 `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// How long to hold the stock; defaults to the server's configured TTL.
+	Ttl *durationpb.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Holds stock of this variant of the product instead of the product's
+	// own stock.
+	VariantId     uint64 `protobuf:"varint,4,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockRequest) Reset() {
+	*x = ReserveStockRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockRequest) ProtoMessage() {}
+
+func (x *ReserveStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockRequest.ProtoReflect.Descriptor instead.
+func (*ReserveStockRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ReserveStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetQuantity() uint32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReserveStockRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *ReserveStockRequest) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+type ReserveStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservation   *StockReservation      `protobuf:"bytes,1,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReserveStockResponse) Reset() {
+	*x = ReserveStockResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveStockResponse) ProtoMessage() {}
+
+func (x *ReserveStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveStockResponse.ProtoReflect.Descriptor instead.
+func (*ReserveStockResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *ReserveStockResponse) GetReservation() *StockReservation {
+	if x != nil {
+		return x.Reservation
+	}
+	return nil
+}
+
+type CommitReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId int64                  `protobuf:"varint,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationRequest) Reset() {
+	*x = CommitReservationRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationRequest) ProtoMessage() {}
+
+func (x *CommitReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationRequest.ProtoReflect.Descriptor instead.
+func (*CommitReservationRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *CommitReservationRequest) GetReservationId() int64 {
+	if x != nil {
+		return x.ReservationId
+	}
+	return 0
+}
+
+type CommitReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservation   *StockReservation      `protobuf:"bytes,1,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitReservationResponse) Reset() {
+	*x = CommitReservationResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitReservationResponse) ProtoMessage() {}
+
+func (x *CommitReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitReservationResponse.ProtoReflect.Descriptor instead.
+func (*CommitReservationResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *CommitReservationResponse) GetReservation() *StockReservation {
+	if x != nil {
+		return x.Reservation
+	}
+	return nil
+}
+
+type ReleaseReservationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReservationId int64                  `protobuf:"varint,1,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationRequest) Reset() {
+	*x = ReleaseReservationRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationRequest) ProtoMessage() {}
+
+func (x *ReleaseReservationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *ReleaseReservationRequest) GetReservationId() int64 {
+	if x != nil {
+		return x.ReservationId
+	}
+	return 0
+}
+
+type ReleaseReservationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reservation   *StockReservation      `protobuf:"bytes,1,opt,name=reservation,proto3" json:"reservation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseReservationResponse) Reset() {
+	*x = ReleaseReservationResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseReservationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseReservationResponse) ProtoMessage() {}
+
+func (x *ReleaseReservationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseReservationResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseReservationResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *ReleaseReservationResponse) GetReservation() *StockReservation {
+	if x != nil {
+		return x.Reservation
+	}
+	return nil
+}
+
+// StockMovement is one entry in a product's inventory ledger.
+type StockMovement struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Set when the movement changed the stock of a variant of the product.
+	VariantId uint64 `protobuf:"varint,8,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	// Signed change in on-hand quantity.
+	Delta  int32               `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	Reason StockMovementReason `protobuf:"varint,4,opt,name=reason,proto3,enum=products.v1.StockMovementReason" json:"reason,omitempty"`
+	// External correlation id, e.g. a purchase order or reservation.
+	Reference     string                 `protobuf:"bytes,5,opt,name=reference,proto3" json:"reference,omitempty"`
+	Note          string                 `protobuf:"bytes,6,opt,name=note,proto3" json:"note,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockMovement) Reset() {
+	*x = StockMovement{}
+	mi := &file_products_v1_products_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockMovement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockMovement) ProtoMessage() {}
+
+func (x *StockMovement) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockMovement.ProtoReflect.Descriptor instead.
+func (*StockMovement) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *StockMovement) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *StockMovement) GetProductId() uint64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *StockMovement) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+func (x *StockMovement) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *StockMovement) GetReason() StockMovementReason {
+	if x != nil {
+		return x.Reason
+	}
+	return StockMovementReason_STOCK_MOVEMENT_REASON_UNSPECIFIED
+}
+
+func (x *StockMovement) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *StockMovement) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *StockMovement) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type AdjustStockRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Receipts and returns must be positive, sales and shrinkage negative;
+	// corrections may go either way.
+	Delta     int32               `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	Reason    StockMovementReason `protobuf:"varint,3,opt,name=reason,proto3,enum=products.v1.StockMovementReason" json:"reason,omitempty"`
+	Reference string              `protobuf:"bytes,4,opt,name=reference,proto3" json:"reference,omitempty"`
+	Note      string              `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`
+	// Adjusts the stock of this variant of the product instead of the
+	// product's own stock.
+	VariantId     uint64 `protobuf:"varint,6,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockRequest) Reset() {
+	*x = AdjustStockRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockRequest) ProtoMessage() {}
+
+func (x *AdjustStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustStockRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *AdjustStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetReason() StockMovementReason {
+	if x != nil {
+		return x.Reason
+	}
+	return StockMovementReason_STOCK_MOVEMENT_REASON_UNSPECIFIED
+}
+
+func (x *AdjustStockRequest) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *AdjustStockRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *AdjustStockRequest) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+type AdjustStockResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Movement *StockMovement         `protobuf:"bytes,1,opt,name=movement,proto3" json:"movement,omitempty"`
+	// The stock quantity of the product, or of the variant, after the
+	// adjustment.
+	StockQuantity uint32 `protobuf:"varint,2,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockResponse) Reset() {
+	*x = AdjustStockResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockResponse) ProtoMessage() {}
+
+func (x *AdjustStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockResponse.ProtoReflect.Descriptor instead.
+func (*AdjustStockResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *AdjustStockResponse) GetMovement() *StockMovement {
+	if x != nil {
+		return x.Movement
+	}
+	return nil
+}
+
+func (x *AdjustStockResponse) GetStockQuantity() uint32 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+type ListStockMovementsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Restricts results to one reason when set.
+	Reason StockMovementReason `protobuf:"varint,2,opt,name=reason,proto3,enum=products.v1.StockMovementReason" json:"reason,omitempty"`
+	// Defaults to 10. Values above 100 are rejected.
+	PageSize  uint32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// Restricts results to one variant when set.
+	VariantId     uint64 `protobuf:"varint,5,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStockMovementsRequest) Reset() {
+	*x = ListStockMovementsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStockMovementsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStockMovementsRequest) ProtoMessage() {}
+
+func (x *ListStockMovementsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStockMovementsRequest.ProtoReflect.Descriptor instead.
+func (*ListStockMovementsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *ListStockMovementsRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ListStockMovementsRequest) GetReason() StockMovementReason {
+	if x != nil {
+		return x.Reason
+	}
+	return StockMovementReason_STOCK_MOVEMENT_REASON_UNSPECIFIED
+}
+
+func (x *ListStockMovementsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListStockMovementsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListStockMovementsRequest) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+type ListStockMovementsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first.
+	Movements     []*StockMovement `protobuf:"bytes,1,rep,name=movements,proto3" json:"movements,omitempty"`
+	NextPageToken string           `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStockMovementsResponse) Reset() {
+	*x = ListStockMovementsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStockMovementsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStockMovementsResponse) ProtoMessage() {}
+
+func (x *ListStockMovementsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStockMovementsResponse.ProtoReflect.Descriptor instead.
+func (*ListStockMovementsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ListStockMovementsResponse) GetMovements() []*StockMovement {
+	if x != nil {
+		return x.Movements
+	}
+	return nil
+}
+
+func (x *ListStockMovementsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ReconcileStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxResults    uint32                 `protobuf:"varint,1,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconcileStockRequest) Reset() {
+	*x = ReconcileStockRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconcileStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconcileStockRequest) ProtoMessage() {}
+
+func (x *ReconcileStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconcileStockRequest.ProtoReflect.Descriptor instead.
+func (*ReconcileStockRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *ReconcileStockRequest) GetMaxResults() uint32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+// StockDrift reports a product or variant whose stored quantity disagrees
+// with its ledger. Expected: stock_quantity + held_quantity ==
+// ledger_quantity.
+type StockDrift struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId uint64                 `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Set when the drift is in the stock of a variant of the product.
+	VariantId     uint64 `protobuf:"varint,6,opt,name=variant_id,json=variantId,proto3" json:"variant_id,omitempty"`
+	StockQuantity int64  `protobuf:"varint,2,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	// Stock held by pending reservations.
+	HeldQuantity int64 `protobuf:"varint,3,opt,name=held_quantity,json=heldQuantity,proto3" json:"held_quantity,omitempty"`
+	// Sum of the product's ledger deltas.
+	LedgerQuantity int64 `protobuf:"varint,4,opt,name=ledger_quantity,json=ledgerQuantity,proto3" json:"ledger_quantity,omitempty"`
+	// stock_quantity + held_quantity - ledger_quantity.
+	Drift         int64 `protobuf:"varint,5,opt,name=drift,proto3" json:"drift,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockDrift) Reset() {
+	*x = StockDrift{}
+	mi := &file_products_v1_products_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockDrift) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductRequest) ProtoMessage() {}
+func (*StockDrift) ProtoMessage() {}
 
-func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[1]
+func (x *StockDrift) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[77]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -154,40 +4828,75 @@ func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
-func (*GetProductRequest) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use StockDrift.ProtoReflect.Descriptor instead.
+func (*StockDrift) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{77}
 }
 
-func (x *GetProductRequest) GetId() int64 {
+func (x *StockDrift) GetProductId() uint64 {
 	if x != nil {
-		return x.Id
+		return x.ProductId
 	}
 	return 0
 }
 
-type GetProductResponse struct {
+func (x *StockDrift) GetVariantId() uint64 {
+	if x != nil {
+		return x.VariantId
+	}
+	return 0
+}
+
+func (x *StockDrift) GetStockQuantity() int64 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+func (x *StockDrift) GetHeldQuantity() int64 {
+	if x != nil {
+		return x.HeldQuantity
+	}
+	return 0
+}
+
+func (x *StockDrift) GetLedgerQuantity() int64 {
+	if x != nil {
+		return x.LedgerQuantity
+	}
+	return 0
+}
+
+func (x *StockDrift) GetDrift() int64 {
+	if x != nil {
+		return x.Drift
+	}
+	return 0
+}
+
+type ReconcileStockResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Drifts        []*StockDrift          `protobuf:"bytes,1,rep,name=drifts,proto3" json:"drifts,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductResponse) Reset() {
-	*x = GetProductResponse{}
-	mi := &file_products_v1_products_proto_msgTypes[2]
+func (x *ReconcileStockResponse) Reset() {
+	*x = ReconcileStockResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[78]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductResponse) String() string {
+func (x *ReconcileStockResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductResponse) ProtoMessage() {}
+func (*ReconcileStockResponse) ProtoMessage() {}
 
-func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[2]
+func (x *ReconcileStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[78]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -198,41 +4907,42 @@ func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
-func (*GetProductResponse) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ReconcileStockResponse.ProtoReflect.Descriptor instead.
+func (*ReconcileStockResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{78}
 }
 
-func (x *GetProductResponse) GetProduct() *Product {
+func (x *ReconcileStockResponse) GetDrifts() []*StockDrift {
 	if x != nil {
-		return x.Product
+		return x.Drifts
 	}
 	return nil
 }
 
-type ListProductsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	PageSize      uint32                 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
-	PageToken     uint32                 `protobuf:"varint,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+type WatchProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Cursor of the last event the client processed. Empty starts watching
+	// from the current time.
+	Cursor        string `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsRequest) Reset() {
-	*x = ListProductsRequest{}
-	mi := &file_products_v1_products_proto_msgTypes[3]
+func (x *WatchProductsRequest) Reset() {
+	*x = WatchProductsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[79]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsRequest) String() string {
+func (x *WatchProductsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsRequest) ProtoMessage() {}
+func (*WatchProductsRequest) ProtoMessage() {}
 
-func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[3]
+func (x *WatchProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[79]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -243,48 +4953,47 @@ func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
-func (*ListProductsRequest) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{3}
-}
-
-func (x *ListProductsRequest) GetPageSize() uint32 {
-	if x != nil {
-		return x.PageSize
-	}
-	return 0
+// Deprecated: Use WatchProductsRequest.ProtoReflect.Descriptor instead.
+func (*WatchProductsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{79}
 }
 
-func (x *ListProductsRequest) GetPageToken() uint32 {
+func (x *WatchProductsRequest) GetCursor() string {
 	if x != nil {
-		return x.PageToken
+		return x.Cursor
 	}
-	return 0
+	return ""
 }
 
-type ListProductsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
-	NextPageToken uint32                 `protobuf:"varint,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+// ProductEvent carries the latest state of a changed product. Changes made
+// between two polls are coalesced, so a client may see a single UPDATED
+// event for several writes.
+type ProductEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Type    ProductEventType       `protobuf:"varint,1,opt,name=type,proto3,enum=products.v1.ProductEventType" json:"type,omitempty"`
+	Product *Product               `protobuf:"bytes,2,opt,name=product,proto3" json:"product,omitempty"`
+	// Resume token; pass it as WatchProductsRequest.cursor to continue
+	// after this event.
+	Cursor        string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsResponse) Reset() {
-	*x = ListProductsResponse{}
-	mi := &file_products_v1_products_proto_msgTypes[4]
+func (x *ProductEvent) Reset() {
+	*x = ProductEvent{}
+	mi := &file_products_v1_products_proto_msgTypes[80]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsResponse) String() string {
+func (x *ProductEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsResponse) ProtoMessage() {}
+func (*ProductEvent) ProtoMessage() {}
 
-func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[4]
+func (x *ProductEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[80]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -295,51 +5004,70 @@ func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
-func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ProductEvent.ProtoReflect.Descriptor instead.
+func (*ProductEvent) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{80}
 }
 
-func (x *ListProductsResponse) GetProducts() []*Product {
+func (x *ProductEvent) GetType() ProductEventType {
 	if x != nil {
-		return x.Products
+		return x.Type
+	}
+	return ProductEventType_PRODUCT_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *ProductEvent) GetProduct() *Product {
+	if x != nil {
+		return x.Product
 	}
 	return nil
 }
 
-func (x *ListProductsResponse) GetNextPageToken() uint32 {
+func (x *ProductEvent) GetCursor() string {
 	if x != nil {
-		return x.NextPageToken
+		return x.Cursor
 	}
-	return 0
+	return ""
 }
 
-type CreateProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
-	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
-	StockQuantity uint32                 `protobuf:"varint,5,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+// ProductRevision is one change in a product's history. Every version of
+// a product has one, including versions that only changed its stock.
+type ProductRevision struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId uint64                 `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// The product's etag after the change.
+	Etag   string         `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	Action RevisionAction `protobuf:"varint,4,opt,name=action,proto3,enum=products.v1.RevisionAction" json:"action,omitempty"`
+	// The user-id of the caller that made the change.
+	Actor     string                 `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// The product before the change; unset for CREATE and BASELINE.
+	Before *Product `protobuf:"bytes,7,opt,name=before,proto3" json:"before,omitempty"`
+	After  *Product `protobuf:"bytes,8,opt,name=after,proto3" json:"after,omitempty"`
+	// Fields that differ between before and after, using UpdateProduct's
+	// update_mask paths plus deleted_at, category_ids and tags. Revisions
+	// recorded before categories and tags were kept never list those.
+	ChangedFields *fieldmaskpb.FieldMask `protobuf:"bytes,9,opt,name=changed_fields,json=changedFields,proto3" json:"changed_fields,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateProductRequest) Reset() {
-	*x = CreateProductRequest{}
-	mi := &file_products_v1_products_proto_msgTypes[5]
+func (x *ProductRevision) Reset() {
+	*x = ProductRevision{}
+	mi := &file_products_v1_products_proto_msgTypes[81]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateProductRequest) String() string {
+func (x *ProductRevision) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateProductRequest) ProtoMessage() {}
+func (*ProductRevision) ProtoMessage() {}
 
-func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[5]
+func (x *ProductRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[81]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -350,112 +5078,99 @@ func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
-func (*CreateProductRequest) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *CreateProductRequest) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
+// Deprecated: Use ProductRevision.ProtoReflect.Descriptor instead.
+func (*ProductRevision) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{81}
 }
 
-func (x *CreateProductRequest) GetDescription() string {
+func (x *ProductRevision) GetId() uint64 {
 	if x != nil {
-		return x.Description
+		return x.Id
 	}
-	return ""
+	return 0
 }
 
-func (x *CreateProductRequest) GetPrice() float64 {
+func (x *ProductRevision) GetProductId() uint64 {
 	if x != nil {
-		return x.Price
+		return x.ProductId
 	}
 	return 0
 }
 
-func (x *CreateProductRequest) GetCurrency() string {
+func (x *ProductRevision) GetEtag() string {
 	if x != nil {
-		return x.Currency
+		return x.Etag
 	}
 	return ""
 }
 
-func (x *CreateProductRequest) GetStockQuantity() uint32 {
+func (x *ProductRevision) GetAction() RevisionAction {
 	if x != nil {
-		return x.StockQuantity
+		return x.Action
 	}
-	return 0
-}
-
-type CreateProductResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return RevisionAction_REVISION_ACTION_UNSPECIFIED
 }
 
-func (x *CreateProductResponse) Reset() {
-	*x = CreateProductResponse{}
-	mi := &file_products_v1_products_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ProductRevision) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
 }
 
-func (x *CreateProductResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ProductRevision) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
 }
 
-func (*CreateProductResponse) ProtoMessage() {}
-
-func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[6]
+func (x *ProductRevision) GetBefore() *Product {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Before
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use CreateProductResponse.ProtoReflect.Descriptor instead.
-func (*CreateProductResponse) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{6}
+func (x *ProductRevision) GetAfter() *Product {
+	if x != nil {
+		return x.After
+	}
+	return nil
 }
 
-func (x *CreateProductResponse) GetProduct() *Product {
+func (x *ProductRevision) GetChangedFields() *fieldmaskpb.FieldMask {
 	if x != nil {
-		return x.Product
+		return x.ChangedFields
 	}
 	return nil
 }
 
-type DeleteProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+type ListProductRevisionsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ProductId int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Defaults to 10. Values above 100 are rejected.
+	PageSize      uint32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductRequest) Reset() {
-	*x = DeleteProductRequest{}
-	mi := &file_products_v1_products_proto_msgTypes[7]
+func (x *ListProductRevisionsRequest) Reset() {
+	*x = ListProductRevisionsRequest{}
+	mi := &file_products_v1_products_proto_msgTypes[82]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductRequest) String() string {
+func (x *ListProductRevisionsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductRequest) ProtoMessage() {}
+func (*ListProductRevisionsRequest) ProtoMessage() {}
 
-func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[7]
+func (x *ListProductRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[82]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -466,40 +5181,56 @@ func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
-func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ListProductRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{82}
 }
 
-func (x *DeleteProductRequest) GetId() int64 {
+func (x *ListProductRevisionsRequest) GetProductId() int64 {
 	if x != nil {
-		return x.Id
+		return x.ProductId
 	}
 	return 0
 }
 
-type DeleteProductResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+func (x *ListProductRevisionsRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListProductRevisionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListProductRevisionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first.
+	Revisions     []*ProductRevision `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	NextPageToken string             `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductResponse) Reset() {
-	*x = DeleteProductResponse{}
-	mi := &file_products_v1_products_proto_msgTypes[8]
+func (x *ListProductRevisionsResponse) Reset() {
+	*x = ListProductRevisionsResponse{}
+	mi := &file_products_v1_products_proto_msgTypes[83]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductResponse) String() string {
+func (x *ListProductRevisionsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductResponse) ProtoMessage() {}
+func (*ListProductRevisionsResponse) ProtoMessage() {}
 
-func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_products_v1_products_proto_msgTypes[8]
+func (x *ListProductRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_products_v1_products_proto_msgTypes[83]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -510,63 +5241,514 @@ func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
-func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
-	return file_products_v1_products_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ListProductRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_products_v1_products_proto_rawDescGZIP(), []int{83}
 }
 
-func (x *DeleteProductResponse) GetSuccess() bool {
+func (x *ListProductRevisionsResponse) GetRevisions() []*ProductRevision {
 	if x != nil {
-		return x.Success
+		return x.Revisions
 	}
-	return false
+	return nil
+}
+
+func (x *ListProductRevisionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
 }
 
 var File_products_v1_products_proto protoreflect.FileDescriptor
 
 const file_products_v1_products_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproducts/v1/products.proto\x12\vproducts.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9e\x02\n" +
+	"\x1aproducts/v1/products.proto\x12\vproducts.v1\x1a\x1egoogle/protobuf/duration.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1avalidate/v1/validate.proto\"\x87\x01\n" +
+	"\x05Money\x129\n" +
+	"\rcurrency_code\x18\x01 \x01(\tB\x14\xfa\xf7\x18\x10\b\x01\x12\f\"\n" +
+	"^[A-Z]{3}$R\fcurrencyCode\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12-\n" +
+	"\x05nanos\x18\x03 \x01(\x05B\x17\xfa\xf7\x18\x13\x1a\x11\x10\x81씣\xfc\xff\xff\xff\xff\x01 \xff\x93\xeb\xdc\x03R\x05nanos\"\xde\x04\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x14\n" +
-	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x1a\n" +
-	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12(\n" +
+	"\x05price\x18\v \x01(\v2\x12.products.v1.MoneyR\x05price\x12%\n" +
+	"\x0estock_quantity\x18\x06 \x01(\rR\rstockQuantity\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04etag\x18\t \x01(\tR\x04etag\x129\n" +
+	"\n" +
+	"deleted_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12@\n" +
+	"\rdisplay_price\x18\f \x01(\v2\x1b.products.v1.ConvertedPriceR\fdisplayPrice\x12!\n" +
+	"\fcategory_ids\x18\r \x03(\x04R\vcategoryIds\x12\x12\n" +
+	"\x04tags\x18\x0e \x03(\tR\x04tags\x127\n" +
+	"\bvariants\x18\x0f \x03(\v2\x1b.products.v1.ProductVariantR\bvariants\x12/\n" +
+	"\x05media\x18\x10 \x03(\v2\x19.products.v1.ProductMediaR\x05mediaJ\x04\b\x04\x10\x05J\x04\b\x05\x10\x06R\bcurrency\"\xfb\x02\n" +
+	"\fProductMedia\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12\x1f\n" +
+	"\vstorage_key\x18\x04 \x01(\tR\n" +
+	"storageKey\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12'\n" +
+	"\x0fchecksum_sha256\x18\x06 \x01(\tR\x0echecksumSha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\a \x01(\x04R\tsizeBytes\x12\x14\n" +
+	"\x05width\x18\b \x01(\rR\x05width\x12\x16\n" +
+	"\x06height\x18\t \x01(\rR\x06height\x12\x19\n" +
+	"\balt_text\x18\n" +
+	" \x01(\tR\aaltText\x12\x1a\n" +
+	"\bposition\x18\v \x01(\rR\bposition\x129\n" +
+	"\n" +
+	"created_at\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x98\x03\n" +
+	"\x0eProductVariant\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x10\n" +
+	"\x03sku\x18\x03 \x01(\tR\x03sku\x12B\n" +
+	"\aoptions\x18\x04 \x03(\v2(.products.v1.ProductVariant.OptionsEntryR\aoptions\x12(\n" +
+	"\x05price\x18\x05 \x01(\v2\x12.products.v1.MoneyR\x05price\x12%\n" +
 	"\x0estock_quantity\x18\x06 \x01(\rR\rstockQuantity\x129\n" +
 	"\n" +
 	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"#\n" +
-	"\x11GetProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"D\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd5\x01\n" +
+	"\bCategory\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\x04R\bparentId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xad\x01\n" +
+	"\fExchangeRate\x12#\n" +
+	"\rbase_currency\x18\x01 \x01(\tR\fbaseCurrency\x12%\n" +
+	"\x0equote_currency\x18\x02 \x01(\tR\rquoteCurrency\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\tR\x04rate\x12=\n" +
+	"\feffective_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\veffectiveAt\"i\n" +
+	"\x0eConvertedPrice\x12(\n" +
+	"\x05price\x18\x01 \x01(\v2\x12.products.v1.MoneyR\x05price\x12-\n" +
+	"\x04rate\x18\x02 \x01(\v2\x19.products.v1.ExchangeRateR\x04rate\"\x9f\x01\n" +
+	"\x11GetProductRequest\x12\x1a\n" +
+	"\x02id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\x02id\x12=\n" +
+	"\x10display_currency\x18\x02 \x01(\tB\x12\xfa\xf7\x18\x0e\x12\f\"\n" +
+	"^[A-Z]{3}$R\x0fdisplayCurrency\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"D\n" +
 	"\x12GetProductResponse\x12.\n" +
-	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"Q\n" +
-	"\x13ListProductsRequest\x12\x1b\n" +
-	"\tpage_size\x18\x01 \x01(\rR\bpageSize\x12\x1d\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"\xaf\x02\n" +
+	"\rProductFilter\x12.\n" +
+	"\bcurrency\x18\x01 \x01(\tB\x12\xfa\xf7\x18\x0e\x12\f\"\n" +
+	"^[A-Z]{3}$R\bcurrency\x12/\n" +
+	"\tmin_price\x18\x06 \x01(\v2\x12.products.v1.MoneyR\bminPrice\x12/\n" +
+	"\tmax_price\x18\a \x01(\v2\x12.products.v1.MoneyR\bmaxPrice\x12\x1f\n" +
+	"\vname_prefix\x18\x04 \x01(\tR\n" +
+	"namePrefix\x12\"\n" +
+	"\rin_stock_only\x18\x05 \x01(\bR\vinStockOnly\x12\x1f\n" +
+	"\vcategory_id\x18\b \x01(\x04R\n" +
+	"categoryId\x12\x1a\n" +
+	"\x03tag\x18\t \x01(\tB\b\xfa\xf7\x18\x04\x12\x02\x18@R\x03tagJ\x04\b\x02\x10\x03J\x04\b\x03\x10\x04\"\x92\x02\n" +
+	"\x13ListProductsRequest\x12%\n" +
+	"\tpage_size\x18\x01 \x01(\rB\b\xfa\xf7\x18\x04\x1a\x02 dR\bpageSize\x12\x1d\n" +
 	"\n" +
-	"page_token\x18\x02 \x01(\rR\tpageToken\"p\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x122\n" +
+	"\x06filter\x18\x04 \x01(\v2\x1a.products.v1.ProductFilterR\x06filter\x12\x19\n" +
+	"\border_by\x18\x05 \x01(\tR\aorderBy\x12!\n" +
+	"\fshow_deleted\x18\x06 \x01(\bR\vshowDeleted\x12=\n" +
+	"\x10display_currency\x18\a \x01(\tB\x12\xfa\xf7\x18\x0e\x12\f\"\n" +
+	"^[A-Z]{3}$R\x0fdisplayCurrencyJ\x04\b\x02\x10\x03\"v\n" +
 	"\x14ListProductsResponse\x120\n" +
 	"\bproducts\x18\x01 \x03(\v2\x14.products.v1.ProductR\bproducts\x12&\n" +
-	"\x0fnext_page_token\x18\x02 \x01(\rR\rnextPageToken\"\xa5\x01\n" +
-	"\x14CreateProductRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
-	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x14\n" +
-	"\x05price\x18\x03 \x01(\x01R\x05price\x12\x1a\n" +
-	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12%\n" +
-	"\x0estock_quantity\x18\x05 \x01(\rR\rstockQuantity\"G\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageTokenJ\x04\b\x02\x10\x03\"\x82\x01\n" +
+	"\x15SearchProductsRequest\x12#\n" +
+	"\x05query\x18\x01 \x01(\tB\r\xfa\xf7\x18\t\b\x01\x12\x05\x18\x80\x02(\x01R\x05query\x12%\n" +
+	"\tpage_size\x18\x02 \x01(\rB\b\xfa\xf7\x18\x04\x1a\x02 dR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xa8\x01\n" +
+	"\fSearchResult\x12.\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12!\n" +
+	"\fname_snippet\x18\x03 \x01(\tR\vnameSnippet\x12/\n" +
+	"\x13description_snippet\x18\x04 \x01(\tR\x12descriptionSnippet\"u\n" +
+	"\x16SearchProductsResponse\x123\n" +
+	"\aresults\x18\x01 \x03(\v2\x19.products.v1.SearchResultR\aresults\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"]\n" +
+	"\x16SuggestProductsRequest\x12\"\n" +
+	"\x06prefix\x18\x01 \x01(\tB\n" +
+	"\xfa\xf7\x18\x06\b\x01\x12\x02(\x01R\x06prefix\x12\x1f\n" +
+	"\vmax_results\x18\x02 \x01(\rR\n" +
+	"maxResults\"7\n" +
+	"\x11ProductSuggestion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"[\n" +
+	"\x17SuggestProductsResponse\x12@\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\x1e.products.v1.ProductSuggestionR\vsuggestions\"\xb0\x02\n" +
+	"\x14CreateProductRequest\x12\x1f\n" +
+	"\x04name\x18\x01 \x01(\tB\v\xfa\xf7\x18\a\b\x01\x12\x03\x10\xc8\x01R\x04name\x12+\n" +
+	"\vdescription\x18\x02 \x01(\tB\t\xfa\xf7\x18\x05\x12\x03\x10\x88'R\vdescription\x124\n" +
+	"\x05price\x18\x06 \x01(\v2\x12.products.v1.MoneyB\n" +
+	"\xfa\xf7\x18\x06\b\x01\"\x02\b\x01R\x05price\x12%\n" +
+	"\x0estock_quantity\x18\x05 \x01(\rR\rstockQuantity\x12/\n" +
+	"\fcategory_ids\x18\a \x03(\x04B\f\xfa\xf7\x18\b2\x06\x10 \x1a\x02\b\x01R\vcategoryIds\x12&\n" +
+	"\x04tags\x18\b \x03(\tB\x12\xfa\xf7\x18\x0e2\f\x10 \x1a\b\b\x01\x12\x04\x18@(\x01R\x04tagsJ\x04\b\x03\x10\x04J\x04\b\x04\x10\x05R\bcurrency\"G\n" +
 	"\x15CreateProductResponse\x12.\n" +
-	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"&\n" +
-	"\x14DeleteProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"1\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"?\n" +
+	"\x17BatchGetProductsRequest\x12$\n" +
+	"\x03ids\x18\x01 \x03(\x03B\x12\xfa\xf7\x18\x0e\b\x012\n" +
+	"\x10d\x1a\x06\b\x01\x1a\x02\b\x00R\x03ids\"m\n" +
+	"\x18BatchGetProductsResponse\x120\n" +
+	"\bproducts\x18\x01 \x03(\v2\x14.products.v1.ProductR\bproducts\x12\x1f\n" +
+	"\vmissing_ids\x18\x02 \x03(\x03R\n" +
+	"missingIds\"g\n" +
+	"\x1aBatchCreateProductsRequest\x12I\n" +
+	"\brequests\x18\x01 \x03(\v2!.products.v1.CreateProductRequestB\n" +
+	"\xfa\xf7\x18\x06\b\x012\x02\x10dR\brequests\"O\n" +
+	"\x1bBatchCreateProductsResponse\x120\n" +
+	"\bproducts\x18\x01 \x03(\v2\x14.products.v1.ProductR\bproducts\"\xfe\x01\n" +
+	"\x14UpdateProductRequest\x12\xa8\x01\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductBx\xfa\xf7\x18t\b\x01:p\n" +
+	"\x18\n" +
+	"\fcategory_ids\x12\b2\x06\x10 \x1a\x02\b\x01\n" +
+	"\x14\n" +
+	"\vdescription\x12\x05\x12\x03\x10\x88'\n" +
+	"\b\n" +
+	"\x02id\x12\x02\b\x01\n" +
+	"\r\n" +
+	"\x04name\x12\x05\x12\x03\x10\xc8\x01\n" +
+	"\r\n" +
+	"\x05price\x12\x04\"\x02\b\x01\n" +
+	"\x16\n" +
+	"\x04tags\x12\x0e2\f\x10 \x1a\b\b\x01\x12\x04\x18@(\x01R\aproduct\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"G\n" +
+	"\x15UpdateProductResponse\x12.\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"F\n" +
+	"\x14DeleteProductRequest\x12\x1a\n" +
+	"\x02id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\x02id\x12\x12\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\"a\n" +
 	"\x15DeleteProductResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xe4\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12.\n" +
+	"\aproduct\x18\x02 \x01(\v2\x14.products.v1.ProductR\aproduct\"H\n" +
+	"\x16UndeleteProductRequest\x12\x1a\n" +
+	"\x02id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\x02id\x12\x12\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\"I\n" +
+	"\x17UndeleteProductResponse\x12.\n" +
+	"\aproduct\x18\x01 \x01(\v2\x14.products.v1.ProductR\aproduct\"J\n" +
+	"\x19UpsertExchangeRateRequest\x12-\n" +
+	"\x04rate\x18\x01 \x01(\v2\x19.products.v1.ExchangeRateR\x04rate\"K\n" +
+	"\x1aUpsertExchangeRateResponse\x12-\n" +
+	"\x04rate\x18\x01 \x01(\v2\x19.products.v1.ExchangeRateR\x04rate\"\xdd\x02\n" +
+	"\x14CreateVariantRequest\x12%\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\tproductId\x12=\n" +
+	"\x03sku\x18\x02 \x01(\tB+\xfa\xf7\x18'\b\x01\x12#\"!^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$R\x03sku\x12H\n" +
+	"\aoptions\x18\x03 \x03(\v2..products.v1.CreateVariantRequest.OptionsEntryR\aoptions\x122\n" +
+	"\x05price\x18\x04 \x01(\v2\x12.products.v1.MoneyB\b\xfa\xf7\x18\x04\"\x02\b\x01R\x05price\x12%\n" +
+	"\x0estock_quantity\x18\x05 \x01(\rR\rstockQuantity\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"N\n" +
+	"\x15CreateVariantResponse\x125\n" +
+	"\avariant\x18\x01 \x01(\v2\x1b.products.v1.ProductVariantR\avariant\"<\n" +
+	"\x13ListVariantsRequest\x12%\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\tproductId\"O\n" +
+	"\x14ListVariantsResponse\x127\n" +
+	"\bvariants\x18\x01 \x03(\v2\x1b.products.v1.ProductVariantR\bvariants\"2\n" +
+	"\x16GetVariantBySkuRequest\x12\x18\n" +
+	"\x03sku\x18\x01 \x01(\tB\x06\xfa\xf7\x18\x02\b\x01R\x03sku\"\x80\x01\n" +
+	"\x17GetVariantBySkuResponse\x125\n" +
+	"\avariant\x18\x01 \x01(\v2\x1b.products.v1.ProductVariantR\avariant\x12.\n" +
+	"\aproduct\x18\x02 \x01(\v2\x14.products.v1.ProductR\aproduct\"\xdc\x01\n" +
+	"\x14UpdateVariantRequest\x12\x86\x01\n" +
+	"\avariant\x18\x01 \x01(\v2\x1b.products.v1.ProductVariantBO\xfa\xf7\x18K\b\x01:G\n" +
+	"\b\n" +
+	"\x02id\x12\x02\b\x01\n" +
+	"\r\n" +
+	"\x05price\x12\x04\"\x02\b\x01\n" +
+	",\n" +
+	"\x03sku\x12%\x12#\"!^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$R\avariant\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"N\n" +
+	"\x15UpdateVariantResponse\x125\n" +
+	"\avariant\x18\x01 \x01(\v2\x1b.products.v1.ProductVariantR\avariant\".\n" +
+	"\x14DeleteVariantRequest\x12\x16\n" +
+	"\x02id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\x02id\"N\n" +
+	"\x15DeleteVariantResponse\x125\n" +
+	"\avariant\x18\x01 \x01(\v2\x1b.products.v1.ProductVariantR\avariant\"\xc4\x02\n" +
+	"\x16AddProductMediaRequest\x12%\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\tproductId\x12,\n" +
+	"\vstorage_key\x18\x02 \x01(\tB\v\xfa\xf7\x18\a\b\x01\x12\x03\x18\x80\x04R\n" +
+	"storageKey\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12?\n" +
+	"\x0fchecksum_sha256\x18\x04 \x01(\tB\x16\xfa\xf7\x18\x12\x12\x10\"\x0e^[0-9a-f]{64}$R\x0echecksumSha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x04R\tsizeBytes\x12\x14\n" +
+	"\x05width\x18\x06 \x01(\rR\x05width\x12\x16\n" +
+	"\x06height\x18\a \x01(\rR\x06height\x12$\n" +
+	"\balt_text\x18\b \x01(\tB\t\xfa\xf7\x18\x05\x12\x03\x18\x80\x04R\aaltText\"J\n" +
+	"\x17AddProductMediaResponse\x12/\n" +
+	"\x05media\x18\x01 \x01(\v2\x19.products.v1.ProductMediaR\x05media\"\xc2\x01\n" +
+	"\x19UpdateProductMediaRequest\x12h\n" +
+	"\x05media\x18\x01 \x01(\v2\x19.products.v1.ProductMediaB7\xfa\xf7\x183\b\x01:/\n" +
+	"\x11\n" +
+	"\balt_text\x12\x05\x12\x03\x18\x80\x04\n" +
+	"\b\n" +
+	"\x02id\x12\x02\b\x01\n" +
+	"\x10\n" +
+	"\n" +
+	"product_id\x12\x02\b\x01R\x05media\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"M\n" +
+	"\x1aUpdateProductMediaResponse\x12/\n" +
+	"\x05media\x18\x01 \x01(\v2\x19.products.v1.ProductMediaR\x05media\"j\n" +
+	"\x1aReorderProductMediaRequest\x12%\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\tproductId\x12%\n" +
+	"\tmedia_ids\x18\x02 \x03(\x04B\b\xfa\xf7\x18\x042\x02\x10\x14R\bmediaIds\"N\n" +
+	"\x1bReorderProductMediaResponse\x12/\n" +
+	"\x05media\x18\x01 \x03(\v2\x19.products.v1.ProductMediaR\x05media\"e\n" +
+	"\x19DeleteProductMediaRequest\x12%\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\tproductId\x12!\n" +
+	"\bmedia_id\x18\x02 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\amediaId\"M\n" +
+	"\x1aDeleteProductMediaResponse\x12/\n" +
+	"\x05media\x18\x01 \x01(\v2\x19.products.v1.ProductMediaR\x05media\"@\n" +
+	"\x16ListPurgedMediaRequest\x12&\n" +
+	"\tpage_size\x18\x01 \x01(\rB\t\xfa\xf7\x18\x05\x1a\x03 \xe8\aR\bpageSize\"<\n" +
+	"\x17ListPurgedMediaResponse\x12!\n" +
+	"\fstorage_keys\x18\x01 \x03(\tR\vstorageKeys\"I\n" +
+	"\x19ConfirmPurgedMediaRequest\x12,\n" +
+	"\fstorage_keys\x18\x01 \x03(\tB\t\xfa\xf7\x18\x052\x03\x10\xe8\aR\vstorageKeys\":\n" +
+	"\x1aConfirmPurgedMediaResponse\x12\x1c\n" +
+	"\tconfirmed\x18\x01 \x01(\x04R\tconfirmed\"W\n" +
+	"\x15CreateCategoryRequest\x12\x1b\n" +
+	"\tparent_id\x18\x01 \x01(\x04R\bparentId\x12!\n" +
+	"\x04name\x18\x02 \x01(\tB\r\xfa\xf7\x18\t\b\x01\x12\x05\x10\xc8\x01(\x01R\x04name\"K\n" +
+	"\x16CreateCategoryResponse\x121\n" +
+	"\bcategory\x18\x01 \x01(\v2\x15.products.v1.CategoryR\bcategory\",\n" +
+	"\x12GetCategoryRequest\x12\x16\n" +
+	"\x02id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\x02id\"H\n" +
+	"\x13GetCategoryResponse\x121\n" +
+	"\bcategory\x18\x01 \x01(\v2\x15.products.v1.CategoryR\bcategory\"z\n" +
+	"\x15ListCategoriesRequest\x12\x1b\n" +
+	"\tparent_id\x18\x01 \x01(\x04R\bparentId\x12%\n" +
+	"\tpage_size\x18\x02 \x01(\rB\b\xfa\xf7\x18\x04\x1a\x02 dR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"w\n" +
+	"\x16ListCategoriesResponse\x125\n" +
+	"\n" +
+	"categories\x18\x01 \x03(\v2\x15.products.v1.CategoryR\n" +
+	"categories\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xac\x01\n" +
+	"\x15UpdateCategoryRequest\x12V\n" +
+	"\bcategory\x18\x01 \x01(\v2\x15.products.v1.CategoryB#\xfa\xf7\x18\x1f\b\x01:\x1b\n" +
+	"\b\n" +
+	"\x02id\x12\x02\b\x01\n" +
+	"\x0f\n" +
+	"\x04name\x12\a\x12\x05\x10\xc8\x01(\x01R\bcategory\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"K\n" +
+	"\x16UpdateCategoryResponse\x121\n" +
+	"\bcategory\x18\x01 \x01(\v2\x15.products.v1.CategoryR\bcategory\"/\n" +
+	"\x15DeleteCategoryRequest\x12\x16\n" +
+	"\x02id\x18\x01 \x01(\x04B\x06\xfa\xf7\x18\x02\b\x01R\x02id\"K\n" +
+	"\x16DeleteCategoryResponse\x121\n" +
+	"\bcategory\x18\x01 \x01(\v2\x15.products.v1.CategoryR\bcategory\"\xe5\x02\n" +
+	"\x10StockReservation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\b \x01(\x04R\tvariantId\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\rR\bquantity\x126\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1e.products.v1.ReservationStatusR\x06status\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xb0\x01\n" +
+	"\x13ReserveStockRequest\x12)\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\tproductId\x12\"\n" +
+	"\bquantity\x18\x02 \x01(\rB\x06\xfa\xf7\x18\x02\b\x01R\bquantity\x12+\n" +
+	"\x03ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x04 \x01(\x04R\tvariantId\"W\n" +
+	"\x14ReserveStockResponse\x12?\n" +
+	"\vreservation\x18\x01 \x01(\v2\x1d.products.v1.StockReservationR\vreservation\"M\n" +
+	"\x18CommitReservationRequest\x121\n" +
+	"\x0ereservation_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\rreservationId\"\\\n" +
+	"\x19CommitReservationResponse\x12?\n" +
+	"\vreservation\x18\x01 \x01(\v2\x1d.products.v1.StockReservationR\vreservation\"N\n" +
+	"\x19ReleaseReservationRequest\x121\n" +
+	"\x0ereservation_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\rreservationId\"]\n" +
+	"\x1aReleaseReservationResponse\x12?\n" +
+	"\vreservation\x18\x01 \x01(\v2\x1d.products.v1.StockReservationR\vreservation\"\x9a\x02\n" +
+	"\rStockMovement\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\b \x01(\x04R\tvariantId\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\x05R\x05delta\x128\n" +
+	"\x06reason\x18\x04 \x01(\x0e2 .products.v1.StockMovementReasonR\x06reason\x12\x1c\n" +
+	"\treference\x18\x05 \x01(\tR\treference\x12\x12\n" +
+	"\x04note\x18\x06 \x01(\tR\x04note\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xf4\x01\n" +
+	"\x12AdjustStockRequest\x12)\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\tproductId\x12\x1c\n" +
+	"\x05delta\x18\x02 \x01(\x05B\x06\xfa\xf7\x18\x02\b\x01R\x05delta\x12D\n" +
+	"\x06reason\x18\x03 \x01(\x0e2 .products.v1.StockMovementReasonB\n" +
+	"\xfa\xf7\x18\x06\b\x01*\x02\b\x01R\x06reason\x12\x1c\n" +
+	"\treference\x18\x04 \x01(\tR\treference\x12\x12\n" +
+	"\x04note\x18\x05 \x01(\tR\x04note\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x06 \x01(\x04R\tvariantId\"t\n" +
+	"\x13AdjustStockResponse\x126\n" +
+	"\bmovement\x18\x01 \x01(\v2\x1a.products.v1.StockMovementR\bmovement\x12%\n" +
+	"\x0estock_quantity\x18\x02 \x01(\rR\rstockQuantity\"\xef\x01\n" +
+	"\x19ListStockMovementsRequest\x12)\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\tproductId\x12B\n" +
+	"\x06reason\x18\x02 \x01(\x0e2 .products.v1.StockMovementReasonB\b\xfa\xf7\x18\x04*\x02\b\x01R\x06reason\x12%\n" +
+	"\tpage_size\x18\x03 \x01(\rB\b\xfa\xf7\x18\x04\x1a\x02 dR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x05 \x01(\x04R\tvariantId\"~\n" +
+	"\x1aListStockMovementsResponse\x128\n" +
+	"\tmovements\x18\x01 \x03(\v2\x1a.products.v1.StockMovementR\tmovements\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"8\n" +
+	"\x15ReconcileStockRequest\x12\x1f\n" +
+	"\vmax_results\x18\x01 \x01(\rR\n" +
+	"maxResults\"\xd5\x01\n" +
+	"\n" +
+	"StockDrift\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x04R\tproductId\x12\x1d\n" +
+	"\n" +
+	"variant_id\x18\x06 \x01(\x04R\tvariantId\x12%\n" +
+	"\x0estock_quantity\x18\x02 \x01(\x03R\rstockQuantity\x12#\n" +
+	"\rheld_quantity\x18\x03 \x01(\x03R\fheldQuantity\x12'\n" +
+	"\x0fledger_quantity\x18\x04 \x01(\x03R\x0eledgerQuantity\x12\x14\n" +
+	"\x05drift\x18\x05 \x01(\x03R\x05drift\"I\n" +
+	"\x16ReconcileStockResponse\x12/\n" +
+	"\x06drifts\x18\x01 \x03(\v2\x17.products.v1.StockDriftR\x06drifts\".\n" +
+	"\x14WatchProductsRequest\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\"\x89\x01\n" +
+	"\fProductEvent\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.products.v1.ProductEventTypeR\x04type\x12.\n" +
+	"\aproduct\x18\x02 \x01(\v2\x14.products.v1.ProductR\aproduct\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"\xf7\x02\n" +
+	"\x0fProductRevision\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x04R\tproductId\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\x123\n" +
+	"\x06action\x18\x04 \x01(\x0e2\x1b.products.v1.RevisionActionR\x06action\x12\x14\n" +
+	"\x05actor\x18\x05 \x01(\tR\x05actor\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12,\n" +
+	"\x06before\x18\a \x01(\v2\x14.products.v1.ProductR\x06before\x12*\n" +
+	"\x05after\x18\b \x01(\v2\x14.products.v1.ProductR\x05after\x12A\n" +
+	"\x0echanged_fields\x18\t \x01(\v2\x1a.google.protobuf.FieldMaskR\rchangedFields\"\x8e\x01\n" +
+	"\x1bListProductRevisionsRequest\x12)\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03B\n" +
+	"\xfa\xf7\x18\x06\b\x01\x1a\x02\b\x00R\tproductId\x12%\n" +
+	"\tpage_size\x18\x02 \x01(\rB\b\xfa\xf7\x18\x04\x1a\x02 dR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\x82\x01\n" +
+	"\x1cListProductRevisionsResponse\x12:\n" +
+	"\trevisions\x18\x01 \x03(\v2\x1c.products.v1.ProductRevisionR\trevisions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken*\xba\x01\n" +
+	"\x11ReservationStatus\x12\"\n" +
+	"\x1eRESERVATION_STATUS_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aRESERVATION_STATUS_PENDING\x10\x01\x12 \n" +
+	"\x1cRESERVATION_STATUS_COMMITTED\x10\x02\x12\x1f\n" +
+	"\x1bRESERVATION_STATUS_RELEASED\x10\x03\x12\x1e\n" +
+	"\x1aRESERVATION_STATUS_EXPIRED\x10\x04*\xec\x01\n" +
+	"\x13StockMovementReason\x12%\n" +
+	"!STOCK_MOVEMENT_REASON_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dSTOCK_MOVEMENT_REASON_RECEIPT\x10\x01\x12\x1e\n" +
+	"\x1aSTOCK_MOVEMENT_REASON_SALE\x10\x02\x12 \n" +
+	"\x1cSTOCK_MOVEMENT_REASON_RETURN\x10\x03\x12#\n" +
+	"\x1fSTOCK_MOVEMENT_REASON_SHRINKAGE\x10\x04\x12$\n" +
+	" STOCK_MOVEMENT_REASON_CORRECTION\x10\x05*\xb5\x01\n" +
+	"\x10ProductEventType\x12\"\n" +
+	"\x1ePRODUCT_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_CREATED\x10\x01\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_UPDATED\x10\x02\x12\x1e\n" +
+	"\x1aPRODUCT_EVENT_TYPE_DELETED\x10\x03\x12\x1d\n" +
+	"\x19PRODUCT_EVENT_TYPE_PURGED\x10\x04*\xf7\x01\n" +
+	"\x0eRevisionAction\x12\x1f\n" +
+	"\x1bREVISION_ACTION_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16REVISION_ACTION_CREATE\x10\x01\x12\x1a\n" +
+	"\x16REVISION_ACTION_UPDATE\x10\x02\x12\x1a\n" +
+	"\x16REVISION_ACTION_DELETE\x10\x03\x12\x1c\n" +
+	"\x18REVISION_ACTION_UNDELETE\x10\x04\x12\x1c\n" +
+	"\x18REVISION_ACTION_BASELINE\x10\x05\x12\x19\n" +
+	"\x15REVISION_ACTION_PURGE\x10\x06\x12\x19\n" +
+	"\x15REVISION_ACTION_STOCK\x10\a2\xcb\x19\n" +
 	"\x0eProductService\x12M\n" +
 	"\n" +
 	"GetProduct\x12\x1e.products.v1.GetProductRequest\x1a\x1f.products.v1.GetProductResponse\x12S\n" +
-	"\fListProducts\x12 .products.v1.ListProductsRequest\x1a!.products.v1.ListProductsResponse\x12V\n" +
-	"\rCreateProduct\x12!.products.v1.CreateProductRequest\x1a\".products.v1.CreateProductResponse\x12V\n" +
-	"\rDeleteProduct\x12!.products.v1.DeleteProductRequest\x1a\".products.v1.DeleteProductResponseB\xaa\x01\n" +
+	"\fListProducts\x12 .products.v1.ListProductsRequest\x1a!.products.v1.ListProductsResponse\x12O\n" +
+	"\rWatchProducts\x12!.products.v1.WatchProductsRequest\x1a\x19.products.v1.ProductEvent0\x01\x12Y\n" +
+	"\x0eSearchProducts\x12\".products.v1.SearchProductsRequest\x1a#.products.v1.SearchProductsResponse\x12\\\n" +
+	"\x0fSuggestProducts\x12#.products.v1.SuggestProductsRequest\x1a$.products.v1.SuggestProductsResponse\x12V\n" +
+	"\rCreateProduct\x12!.products.v1.CreateProductRequest\x1a\".products.v1.CreateProductResponse\x12_\n" +
+	"\x10BatchGetProducts\x12$.products.v1.BatchGetProductsRequest\x1a%.products.v1.BatchGetProductsResponse\x12h\n" +
+	"\x13BatchCreateProducts\x12'.products.v1.BatchCreateProductsRequest\x1a(.products.v1.BatchCreateProductsResponse\x12V\n" +
+	"\rUpdateProduct\x12!.products.v1.UpdateProductRequest\x1a\".products.v1.UpdateProductResponse\x12V\n" +
+	"\rDeleteProduct\x12!.products.v1.DeleteProductRequest\x1a\".products.v1.DeleteProductResponse\x12\\\n" +
+	"\x0fUndeleteProduct\x12#.products.v1.UndeleteProductRequest\x1a$.products.v1.UndeleteProductResponse\x12k\n" +
+	"\x14ListProductRevisions\x12(.products.v1.ListProductRevisionsRequest\x1a).products.v1.ListProductRevisionsResponse\x12S\n" +
+	"\fReserveStock\x12 .products.v1.ReserveStockRequest\x1a!.products.v1.ReserveStockResponse\x12b\n" +
+	"\x11CommitReservation\x12%.products.v1.CommitReservationRequest\x1a&.products.v1.CommitReservationResponse\x12e\n" +
+	"\x12ReleaseReservation\x12&.products.v1.ReleaseReservationRequest\x1a'.products.v1.ReleaseReservationResponse\x12P\n" +
+	"\vAdjustStock\x12\x1f.products.v1.AdjustStockRequest\x1a .products.v1.AdjustStockResponse\x12e\n" +
+	"\x12ListStockMovements\x12&.products.v1.ListStockMovementsRequest\x1a'.products.v1.ListStockMovementsResponse\x12Y\n" +
+	"\x0eReconcileStock\x12\".products.v1.ReconcileStockRequest\x1a#.products.v1.ReconcileStockResponse\x12e\n" +
+	"\x12UpsertExchangeRate\x12&.products.v1.UpsertExchangeRateRequest\x1a'.products.v1.UpsertExchangeRateResponse\x12Y\n" +
+	"\x0eCreateCategory\x12\".products.v1.CreateCategoryRequest\x1a#.products.v1.CreateCategoryResponse\x12P\n" +
+	"\vGetCategory\x12\x1f.products.v1.GetCategoryRequest\x1a .products.v1.GetCategoryResponse\x12Y\n" +
+	"\x0eListCategories\x12\".products.v1.ListCategoriesRequest\x1a#.products.v1.ListCategoriesResponse\x12Y\n" +
+	"\x0eUpdateCategory\x12\".products.v1.UpdateCategoryRequest\x1a#.products.v1.UpdateCategoryResponse\x12Y\n" +
+	"\x0eDeleteCategory\x12\".products.v1.DeleteCategoryRequest\x1a#.products.v1.DeleteCategoryResponse\x12V\n" +
+	"\rCreateVariant\x12!.products.v1.CreateVariantRequest\x1a\".products.v1.CreateVariantResponse\x12S\n" +
+	"\fListVariants\x12 .products.v1.ListVariantsRequest\x1a!.products.v1.ListVariantsResponse\x12\\\n" +
+	"\x0fGetVariantBySku\x12#.products.v1.GetVariantBySkuRequest\x1a$.products.v1.GetVariantBySkuResponse\x12V\n" +
+	"\rUpdateVariant\x12!.products.v1.UpdateVariantRequest\x1a\".products.v1.UpdateVariantResponse\x12V\n" +
+	"\rDeleteVariant\x12!.products.v1.DeleteVariantRequest\x1a\".products.v1.DeleteVariantResponse\x12\\\n" +
+	"\x0fAddProductMedia\x12#.products.v1.AddProductMediaRequest\x1a$.products.v1.AddProductMediaResponse\x12e\n" +
+	"\x12UpdateProductMedia\x12&.products.v1.UpdateProductMediaRequest\x1a'.products.v1.UpdateProductMediaResponse\x12h\n" +
+	"\x13ReorderProductMedia\x12'.products.v1.ReorderProductMediaRequest\x1a(.products.v1.ReorderProductMediaResponse\x12e\n" +
+	"\x12DeleteProductMedia\x12&.products.v1.DeleteProductMediaRequest\x1a'.products.v1.DeleteProductMediaResponse\x12\\\n" +
+	"\x0fListPurgedMedia\x12#.products.v1.ListPurgedMediaRequest\x1a$.products.v1.ListPurgedMediaResponse\x12e\n" +
+	"\x12ConfirmPurgedMedia\x12&.products.v1.ConfirmPurgedMediaRequest\x1a'.products.v1.ConfirmPurgedMediaResponseB\xaa\x01\n" +
 	"\x0fcom.products.v1B\rProductsProtoP\x01Z;github.com/yaninyzwitty/go-fx-v1/gen/products/v1;productsv1\xa2\x02\x03PXX\xaa\x02\vProducts.V1\xca\x02\vProducts\\V1\xe2\x02\x17Products\\V1\\GPBMetadata\xea\x02\fProducts::V1b\x06proto3"
 
 var (
@@ -581,38 +5763,263 @@ func file_products_v1_products_proto_rawDescGZIP() []byte {
 	return file_products_v1_products_proto_rawDescData
 }
 
-var file_products_v1_products_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_products_v1_products_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_products_v1_products_proto_msgTypes = make([]protoimpl.MessageInfo, 86)
 var file_products_v1_products_proto_goTypes = []any{
-	(*Product)(nil),               // 0: products.v1.Product
-	(*GetProductRequest)(nil),     // 1: products.v1.GetProductRequest
-	(*GetProductResponse)(nil),    // 2: products.v1.GetProductResponse
-	(*ListProductsRequest)(nil),   // 3: products.v1.ListProductsRequest
-	(*ListProductsResponse)(nil),  // 4: products.v1.ListProductsResponse
-	(*CreateProductRequest)(nil),  // 5: products.v1.CreateProductRequest
-	(*CreateProductResponse)(nil), // 6: products.v1.CreateProductResponse
-	(*DeleteProductRequest)(nil),  // 7: products.v1.DeleteProductRequest
-	(*DeleteProductResponse)(nil), // 8: products.v1.DeleteProductResponse
-	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(ReservationStatus)(0),               // 0: products.v1.ReservationStatus
+	(StockMovementReason)(0),             // 1: products.v1.StockMovementReason
+	(ProductEventType)(0),                // 2: products.v1.ProductEventType
+	(RevisionAction)(0),                  // 3: products.v1.RevisionAction
+	(*Money)(nil),                        // 4: products.v1.Money
+	(*Product)(nil),                      // 5: products.v1.Product
+	(*ProductMedia)(nil),                 // 6: products.v1.ProductMedia
+	(*ProductVariant)(nil),               // 7: products.v1.ProductVariant
+	(*Category)(nil),                     // 8: products.v1.Category
+	(*ExchangeRate)(nil),                 // 9: products.v1.ExchangeRate
+	(*ConvertedPrice)(nil),               // 10: products.v1.ConvertedPrice
+	(*GetProductRequest)(nil),            // 11: products.v1.GetProductRequest
+	(*GetProductResponse)(nil),           // 12: products.v1.GetProductResponse
+	(*ProductFilter)(nil),                // 13: products.v1.ProductFilter
+	(*ListProductsRequest)(nil),          // 14: products.v1.ListProductsRequest
+	(*ListProductsResponse)(nil),         // 15: products.v1.ListProductsResponse
+	(*SearchProductsRequest)(nil),        // 16: products.v1.SearchProductsRequest
+	(*SearchResult)(nil),                 // 17: products.v1.SearchResult
+	(*SearchProductsResponse)(nil),       // 18: products.v1.SearchProductsResponse
+	(*SuggestProductsRequest)(nil),       // 19: products.v1.SuggestProductsRequest
+	(*ProductSuggestion)(nil),            // 20: products.v1.ProductSuggestion
+	(*SuggestProductsResponse)(nil),      // 21: products.v1.SuggestProductsResponse
+	(*CreateProductRequest)(nil),         // 22: products.v1.CreateProductRequest
+	(*CreateProductResponse)(nil),        // 23: products.v1.CreateProductResponse
+	(*BatchGetProductsRequest)(nil),      // 24: products.v1.BatchGetProductsRequest
+	(*BatchGetProductsResponse)(nil),     // 25: products.v1.BatchGetProductsResponse
+	(*BatchCreateProductsRequest)(nil),   // 26: products.v1.BatchCreateProductsRequest
+	(*BatchCreateProductsResponse)(nil),  // 27: products.v1.BatchCreateProductsResponse
+	(*UpdateProductRequest)(nil),         // 28: products.v1.UpdateProductRequest
+	(*UpdateProductResponse)(nil),        // 29: products.v1.UpdateProductResponse
+	(*DeleteProductRequest)(nil),         // 30: products.v1.DeleteProductRequest
+	(*DeleteProductResponse)(nil),        // 31: products.v1.DeleteProductResponse
+	(*UndeleteProductRequest)(nil),       // 32: products.v1.UndeleteProductRequest
+	(*UndeleteProductResponse)(nil),      // 33: products.v1.UndeleteProductResponse
+	(*UpsertExchangeRateRequest)(nil),    // 34: products.v1.UpsertExchangeRateRequest
+	(*UpsertExchangeRateResponse)(nil),   // 35: products.v1.UpsertExchangeRateResponse
+	(*CreateVariantRequest)(nil),         // 36: products.v1.CreateVariantRequest
+	(*CreateVariantResponse)(nil),        // 37: products.v1.CreateVariantResponse
+	(*ListVariantsRequest)(nil),          // 38: products.v1.ListVariantsRequest
+	(*ListVariantsResponse)(nil),         // 39: products.v1.ListVariantsResponse
+	(*GetVariantBySkuRequest)(nil),       // 40: products.v1.GetVariantBySkuRequest
+	(*GetVariantBySkuResponse)(nil),      // 41: products.v1.GetVariantBySkuResponse
+	(*UpdateVariantRequest)(nil),         // 42: products.v1.UpdateVariantRequest
+	(*UpdateVariantResponse)(nil),        // 43: products.v1.UpdateVariantResponse
+	(*DeleteVariantRequest)(nil),         // 44: products.v1.DeleteVariantRequest
+	(*DeleteVariantResponse)(nil),        // 45: products.v1.DeleteVariantResponse
+	(*AddProductMediaRequest)(nil),       // 46: products.v1.AddProductMediaRequest
+	(*AddProductMediaResponse)(nil),      // 47: products.v1.AddProductMediaResponse
+	(*UpdateProductMediaRequest)(nil),    // 48: products.v1.UpdateProductMediaRequest
+	(*UpdateProductMediaResponse)(nil),   // 49: products.v1.UpdateProductMediaResponse
+	(*ReorderProductMediaRequest)(nil),   // 50: products.v1.ReorderProductMediaRequest
+	(*ReorderProductMediaResponse)(nil),  // 51: products.v1.ReorderProductMediaResponse
+	(*DeleteProductMediaRequest)(nil),    // 52: products.v1.DeleteProductMediaRequest
+	(*DeleteProductMediaResponse)(nil),   // 53: products.v1.DeleteProductMediaResponse
+	(*ListPurgedMediaRequest)(nil),       // 54: products.v1.ListPurgedMediaRequest
+	(*ListPurgedMediaResponse)(nil),      // 55: products.v1.ListPurgedMediaResponse
+	(*ConfirmPurgedMediaRequest)(nil),    // 56: products.v1.ConfirmPurgedMediaRequest
+	(*ConfirmPurgedMediaResponse)(nil),   // 57: products.v1.ConfirmPurgedMediaResponse
+	(*CreateCategoryRequest)(nil),        // 58: products.v1.CreateCategoryRequest
+	(*CreateCategoryResponse)(nil),       // 59: products.v1.CreateCategoryResponse
+	(*GetCategoryRequest)(nil),           // 60: products.v1.GetCategoryRequest
+	(*GetCategoryResponse)(nil),          // 61: products.v1.GetCategoryResponse
+	(*ListCategoriesRequest)(nil),        // 62: products.v1.ListCategoriesRequest
+	(*ListCategoriesResponse)(nil),       // 63: products.v1.ListCategoriesResponse
+	(*UpdateCategoryRequest)(nil),        // 64: products.v1.UpdateCategoryRequest
+	(*UpdateCategoryResponse)(nil),       // 65: products.v1.UpdateCategoryResponse
+	(*DeleteCategoryRequest)(nil),        // 66: products.v1.DeleteCategoryRequest
+	(*DeleteCategoryResponse)(nil),       // 67: products.v1.DeleteCategoryResponse
+	(*StockReservation)(nil),             // 68: products.v1.StockReservation
+	(*ReserveStockRequest)(nil),          // 69: products.v1.ReserveStockRequest
+	(*ReserveStockResponse)(nil),         // 70: products.v1.ReserveStockResponse
+	(*CommitReservationRequest)(nil),     // 71: products.v1.CommitReservationRequest
+	(*CommitReservationResponse)(nil),    // 72: products.v1.CommitReservationResponse
+	(*ReleaseReservationRequest)(nil),    // 73: products.v1.ReleaseReservationRequest
+	(*ReleaseReservationResponse)(nil),   // 74: products.v1.ReleaseReservationResponse
+	(*StockMovement)(nil),                // 75: products.v1.StockMovement
+	(*AdjustStockRequest)(nil),           // 76: products.v1.AdjustStockRequest
+	(*AdjustStockResponse)(nil),          // 77: products.v1.AdjustStockResponse
+	(*ListStockMovementsRequest)(nil),    // 78: products.v1.ListStockMovementsRequest
+	(*ListStockMovementsResponse)(nil),   // 79: products.v1.ListStockMovementsResponse
+	(*ReconcileStockRequest)(nil),        // 80: products.v1.ReconcileStockRequest
+	(*StockDrift)(nil),                   // 81: products.v1.StockDrift
+	(*ReconcileStockResponse)(nil),       // 82: products.v1.ReconcileStockResponse
+	(*WatchProductsRequest)(nil),         // 83: products.v1.WatchProductsRequest
+	(*ProductEvent)(nil),                 // 84: products.v1.ProductEvent
+	(*ProductRevision)(nil),              // 85: products.v1.ProductRevision
+	(*ListProductRevisionsRequest)(nil),  // 86: products.v1.ListProductRevisionsRequest
+	(*ListProductRevisionsResponse)(nil), // 87: products.v1.ListProductRevisionsResponse
+	nil,                                  // 88: products.v1.ProductVariant.OptionsEntry
+	nil,                                  // 89: products.v1.CreateVariantRequest.OptionsEntry
+	(*timestamppb.Timestamp)(nil),        // 90: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),        // 91: google.protobuf.FieldMask
+	(*durationpb.Duration)(nil),          // 92: google.protobuf.Duration
 }
 var file_products_v1_products_proto_depIdxs = []int32{
-	9, // 0: products.v1.Product.created_at:type_name -> google.protobuf.Timestamp
-	9, // 1: products.v1.Product.updated_at:type_name -> google.protobuf.Timestamp
-	0, // 2: products.v1.GetProductResponse.product:type_name -> products.v1.Product
-	0, // 3: products.v1.ListProductsResponse.products:type_name -> products.v1.Product
-	0, // 4: products.v1.CreateProductResponse.product:type_name -> products.v1.Product
-	1, // 5: products.v1.ProductService.GetProduct:input_type -> products.v1.GetProductRequest
-	3, // 6: products.v1.ProductService.ListProducts:input_type -> products.v1.ListProductsRequest
-	5, // 7: products.v1.ProductService.CreateProduct:input_type -> products.v1.CreateProductRequest
-	7, // 8: products.v1.ProductService.DeleteProduct:input_type -> products.v1.DeleteProductRequest
-	2, // 9: products.v1.ProductService.GetProduct:output_type -> products.v1.GetProductResponse
-	4, // 10: products.v1.ProductService.ListProducts:output_type -> products.v1.ListProductsResponse
-	6, // 11: products.v1.ProductService.CreateProduct:output_type -> products.v1.CreateProductResponse
-	8, // 12: products.v1.ProductService.DeleteProduct:output_type -> products.v1.DeleteProductResponse
-	9, // [9:13] is the sub-list for method output_type
-	5, // [5:9] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	4,   // 0: products.v1.Product.price:type_name -> products.v1.Money
+	90,  // 1: products.v1.Product.created_at:type_name -> google.protobuf.Timestamp
+	90,  // 2: products.v1.Product.updated_at:type_name -> google.protobuf.Timestamp
+	90,  // 3: products.v1.Product.deleted_at:type_name -> google.protobuf.Timestamp
+	10,  // 4: products.v1.Product.display_price:type_name -> products.v1.ConvertedPrice
+	7,   // 5: products.v1.Product.variants:type_name -> products.v1.ProductVariant
+	6,   // 6: products.v1.Product.media:type_name -> products.v1.ProductMedia
+	90,  // 7: products.v1.ProductMedia.created_at:type_name -> google.protobuf.Timestamp
+	88,  // 8: products.v1.ProductVariant.options:type_name -> products.v1.ProductVariant.OptionsEntry
+	4,   // 9: products.v1.ProductVariant.price:type_name -> products.v1.Money
+	90,  // 10: products.v1.ProductVariant.created_at:type_name -> google.protobuf.Timestamp
+	90,  // 11: products.v1.ProductVariant.updated_at:type_name -> google.protobuf.Timestamp
+	90,  // 12: products.v1.Category.created_at:type_name -> google.protobuf.Timestamp
+	90,  // 13: products.v1.Category.updated_at:type_name -> google.protobuf.Timestamp
+	90,  // 14: products.v1.ExchangeRate.effective_at:type_name -> google.protobuf.Timestamp
+	4,   // 15: products.v1.ConvertedPrice.price:type_name -> products.v1.Money
+	9,   // 16: products.v1.ConvertedPrice.rate:type_name -> products.v1.ExchangeRate
+	90,  // 17: products.v1.GetProductRequest.as_of:type_name -> google.protobuf.Timestamp
+	5,   // 18: products.v1.GetProductResponse.product:type_name -> products.v1.Product
+	4,   // 19: products.v1.ProductFilter.min_price:type_name -> products.v1.Money
+	4,   // 20: products.v1.ProductFilter.max_price:type_name -> products.v1.Money
+	13,  // 21: products.v1.ListProductsRequest.filter:type_name -> products.v1.ProductFilter
+	5,   // 22: products.v1.ListProductsResponse.products:type_name -> products.v1.Product
+	5,   // 23: products.v1.SearchResult.product:type_name -> products.v1.Product
+	17,  // 24: products.v1.SearchProductsResponse.results:type_name -> products.v1.SearchResult
+	20,  // 25: products.v1.SuggestProductsResponse.suggestions:type_name -> products.v1.ProductSuggestion
+	4,   // 26: products.v1.CreateProductRequest.price:type_name -> products.v1.Money
+	5,   // 27: products.v1.CreateProductResponse.product:type_name -> products.v1.Product
+	5,   // 28: products.v1.BatchGetProductsResponse.products:type_name -> products.v1.Product
+	22,  // 29: products.v1.BatchCreateProductsRequest.requests:type_name -> products.v1.CreateProductRequest
+	5,   // 30: products.v1.BatchCreateProductsResponse.products:type_name -> products.v1.Product
+	5,   // 31: products.v1.UpdateProductRequest.product:type_name -> products.v1.Product
+	91,  // 32: products.v1.UpdateProductRequest.update_mask:type_name -> google.protobuf.FieldMask
+	5,   // 33: products.v1.UpdateProductResponse.product:type_name -> products.v1.Product
+	5,   // 34: products.v1.DeleteProductResponse.product:type_name -> products.v1.Product
+	5,   // 35: products.v1.UndeleteProductResponse.product:type_name -> products.v1.Product
+	9,   // 36: products.v1.UpsertExchangeRateRequest.rate:type_name -> products.v1.ExchangeRate
+	9,   // 37: products.v1.UpsertExchangeRateResponse.rate:type_name -> products.v1.ExchangeRate
+	89,  // 38: products.v1.CreateVariantRequest.options:type_name -> products.v1.CreateVariantRequest.OptionsEntry
+	4,   // 39: products.v1.CreateVariantRequest.price:type_name -> products.v1.Money
+	7,   // 40: products.v1.CreateVariantResponse.variant:type_name -> products.v1.ProductVariant
+	7,   // 41: products.v1.ListVariantsResponse.variants:type_name -> products.v1.ProductVariant
+	7,   // 42: products.v1.GetVariantBySkuResponse.variant:type_name -> products.v1.ProductVariant
+	5,   // 43: products.v1.GetVariantBySkuResponse.product:type_name -> products.v1.Product
+	7,   // 44: products.v1.UpdateVariantRequest.variant:type_name -> products.v1.ProductVariant
+	91,  // 45: products.v1.UpdateVariantRequest.update_mask:type_name -> google.protobuf.FieldMask
+	7,   // 46: products.v1.UpdateVariantResponse.variant:type_name -> products.v1.ProductVariant
+	7,   // 47: products.v1.DeleteVariantResponse.variant:type_name -> products.v1.ProductVariant
+	6,   // 48: products.v1.AddProductMediaResponse.media:type_name -> products.v1.ProductMedia
+	6,   // 49: products.v1.UpdateProductMediaRequest.media:type_name -> products.v1.ProductMedia
+	91,  // 50: products.v1.UpdateProductMediaRequest.update_mask:type_name -> google.protobuf.FieldMask
+	6,   // 51: products.v1.UpdateProductMediaResponse.media:type_name -> products.v1.ProductMedia
+	6,   // 52: products.v1.ReorderProductMediaResponse.media:type_name -> products.v1.ProductMedia
+	6,   // 53: products.v1.DeleteProductMediaResponse.media:type_name -> products.v1.ProductMedia
+	8,   // 54: products.v1.CreateCategoryResponse.category:type_name -> products.v1.Category
+	8,   // 55: products.v1.GetCategoryResponse.category:type_name -> products.v1.Category
+	8,   // 56: products.v1.ListCategoriesResponse.categories:type_name -> products.v1.Category
+	8,   // 57: products.v1.UpdateCategoryRequest.category:type_name -> products.v1.Category
+	91,  // 58: products.v1.UpdateCategoryRequest.update_mask:type_name -> google.protobuf.FieldMask
+	8,   // 59: products.v1.UpdateCategoryResponse.category:type_name -> products.v1.Category
+	8,   // 60: products.v1.DeleteCategoryResponse.category:type_name -> products.v1.Category
+	0,   // 61: products.v1.StockReservation.status:type_name -> products.v1.ReservationStatus
+	90,  // 62: products.v1.StockReservation.expires_at:type_name -> google.protobuf.Timestamp
+	90,  // 63: products.v1.StockReservation.created_at:type_name -> google.protobuf.Timestamp
+	90,  // 64: products.v1.StockReservation.updated_at:type_name -> google.protobuf.Timestamp
+	92,  // 65: products.v1.ReserveStockRequest.ttl:type_name -> google.protobuf.Duration
+	68,  // 66: products.v1.ReserveStockResponse.reservation:type_name -> products.v1.StockReservation
+	68,  // 67: products.v1.CommitReservationResponse.reservation:type_name -> products.v1.StockReservation
+	68,  // 68: products.v1.ReleaseReservationResponse.reservation:type_name -> products.v1.StockReservation
+	1,   // 69: products.v1.StockMovement.reason:type_name -> products.v1.StockMovementReason
+	90,  // 70: products.v1.StockMovement.created_at:type_name -> google.protobuf.Timestamp
+	1,   // 71: products.v1.AdjustStockRequest.reason:type_name -> products.v1.StockMovementReason
+	75,  // 72: products.v1.AdjustStockResponse.movement:type_name -> products.v1.StockMovement
+	1,   // 73: products.v1.ListStockMovementsRequest.reason:type_name -> products.v1.StockMovementReason
+	75,  // 74: products.v1.ListStockMovementsResponse.movements:type_name -> products.v1.StockMovement
+	81,  // 75: products.v1.ReconcileStockResponse.drifts:type_name -> products.v1.StockDrift
+	2,   // 76: products.v1.ProductEvent.type:type_name -> products.v1.ProductEventType
+	5,   // 77: products.v1.ProductEvent.product:type_name -> products.v1.Product
+	3,   // 78: products.v1.ProductRevision.action:type_name -> products.v1.RevisionAction
+	90,  // 79: products.v1.ProductRevision.created_at:type_name -> google.protobuf.Timestamp
+	5,   // 80: products.v1.ProductRevision.before:type_name -> products.v1.Product
+	5,   // 81: products.v1.ProductRevision.after:type_name -> products.v1.Product
+	91,  // 82: products.v1.ProductRevision.changed_fields:type_name -> google.protobuf.FieldMask
+	85,  // 83: products.v1.ListProductRevisionsResponse.revisions:type_name -> products.v1.ProductRevision
+	11,  // 84: products.v1.ProductService.GetProduct:input_type -> products.v1.GetProductRequest
+	14,  // 85: products.v1.ProductService.ListProducts:input_type -> products.v1.ListProductsRequest
+	83,  // 86: products.v1.ProductService.WatchProducts:input_type -> products.v1.WatchProductsRequest
+	16,  // 87: products.v1.ProductService.SearchProducts:input_type -> products.v1.SearchProductsRequest
+	19,  // 88: products.v1.ProductService.SuggestProducts:input_type -> products.v1.SuggestProductsRequest
+	22,  // 89: products.v1.ProductService.CreateProduct:input_type -> products.v1.CreateProductRequest
+	24,  // 90: products.v1.ProductService.BatchGetProducts:input_type -> products.v1.BatchGetProductsRequest
+	26,  // 91: products.v1.ProductService.BatchCreateProducts:input_type -> products.v1.BatchCreateProductsRequest
+	28,  // 92: products.v1.ProductService.UpdateProduct:input_type -> products.v1.UpdateProductRequest
+	30,  // 93: products.v1.ProductService.DeleteProduct:input_type -> products.v1.DeleteProductRequest
+	32,  // 94: products.v1.ProductService.UndeleteProduct:input_type -> products.v1.UndeleteProductRequest
+	86,  // 95: products.v1.ProductService.ListProductRevisions:input_type -> products.v1.ListProductRevisionsRequest
+	69,  // 96: products.v1.ProductService.ReserveStock:input_type -> products.v1.ReserveStockRequest
+	71,  // 97: products.v1.ProductService.CommitReservation:input_type -> products.v1.CommitReservationRequest
+	73,  // 98: products.v1.ProductService.ReleaseReservation:input_type -> products.v1.ReleaseReservationRequest
+	76,  // 99: products.v1.ProductService.AdjustStock:input_type -> products.v1.AdjustStockRequest
+	78,  // 100: products.v1.ProductService.ListStockMovements:input_type -> products.v1.ListStockMovementsRequest
+	80,  // 101: products.v1.ProductService.ReconcileStock:input_type -> products.v1.ReconcileStockRequest
+	34,  // 102: products.v1.ProductService.UpsertExchangeRate:input_type -> products.v1.UpsertExchangeRateRequest
+	58,  // 103: products.v1.ProductService.CreateCategory:input_type -> products.v1.CreateCategoryRequest
+	60,  // 104: products.v1.ProductService.GetCategory:input_type -> products.v1.GetCategoryRequest
+	62,  // 105: products.v1.ProductService.ListCategories:input_type -> products.v1.ListCategoriesRequest
+	64,  // 106: products.v1.ProductService.UpdateCategory:input_type -> products.v1.UpdateCategoryRequest
+	66,  // 107: products.v1.ProductService.DeleteCategory:input_type -> products.v1.DeleteCategoryRequest
+	36,  // 108: products.v1.ProductService.CreateVariant:input_type -> products.v1.CreateVariantRequest
+	38,  // 109: products.v1.ProductService.ListVariants:input_type -> products.v1.ListVariantsRequest
+	40,  // 110: products.v1.ProductService.GetVariantBySku:input_type -> products.v1.GetVariantBySkuRequest
+	42,  // 111: products.v1.ProductService.UpdateVariant:input_type -> products.v1.UpdateVariantRequest
+	44,  // 112: products.v1.ProductService.DeleteVariant:input_type -> products.v1.DeleteVariantRequest
+	46,  // 113: products.v1.ProductService.AddProductMedia:input_type -> products.v1.AddProductMediaRequest
+	48,  // 114: products.v1.ProductService.UpdateProductMedia:input_type -> products.v1.UpdateProductMediaRequest
+	50,  // 115: products.v1.ProductService.ReorderProductMedia:input_type -> products.v1.ReorderProductMediaRequest
+	52,  // 116: products.v1.ProductService.DeleteProductMedia:input_type -> products.v1.DeleteProductMediaRequest
+	54,  // 117: products.v1.ProductService.ListPurgedMedia:input_type -> products.v1.ListPurgedMediaRequest
+	56,  // 118: products.v1.ProductService.ConfirmPurgedMedia:input_type -> products.v1.ConfirmPurgedMediaRequest
+	12,  // 119: products.v1.ProductService.GetProduct:output_type -> products.v1.GetProductResponse
+	15,  // 120: products.v1.ProductService.ListProducts:output_type -> products.v1.ListProductsResponse
+	84,  // 121: products.v1.ProductService.WatchProducts:output_type -> products.v1.ProductEvent
+	18,  // 122: products.v1.ProductService.SearchProducts:output_type -> products.v1.SearchProductsResponse
+	21,  // 123: products.v1.ProductService.SuggestProducts:output_type -> products.v1.SuggestProductsResponse
+	23,  // 124: products.v1.ProductService.CreateProduct:output_type -> products.v1.CreateProductResponse
+	25,  // 125: products.v1.ProductService.BatchGetProducts:output_type -> products.v1.BatchGetProductsResponse
+	27,  // 126: products.v1.ProductService.BatchCreateProducts:output_type -> products.v1.BatchCreateProductsResponse
+	29,  // 127: products.v1.ProductService.UpdateProduct:output_type -> products.v1.UpdateProductResponse
+	31,  // 128: products.v1.ProductService.DeleteProduct:output_type -> products.v1.DeleteProductResponse
+	33,  // 129: products.v1.ProductService.UndeleteProduct:output_type -> products.v1.UndeleteProductResponse
+	87,  // 130: products.v1.ProductService.ListProductRevisions:output_type -> products.v1.ListProductRevisionsResponse
+	70,  // 131: products.v1.ProductService.ReserveStock:output_type -> products.v1.ReserveStockResponse
+	72,  // 132: products.v1.ProductService.CommitReservation:output_type -> products.v1.CommitReservationResponse
+	74,  // 133: products.v1.ProductService.ReleaseReservation:output_type -> products.v1.ReleaseReservationResponse
+	77,  // 134: products.v1.ProductService.AdjustStock:output_type -> products.v1.AdjustStockResponse
+	79,  // 135: products.v1.ProductService.ListStockMovements:output_type -> products.v1.ListStockMovementsResponse
+	82,  // 136: products.v1.ProductService.ReconcileStock:output_type -> products.v1.ReconcileStockResponse
+	35,  // 137: products.v1.ProductService.UpsertExchangeRate:output_type -> products.v1.UpsertExchangeRateResponse
+	59,  // 138: products.v1.ProductService.CreateCategory:output_type -> products.v1.CreateCategoryResponse
+	61,  // 139: products.v1.ProductService.GetCategory:output_type -> products.v1.GetCategoryResponse
+	63,  // 140: products.v1.ProductService.ListCategories:output_type -> products.v1.ListCategoriesResponse
+	65,  // 141: products.v1.ProductService.UpdateCategory:output_type -> products.v1.UpdateCategoryResponse
+	67,  // 142: products.v1.ProductService.DeleteCategory:output_type -> products.v1.DeleteCategoryResponse
+	37,  // 143: products.v1.ProductService.CreateVariant:output_type -> products.v1.CreateVariantResponse
+	39,  // 144: products.v1.ProductService.ListVariants:output_type -> products.v1.ListVariantsResponse
+	41,  // 145: products.v1.ProductService.GetVariantBySku:output_type -> products.v1.GetVariantBySkuResponse
+	43,  // 146: products.v1.ProductService.UpdateVariant:output_type -> products.v1.UpdateVariantResponse
+	45,  // 147: products.v1.ProductService.DeleteVariant:output_type -> products.v1.DeleteVariantResponse
+	47,  // 148: products.v1.ProductService.AddProductMedia:output_type -> products.v1.AddProductMediaResponse
+	49,  // 149: products.v1.ProductService.UpdateProductMedia:output_type -> products.v1.UpdateProductMediaResponse
+	51,  // 150: products.v1.ProductService.ReorderProductMedia:output_type -> products.v1.ReorderProductMediaResponse
+	53,  // 151: products.v1.ProductService.DeleteProductMedia:output_type -> products.v1.DeleteProductMediaResponse
+	55,  // 152: products.v1.ProductService.ListPurgedMedia:output_type -> products.v1.ListPurgedMediaResponse
+	57,  // 153: products.v1.ProductService.ConfirmPurgedMedia:output_type -> products.v1.ConfirmPurgedMediaResponse
+	119, // [119:154] is the sub-list for method output_type
+	84,  // [84:119] is the sub-list for method input_type
+	84,  // [84:84] is the sub-list for extension type_name
+	84,  // [84:84] is the sub-list for extension extendee
+	0,   // [0:84] is the sub-list for field type_name
 }
 
 func init() { file_products_v1_products_proto_init() }
@@ -625,13 +6032,14 @@ func file_products_v1_products_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_products_v1_products_proto_rawDesc), len(file_products_v1_products_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      4,
+			NumMessages:   86,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_products_v1_products_proto_goTypes,
 		DependencyIndexes: file_products_v1_products_proto_depIdxs,
+		EnumInfos:         file_products_v1_products_proto_enumTypes,
 		MessageInfos:      file_products_v1_products_proto_msgTypes,
 	}.Build()
 	File_products_v1_products_proto = out.File
@@ -0,0 +1,670 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: validate/v1/validate.proto
+
+package validatev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FieldRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The field must be set: a non-zero scalar, a non-empty string or list,
+	// or a present message.
+	Required bool `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	// Types that are valid to be assigned to Type:
+	//
+	//	*FieldRules_String_
+	//	*FieldRules_Int
+	//	*FieldRules_Money
+	//	*FieldRules_Enum
+	//	*FieldRules_Repeated
+	//	*FieldRules_Message
+	Type          isFieldRules_Type `protobuf_oneof:"type"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldRules) Reset() {
+	*x = FieldRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldRules) ProtoMessage() {}
+
+func (x *FieldRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldRules.ProtoReflect.Descriptor instead.
+func (*FieldRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FieldRules) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *FieldRules) GetType() isFieldRules_Type {
+	if x != nil {
+		return x.Type
+	}
+	return nil
+}
+
+func (x *FieldRules) GetString_() *StringRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_String_); ok {
+			return x.String_
+		}
+	}
+	return nil
+}
+
+func (x *FieldRules) GetInt() *IntRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_Int); ok {
+			return x.Int
+		}
+	}
+	return nil
+}
+
+func (x *FieldRules) GetMoney() *MoneyRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_Money); ok {
+			return x.Money
+		}
+	}
+	return nil
+}
+
+func (x *FieldRules) GetEnum() *EnumRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_Enum); ok {
+			return x.Enum
+		}
+	}
+	return nil
+}
+
+func (x *FieldRules) GetRepeated() *RepeatedRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_Repeated); ok {
+			return x.Repeated
+		}
+	}
+	return nil
+}
+
+func (x *FieldRules) GetMessage() *MessageRules {
+	if x != nil {
+		if x, ok := x.Type.(*FieldRules_Message); ok {
+			return x.Message
+		}
+	}
+	return nil
+}
+
+type isFieldRules_Type interface {
+	isFieldRules_Type()
+}
+
+type FieldRules_String_ struct {
+	String_ *StringRules `protobuf:"bytes,2,opt,name=string,proto3,oneof"`
+}
+
+type FieldRules_Int struct {
+	Int *IntRules `protobuf:"bytes,3,opt,name=int,proto3,oneof"`
+}
+
+type FieldRules_Money struct {
+	Money *MoneyRules `protobuf:"bytes,4,opt,name=money,proto3,oneof"`
+}
+
+type FieldRules_Enum struct {
+	Enum *EnumRules `protobuf:"bytes,5,opt,name=enum,proto3,oneof"`
+}
+
+type FieldRules_Repeated struct {
+	Repeated *RepeatedRules `protobuf:"bytes,6,opt,name=repeated,proto3,oneof"`
+}
+
+type FieldRules_Message struct {
+	Message *MessageRules `protobuf:"bytes,7,opt,name=message,proto3,oneof"`
+}
+
+func (*FieldRules_String_) isFieldRules_Type() {}
+
+func (*FieldRules_Int) isFieldRules_Type() {}
+
+func (*FieldRules_Money) isFieldRules_Type() {}
+
+func (*FieldRules_Enum) isFieldRules_Type() {}
+
+func (*FieldRules_Repeated) isFieldRules_Type() {}
+
+func (*FieldRules_Message) isFieldRules_Type() {}
+
+type StringRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Bounds on the length in characters.
+	MinLen uint64 `protobuf:"varint,1,opt,name=min_len,json=minLen,proto3" json:"min_len,omitempty"`
+	MaxLen uint64 `protobuf:"varint,2,opt,name=max_len,json=maxLen,proto3" json:"max_len,omitempty"`
+	// Bound on the length in UTF-8 bytes.
+	MaxBytes uint64 `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// RE2 expression the whole value must match.
+	Pattern string `protobuf:"bytes,4,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// The value must hold more than whitespace.
+	NotBlank      bool `protobuf:"varint,5,opt,name=not_blank,json=notBlank,proto3" json:"not_blank,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StringRules) Reset() {
+	*x = StringRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StringRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StringRules) ProtoMessage() {}
+
+func (x *StringRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StringRules.ProtoReflect.Descriptor instead.
+func (*StringRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StringRules) GetMinLen() uint64 {
+	if x != nil {
+		return x.MinLen
+	}
+	return 0
+}
+
+func (x *StringRules) GetMaxLen() uint64 {
+	if x != nil {
+		return x.MaxLen
+	}
+	return 0
+}
+
+func (x *StringRules) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *StringRules) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *StringRules) GetNotBlank() bool {
+	if x != nil {
+		return x.NotBlank
+	}
+	return false
+}
+
+// Bounds on any integer field.
+type IntRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Gt            *int64                 `protobuf:"varint,1,opt,name=gt,proto3,oneof" json:"gt,omitempty"`
+	Gte           *int64                 `protobuf:"varint,2,opt,name=gte,proto3,oneof" json:"gte,omitempty"`
+	Lt            *int64                 `protobuf:"varint,3,opt,name=lt,proto3,oneof" json:"lt,omitempty"`
+	Lte           *int64                 `protobuf:"varint,4,opt,name=lte,proto3,oneof" json:"lte,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntRules) Reset() {
+	*x = IntRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntRules) ProtoMessage() {}
+
+func (x *IntRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntRules.ProtoReflect.Descriptor instead.
+func (*IntRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IntRules) GetGt() int64 {
+	if x != nil && x.Gt != nil {
+		return *x.Gt
+	}
+	return 0
+}
+
+func (x *IntRules) GetGte() int64 {
+	if x != nil && x.Gte != nil {
+		return *x.Gte
+	}
+	return 0
+}
+
+func (x *IntRules) GetLt() int64 {
+	if x != nil && x.Lt != nil {
+		return *x.Lt
+	}
+	return 0
+}
+
+func (x *IntRules) GetLte() int64 {
+	if x != nil && x.Lte != nil {
+		return *x.Lte
+	}
+	return 0
+}
+
+// Rules for a message laid out like products.v1.Money, read through its
+// units and nanos fields.
+type MoneyRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The amount must be greater than zero.
+	Positive      bool `protobuf:"varint,1,opt,name=positive,proto3" json:"positive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MoneyRules) Reset() {
+	*x = MoneyRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MoneyRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoneyRules) ProtoMessage() {}
+
+func (x *MoneyRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoneyRules.ProtoReflect.Descriptor instead.
+func (*MoneyRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MoneyRules) GetPositive() bool {
+	if x != nil {
+		return x.Positive
+	}
+	return false
+}
+
+type EnumRules struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The value must be one of the enum's declared values.
+	DefinedOnly   bool `protobuf:"varint,1,opt,name=defined_only,json=definedOnly,proto3" json:"defined_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnumRules) Reset() {
+	*x = EnumRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnumRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnumRules) ProtoMessage() {}
+
+func (x *EnumRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnumRules.ProtoReflect.Descriptor instead.
+func (*EnumRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EnumRules) GetDefinedOnly() bool {
+	if x != nil {
+		return x.DefinedOnly
+	}
+	return false
+}
+
+// Rules for the fields of a message field, by field name. They replace the
+// rules the message type declares on those fields, so that messages shared
+// with responses, such as products.v1.Product, are only constrained where a
+// request carries them.
+type MessageRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        map[string]*FieldRules `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageRules) Reset() {
+	*x = MessageRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageRules) ProtoMessage() {}
+
+func (x *MessageRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageRules.ProtoReflect.Descriptor instead.
+func (*MessageRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MessageRules) GetFields() map[string]*FieldRules {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type RepeatedRules struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	MinItems uint64                 `protobuf:"varint,1,opt,name=min_items,json=minItems,proto3" json:"min_items,omitempty"`
+	MaxItems uint64                 `protobuf:"varint,2,opt,name=max_items,json=maxItems,proto3" json:"max_items,omitempty"`
+	// Rules applied to every item.
+	Items         *FieldRules `protobuf:"bytes,3,opt,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RepeatedRules) Reset() {
+	*x = RepeatedRules{}
+	mi := &file_validate_v1_validate_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RepeatedRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RepeatedRules) ProtoMessage() {}
+
+func (x *RepeatedRules) ProtoReflect() protoreflect.Message {
+	mi := &file_validate_v1_validate_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RepeatedRules.ProtoReflect.Descriptor instead.
+func (*RepeatedRules) Descriptor() ([]byte, []int) {
+	return file_validate_v1_validate_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RepeatedRules) GetMinItems() uint64 {
+	if x != nil {
+		return x.MinItems
+	}
+	return 0
+}
+
+func (x *RepeatedRules) GetMaxItems() uint64 {
+	if x != nil {
+		return x.MaxItems
+	}
+	return 0
+}
+
+func (x *RepeatedRules) GetItems() *FieldRules {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var file_validate_v1_validate_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*FieldRules)(nil),
+		Field:         51071,
+		Name:          "validate.v1.field",
+		Tag:           "bytes,51071,opt,name=field",
+		Filename:      "validate/v1/validate.proto",
+	},
+}
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional validate.v1.FieldRules field = 51071;
+	E_Field = &file_validate_v1_validate_proto_extTypes[0]
+)
+
+var File_validate_v1_validate_proto protoreflect.FileDescriptor
+
+const file_validate_v1_validate_proto_rawDesc = "" +
+	"\n" +
+	"\x1avalidate/v1/validate.proto\x12\vvalidate.v1\x1a google/protobuf/descriptor.proto\"\xdf\x02\n" +
+	"\n" +
+	"FieldRules\x12\x1a\n" +
+	"\brequired\x18\x01 \x01(\bR\brequired\x122\n" +
+	"\x06string\x18\x02 \x01(\v2\x18.validate.v1.StringRulesH\x00R\x06string\x12)\n" +
+	"\x03int\x18\x03 \x01(\v2\x15.validate.v1.IntRulesH\x00R\x03int\x12/\n" +
+	"\x05money\x18\x04 \x01(\v2\x17.validate.v1.MoneyRulesH\x00R\x05money\x12,\n" +
+	"\x04enum\x18\x05 \x01(\v2\x16.validate.v1.EnumRulesH\x00R\x04enum\x128\n" +
+	"\brepeated\x18\x06 \x01(\v2\x1a.validate.v1.RepeatedRulesH\x00R\brepeated\x125\n" +
+	"\amessage\x18\a \x01(\v2\x19.validate.v1.MessageRulesH\x00R\amessageB\x06\n" +
+	"\x04type\"\x93\x01\n" +
+	"\vStringRules\x12\x17\n" +
+	"\amin_len\x18\x01 \x01(\x04R\x06minLen\x12\x17\n" +
+	"\amax_len\x18\x02 \x01(\x04R\x06maxLen\x12\x1b\n" +
+	"\tmax_bytes\x18\x03 \x01(\x04R\bmaxBytes\x12\x18\n" +
+	"\apattern\x18\x04 \x01(\tR\apattern\x12\x1b\n" +
+	"\tnot_blank\x18\x05 \x01(\bR\bnotBlank\"\x80\x01\n" +
+	"\bIntRules\x12\x13\n" +
+	"\x02gt\x18\x01 \x01(\x03H\x00R\x02gt\x88\x01\x01\x12\x15\n" +
+	"\x03gte\x18\x02 \x01(\x03H\x01R\x03gte\x88\x01\x01\x12\x13\n" +
+	"\x02lt\x18\x03 \x01(\x03H\x02R\x02lt\x88\x01\x01\x12\x15\n" +
+	"\x03lte\x18\x04 \x01(\x03H\x03R\x03lte\x88\x01\x01B\x05\n" +
+	"\x03_gtB\x06\n" +
+	"\x04_gteB\x05\n" +
+	"\x03_ltB\x06\n" +
+	"\x04_lte\"(\n" +
+	"\n" +
+	"MoneyRules\x12\x1a\n" +
+	"\bpositive\x18\x01 \x01(\bR\bpositive\".\n" +
+	"\tEnumRules\x12!\n" +
+	"\fdefined_only\x18\x01 \x01(\bR\vdefinedOnly\"\xa1\x01\n" +
+	"\fMessageRules\x12=\n" +
+	"\x06fields\x18\x01 \x03(\v2%.validate.v1.MessageRules.FieldsEntryR\x06fields\x1aR\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.validate.v1.FieldRulesR\x05value:\x028\x01\"x\n" +
+	"\rRepeatedRules\x12\x1b\n" +
+	"\tmin_items\x18\x01 \x01(\x04R\bminItems\x12\x1b\n" +
+	"\tmax_items\x18\x02 \x01(\x04R\bmaxItems\x12-\n" +
+	"\x05items\x18\x03 \x01(\v2\x17.validate.v1.FieldRulesR\x05items:N\n" +
+	"\x05field\x12\x1d.google.protobuf.FieldOptions\x18\xff\x8e\x03 \x01(\v2\x17.validate.v1.FieldRulesR\x05fieldB\xaa\x01\n" +
+	"\x0fcom.validate.v1B\rValidateProtoP\x01Z;github.com/yaninyzwitty/go-fx-v1/gen/validate/v1;validatev1\xa2\x02\x03VXX\xaa\x02\vValidate.V1\xca\x02\vValidate\\V1\xe2\x02\x17Validate\\V1\\GPBMetadata\xea\x02\fValidate::V1b\x06proto3"
+
+var (
+	file_validate_v1_validate_proto_rawDescOnce sync.Once
+	file_validate_v1_validate_proto_rawDescData []byte
+)
+
+func file_validate_v1_validate_proto_rawDescGZIP() []byte {
+	file_validate_v1_validate_proto_rawDescOnce.Do(func() {
+		file_validate_v1_validate_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_validate_v1_validate_proto_rawDesc), len(file_validate_v1_validate_proto_rawDesc)))
+	})
+	return file_validate_v1_validate_proto_rawDescData
+}
+
+var file_validate_v1_validate_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_validate_v1_validate_proto_goTypes = []any{
+	(*FieldRules)(nil),                // 0: validate.v1.FieldRules
+	(*StringRules)(nil),               // 1: validate.v1.StringRules
+	(*IntRules)(nil),                  // 2: validate.v1.IntRules
+	(*MoneyRules)(nil),                // 3: validate.v1.MoneyRules
+	(*EnumRules)(nil),                 // 4: validate.v1.EnumRules
+	(*MessageRules)(nil),              // 5: validate.v1.MessageRules
+	(*RepeatedRules)(nil),             // 6: validate.v1.RepeatedRules
+	nil,                               // 7: validate.v1.MessageRules.FieldsEntry
+	(*descriptorpb.FieldOptions)(nil), // 8: google.protobuf.FieldOptions
+}
+var file_validate_v1_validate_proto_depIdxs = []int32{
+	1,  // 0: validate.v1.FieldRules.string:type_name -> validate.v1.StringRules
+	2,  // 1: validate.v1.FieldRules.int:type_name -> validate.v1.IntRules
+	3,  // 2: validate.v1.FieldRules.money:type_name -> validate.v1.MoneyRules
+	4,  // 3: validate.v1.FieldRules.enum:type_name -> validate.v1.EnumRules
+	6,  // 4: validate.v1.FieldRules.repeated:type_name -> validate.v1.RepeatedRules
+	5,  // 5: validate.v1.FieldRules.message:type_name -> validate.v1.MessageRules
+	7,  // 6: validate.v1.MessageRules.fields:type_name -> validate.v1.MessageRules.FieldsEntry
+	0,  // 7: validate.v1.RepeatedRules.items:type_name -> validate.v1.FieldRules
+	0,  // 8: validate.v1.MessageRules.FieldsEntry.value:type_name -> validate.v1.FieldRules
+	8,  // 9: validate.v1.field:extendee -> google.protobuf.FieldOptions
+	0,  // 10: validate.v1.field:type_name -> validate.v1.FieldRules
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	10, // [10:11] is the sub-list for extension type_name
+	9,  // [9:10] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_validate_v1_validate_proto_init() }
+func file_validate_v1_validate_proto_init() {
+	if File_validate_v1_validate_proto != nil {
+		return
+	}
+	file_validate_v1_validate_proto_msgTypes[0].OneofWrappers = []any{
+		(*FieldRules_String_)(nil),
+		(*FieldRules_Int)(nil),
+		(*FieldRules_Money)(nil),
+		(*FieldRules_Enum)(nil),
+		(*FieldRules_Repeated)(nil),
+		(*FieldRules_Message)(nil),
+	}
+	file_validate_v1_validate_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_validate_v1_validate_proto_rawDesc), len(file_validate_v1_validate_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_validate_v1_validate_proto_goTypes,
+		DependencyIndexes: file_validate_v1_validate_proto_depIdxs,
+		MessageInfos:      file_validate_v1_validate_proto_msgTypes,
+		ExtensionInfos:    file_validate_v1_validate_proto_extTypes,
+	}.Build()
+	File_validate_v1_validate_proto = out.File
+	file_validate_v1_validate_proto_goTypes = nil
+	file_validate_v1_validate_proto_depIdxs = nil
+}
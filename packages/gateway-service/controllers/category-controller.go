@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/router"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// CategoriesRouteHandler handles HTTP requests for the category tree.
+// Products are browsed by category through
+// /api/v1/products?category_id={id}.
+type CategoriesRouteHandler struct {
+	controller *ProductController
+}
+
+// NewCategoriesRouteHandler constructs a route handler for category
+// endpoints.
+func NewCategoriesRouteHandler(controller *ProductController) router.RouteHandler {
+	return &CategoriesRouteHandler{controller: controller}
+}
+
+// Pattern returns the base route for categories.
+func (h *CategoriesRouteHandler) Pattern() string {
+	return "/api/v1/categories"
+}
+
+// Patterns returns all supported route patterns.
+func (h *CategoriesRouteHandler) Patterns() []string {
+	return []string{
+		"/api/v1/categories",  // collection
+		"/api/v1/categories/", // item prefix
+	}
+}
+
+// ServeHTTP dispatches requests to the appropriate handler.
+func (h *CategoriesRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := h.parseRoute(r.URL.Path)
+	if route == nil {
+		h.controller.notFound(w, r)
+		return
+	}
+
+	switch route.Type {
+	case routeTypeCollection:
+		switch r.Method {
+		case http.MethodGet:
+			h.handleListCategories(w, r)
+		case http.MethodPost:
+			h.handleCreateCategory(w, r)
+		default:
+			h.controller.methodNotAllowed(w, r)
+		}
+
+	case routeTypeItem:
+		switch r.Method {
+		case http.MethodGet:
+			h.handleGetCategory(w, r, uint64(route.ID))
+		case http.MethodPatch:
+			h.handleUpdateCategory(w, r, uint64(route.ID))
+		case http.MethodDelete:
+			h.handleDeleteCategory(w, r, uint64(route.ID))
+		default:
+			h.controller.methodNotAllowed(w, r)
+		}
+
+	default:
+		h.controller.notFound(w, r)
+	}
+}
+
+// parseRoute determines if the request is for the collection or an item.
+func (h *CategoriesRouteHandler) parseRoute(path string) *parsedRoute {
+	base := "/api/v1/categories"
+	path = strings.TrimSuffix(path, "/")
+
+	if path == base {
+		return &parsedRoute{Type: routeTypeCollection}
+	}
+	if idStr, ok := strings.CutPrefix(path, base+"/"); ok {
+		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil && id > 0 {
+			return &parsedRoute{Type: routeTypeItem, ID: id}
+		}
+	}
+	return nil
+}
+
+// handleListCategories lists the children of ?parent_id, or the roots when
+// it is absent.
+func (h *CategoriesRouteHandler) handleListCategories(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	q := r.URL.Query()
+
+	var parentID uint64
+	if v := q.Get("parent_id"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 63)
+		if err != nil {
+			h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("parent_id", "must be a category ID"))
+			return
+		}
+		parentID = parsed
+	}
+
+	var pageSize uint32
+	if ps := q.Get("page_size"); ps != "" {
+		if parsed, err := strconv.ParseUint(ps, 10, 32); err == nil {
+			pageSize = uint32(parsed)
+		}
+	}
+
+	resp, err := h.controller.client.ListCategories(ctx, &productsv1.ListCategoriesRequest{
+		ParentId:  parentID,
+		PageSize:  pageSize,
+		PageToken: q.Get("page_token"),
+	})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to list categories")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateCategory creates a category from {"parent_id": ..., "name": ...}.
+func (h *CategoriesRouteHandler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var req productsv1.CreateCategoryRequest
+	if err := h.controller.decodeJSONBody(r, &req); err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+
+	resp, err := h.controller.client.CreateCategory(ctx, &req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to create category")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleGetCategory retrieves a single category by ID.
+func (h *CategoriesRouteHandler) handleGetCategory(w http.ResponseWriter, r *http.Request, id uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+
+	resp, err := h.controller.client.GetCategory(ctx, &productsv1.GetCategoryRequest{Id: id})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to get category")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleUpdateCategory applies a JSON merge patch (RFC 7396) to a category.
+// Setting parent_id moves the category with its subtree; null makes it a
+// root.
+func (h *CategoriesRouteHandler) handleUpdateCategory(w http.ResponseWriter, r *http.Request, id uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var doc map[string]json.RawMessage
+	if err := h.controller.decodeJSONBody(r, &doc); err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+	req, err := parseCategoryMergePatch(id, doc)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// An empty merge patch is a no-op, so return the category unchanged.
+	if len(req.GetUpdateMask().GetPaths()) == 0 {
+		h.handleGetCategory(w, r, id)
+		return
+	}
+
+	resp, err := h.controller.client.UpdateCategory(ctx, req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to update category")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// parseCategoryMergePatch converts a merge patch document into an
+// UpdateCategoryRequest.
+func parseCategoryMergePatch(id uint64, doc map[string]json.RawMessage) (*productsv1.UpdateCategoryRequest, error) {
+	if doc == nil {
+		return nil, validation.Invalid("body", "must be a JSON object")
+	}
+
+	category := &productsv1.Category{Id: id}
+	paths := make([]string, 0, len(doc))
+	for field, raw := range doc {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		var err error
+		switch field {
+		case "name":
+			err = decodeRequired(field, raw, isNull, &category.Name)
+		case "parent_id":
+			if !isNull {
+				err = json.Unmarshal(raw, &category.ParentId)
+			}
+		default:
+			return nil, validation.Invalid(field, "cannot be updated")
+		}
+		if err != nil {
+			return nil, invalidMember(field, err)
+		}
+
+		paths = append(paths, field)
+	}
+	sort.Strings(paths)
+
+	return &productsv1.UpdateCategoryRequest{
+		Category:   category,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+	}, nil
+}
+
+// handleDeleteCategory deletes a leaf category.
+func (h *CategoriesRouteHandler) handleDeleteCategory(w http.ResponseWriter, r *http.Request, id uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.controller.client.DeleteCategory(ctx, &productsv1.DeleteCategoryRequest{Id: id})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to delete category")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// fakeCategoryClient records the category requests it receives.
+type fakeCategoryClient struct {
+	productsv1.ProductServiceClient
+	list   *productsv1.ListCategoriesRequest
+	update *productsv1.UpdateCategoryRequest
+}
+
+func (f *fakeCategoryClient) ListCategories(_ context.Context, req *productsv1.ListCategoriesRequest, _ ...grpc.CallOption) (*productsv1.ListCategoriesResponse, error) {
+	f.list = req
+	return &productsv1.ListCategoriesResponse{Categories: []*productsv1.Category{{Id: 8, ParentId: req.GetParentId(), Name: "Shoes"}}}, nil
+}
+
+func (f *fakeCategoryClient) UpdateCategory(_ context.Context, req *productsv1.UpdateCategoryRequest, _ ...grpc.CallOption) (*productsv1.UpdateCategoryResponse, error) {
+	f.update = req
+	return &productsv1.UpdateCategoryResponse{Category: req.GetCategory()}, nil
+}
+
+func TestCategoriesRouteHandler_parseRoute(t *testing.T) {
+	handler := &CategoriesRouteHandler{}
+
+	tests := []struct {
+		path string
+		want *parsedRoute
+	}{
+		{"/api/v1/categories", &parsedRoute{Type: routeTypeCollection}},
+		{"/api/v1/categories/", &parsedRoute{Type: routeTypeCollection}},
+		{"/api/v1/categories/7", &parsedRoute{Type: routeTypeItem, ID: 7}},
+		{"/api/v1/categories/0", nil},
+		{"/api/v1/categories/shoes", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, handler.parseRoute(tt.path), tt.path)
+	}
+}
+
+func TestCategoriesRouteHandler_ListChildren(t *testing.T) {
+	client := &fakeCategoryClient{}
+	handler := &CategoriesRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories?parent_id=3&page_size=5", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(3), client.list.GetParentId())
+	assert.Equal(t, uint32(5), client.list.GetPageSize())
+	assert.Contains(t, w.Body.String(), `"name":"Shoes"`)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/categories?parent_id=root", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCategoriesRouteHandler_MoveToRoot(t *testing.T) {
+	client := &fakeCategoryClient{}
+	handler := &CategoriesRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/v1/categories/8", strings.NewReader(`{"parent_id": null}`)))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(8), client.update.GetCategory().GetId())
+	assert.Zero(t, client.update.GetCategory().GetParentId())
+	assert.Equal(t, []string{"parent_id"}, client.update.GetUpdateMask().GetPaths())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/v1/categories/8", strings.NewReader(`{"path": "/1/"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+)
+
+// errWeakETag rejects a weak If-Match tag. If-Match uses the strong
+// comparison (RFC 9110, section 13.1.1), which a weak tag never passes.
+var errWeakETag = errors.New("If-Match requires a strong entity tag")
+
+// setETag exposes a product etag as a strong HTTP entity tag.
+func setETag(w http.ResponseWriter, etag string) {
+	if etag != "" {
+		w.Header().Set("ETag", `"`+etag+`"`)
+	}
+}
+
+// ifMatchETag extracts the entity tag from an If-Match request header.
+// A missing header or "*" yields an empty etag, meaning "no precondition".
+func ifMatchETag(r *http.Request) (string, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(value, "W/") {
+		return "", errWeakETag
+	}
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return "", validation.Invalid("If-Match", "must contain a single quoted entity tag")
+	}
+
+	etag := value[1 : len(value)-1]
+	if etag == "" || strings.Contains(etag, `"`) {
+		return "", validation.Invalid("If-Match", "must contain a single quoted entity tag")
+	}
+	return etag, nil
+}
+
+// ifMatchError responds to an If-Match header ifMatchETag rejected: a weak
+// tag fails the precondition, anything else is a malformed request.
+func (c *ProductController) ifMatchError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errWeakETag) {
+		c.handleError(w, r, apierror.Errorf(codes.Aborted, apierror.ReasonEtagMismatch, nil, "%v", err), "precondition failed")
+		return
+	}
+	c.reject(w, r, http.StatusBadRequest, err)
+}
+
+// etagMismatchError reports a failed If-Match check made by the gateway
+// itself, in the same shape as the product service's.
+func etagMismatchError(id int64, current string) error {
+	return apierror.Errorf(codes.Aborted, apierror.ReasonEtagMismatch,
+		map[string]string{"product_id": strconv.FormatInt(id, 10), "current_etag": current},
+		"etag mismatch for product %d: current etag is %q", id, current)
+}
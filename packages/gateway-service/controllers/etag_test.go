@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIfMatchETag(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    string
+		wantErr bool
+		weak    bool
+	}{
+		{header: "", want: ""},
+		{header: "*", want: ""},
+		{header: `"7"`, want: "7"},
+		{header: `W/"7"`, wantErr: true, weak: true},
+		{header: "7", wantErr: true},
+		{header: `"7", "8"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/1", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := ifMatchETag(req)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.weak, errors.Is(err, errWeakETag))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProductController_handleError_Aborted(t *testing.T) {
+	controller := &ProductController{}
+	w := httptest.NewRecorder()
+
+	r := httptest.NewRequest(http.MethodPatch, "/api/v1/products/1", nil)
+	controller.handleError(w, r, status.Error(codes.Aborted, "etag mismatch"), "failed")
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestProductsRouteHandler_WeakIfMatchFailsPrecondition(t *testing.T) {
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: &fakeProductClient{}}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/products/42", nil)
+	req.Header.Set("If-Match", `W/"3"`)
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestProductsRouteHandler_EmptyPatchChecksIfMatch(t *testing.T) {
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: &fakeProductClient{}}}
+
+	tests := []struct {
+		ifMatch string
+		want    int
+	}{
+		{ifMatch: "", want: http.StatusOK},
+		{ifMatch: `"3"`, want: http.StatusOK},
+		{ifMatch: `"2"`, want: http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifMatch, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/products/42", strings.NewReader(`{}`))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/metadata"
+)
+
+// maxIdempotencyKeyLength matches the limit enforced by the product service.
+const maxIdempotencyKeyLength = 255
+
+// withIdempotencyKey forwards the Idempotency-Key request header to the
+// product service, which replays the stored response for repeated keys.
+// Requests without the header are passed through unchanged.
+func withIdempotencyKey(ctx context.Context, r *http.Request) (context.Context, error) {
+	key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if key == "" {
+		return ctx, nil
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return ctx, validation.Invalid("Idempotency-Key", "must be at most %d bytes", maxIdempotencyKeyLength)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "idempotency-key", key), nil
+}
@@ -0,0 +1,468 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF for image.DecodeConfig
+	_ "image/jpeg" // register JPEG for image.DecodeConfig
+	_ "image/png"  // register PNG for image.DecodeConfig
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/blobstore"
+	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/router"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	defaultMaxUploadBytes = 10 << 20
+	// multipartOverhead leaves room for the form's boundaries and other
+	// fields on top of the file itself.
+	multipartOverhead = 64 << 10
+	// uploadMemory is how much of an upload is buffered in memory before
+	// the rest spills to a temporary file.
+	uploadMemory = 1 << 20
+)
+
+// mediaExtensions lists the accepted upload types and the extension their
+// blobs are stored with. Each must have an image decoder registered.
+var mediaExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+// itemMethodReorder is addressed as /api/v1/products/{id}/media:reorder.
+const itemMethodReorder = "reorder"
+
+// MediaRouteHandler handles HTTP requests for the images of a product.
+// Uploads are stored in blob storage by the gateway; the product service
+// keeps their metadata.
+type MediaRouteHandler struct {
+	controller     *ProductController
+	store          blobstore.Store
+	maxUploadBytes int64
+}
+
+// mediaRoute addresses a product's media collection or one of its items.
+type mediaRoute struct {
+	ProductID uint64
+	MediaID   uint64
+	Method    string
+}
+
+// NewMediaRouteHandler constructs a route handler for product media
+// endpoints.
+func NewMediaRouteHandler(controller *ProductController, store blobstore.Store, cfg *config.Config) router.RouteHandler {
+	maxUploadBytes := cfg.MediaConfig.MaxUploadBytes
+	if maxUploadBytes <= 0 {
+		maxUploadBytes = defaultMaxUploadBytes
+	}
+	return &MediaRouteHandler{controller: controller, store: store, maxUploadBytes: maxUploadBytes}
+}
+
+// Pattern returns the base route for product media.
+func (h *MediaRouteHandler) Pattern() string {
+	return "/api/v1/products/{id}/media"
+}
+
+// Patterns returns all supported route patterns. They are more specific
+// than the products item prefix, so the mux routes media requests here.
+func (h *MediaRouteHandler) Patterns() []string {
+	return []string{
+		"/api/v1/products/{id}/media",                      // collection
+		"/api/v1/products/{id}/media/",                     // item prefix
+		"/api/v1/products/{id}/media:" + itemMethodReorder, // custom method
+	}
+}
+
+// ServeHTTP dispatches requests to the appropriate handler.
+func (h *MediaRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := h.parseRoute(r.URL.Path)
+	if route == nil {
+		h.controller.notFound(w, r)
+		return
+	}
+
+	switch {
+	case route.Method == itemMethodReorder && r.Method == http.MethodPost:
+		h.handleReorderMedia(w, r, route.ProductID)
+	case route.Method != "":
+		h.controller.methodNotAllowed(w, r)
+	case route.MediaID == 0 && r.Method == http.MethodPost:
+		h.handleUploadMedia(w, r, route.ProductID)
+	case route.MediaID != 0 && r.Method == http.MethodPatch:
+		h.handleUpdateMedia(w, r, route.ProductID, route.MediaID)
+	case route.MediaID != 0 && r.Method == http.MethodDelete:
+		h.handleDeleteMedia(w, r, route.ProductID, route.MediaID)
+	default:
+		h.controller.methodNotAllowed(w, r)
+	}
+}
+
+// parseRoute splits /api/v1/products/{id}/media[/{media_id}|:reorder].
+func (h *MediaRouteHandler) parseRoute(p string) *mediaRoute {
+	rest, ok := strings.CutPrefix(strings.TrimSuffix(p, "/"), "/api/v1/products/")
+	if !ok {
+		return nil
+	}
+	idStr, rest, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil
+	}
+	productID, err := strconv.ParseUint(idStr, 10, 63)
+	if err != nil || productID == 0 {
+		return nil
+	}
+
+	switch {
+	case rest == "media":
+		return &mediaRoute{ProductID: productID}
+	case rest == "media:"+itemMethodReorder:
+		return &mediaRoute{ProductID: productID, Method: itemMethodReorder}
+	}
+	if mediaStr, ok := strings.CutPrefix(rest, "media/"); ok {
+		if mediaID, err := strconv.ParseUint(mediaStr, 10, 63); err == nil && mediaID > 0 {
+			return &mediaRoute{ProductID: productID, MediaID: mediaID}
+		}
+	}
+	return nil
+}
+
+// upload is an uploaded image that has been checked and hashed.
+type upload struct {
+	file        multipart.File
+	contentType string
+	checksum    string
+	size        int64
+	width       int
+	height      int
+}
+
+// inspectUpload checks that file is an accepted image and reads its
+// checksum and dimensions. file is left positioned at its start.
+func inspectUpload(file multipart.File) (*upload, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if _, ok := mediaExtensions[contentType]; !ok {
+		return nil, fmt.Errorf("unsupported image type %q", contentType)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, errors.New("image has no pixels")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return &upload{
+		file:        file,
+		contentType: contentType,
+		checksum:    hex.EncodeToString(hash.Sum(nil)),
+		size:        size,
+		width:       cfg.Width,
+		height:      cfg.Height,
+	}, nil
+}
+
+// storageKey names the blob of an upload. Keys are content-addressed per
+// product, matching the uniqueness of (product_id, checksum) in the
+// product service.
+func (u *upload) storageKey(productID uint64) string {
+	return fmt.Sprintf("products/%d/%s%s", productID, u.checksum, mediaExtensions[u.contentType])
+}
+
+// stagingKey names a blob that no media refers to yet. Each upload gets its
+// own, so it can be deleted without checking who else uses it.
+func stagingKey() string {
+	return "uploads/" + rand.Text()
+}
+
+// mediaMayExist reports whether media may refer to the storage key after
+// AddProductMedia returned err: the call succeeded, the image was already
+// attached, or the call failed without an answer and may have committed.
+func mediaMayExist(err error) bool {
+	switch status.Code(err) {
+	case codes.OK, codes.AlreadyExists, codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// handleUploadMedia stores a multipart upload ("file" plus an optional
+// "alt_text" field) in blob storage and attaches it to the product.
+//
+// The blob is staged under its own key and only moved to its
+// content-addressed key once media may refer to it. The content-addressed
+// blob may belong to an image attached by an earlier or concurrent upload,
+// so a failed upload never deletes it.
+func (h *MediaRouteHandler) handleUploadMedia(w http.ResponseWriter, r *http.Request, productID uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadBytes+multipartOverhead)
+	if err := r.ParseMultipartForm(uploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.controller.reject(w, r, http.StatusRequestEntityTooLarge, validation.Invalid("file", "must be at most %d bytes", h.maxUploadBytes))
+			return
+		}
+		h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("body", "must be a multipart form"))
+		return
+	}
+	defer func() {
+		if err := r.MultipartForm.RemoveAll(); err != nil {
+			h.controller.logger.Error("failed to remove upload", zap.Error(err))
+		}
+	}()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("file", "is required"))
+		return
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			h.controller.logger.Error("failed to close upload", zap.Error(err))
+		}
+	}()
+	if header.Size > h.maxUploadBytes {
+		h.controller.reject(w, r, http.StatusRequestEntityTooLarge, validation.Invalid("file", "must be at most %d bytes", h.maxUploadBytes))
+		return
+	}
+
+	up, err := inspectUpload(file)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("file", "%v", err))
+		return
+	}
+
+	staged := stagingKey()
+	if err := h.store.Put(ctx, staged, up.file); err != nil {
+		h.controller.logger.Error("failed to store upload", zap.Error(err), zap.String("key", staged))
+		h.controller.internalError(w, r)
+		return
+	}
+
+	key := up.storageKey(productID)
+	resp, err := h.controller.client.AddProductMedia(ctx, &productsv1.AddProductMediaRequest{
+		ProductId:      productID,
+		StorageKey:     key,
+		ContentType:    up.contentType,
+		ChecksumSha256: up.checksum,
+		SizeBytes:      uint64(up.size),
+		Width:          uint32(up.width),
+		Height:         uint32(up.height),
+		AltText:        r.FormValue("alt_text"),
+	})
+	// The blob must be in place even if the client has gone away.
+	cleanupCtx := context.WithoutCancel(ctx)
+	if !mediaMayExist(err) {
+		h.deleteBlob(cleanupCtx, staged)
+		h.controller.handleError(w, r, err, "failed to add product media")
+		return
+	}
+	// The blob is the same whichever upload moves it into place, so
+	// replacing that of an existing image is harmless.
+	if moveErr := h.store.Move(cleanupCtx, staged, key); moveErr != nil {
+		h.controller.logger.Error("failed to move upload into place", zap.Error(moveErr), zap.String("key", key))
+		h.deleteBlob(cleanupCtx, staged)
+		if err == nil {
+			h.controller.internalError(w, r)
+			return
+		}
+	}
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to add product media")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleUpdateMedia changes the alt text of an image from
+// {"alt_text": "..."}.
+func (h *MediaRouteHandler) handleUpdateMedia(w http.ResponseWriter, r *http.Request, productID, mediaID uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var body struct {
+		AltText *string `json:"alt_text"`
+	}
+	if err := h.controller.decodeJSONBody(r, &body); err != nil || body.AltText == nil {
+		h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("alt_text", "is required"))
+		return
+	}
+
+	resp, err := h.controller.client.UpdateProductMedia(ctx, &productsv1.UpdateProductMediaRequest{
+		Media:      &productsv1.ProductMedia{Id: mediaID, ProductId: productID, AltText: *body.AltText},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"alt_text"}},
+	})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to update product media")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleReorderMedia sets the display order from {"media_ids": [...]},
+// which must list every image of the product.
+func (h *MediaRouteHandler) handleReorderMedia(w http.ResponseWriter, r *http.Request, productID uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var req productsv1.ReorderProductMediaRequest
+	if err := h.controller.decodeJSONBody(r, &req); err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+	req.ProductId = productID
+
+	resp, err := h.controller.client.ReorderProductMedia(ctx, &req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to reorder product media")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleDeleteMedia detaches an image from the product and removes its
+// blob.
+func (h *MediaRouteHandler) handleDeleteMedia(w http.ResponseWriter, r *http.Request, productID, mediaID uint64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := h.controller.client.DeleteProductMedia(ctx, &productsv1.DeleteProductMediaRequest{
+		ProductId: productID,
+		MediaId:   mediaID,
+	})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to delete product media")
+		return
+	}
+
+	h.deleteBlob(ctx, resp.GetMedia().GetStorageKey())
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// deleteBlob removes a blob that no media refers to any more. Failures
+// only leave an orphaned blob behind, so they are logged, not returned.
+func (h *MediaRouteHandler) deleteBlob(ctx context.Context, key string) {
+	if err := h.store.Delete(ctx, key); err != nil {
+		h.controller.logger.Warn("failed to delete blob", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// MediaBlobsRouteHandler serves stored blobs at /media/{key}, the default
+// media.base_url of the product service.
+type MediaBlobsRouteHandler struct {
+	controller *ProductController
+	store      blobstore.Store
+}
+
+// NewMediaBlobsRouteHandler constructs a route handler that serves blobs.
+func NewMediaBlobsRouteHandler(controller *ProductController, store blobstore.Store) router.RouteHandler {
+	return &MediaBlobsRouteHandler{controller: controller, store: store}
+}
+
+// Pattern returns the prefix blobs are served under.
+func (h *MediaBlobsRouteHandler) Pattern() string {
+	return "/media/"
+}
+
+// ServeHTTP streams the blob named by the rest of the path.
+func (h *MediaBlobsRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		h.controller.methodNotAllowed(w, r)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, h.Pattern())
+	blob, err := h.store.Open(r.Context(), key)
+	if errors.Is(err, blobstore.ErrNotFound) || errors.Is(err, blobstore.ErrInvalidKey) {
+		h.controller.notFound(w, r)
+		return
+	}
+	if err != nil {
+		h.controller.logger.Error("failed to open blob", zap.Error(err), zap.String("key", key))
+		h.controller.internalError(w, r)
+		return
+	}
+	defer func() {
+		if err := blob.Close(); err != nil {
+			h.controller.logger.Error("failed to close blob", zap.Error(err))
+		}
+	}()
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	// Keys are content-addressed, so a blob never changes.
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	if rs, ok := blob.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", time.Time{}, rs)
+		return
+	}
+	if _, err := io.Copy(w, blob); err != nil {
+		h.controller.logger.Error("failed to write blob", zap.Error(err), zap.String("key", key))
+	}
+}
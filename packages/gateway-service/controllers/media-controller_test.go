@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/blobstore"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeMediaClient records the media requests it receives.
+type fakeMediaClient struct {
+	productsv1.ProductServiceClient
+	add    *productsv1.AddProductMediaRequest
+	addErr error
+	del    *productsv1.DeleteProductMediaRequest
+}
+
+func (f *fakeMediaClient) AddProductMedia(_ context.Context, req *productsv1.AddProductMediaRequest, _ ...grpc.CallOption) (*productsv1.AddProductMediaResponse, error) {
+	f.add = req
+	if f.addErr != nil {
+		return nil, f.addErr
+	}
+	return &productsv1.AddProductMediaResponse{Media: &productsv1.ProductMedia{Id: 1, ProductId: req.GetProductId(), StorageKey: req.GetStorageKey()}}, nil
+}
+
+func (f *fakeMediaClient) DeleteProductMedia(_ context.Context, req *productsv1.DeleteProductMediaRequest, _ ...grpc.CallOption) (*productsv1.DeleteProductMediaResponse, error) {
+	f.del = req
+	return &productsv1.DeleteProductMediaResponse{Media: &productsv1.ProductMedia{Id: req.GetMediaId(), StorageKey: "products/7/a.png"}}, nil
+}
+
+// testPNG encodes a blank PNG of the given size.
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+// uploadRequest builds a multipart upload of file to product 7.
+func uploadRequest(t *testing.T, file []byte, altText string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "front.png")
+	require.NoError(t, err)
+	_, err = fw.Write(file)
+	require.NoError(t, err)
+	require.NoError(t, mw.WriteField("alt_text", altText))
+	require.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/7/media", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func newTestMediaHandler(t *testing.T, client productsv1.ProductServiceClient) (*MediaRouteHandler, *blobstore.LocalStore) {
+	t.Helper()
+
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	return &MediaRouteHandler{
+		controller:     &ProductController{logger: zap.NewNop(), client: client},
+		store:          store,
+		maxUploadBytes: 1 << 16,
+	}, store
+}
+
+func TestMediaRouteHandler_parseRoute(t *testing.T) {
+	handler := &MediaRouteHandler{}
+
+	tests := []struct {
+		path string
+		want *mediaRoute
+	}{
+		{"/api/v1/products/7/media", &mediaRoute{ProductID: 7}},
+		{"/api/v1/products/7/media/", &mediaRoute{ProductID: 7}},
+		{"/api/v1/products/7/media/3", &mediaRoute{ProductID: 7, MediaID: 3}},
+		{"/api/v1/products/7/media:reorder", &mediaRoute{ProductID: 7, Method: itemMethodReorder}},
+		{"/api/v1/products/7/media:explode", nil},
+		{"/api/v1/products/0/media", nil},
+		{"/api/v1/products/7/media/front.png", nil},
+		{"/api/v1/products/7", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, handler.parseRoute(tt.path), tt.path)
+	}
+}
+
+func TestMediaRouteHandler_Patterns(t *testing.T) {
+	mux := http.NewServeMux()
+	products := &ProductsRouteHandler{}
+	media := &MediaRouteHandler{}
+	for _, pattern := range products.Patterns() {
+		mux.Handle(pattern, products)
+	}
+	for _, pattern := range media.Patterns() {
+		mux.Handle(pattern, media)
+	}
+
+	for path, want := range map[string]http.Handler{
+		"/api/v1/products/7":               products,
+		"/api/v1/products/7:undelete":      products,
+		"/api/v1/products/7/media":         media,
+		"/api/v1/products/7/media/3":       media,
+		"/api/v1/products/7/media:reorder": media,
+	} {
+		got, _ := mux.Handler(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Same(t, want, got, path)
+	}
+}
+
+func TestMediaRouteHandler_Upload(t *testing.T) {
+	client := &fakeMediaClient{}
+	handler, store := newTestMediaHandler(t, client)
+	file := testPNG(t, 4, 3)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, uploadRequest(t, file, "Front view"))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	sum := sha256.Sum256(file)
+	checksum := hex.EncodeToString(sum[:])
+	assert.Equal(t, uint64(7), client.add.GetProductId())
+	assert.Equal(t, "products/7/"+checksum+".png", client.add.GetStorageKey())
+	assert.Equal(t, "image/png", client.add.GetContentType())
+	assert.Equal(t, checksum, client.add.GetChecksumSha256())
+	assert.Equal(t, uint64(len(file)), client.add.GetSizeBytes())
+	assert.Equal(t, uint32(4), client.add.GetWidth())
+	assert.Equal(t, uint32(3), client.add.GetHeight())
+	assert.Equal(t, "Front view", client.add.GetAltText())
+
+	blob, err := store.Open(context.Background(), client.add.GetStorageKey())
+	require.NoError(t, err)
+	assert.NoError(t, blob.Close())
+}
+
+func TestMediaRouteHandler_Upload_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		file []byte
+		want int
+	}{
+		{"not an image", []byte("hello, world"), http.StatusBadRequest},
+		{"empty", nil, http.StatusBadRequest},
+		{"too large", bytes.Repeat([]byte{0}, 1<<17), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMediaClient{}
+			handler, _ := newTestMediaHandler(t, client)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, uploadRequest(t, tt.file, ""))
+			assert.Equal(t, tt.want, w.Code)
+			assert.Nil(t, client.add)
+
+			var p problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+			assert.Equal(t, apierror.ReasonValidationFailed, p.Code)
+			require.Len(t, p.Errors, 1)
+			assert.Equal(t, "file", p.Errors[0].Field)
+		})
+	}
+}
+
+func TestMediaRouteHandler_Upload_KeepsBlobsInUse(t *testing.T) {
+	file := testPNG(t, 1, 1)
+	sum := sha256.Sum256(file)
+	key := "products/7/" + hex.EncodeToString(sum[:]) + ".png"
+
+	tests := []struct {
+		name     string
+		err      error
+		existing bool
+		wantBlob bool
+	}{
+		{"product not found", status.Error(codes.NotFound, "product with ID 7 not found"), false, false},
+		// A concurrent upload of the same image attached it first.
+		{"too many images", status.Error(codes.FailedPrecondition, "too many images"), true, true},
+		// The blob belongs to the existing image.
+		{"duplicate", status.Error(codes.AlreadyExists, "duplicate image"), false, true},
+		// The image may have been attached before the call failed.
+		{"unavailable", status.Error(codes.Unavailable, "connection reset"), false, true},
+		{"deadline exceeded", status.Error(codes.DeadlineExceeded, "deadline exceeded"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeMediaClient{addErr: tt.err}
+			handler, store := newTestMediaHandler(t, client)
+			if tt.existing {
+				require.NoError(t, store.Put(context.Background(), key, bytes.NewReader(file)))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, uploadRequest(t, file, ""))
+			require.NotNil(t, client.add)
+			require.Equal(t, key, client.add.GetStorageKey())
+
+			blob, err := store.Open(context.Background(), client.add.GetStorageKey())
+			if tt.wantBlob {
+				require.NoError(t, err)
+				assert.NoError(t, blob.Close())
+			} else {
+				assert.ErrorIs(t, err, blobstore.ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestMediaRouteHandler_DeleteRemovesBlob(t *testing.T) {
+	client := &fakeMediaClient{}
+	handler, store := newTestMediaHandler(t, client)
+	require.NoError(t, store.Put(context.Background(), "products/7/a.png", strings.NewReader("png")))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/products/7/media/3", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, uint64(3), client.del.GetMediaId())
+	_, err := store.Open(context.Background(), "products/7/a.png")
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+}
+
+func TestMediaBlobsRouteHandler(t *testing.T) {
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	file := testPNG(t, 2, 2)
+	require.NoError(t, store.Put(context.Background(), "products/7/a.png", bytes.NewReader(file)))
+	handler := &MediaBlobsRouteHandler{controller: &ProductController{logger: zap.NewNop()}, store: store}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media/products/7/a.png", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, file, w.Body.Bytes())
+
+	for _, path := range []string{"/media/products/7/b.png", "/media/../config.yaml"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+}
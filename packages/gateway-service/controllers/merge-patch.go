@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// parseMergePatch converts a JSON merge patch document (RFC 7396) into an
+// UpdateProductRequest. Every member present in the document becomes a path
+// in the update mask; a null member clears optional fields and is rejected
+// for required ones.
+func parseMergePatch(id int64, body []byte) (*productsv1.UpdateProductRequest, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil || doc == nil {
+		return nil, validation.Invalid("body", "must be a JSON object")
+	}
+
+	product := &productsv1.Product{Id: uint64(id)}
+	paths := make([]string, 0, len(doc))
+
+	for field, raw := range doc {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+
+		var err error
+		switch field {
+		case "name":
+			err = decodeRequired(field, raw, isNull, &product.Name)
+		case "description":
+			if !isNull {
+				err = json.Unmarshal(raw, &product.Description)
+			}
+		case "price":
+			// Replaced as a whole so amount and currency stay consistent.
+			err = decodeRequired(field, raw, isNull, &product.Price)
+		case "stock_quantity":
+			if !isNull {
+				err = json.Unmarshal(raw, &product.StockQuantity)
+			}
+		case "category_ids":
+			// Both lists are replaced as a whole; null empties them.
+			if !isNull {
+				err = json.Unmarshal(raw, &product.CategoryIds)
+			}
+		case "tags":
+			if !isNull {
+				err = json.Unmarshal(raw, &product.Tags)
+			}
+		default:
+			return nil, validation.Invalid(field, "cannot be updated")
+		}
+		if err != nil {
+			return nil, invalidMember(field, err)
+		}
+
+		paths = append(paths, field)
+	}
+	sort.Strings(paths)
+
+	return &productsv1.UpdateProductRequest{
+		Product:    product,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+	}, nil
+}
+
+// invalidMember reports a merge patch member that cannot be applied.
+func invalidMember(field string, err error) error {
+	if errors.As(err, new(*validation.Error)) {
+		return err
+	}
+	return validation.Invalid(field, "has an invalid value: %v", err)
+}
+
+// decodeRequired unmarshals a member that may not be removed by the patch.
+func decodeRequired(field string, raw json.RawMessage, isNull bool, dst any) error {
+	if isNull {
+		return validation.Invalid(field, "cannot be null")
+	}
+	return json.Unmarshal(raw, dst)
+}
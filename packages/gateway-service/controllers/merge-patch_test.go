@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMergePatch(t *testing.T) {
+	req, err := parseMergePatch(42, []byte(`{"price": {"currency_code": "USD", "units": 19, "nanos": 990000000}, "description": null}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(42), req.GetProduct().GetId())
+	assert.Equal(t, int64(19), req.GetProduct().GetPrice().GetUnits())
+	assert.Equal(t, int32(990000000), req.GetProduct().GetPrice().GetNanos())
+	assert.Empty(t, req.GetProduct().GetDescription())
+	assert.Equal(t, []string{"description", "price"}, req.GetUpdateMask().GetPaths())
+}
+
+func TestParseMergePatch_Taxonomy(t *testing.T) {
+	req, err := parseMergePatch(42, []byte(`{"category_ids": [3, 9], "tags": null}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{3, 9}, req.GetProduct().GetCategoryIds())
+	assert.Empty(t, req.GetProduct().GetTags())
+	assert.Equal(t, []string{"category_ids", "tags"}, req.GetUpdateMask().GetPaths())
+}
+
+func TestParseMergePatch_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not an object":  `[1, 2]`,
+		"null required":  `{"name": null}`,
+		"unknown field":  `{"id": 7}`,
+		"currency alone": `{"currency": "EUR"}`,
+		"wrong type":     `{"price": "cheap"}`,
+		"negative stock": `{"stock_quantity": -1}`,
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseMergePatch(42, []byte(body))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// problemContentType is the media type of RFC 7807 problem details.
+const problemContentType = "application/problem+json"
+
+// problem is an RFC 7807 problem details body, extended with the stable
+// error code and trace id of the failed call.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the ErrorInfo reason, e.g. "ETAG_MISMATCH".
+	Code    string `json:"code"`
+	TraceID string `json:"trace_id,omitempty"`
+	// Errors lists the fields of the request that are invalid.
+	Errors []problemField `json:"errors,omitempty"`
+	// Metadata is the ErrorInfo metadata, e.g. the current etag.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type problemField struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// statusClientClosedRequest is the non-standard status, popularized by
+// nginx, of a request the client gave up on before it was answered.
+const statusClientClosedRequest = 499
+
+// httpStatus maps gRPC codes to the HTTP status the gateway responds with;
+// codes not listed are internal errors.
+var httpStatus = map[codes.Code]int{
+	codes.Canceled:           statusClientClosedRequest,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusConflict,
+	codes.Aborted:            http.StatusPreconditionFailed,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// statusText is http.StatusText, extended with statusClientClosedRequest.
+func statusText(code int) string {
+	if code == statusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(code)
+}
+
+// newProblem builds the problem for a gRPC status from its details. Internal
+// errors keep their code and trace id but hide their message.
+func newProblem(r *http.Request, st *status.Status) (problem, *errdetails.RetryInfo) {
+	code, ok := httpStatus[st.Code()]
+	detail := st.Message()
+	if !ok {
+		code, detail = http.StatusInternalServerError, "Internal server error"
+	}
+	p := problem{
+		Type:     "about:blank",
+		Title:    statusText(code),
+		Status:   code,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     apierror.ReasonFor(st.Code()),
+	}
+
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			p.Code = d.GetReason()
+			for k, v := range d.GetMetadata() {
+				if k == apierror.TraceIDKey {
+					p.TraceID = v
+					continue
+				}
+				if p.Metadata == nil {
+					p.Metadata = map[string]string{}
+				}
+				p.Metadata[k] = v
+			}
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				p.Errors = append(p.Errors, problemField{Field: v.GetField(), Description: v.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	if p.TraceID == "" {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			p.TraceID = sc.TraceID().String()
+		}
+	}
+	return p, retry
+}
+
+// reasonMethodNotAllowed is the code of problems for a method the route
+// does not support.
+const reasonMethodNotAllowed = "METHOD_NOT_ALLOWED"
+
+// reject answers a request the gateway turns down itself, before or
+// without calling the product service, with a problem of the given HTTP
+// status built from err as handleError would. A validation error, as
+// returned by validation.Invalid, gives the problem the code
+// VALIDATION_FAILED and lists the field.
+func (c *ProductController) reject(w http.ResponseWriter, r *http.Request, code int, err error) {
+	st := status.Convert(err)
+	p, _ := newProblem(r, st)
+	p.Status, p.Title, p.Detail = code, statusText(code), st.Message()
+	c.writeProblem(w, p, nil)
+}
+
+// notFound answers a path no route matches.
+func (c *ProductController) notFound(w http.ResponseWriter, r *http.Request) {
+	c.reject(w, r, http.StatusNotFound, status.Error(codes.NotFound, "Not found"))
+}
+
+// methodNotAllowed answers a method the matched route does not support.
+func (c *ProductController) methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	c.reject(w, r, http.StatusMethodNotAllowed,
+		apierror.Errorf(codes.Unimplemented, reasonMethodNotAllowed, nil, "Method not allowed"))
+}
+
+// internalError answers a failure of the gateway itself, which the caller
+// has already logged.
+func (c *ProductController) internalError(w http.ResponseWriter, r *http.Request) {
+	c.reject(w, r, http.StatusInternalServerError, status.Error(codes.Internal, "Internal server error"))
+}
+
+// writeProblem writes p, with a Retry-After header when the error suggests
+// a retry delay.
+func (c *ProductController) writeProblem(w http.ResponseWriter, p problem, retry *errdetails.RetryInfo) {
+	if retry != nil {
+		seconds := int(math.Ceil(retry.GetRetryDelay().AsDuration().Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		c.logger.Error("failed to encode problem", zap.Error(err))
+	}
+}
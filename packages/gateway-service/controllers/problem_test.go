@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serveError runs handleError for err and decodes the problem it writes.
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, problem) {
+	t.Helper()
+
+	controller := &ProductController{logger: zap.NewNop()}
+	w := httptest.NewRecorder()
+	controller.handleError(w, httptest.NewRequest(http.MethodPost, "/api/v1/products", nil), err, "failed")
+
+	require.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+	var p problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	return w, p
+}
+
+func TestProductController_handleError_ValidationProblem(t *testing.T) {
+	err := validation.Validate(&productsv1.CreateProductRequest{Price: &productsv1.Money{CurrencyCode: "usd", Units: 1}})
+	st := status.Convert(err)
+	withTrace, _ := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   apierror.ReasonValidationFailed,
+		Metadata: map[string]string{apierror.TraceIDKey: "4bf92f3577b34da6a3ce929d0e0e4736"},
+	})
+
+	w, p := serveError(t, withTrace.Err())
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problem{
+		Type:     "about:blank",
+		Title:    "Bad Request",
+		Status:   http.StatusBadRequest,
+		Detail:   "invalid request: name is required; price.currency_code must match ^[A-Z]{3}$",
+		Instance: "/api/v1/products",
+		Code:     apierror.ReasonValidationFailed,
+		TraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+		Errors: []problemField{
+			{Field: "name", Description: "is required"},
+			{Field: "price.currency_code", Description: "must match ^[A-Z]{3}$"},
+		},
+	}, p)
+}
+
+func TestProductController_handleError_ReasonAndMetadata(t *testing.T) {
+	err := apierror.Errorf(codes.Aborted, apierror.ReasonEtagMismatch, map[string]string{"current_etag": "5"}, "etag mismatch")
+
+	w, p := serveError(t, err)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, apierror.ReasonEtagMismatch, p.Code)
+	assert.Equal(t, map[string]string{"current_etag": "5"}, p.Metadata)
+}
+
+func TestProductController_handleError_RetryAfter(t *testing.T) {
+	err := apierror.Retry(status.Error(codes.Unavailable, "still in progress"), 1500*time.Millisecond)
+
+	w, p := serveError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Equal(t, "UNAVAILABLE", p.Code)
+}
+
+func TestProductController_handleError_StatusCodes(t *testing.T) {
+	for code, want := range map[codes.Code]int{
+		codes.Canceled:           statusClientClosedRequest,
+		codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+		codes.FailedPrecondition: http.StatusConflict,
+		codes.ResourceExhausted:  http.StatusTooManyRequests,
+		codes.Unimplemented:      http.StatusNotImplemented,
+		codes.PermissionDenied:   http.StatusForbidden,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+	} {
+		t.Run(code.String(), func(t *testing.T) {
+			w, p := serveError(t, status.Error(code, "not now"))
+
+			assert.Equal(t, want, w.Code)
+			assert.Equal(t, want, p.Status)
+			assert.NotEmpty(t, p.Title)
+			assert.Equal(t, "not now", p.Detail)
+		})
+	}
+}
+
+func TestProductController_handleError_HidesInternalErrors(t *testing.T) {
+	for _, err := range []error{
+		status.Error(codes.Internal, "failed to get product: connection refused"),
+		errors.New("boom"),
+	} {
+		w, p := serveError(t, err)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "Internal server error", p.Detail)
+		assert.NotEmpty(t, p.Code)
+	}
+}
+
+func TestProductController_reject(t *testing.T) {
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop()}}
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   string
+		fields []problemField
+	}{
+		{"unknown path", httptest.NewRequest(http.MethodGet, "/api/v1/products:frobnicate", nil), http.StatusNotFound, "NOT_FOUND", nil},
+		{"unsupported method", httptest.NewRequest(http.MethodPut, "/api/v1/products", nil), http.StatusMethodNotAllowed, reasonMethodNotAllowed, nil},
+		{
+			"invalid query", httptest.NewRequest(http.MethodGet, "/api/v1/products?in_stock=maybe", nil), http.StatusBadRequest, apierror.ReasonValidationFailed,
+			[]problemField{{Field: "in_stock", Description: "must be true or false"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tt.req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			var p problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, http.StatusText(tt.status), p.Title)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.fields, p.Errors)
+		})
+	}
+}
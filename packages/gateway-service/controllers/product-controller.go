@@ -5,17 +5,22 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
 	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/router"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // Params contains dependencies for the product controller.
@@ -45,18 +50,48 @@ type ProductsRouteHandler struct {
 	controller *ProductController
 }
 
-// routeType distinguishes between collection and item routes.
+// routeType distinguishes between collection, item and custom method routes.
 type routeType int
 
 const (
 	routeTypeUnknown routeType = iota
 	routeTypeCollection
 	routeTypeItem
+	routeTypeCustom
 )
 
+// Custom methods on the product collection, addressed as
+// /api/v1/products:<method>.
+const (
+	customMethodSearch      = "search"
+	customMethodSuggest     = "suggest"
+	customMethodBatchGet    = "batchGet"
+	customMethodBatchCreate = "batchCreate"
+)
+
+// Custom methods on a single product, addressed as
+// /api/v1/products/{id}:<method>.
+const (
+	itemMethodUndelete  = "undelete"
+	itemMethodRevisions = "revisions"
+)
+
+var itemMethods = []string{
+	itemMethodUndelete,
+	itemMethodRevisions,
+}
+
+var customMethods = []string{
+	customMethodSearch,
+	customMethodSuggest,
+	customMethodBatchGet,
+	customMethodBatchCreate,
+}
+
 type parsedRoute struct {
-	Type routeType
-	ID   int64
+	Type   routeType
+	ID     int64
+	Method string
 }
 
 // NewProductsRouteHandler constructs a route handler for product endpoints.
@@ -71,17 +106,21 @@ func (h *ProductsRouteHandler) Pattern() string {
 
 // Patterns returns all supported route patterns.
 func (h *ProductsRouteHandler) Patterns() []string {
-	return []string{
+	patterns := []string{
 		"/api/v1/products",  // collection
 		"/api/v1/products/", // item prefix
 	}
+	for _, method := range customMethods {
+		patterns = append(patterns, "/api/v1/products:"+method)
+	}
+	return patterns
 }
 
 // ServeHTTP dispatches requests to the appropriate handler.
 func (h *ProductsRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	route := h.parseRoute(r.URL.Path)
 	if route == nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+		h.controller.notFound(w, r)
 		return
 	}
 
@@ -93,21 +132,54 @@ func (h *ProductsRouteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		case http.MethodPost:
 			h.handleCreateProduct(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			h.controller.methodNotAllowed(w, r)
 		}
 
 	case routeTypeItem:
+		if route.Method != "" {
+			switch {
+			case route.Method == itemMethodUndelete && r.Method == http.MethodPost:
+				h.handleUndeleteProduct(w, r, route.ID)
+			case route.Method == itemMethodRevisions && r.Method == http.MethodGet:
+				h.handleListProductRevisions(w, r, route.ID)
+			default:
+				h.controller.methodNotAllowed(w, r)
+			}
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			h.handleGetProduct(w, r, route.ID)
+		case http.MethodPatch:
+			h.handleUpdateProduct(w, r, route.ID)
 		case http.MethodDelete:
 			h.handleDeleteProduct(w, r, route.ID)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			h.controller.methodNotAllowed(w, r)
 		}
 
+	case routeTypeCustom:
+		h.serveCustomMethod(w, r, route.Method)
+
 	default:
-		http.Error(w, "Not found", http.StatusNotFound)
+		h.controller.notFound(w, r)
+	}
+}
+
+// serveCustomMethod dispatches /api/v1/products:<method> requests.
+func (h *ProductsRouteHandler) serveCustomMethod(w http.ResponseWriter, r *http.Request, method string) {
+	switch {
+	case method == customMethodSearch && r.Method == http.MethodGet:
+		h.handleSearchProducts(w, r)
+	case method == customMethodSuggest && r.Method == http.MethodGet:
+		h.handleSuggestProducts(w, r)
+	case method == customMethodBatchGet && r.Method == http.MethodPost:
+		h.handleBatchGetProducts(w, r)
+	case method == customMethodBatchCreate && r.Method == http.MethodPost:
+		h.handleBatchCreateProducts(w, r)
+	default:
+		h.controller.methodNotAllowed(w, r)
 	}
 }
 
@@ -120,10 +192,20 @@ func (h *ProductsRouteHandler) parseRoute(path string) *parsedRoute {
 		return &parsedRoute{Type: routeTypeCollection}
 	}
 
+	if method, ok := strings.CutPrefix(path, base+":"); ok {
+		if slices.Contains(customMethods, method) {
+			return &parsedRoute{Type: routeTypeCustom, Method: method}
+		}
+		return nil
+	}
+
 	if after, ok := strings.CutPrefix(path, base+"/"); ok {
-		idStr := after
+		idStr, method, hasMethod := strings.Cut(after, ":")
+		if hasMethod && !slices.Contains(itemMethods, method) {
+			return nil
+		}
 		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
-			return &parsedRoute{Type: routeTypeItem, ID: id}
+			return &parsedRoute{Type: routeTypeItem, ID: id, Method: method}
 		}
 	}
 
@@ -134,13 +216,27 @@ func (h *ProductsRouteHandler) parseRoute(path string) *parsedRoute {
 func (h *ProductsRouteHandler) handleGetProduct(w http.ResponseWriter, r *http.Request, id int64) {
 	ctx := h.controller.contextWithTelemetry(r.Context())
 
-	resp, err := h.controller.client.GetProduct(ctx, &productsv1.GetProductRequest{Id: id})
+	req := &productsv1.GetProductRequest{
+		Id:              id,
+		DisplayCurrency: r.URL.Query().Get("display_currency"),
+	}
+	if raw := r.URL.Query().Get("as_of"); raw != "" {
+		asOf, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			h.controller.reject(w, r, http.StatusBadRequest, validation.Invalid("as_of", "must be an RFC 3339 timestamp"))
+			return
+		}
+		req.AsOf = timestamppb.New(asOf)
+	}
+
+	resp, err := h.controller.client.GetProduct(ctx, req)
 	if err != nil {
-		h.controller.handleError(w, err, "failed to get product")
+		h.controller.handleError(w, r, err, "failed to get product")
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, resp)
+	setETag(w, resp.GetProduct().GetEtag())
+	h.controller.writeJSON(w, http.StatusOK, resp)
 }
 
 // handleListProducts retrieves a paginated list of products.
@@ -155,40 +251,138 @@ func (h *ProductsRouteHandler) handleListProducts(w http.ResponseWriter, r *http
 		}
 	}
 
-	pageToken := r.URL.Query().Get("page_token")
-	var pageTokenUint32 uint32
-	if pageToken == "" {
-		pageTokenUint32 = 0
-	} else {
-		parsed, err := strconv.ParseUint(pageToken, 10, 32)
+	filter, err := parseProductFilter(r.URL.Query())
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// Page tokens are opaque to the gateway and forwarded untouched.
+	resp, err := h.controller.client.ListProducts(ctx, &productsv1.ListProductsRequest{
+		PageSize:  pageSize,
+		PageToken: r.URL.Query().Get("page_token"),
+		Filter:    filter,
+		OrderBy:   r.URL.Query().Get("order_by"),
+		// Anything but a literal "true" keeps deleted products hidden.
+		ShowDeleted:     r.URL.Query().Get("show_deleted") == "true",
+		DisplayCurrency: r.URL.Query().Get("display_currency"),
+	})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to list products")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// parseProductFilter maps list query parameters onto a ProductFilter.
+func parseProductFilter(q url.Values) (*productsv1.ProductFilter, error) {
+	filter := &productsv1.ProductFilter{
+		Currency:   q.Get("currency"),
+		NamePrefix: q.Get("name_prefix"),
+	}
+
+	// Price bounds are exact decimals in the currency given by the
+	// currency parameter, e.g. currency=USD&min_price=9.99.
+	for param, dst := range map[string]**productsv1.Money{
+		"min_price": &filter.MinPrice,
+		"max_price": &filter.MaxPrice,
+	} {
+		if v := q.Get(param); v != "" {
+			if filter.GetCurrency() == "" {
+				return nil, validation.Invalid(param, "requires currency")
+			}
+			parsed, err := money.Parse(filter.GetCurrency(), v)
+			if err != nil {
+				return nil, validation.Invalid(param, "%v", err)
+			}
+			*dst = parsed
+		}
+	}
+
+	if v := q.Get("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
 		if err != nil {
-			h.controller.handleError(w, err, "invalid page_token")
-			return
+			return nil, validation.Invalid("in_stock", "must be true or false")
 		}
-		pageTokenUint32 = uint32(parsed)
+		filter.InStockOnly = inStock
 	}
 
-	resp, err := h.controller.client.ListProducts(ctx, &productsv1.ListProductsRequest{
+	// category_id also matches products in every category below it.
+	if v := q.Get("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 63)
+		if err != nil {
+			return nil, validation.Invalid("category_id", "must be a category ID")
+		}
+		filter.CategoryId = id
+	}
+	filter.Tag = q.Get("tag")
+
+	return filter, nil
+}
+
+// handleSearchProducts runs a ranked full-text search over products.
+func (h *ProductsRouteHandler) handleSearchProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	q := r.URL.Query()
+
+	var pageSize uint32
+	if ps := q.Get("page_size"); ps != "" {
+		if parsed, err := strconv.ParseUint(ps, 10, 32); err == nil {
+			pageSize = uint32(parsed)
+		}
+	}
+
+	resp, err := h.controller.client.SearchProducts(ctx, &productsv1.SearchProductsRequest{
+		Query:     q.Get("q"),
 		PageSize:  pageSize,
-		PageToken: pageTokenUint32,
+		PageToken: q.Get("page_token"),
 	})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to search products")
+		return
+	}
 
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleSuggestProducts returns name completions for a prefix.
+func (h *ProductsRouteHandler) handleSuggestProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	q := r.URL.Query()
+
+	var maxResults uint32
+	if v := q.Get("max_results"); v != "" {
+		if parsed, err := strconv.ParseUint(v, 10, 32); err == nil {
+			maxResults = uint32(parsed)
+		}
+	}
+
+	resp, err := h.controller.client.SuggestProducts(ctx, &productsv1.SuggestProductsRequest{
+		Prefix:     q.Get("prefix"),
+		MaxResults: maxResults,
+	})
 	if err != nil {
-		h.controller.handleError(w, err, "failed to list products")
+		h.controller.handleError(w, r, err, "failed to suggest products")
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, resp)
+	h.controller.writeJSON(w, http.StatusOK, resp)
 }
 
 // handleCreateProduct creates a new product.
 func (h *ProductsRouteHandler) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
 	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		h.controller.logger.Error("failed to read request body", zap.Error(err))
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
 		return
 	}
 	defer func() {
@@ -201,61 +395,255 @@ func (h *ProductsRouteHandler) handleCreateProduct(w http.ResponseWriter, r *htt
 	var req productsv1.CreateProductRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		h.controller.logger.Error("failed to unmarshal request", zap.Error(err))
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
 		return
 	}
 
 	resp, err := h.controller.client.CreateProduct(ctx, &req)
 	if err != nil {
-		h.controller.handleError(w, err, "failed to create product")
+		h.controller.handleError(w, r, err, "failed to create product")
+		return
+	}
+
+	setETag(w, resp.GetProduct().GetEtag())
+	h.controller.writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleBatchGetProducts fetches several products in one call. The body is
+// {"ids": [...]}; missing ids are reported rather than failing the request.
+func (h *ProductsRouteHandler) handleBatchGetProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+
+	var req productsv1.BatchGetProductsRequest
+	if err := h.controller.decodeJSONBody(r, &req); err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+
+	resp, err := h.controller.client.BatchGetProducts(ctx, &req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to batch get products")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBatchCreateProducts creates several products atomically. The body is
+// {"requests": [...]} with one CreateProduct payload per product.
+func (h *ProductsRouteHandler) handleBatchCreateProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	h.writeJSON(w, http.StatusCreated, resp)
+	var req productsv1.BatchCreateProductsRequest
+	if err := h.controller.decodeJSONBody(r, &req); err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+
+	resp, err := h.controller.client.BatchCreateProducts(ctx, &req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to batch create products")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusCreated, resp)
+}
+
+// errInvalidBody rejects a request body that does not decode into the
+// request.
+var errInvalidBody = validation.Invalid("body", "must be a valid JSON request")
+
+// decodeJSONBody reads and closes the request body and unmarshals it into v.
+func (c *ProductController) decodeJSONBody(r *http.Request, v any) error {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			c.logger.Error("failed to close body", zap.Error(err))
+		}
+	}()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		c.logger.Error("failed to read request body", zap.Error(err))
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		c.logger.Error("failed to unmarshal request", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleUpdateProduct applies a JSON merge patch (RFC 7396) to a product.
+func (h *ProductsRouteHandler) handleUpdateProduct(w http.ResponseWriter, r *http.Request, id int64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.controller.logger.Error("failed to read request body", zap.Error(err))
+		h.controller.reject(w, r, http.StatusBadRequest, errInvalidBody)
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.controller.logger.Error("failed to close body", zap.Error(err))
+		}
+	}()
+
+	req, err := parseMergePatch(id, body)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	etag, err := ifMatchETag(r)
+	if err != nil {
+		h.controller.ifMatchError(w, r, err)
+		return
+	}
+	req.Product.Etag = etag
+
+	// An empty merge patch is a no-op, so return the product unchanged.
+	if len(req.GetUpdateMask().GetPaths()) == 0 {
+		h.handleEmptyPatch(w, r, id, etag)
+		return
+	}
+
+	resp, err := h.controller.client.UpdateProduct(ctx, req)
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to update product")
+		return
+	}
+
+	setETag(w, resp.GetProduct().GetEtag())
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleEmptyPatch answers a merge patch that changes nothing with the
+// current product. If-Match is still checked, so a stale etag fails the
+// same way it would on a real update.
+func (h *ProductsRouteHandler) handleEmptyPatch(w http.ResponseWriter, r *http.Request, id int64, etag string) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+
+	resp, err := h.controller.client.GetProduct(ctx, &productsv1.GetProductRequest{Id: id})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to get product")
+		return
+	}
+	if current := resp.GetProduct().GetEtag(); etag != "" && etag != current {
+		h.controller.handleError(w, r, etagMismatchError(id, current), "failed to update product")
+		return
+	}
+
+	setETag(w, resp.GetProduct().GetEtag())
+	h.controller.writeJSON(w, http.StatusOK, resp)
 }
 
 // handleDeleteProduct deletes a product by ID.
 func (h *ProductsRouteHandler) handleDeleteProduct(w http.ResponseWriter, r *http.Request, id int64) {
 	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	etag, err := ifMatchETag(r)
+	if err != nil {
+		h.controller.ifMatchError(w, r, err)
+		return
+	}
+
+	resp, err := h.controller.client.DeleteProduct(ctx, &productsv1.DeleteProductRequest{Id: id, Etag: etag})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to delete product")
+		return
+	}
+
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleUndeleteProduct restores a soft deleted product.
+func (h *ProductsRouteHandler) handleUndeleteProduct(w http.ResponseWriter, r *http.Request, id int64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+	ctx, err := withIdempotencyKey(ctx, r)
+	if err != nil {
+		h.controller.reject(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	etag, err := ifMatchETag(r)
+	if err != nil {
+		h.controller.ifMatchError(w, r, err)
+		return
+	}
+
+	resp, err := h.controller.client.UndeleteProduct(ctx, &productsv1.UndeleteProductRequest{Id: id, Etag: etag})
+	if err != nil {
+		h.controller.handleError(w, r, err, "failed to undelete product")
+		return
+	}
+
+	setETag(w, resp.GetProduct().GetEtag())
+	h.controller.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleListProductRevisions lists the change history of a product, newest
+// first.
+func (h *ProductsRouteHandler) handleListProductRevisions(w http.ResponseWriter, r *http.Request, id int64) {
+	ctx := h.controller.contextWithTelemetry(r.Context())
+
+	pageSize := uint32(10)
+	if ps := r.URL.Query().Get("page_size"); ps != "" {
+		if parsed, err := strconv.ParseUint(ps, 10, 32); err == nil {
+			pageSize = uint32(parsed)
+		}
+	}
 
-	resp, err := h.controller.client.DeleteProduct(ctx, &productsv1.DeleteProductRequest{Id: id})
+	resp, err := h.controller.client.ListProductRevisions(ctx, &productsv1.ListProductRevisionsRequest{
+		ProductId: id,
+		PageSize:  pageSize,
+		PageToken: r.URL.Query().Get("page_token"),
+	})
 	if err != nil {
-		h.controller.handleError(w, err, "failed to delete product")
+		h.controller.handleError(w, r, err, "failed to list product revisions")
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, resp)
+	h.controller.writeJSON(w, http.StatusOK, resp)
 }
 
 // writeJSON encodes a response as JSON and writes it to the ResponseWriter.
-func (h *ProductsRouteHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+func (c *ProductController) writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		h.controller.logger.Error("failed to encode response", zap.Error(err))
+		c.logger.Error("failed to encode response", zap.Error(err))
 	}
 }
 
-// handleError converts gRPC errors to appropriate HTTP responses.
-func (c *ProductController) handleError(w http.ResponseWriter, err error, msg string) {
+// handleError converts gRPC errors into RFC 7807 problem responses,
+// carrying over the error's reason code, field violations and trace id.
+func (c *ProductController) handleError(w http.ResponseWriter, r *http.Request, err error, msg string) {
 	st, ok := status.FromError(err)
 	if !ok {
-		c.logger.Error(msg, zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		st = status.New(codes.Unknown, err.Error())
 	}
 
-	switch st.Code() {
-	case codes.InvalidArgument, codes.FailedPrecondition:
-		http.Error(w, st.Message(), http.StatusBadRequest)
-	case codes.NotFound:
-		http.Error(w, st.Message(), http.StatusNotFound)
-	case codes.AlreadyExists:
-		http.Error(w, st.Message(), http.StatusConflict)
-	default:
-		c.logger.Error(msg, zap.Error(err), zap.String("code", st.Code().String()))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	p, retry := newProblem(r, st)
+	if p.Status == http.StatusInternalServerError {
+		c.logger.Error(msg, zap.Error(err), zap.String("code", st.Code().String()), zap.String("trace_id", p.TraceID))
 	}
+	c.writeProblem(w, p, retry)
 }
 
 // contextWithTelemetry adds metadata to outgoing gRPC requests.
@@ -268,7 +656,7 @@ func (c *ProductController) contextWithTelemetry(ctx context.Context) context.Co
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
-// Module exports the product controller and route handler.
+// Module exports the product controller and its route handlers.
 var Module = fx.Module("controllers",
 	fx.Provide(
 		NewProductController,
@@ -276,5 +664,17 @@ var Module = fx.Module("controllers",
 			NewProductsRouteHandler,
 			fx.ResultTags(`group:"routes"`),
 		),
+		fx.Annotate(
+			NewCategoriesRouteHandler,
+			fx.ResultTags(`group:"routes"`),
+		),
+		fx.Annotate(
+			NewMediaRouteHandler,
+			fx.ResultTags(`group:"routes"`),
+		),
+		fx.Annotate(
+			NewMediaBlobsRouteHandler,
+			fx.ResultTags(`group:"routes"`),
+		),
 	),
 )
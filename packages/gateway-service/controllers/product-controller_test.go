@@ -1,12 +1,21 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestProductsRouteHandler_Patterns(t *testing.T) {
@@ -39,3 +48,192 @@ func TestProductsRouteHandler_ServeHTTP_NotFound(t *testing.T) {
 	
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestParseProductFilter(t *testing.T) {
+	q, _ := url.ParseQuery("currency=USD&min_price=10&max_price=99.5&name_prefix=Wid&in_stock=true&category_id=7&tag=sale")
+
+	filter, err := parseProductFilter(q)
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", filter.GetCurrency())
+	assert.Equal(t, &productsv1.Money{CurrencyCode: "USD", Units: 10}, filter.GetMinPrice())
+	assert.Equal(t, &productsv1.Money{CurrencyCode: "USD", Units: 99, Nanos: 500_000_000}, filter.GetMaxPrice())
+	assert.Equal(t, "Wid", filter.GetNamePrefix())
+	assert.True(t, filter.GetInStockOnly())
+	assert.Equal(t, uint64(7), filter.GetCategoryId())
+	assert.Equal(t, "sale", filter.GetTag())
+}
+
+func TestParseProductFilter_Invalid(t *testing.T) {
+	for _, raw := range []string{"currency=USD&min_price=cheap", "currency=USD&max_price=1e", "currency=USD&min_price=0.001", "min_price=10", "in_stock=maybe", "category_id=-1"} {
+		q, _ := url.ParseQuery(raw)
+		_, err := parseProductFilter(q)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestProductsRouteHandler_parseRoute(t *testing.T) {
+	handler := &ProductsRouteHandler{}
+
+	tests := []struct {
+		path string
+		want *parsedRoute
+	}{
+		{"/api/v1/products", &parsedRoute{Type: routeTypeCollection}},
+		{"/api/v1/products/42", &parsedRoute{Type: routeTypeItem, ID: 42}},
+		{"/api/v1/products:search", &parsedRoute{Type: routeTypeCustom, Method: customMethodSearch}},
+		{"/api/v1/products:suggest", &parsedRoute{Type: routeTypeCustom, Method: customMethodSuggest}},
+		{"/api/v1/products:batchGet", &parsedRoute{Type: routeTypeCustom, Method: customMethodBatchGet}},
+		{"/api/v1/products:batchCreate", &parsedRoute{Type: routeTypeCustom, Method: customMethodBatchCreate}},
+		{"/api/v1/products:explode", nil},
+		{"/api/v1/products/abc", nil},
+		{"/api/v1/products/42:undelete", &parsedRoute{Type: routeTypeItem, ID: 42, Method: itemMethodUndelete}},
+		{"/api/v1/products/42:revisions", &parsedRoute{Type: routeTypeItem, ID: 42, Method: itemMethodRevisions}},
+		{"/api/v1/products/42:explode", nil},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, handler.parseRoute(tt.path), tt.path)
+	}
+}
+
+func TestProductsRouteHandler_CustomMethodNotAllowed(t *testing.T) {
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop()}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/products:search", nil)
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+// fakeProductClient records the batch requests it receives.
+type fakeProductClient struct {
+	productsv1.ProductServiceClient
+	batchGet    *productsv1.BatchGetProductsRequest
+	batchCreate *productsv1.BatchCreateProductsRequest
+	outgoing    metadata.MD
+	get         *productsv1.GetProductRequest
+	revisions   *productsv1.ListProductRevisionsRequest
+}
+
+func (f *fakeProductClient) GetProduct(_ context.Context, req *productsv1.GetProductRequest, _ ...grpc.CallOption) (*productsv1.GetProductResponse, error) {
+	f.get = req
+	return &productsv1.GetProductResponse{Product: &productsv1.Product{Id: uint64(req.GetId()), Etag: "3"}}, nil
+}
+
+func (f *fakeProductClient) ListProductRevisions(_ context.Context, req *productsv1.ListProductRevisionsRequest, _ ...grpc.CallOption) (*productsv1.ListProductRevisionsResponse, error) {
+	f.revisions = req
+	return &productsv1.ListProductRevisionsResponse{}, nil
+}
+
+func (f *fakeProductClient) BatchGetProducts(_ context.Context, req *productsv1.BatchGetProductsRequest, _ ...grpc.CallOption) (*productsv1.BatchGetProductsResponse, error) {
+	f.batchGet = req
+	return &productsv1.BatchGetProductsResponse{
+		Products:   []*productsv1.Product{{Id: 2, Name: "Two"}},
+		MissingIds: []int64{1},
+	}, nil
+}
+
+func (f *fakeProductClient) BatchCreateProducts(ctx context.Context, req *productsv1.BatchCreateProductsRequest, _ ...grpc.CallOption) (*productsv1.BatchCreateProductsResponse, error) {
+	f.batchCreate = req
+	f.outgoing, _ = metadata.FromOutgoingContext(ctx)
+	return nil, status.Error(codes.InvalidArgument, "requests[0]: name is required")
+}
+
+func TestProductsRouteHandler_BatchGet(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products:batchGet", strings.NewReader(`{"ids":[2,1]}`))
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int64{2, 1}, client.batchGet.GetIds())
+	assert.Contains(t, w.Body.String(), `"missing_ids":[1]`)
+}
+
+func TestProductsRouteHandler_BatchCreate(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products:batchCreate", strings.NewReader(`{"requests":[{"price":{"currency_code":"USD","units":1}}]}`))
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Len(t, client.batchCreate.GetRequests(), 1)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/products:batchCreate", nil)
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestProductsRouteHandler_ForwardsIdempotencyKey(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products:batchCreate", strings.NewReader(`{"requests":[]}`))
+	req.Header.Set("Idempotency-Key", "order-42")
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, []string{"order-42"}, client.outgoing.Get("idempotency-key"))
+
+	client.batchCreate = nil
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/products:batchCreate", strings.NewReader(`{"requests":[]}`))
+	req.Header.Set("Idempotency-Key", strings.Repeat("k", maxIdempotencyKeyLength+1))
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, client.batchCreate)
+}
+
+func TestProductsRouteHandler_GetForwardsDisplayCurrency(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/42?display_currency=EUR", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(42), client.get.GetId())
+	assert.Equal(t, "EUR", client.get.GetDisplayCurrency())
+}
+
+func TestProductsRouteHandler_GetForwardsAsOf(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/42?as_of=2026-01-02T03:04:05Z", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), client.get.GetAsOf().AsTime())
+
+	client.get = nil
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/42?as_of=yesterday", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, client.get)
+}
+
+func TestProductsRouteHandler_ListRevisions(t *testing.T) {
+	client := &fakeProductClient{}
+	handler := &ProductsRouteHandler{controller: &ProductController{logger: zap.NewNop(), client: client}}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/products/42:revisions?page_size=5&page_token=abc", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(42), client.revisions.GetProductId())
+	assert.Equal(t, uint32(5), client.revisions.GetPageSize())
+	assert.Equal(t, "abc", client.revisions.GetPageToken())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/products/42:revisions", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
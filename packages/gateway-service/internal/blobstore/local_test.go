@@ -0,0 +1,65 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "products/1/a.png", strings.NewReader("first")))
+	require.NoError(t, store.Put(ctx, "products/1/a.png", strings.NewReader("second")))
+
+	rc, err := store.Open(ctx, "products/1/a.png")
+	require.NoError(t, err)
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "second", string(body))
+
+	require.NoError(t, store.Delete(ctx, "products/1/a.png"))
+	_, err = store.Open(ctx, "products/1/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Deleting twice is fine.
+	assert.NoError(t, store.Delete(ctx, "products/1/a.png"))
+}
+
+func TestLocalStore_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../escape.png", "products/../../escape.png", "/etc/passwd"} {
+		assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x")), ErrInvalidKey, key)
+		_, err := store.Open(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestLocalStore_Move(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "uploads/x", strings.NewReader("new")))
+	require.NoError(t, store.Put(ctx, "products/1/a.png", strings.NewReader("old")))
+	require.NoError(t, store.Move(ctx, "uploads/x", "products/1/a.png"))
+
+	rc, err := store.Open(ctx, "products/1/a.png")
+	require.NoError(t, err)
+	body, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, "new", string(body))
+
+	assert.ErrorIs(t, store.Move(ctx, "uploads/x", "products/1/a.png"), ErrNotFound)
+}
@@ -0,0 +1,71 @@
+// Package blobstore keeps uploaded media blobs. The product service only
+// stores their metadata; the gateway writes and serves the bytes.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const defaultLocalDir = "./data/media"
+
+// ErrNotFound is returned when no blob is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for keys that could escape the store.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store reads and writes blobs by key. Keys are slash-separated relative
+// paths such as "products/42/<sha256>.png".
+type Store interface {
+	// Put stores the contents of r under key, replacing any existing blob.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns the blob stored under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Move renames the blob stored under src to dst, replacing any blob
+	// stored under dst, or returns ErrNotFound.
+	Move(ctx context.Context, src, dst string) error
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+type Params struct {
+	fx.In
+
+	Logger *zap.Logger
+	Config *config.Config
+}
+
+// Module exports the blob store provider and the sweeper that deletes the
+// blobs of purged media
+// The implementation is selected by media.storage
+var Module = fx.Module("blobstore",
+	fx.Provide(NewStore, NewSweeper),
+	fx.Invoke(func(*Sweeper) {}),
+)
+
+func NewStore(p Params) (Store, error) {
+	cfg := p.Config.MediaConfig
+	switch cfg.Storage {
+	case "", "local":
+		dir := cfg.LocalDir
+		if dir == "" {
+			dir = defaultLocalDir
+		}
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			return nil, err
+		}
+		p.Logger.Info("blob storage ready", zap.String("storage", "local"), zap.String("dir", dir))
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown media.storage %q", cfg.Storage)
+	}
+}
@@ -0,0 +1,116 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSweepInterval = time.Minute
+	sweepPageSize        = 100
+)
+
+type SweeperParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Client    productsv1.ProductServiceClient
+	Store     Store
+}
+
+// Sweeper deletes the blobs of media that went with a purged product. The
+// product service queues their keys when it purges the product; the
+// sweeper deletes each blob and then confirms the key, so a blob that
+// fails to delete is listed again on the next sweep.
+type Sweeper struct {
+	client productsv1.ProductServiceClient
+	store  Store
+	log    *zap.Logger
+}
+
+func NewSweeper(p SweeperParams) *Sweeper {
+	sweeper := &Sweeper{client: p.Client, store: p.Store, log: p.Logger.Named("blobstore")}
+	interval := p.Config.MediaConfig.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						n, err := sweeper.Run(ctx)
+						if err != nil && ctx.Err() == nil {
+							sweeper.log.Warn("deleting purged media blobs failed", zap.Error(err))
+						}
+						if n > 0 {
+							sweeper.log.Info("deleted purged media blobs", zap.Int("count", n))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return sweeper
+}
+
+// Run deletes queued blobs a page at a time until the queue is drained or a
+// blob fails to delete, and returns how many it deleted.
+func (s *Sweeper) Run(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		resp, err := s.client.ListPurgedMedia(ctx, &productsv1.ListPurgedMediaRequest{PageSize: sweepPageSize})
+		if err != nil {
+			return total, err
+		}
+		keys := resp.GetStorageKeys()
+
+		deleted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			// A key the store rejects cannot have a blob.
+			if err := s.store.Delete(ctx, key); err != nil && !errors.Is(err, ErrInvalidKey) {
+				s.log.Warn("failed to delete blob", zap.Error(err), zap.String("key", key))
+				continue
+			}
+			deleted = append(deleted, key)
+		}
+		if len(deleted) > 0 {
+			if _, err := s.client.ConfirmPurgedMedia(ctx, &productsv1.ConfirmPurgedMediaRequest{StorageKeys: deleted}); err != nil {
+				return total, err
+			}
+		}
+		total += len(deleted)
+
+		if len(keys) < sweepPageSize || len(deleted) < len(keys) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package blobstore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// fakePurgedMediaClient serves queued keys until they are confirmed.
+type fakePurgedMediaClient struct {
+	productsv1.ProductServiceClient
+	queued    []string
+	confirmed []string
+}
+
+func (f *fakePurgedMediaClient) ListPurgedMedia(context.Context, *productsv1.ListPurgedMediaRequest, ...grpc.CallOption) (*productsv1.ListPurgedMediaResponse, error) {
+	return &productsv1.ListPurgedMediaResponse{StorageKeys: f.queued}, nil
+}
+
+func (f *fakePurgedMediaClient) ConfirmPurgedMedia(_ context.Context, req *productsv1.ConfirmPurgedMediaRequest, _ ...grpc.CallOption) (*productsv1.ConfirmPurgedMediaResponse, error) {
+	f.confirmed = append(f.confirmed, req.GetStorageKeys()...)
+	f.queued = nil
+	return &productsv1.ConfirmPurgedMediaResponse{Confirmed: uint64(len(req.GetStorageKeys()))}, nil
+}
+
+func TestSweeper_Run(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, "products/1/a.png", strings.NewReader("a")))
+	require.NoError(t, store.Put(ctx, "products/2/b.png", strings.NewReader("b")))
+
+	client := &fakePurgedMediaClient{queued: []string{"products/1/a.png", "products/1/gone.png", "../escape.png"}}
+	sweeper := &Sweeper{client: client, store: store, log: zap.NewNop()}
+
+	n, err := sweeper.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{"products/1/a.png", "products/1/gone.png", "../escape.png"}, client.confirmed)
+
+	_, err = store.Open(ctx, "products/1/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+	rc, err := store.Open(ctx, "products/2/b.png")
+	require.NoError(t, err, "blobs that are not queued stay")
+	assert.NoError(t, rc.Close())
+}
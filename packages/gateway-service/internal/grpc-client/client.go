@@ -11,6 +11,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
@@ -104,6 +105,9 @@ func NewProductClient(p Params) (productsv1.ProductServiceClient, error) {
 				grpcprom.WithLabelsFromContext(labelsFromContext),
 			),
 			logging.UnaryClientInterceptor(zapLogger),
+			// pre-check the rules declared in products.proto so invalid
+			// requests fail with every violation before leaving the gateway
+			validation.UnaryClientInterceptor(),
 		),
 		// Chain of stream interceptors
 		grpc.WithChainStreamInterceptor(
@@ -112,6 +116,7 @@ func NewProductClient(p Params) (productsv1.ProductServiceClient, error) {
 				grpcprom.WithLabelsFromContext(labelsFromContext),
 			),
 			logging.StreamClientInterceptor(zapLogger),
+			validation.StreamClientInterceptor(),
 		),
 	}
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/controllers"
+	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/blobstore"
 	grpcclient "github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/grpc-client"
 	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/router"
 	"github.com/yaninyzwitty/go-fx-v1/packages/gateway-service/internal/server"
@@ -29,6 +30,7 @@ func main() {
 
 		// Gateway service modules
 		grpcclient.Module,  // gRPC client must be provided before controllers
+		blobstore.Module,   // Blob storage for uploaded media
 		controllers.Module, // Controllers depend on gRPC client
 		router.Module,      // Router depends on controllers (route handlers)
 		server.Module,      // Server depends on router (mux)
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (c *ProductServiceHandler) BatchGetProducts(ctx context.Context, req *productsv1.BatchGetProductsRequest) (*productsv1.BatchGetProductsResponse, error) {
+	ctx, span := c.startSpan(ctx, "BatchGetProducts.Handler")
+	defer span.End()
+
+	const op = "batch_get_products"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	ids := req.GetIds()
+	rows, err := c.store(ctx).GetProductsByIDs(ctx, ids)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to get products: %v", err)
+	}
+
+	found := make(map[int64]repository.Product, len(rows))
+	for _, p := range rows {
+		found[p.ID] = p
+	}
+
+	resp := &productsv1.BatchGetProductsResponse{
+		Products: make([]*productsv1.Product, 0, len(ids)),
+	}
+	for _, id := range ids {
+		if p, ok := found[id]; ok {
+			resp.Products = append(resp.Products, mapDBToProto(p))
+		} else {
+			resp.MissingIds = append(resp.MissingIds, id)
+		}
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Products...); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) BatchCreateProducts(ctx context.Context, req *productsv1.BatchCreateProductsRequest) (*productsv1.BatchCreateProductsResponse, error) {
+	ctx, span := c.startSpan(ctx, "BatchCreateProducts.Handler")
+	defer span.End()
+
+	const op = "batch_create_products"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	items := req.GetRequests()
+
+	actor := actorFromContext(ctx)
+	params := make([]repository.BatchCreateProductsParams, 0, len(items))
+	taxonomies := make([]taxonomy, 0, len(items))
+	for i, item := range items {
+		priceMinor, err := priceToMinor(item.GetPrice())
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Nested(fmt.Sprintf("requests[%d]", i), err)
+		}
+		tax, err := parseTaxonomy(item)
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Nested(fmt.Sprintf("requests[%d]", i), err)
+		}
+		taxonomies = append(taxonomies, tax)
+
+		id, err := c.ids.NextID()
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to generate product ID: %v", err)
+		}
+
+		params = append(params, repository.BatchCreateProductsParams{
+			ID:            int64(id),
+			Name:          item.GetName(),
+			Description:   pgtype.Text{String: item.GetDescription(), Valid: item.GetDescription() != ""},
+			PriceMinor:    priceMinor,
+			Currency:      item.GetPrice().GetCurrencyCode(),
+			StockQuantity: int32(item.GetStockQuantity()),
+			Actor:         actor,
+			CategoryIds:   tax.categoryIDs,
+			Tags:          tax.tags,
+		})
+	}
+
+	created, err := c.batchCreate(ctx, params, taxonomies)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	resp := &productsv1.BatchCreateProductsResponse{
+		Products: make([]*productsv1.Product, 0, len(created)),
+	}
+	for i, p := range created {
+		c.suggestions.Put(p.ID, p.Name)
+		product := mapDBToProto(p)
+		product.CategoryIds = toUint64s(taxonomies[i].categoryIDs)
+		product.Tags = taxonomies[i].tags
+		resp.Products = append(resp.Products, product)
+	}
+
+	c.log.Debug("created products in batch", zap.Int("count", len(created)))
+
+	return resp, nil
+}
+
+// batchCreate pipelines the inserts in one round trip inside a transaction,
+// so either every product is created or none is. Categories and tags are
+// written in the same transaction; an unknown category fails the batch with
+// INVALID_ARGUMENT.
+func (c *ProductServiceHandler) batchCreate(ctx context.Context, params []repository.BatchCreateProductsParams, taxonomies []taxonomy) ([]repository.Product, error) {
+	created := make([]repository.Product, len(params))
+	err := c.inTx(ctx, func(q store.ProductStore) error {
+		var batchErr error
+		q.BatchCreateProducts(ctx, params, func(i int, row repository.BatchCreateProductsRow, err error) {
+			if err != nil {
+				if batchErr == nil {
+					batchErr = fmt.Errorf("create products: requests[%d]: %w", i, err)
+				}
+				return
+			}
+			created[i] = repository.Product(row)
+		})
+		if batchErr != nil {
+			return batchErr
+		}
+
+		for i, t := range taxonomies {
+			if t.empty() {
+				continue
+			}
+			if err := setTaxonomy(ctx, q, created[i].ID, t); err != nil {
+				var invalid *validation.Error
+				if errors.As(err, &invalid) {
+					return validation.Nested(fmt.Sprintf("requests[%d]", i), err)
+				}
+				return fmt.Errorf("create products: requests[%d]: %w", i, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProductServiceHandler_BatchGetProducts_PreservesOrder(t *testing.T) {
+	db := &fakeDB{rows: []*fakeRow{
+		{product: repository.Product{ID: 1, Name: "One"}},
+		{product: repository.Product{ID: 3, Name: "Three"}},
+	}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.BatchGetProducts(context.Background(), &productsv1.BatchGetProductsRequest{Ids: []int64{3, 2, 1}})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetProducts(), 2)
+	assert.Equal(t, uint64(3), resp.GetProducts()[0].GetId())
+	assert.Equal(t, uint64(1), resp.GetProducts()[1].GetId())
+	assert.Equal(t, []int64{2}, resp.GetMissingIds())
+}
+
+func TestProductServiceHandler_BatchGetProducts_InvalidRequest(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	for name, ids := range map[string][]int64{
+		"empty":      nil,
+		"invalid id": {1, 0},
+		"too many":   make([]int64, 101),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := validated(context.Background(), &productsv1.BatchGetProductsRequest{Ids: ids}, handler.BatchGetProducts)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_BatchCreateProducts(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{
+		{product: repository.Product{ID: 1, Name: "One", PriceMinor: 100, Currency: "USD"}},
+		{product: repository.Product{ID: 2, Name: "Two", PriceMinor: 200, Currency: "USD"}},
+	}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.BatchCreateProducts(context.Background(), &productsv1.BatchCreateProductsRequest{
+		Requests: []*productsv1.CreateProductRequest{
+			{Name: "One", Price: usd(100)},
+			{Name: "Two", Price: usd(200)},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetProducts(), 2)
+	assert.Equal(t, "Two", resp.GetProducts()[1].GetName())
+	assert.Equal(t, 2, db.batched, "all inserts are sent in one batch")
+	assert.True(t, db.committed)
+	assert.Equal(t, 2, handler.suggestions.Len(), "created products are suggestible")
+}
+
+func TestProductServiceHandler_BatchCreateProducts_RollsBackOnFailure(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{
+		{product: repository.Product{ID: 1, Name: "One", PriceMinor: 100, Currency: "USD"}},
+		{err: errors.New("boom")},
+	}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.BatchCreateProducts(context.Background(), &productsv1.BatchCreateProductsRequest{
+		Requests: []*productsv1.CreateProductRequest{
+			{Name: "One", Price: usd(100)},
+			{Name: "Two", Price: usd(200)},
+		},
+	})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, st.Code())
+	assert.Contains(t, st.Message(), "requests[1]")
+	assert.False(t, db.committed)
+	assert.True(t, db.rolledBack)
+	assert.Zero(t, handler.suggestions.Len())
+}
+
+func TestProductServiceHandler_BatchCreateProducts_ValidatesEveryItem(t *testing.T) {
+	db := &fakeDB{}
+	handler := newTestHandler(t, db)
+
+	_, err := validated(context.Background(), &productsv1.BatchCreateProductsRequest{
+		Requests: []*productsv1.CreateProductRequest{
+			{Name: "One", Price: usd(100)},
+			{Name: "Two", Price: usd(0)},
+		},
+	}, handler.BatchCreateProducts)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "invalid request: requests[1].price must be greater than 0", st.Message())
+	assert.Zero(t, db.batched, "nothing is sent when validation fails")
+}
@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// updatableCategoryFields lists the Category field mask paths accepted by
+// UpdateCategory.
+var updatableCategoryFields = []string{"name", "parent_id"}
+
+func (c *ProductServiceHandler) CreateCategory(ctx context.Context, req *productsv1.CreateCategoryRequest) (*productsv1.CreateCategoryResponse, error) {
+	ctx, span := c.startSpan(ctx, "CreateCategory.Handler")
+	defer span.End()
+
+	const op = "create_category"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	name := strings.TrimSpace(req.GetName())
+	parentID, err := categoryRef("parent_id", req.GetParentId())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	id, err := c.ids.NextID()
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to generate category ID: %v", err)
+	}
+
+	category, err := c.store(ctx).CreateCategory(ctx, repository.CreateCategoryParams{
+		ID:       int64(id),
+		ParentID: parentID,
+		Name:     name,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("parent_id", "names category %d, which does not exist", req.GetParentId())
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to create category: %v", err)
+	}
+
+	return &productsv1.CreateCategoryResponse{
+		Category: mapCategoryToProto(category),
+	}, nil
+}
+
+func (c *ProductServiceHandler) GetCategory(ctx context.Context, req *productsv1.GetCategoryRequest) (*productsv1.GetCategoryResponse, error) {
+	ctx, span := c.startSpan(ctx, "GetCategory.Handler")
+	defer span.End()
+
+	const op = "get_category"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetId() == 0 || req.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("id", "is not a valid ID")
+	}
+
+	category, err := c.store(ctx).GetCategory(ctx, int64(req.GetId()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.NotFound, "category %d not found", req.GetId())
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to get category: %v", err)
+	}
+
+	return &productsv1.GetCategoryResponse{
+		Category: mapCategoryToProto(category),
+	}, nil
+}
+
+func (c *ProductServiceHandler) ListCategories(ctx context.Context, req *productsv1.ListCategoriesRequest) (*productsv1.ListCategoriesResponse, error) {
+	ctx, span := c.startSpan(ctx, "ListCategories.Handler")
+	defer span.End()
+
+	const op = "list_categories"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	parentID, err := categoryRef("parent_id", req.GetParentId())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	params := repository.ListCategoriesParams{
+		ParentID:  parentID,
+		PageLimit: int32(pageSize) + 1,
+	}
+	fingerprint := url.Values{"parent_id": {strconv.FormatUint(req.GetParentId(), 10)}}
+
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := c.pageTokens.Decode(token, fingerprint.Encode())
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("page_token", "%v", err)
+		}
+		params.AfterName = pgtype.Text{String: cursor.LastKey, Valid: true}
+		params.AfterID = cursor.LastID
+	}
+
+	categories, err := c.store(ctx).ListCategories(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to list categories: %v", err)
+	}
+
+	resp := &productsv1.ListCategoriesResponse{}
+	if len(categories) > int(pageSize) {
+		categories = categories[:pageSize]
+		last := categories[len(categories)-1]
+		resp.NextPageToken, err = c.pageTokens.Encode(pagination.Cursor{
+			LastID:  last.ID,
+			LastKey: last.Name,
+			Query:   fingerprint.Encode(),
+		})
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to build page token: %v", err)
+		}
+	}
+
+	resp.Categories = make([]*productsv1.Category, 0, len(categories))
+	for _, category := range categories {
+		resp.Categories = append(resp.Categories, mapCategoryToProto(category))
+	}
+
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) UpdateCategory(ctx context.Context, req *productsv1.UpdateCategoryRequest) (*productsv1.UpdateCategoryResponse, error) {
+	ctx, span := c.startSpan(ctx, "UpdateCategory.Handler")
+	defer span.End()
+
+	const op = "update_category"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	category := req.GetCategory()
+	if category.GetId() == 0 || category.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("category.id", "is not a valid ID")
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = updatableCategoryFields
+	}
+
+	var setName, setParent bool
+	name := strings.TrimSpace(category.GetName())
+	for _, path := range paths {
+		switch path {
+		case "name":
+			if name == "" {
+				c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+				return nil, validation.Invalid("category.name", "is required")
+			}
+			setName = true
+		case "parent_id":
+			setParent = true
+		default:
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("update_mask", "names %q, which cannot be updated", path)
+		}
+	}
+	parentID, err := categoryRef("category.parent_id", category.GetParentId())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	var updated repository.Category
+	err = c.inTx(ctx, func(q store.ProductStore) error {
+		current, err := q.GetCategoryForUpdate(ctx, int64(category.GetId()))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "category %d not found", category.GetId())
+		}
+		if err != nil {
+			return fmt.Errorf("get category: %w", err)
+		}
+
+		params := repository.UpdateCategoryParams{
+			ID:       current.ID,
+			Name:     current.Name,
+			ParentID: current.ParentID,
+			Path:     current.Path,
+		}
+		if setName {
+			params.Name = name
+		}
+		if setParent && parentID != current.ParentID {
+			params.ParentID = parentID
+			params.Path, err = categoryPath(ctx, q, current, parentID)
+			if err != nil {
+				return err
+			}
+		}
+
+		updated, err = q.UpdateCategory(ctx, params)
+		if err != nil {
+			return fmt.Errorf("update category: %w", err)
+		}
+		if params.Path != current.Path {
+			if _, err := q.MoveCategoryDescendants(ctx, repository.MoveCategoryDescendantsParams{
+				OldPath: current.Path,
+				NewPath: params.Path,
+			}); err != nil {
+				return fmt.Errorf("move category subtree: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	return &productsv1.UpdateCategoryResponse{
+		Category: mapCategoryToProto(updated),
+	}, nil
+}
+
+// categoryPath returns the path category gets below parentID, refusing to
+// move a category into its own subtree.
+func categoryPath(ctx context.Context, q store.ProductStore, category repository.Category, parentID pgtype.Int8) (string, error) {
+	own := strconv.FormatInt(category.ID, 10) + "/"
+	if !parentID.Valid {
+		return "/" + own, nil
+	}
+
+	parent, err := q.GetCategory(ctx, parentID.Int64)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", validation.Invalid("category.parent_id", "names category %d, which does not exist", parentID.Int64)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get category: %w", err)
+	}
+	if strings.HasPrefix(parent.Path, category.Path) {
+		return "", validation.Invalid("category.parent_id", "would move category %d below itself", category.ID)
+	}
+	return parent.Path + own, nil
+}
+
+func (c *ProductServiceHandler) DeleteCategory(ctx context.Context, req *productsv1.DeleteCategoryRequest) (*productsv1.DeleteCategoryResponse, error) {
+	ctx, span := c.startSpan(ctx, "DeleteCategory.Handler")
+	defer span.End()
+
+	const op = "delete_category"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetId() == 0 || req.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("id", "is not a valid ID")
+	}
+
+	deleted, err := c.store(ctx).DeleteCategory(ctx, int64(req.GetId()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.deleteCategoryMissError(ctx, int64(req.GetId()))
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to delete category: %v", err)
+	}
+
+	return &productsv1.DeleteCategoryResponse{
+		Category: mapCategoryToProto(deleted),
+	}, nil
+}
+
+// deleteCategoryMissError explains why DeleteCategory removed nothing: the
+// category does not exist or still has children.
+func (c *ProductServiceHandler) deleteCategoryMissError(ctx context.Context, id int64) error {
+	_, err := c.store(ctx).GetCategory(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "category %d not found", id)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get category: %v", err)
+	}
+	return status.Errorf(codes.FailedPrecondition, "category %d has child categories", id)
+}
+
+// categoryRef validates an optional category reference; zero means none.
+func categoryRef(field string, id uint64) (pgtype.Int8, error) {
+	if id > math.MaxInt64 {
+		return pgtype.Int8{}, validation.Invalid(field, "is not a valid ID")
+	}
+	return pgtype.Int8{Int64: int64(id), Valid: id != 0}, nil
+}
+
+func mapCategoryToProto(c repository.Category) *productsv1.Category {
+	return &productsv1.Category{
+		Id:        uint64(c.ID),
+		ParentId:  uint64(c.ParentID.Int64),
+		Name:      c.Name,
+		Path:      c.Path,
+		CreatedAt: timestamppb.New(c.CreatedAt),
+		UpdatedAt: timestamppb.New(c.UpdatedAt),
+	}
+}
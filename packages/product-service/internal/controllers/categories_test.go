@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// categoryRow is a categories row as scanned by the category queries.
+func categoryRow(id, parentID int64, name, path string) *fakeRow {
+	now := time.Now()
+	return &fakeRow{values: []any{
+		id, pgtype.Int8{Int64: parentID, Valid: parentID != 0}, name, path, now, now,
+	}}
+}
+
+func TestProductServiceHandler_CreateProduct_WithTaxonomy(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD"}, affected: 2}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.CreateProduct(context.Background(), &productsv1.CreateProductRequest{
+		Name:        "Widget",
+		Price:       usd(999),
+		CategoryIds: []uint64{9, 3, 9},
+		Tags:        []string{" Sale ", "new", "sale"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{3, 9}, resp.GetProduct().GetCategoryIds())
+	assert.Equal(t, []string{"new", "sale"}, resp.GetProduct().GetTags())
+	assert.Equal(t, []string{"ClearProductCategories", "AddProductCategories", "ClearProductTags", "AddProductTags"}, db.execs)
+	assert.True(t, db.committed)
+
+	// The first revision records the taxonomy the product is filed under.
+	require.Len(t, db.args, 9)
+	assert.Equal(t, []int64{3, 9}, db.args[7])
+	assert.Equal(t, []string{"new", "sale"}, db.args[8])
+}
+
+func TestProductServiceHandler_CreateProduct_UnknownCategory(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD"}, affected: 1}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.CreateProduct(context.Background(), &productsv1.CreateProductRequest{
+		Name:        "Widget",
+		Price:       usd(999),
+		CategoryIds: []uint64{3, 9},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.True(t, db.rolledBack)
+	assert.False(t, db.committed)
+}
+
+func TestProductServiceHandler_CreateProduct_InvalidTags(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	for _, tags := range [][]string{{"  "}, {strings.Repeat("x", 65)}} {
+		_, err := validated(context.Background(), &productsv1.CreateProductRequest{
+			Name:  "Widget",
+			Price: usd(999),
+			Tags:  tags,
+		}, handler.CreateProduct)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "tags %q", tags)
+	}
+}
+
+func TestProductServiceHandler_GetProduct_LoadsTaxonomy(t *testing.T) {
+	db := &fakeDB{
+		product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 999, Currency: "USD"},
+		lookups: map[string][]*fakeRow{
+			"ListCategoryLinks": {{values: []any{int64(42), int64(3)}}, {values: []any{int64(42), int64(9)}}},
+			"ListProductTags":   {{values: []any{int64(42), "sale"}}},
+		},
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42})
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{3, 9}, resp.GetProduct().GetCategoryIds())
+	assert.Equal(t, []string{"sale"}, resp.GetProduct().GetTags())
+}
+
+func TestProductServiceHandler_UpdateProduct_ReplacesTags(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 999, Currency: "USD"}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Tags: []string{"clearance"}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ClearProductTags", "AddProductTags"}, db.execs)
+	assert.True(t, db.committed)
+
+	// The revision records the new tags and keeps the categories.
+	require.Len(t, db.args, 14)
+	assert.Nil(t, db.args[12])
+	assert.Equal(t, []string{"clearance"}, db.args[13])
+}
+
+func TestProductServiceHandler_UpdateProduct_RetriesSerializationFailure(t *testing.T) {
+	db := &fakeDB{
+		product:  repository.Product{ID: 42, Name: "Widget", PriceMinor: 999, Currency: "USD"},
+		execErrs: map[string][]error{"ClearProductTags": {&pgconn.PgError{Code: "40001"}}},
+	}
+	handler := newTestHandler(t, db)
+	withRetries(handler, db, time.Millisecond)
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Tags: []string{"clearance"}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ClearProductTags", "ClearProductTags", "AddProductTags"}, db.execs)
+	assert.True(t, db.committed)
+}
+
+func TestProductServiceHandler_UpdateProduct_RetryOutlivesContext(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ctx  func() (context.Context, context.CancelFunc)
+		code codes.Code
+	}{
+		"deadline": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			code: codes.DeadlineExceeded,
+		},
+		"cancelled": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			code: codes.Canceled,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := &fakeDB{
+				product:  repository.Product{ID: 42, Name: "Widget", PriceMinor: 999, Currency: "USD"},
+				execErrs: map[string][]error{"ClearProductTags": {&pgconn.PgError{Code: "40001"}}},
+			}
+			handler := newTestHandler(t, db)
+			withRetries(handler, db, time.Hour)
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+			_, err := handler.UpdateProduct(ctx, &productsv1.UpdateProductRequest{
+				Product:    &productsv1.Product{Id: 42, Tags: []string{"clearance"}},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}},
+			})
+
+			st, _ := status.FromError(err)
+			assert.Equal(t, tc.code, st.Code())
+			assert.Equal(t, []string{"ClearProductTags"}, db.execs, "the backoff is cut short")
+			assert.False(t, db.committed)
+		})
+	}
+}
+
+func TestProductServiceHandler_ListProducts_CategoryAndTagFilter(t *testing.T) {
+	db := &fakeDB{}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{
+		Filter: &productsv1.ProductFilter{CategoryId: 5, Tag: "Sale"},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, db.sql, "product_categories")
+	assert.Contains(t, db.args, int64(5))
+	assert.Contains(t, db.args, "sale")
+}
+
+func TestProductServiceHandler_CreateCategory(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{categoryRow(1, 7, "Shoes", "/7/1/")}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.CreateCategory(context.Background(), &productsv1.CreateCategoryRequest{ParentId: 7, Name: " Shoes "})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/7/1/", resp.GetCategory().GetPath())
+	assert.Equal(t, uint64(7), resp.GetCategory().GetParentId())
+	assert.Equal(t, []any{int64(1), pgtype.Int8{Int64: 7, Valid: true}, "Shoes"}, db.args)
+}
+
+func TestProductServiceHandler_CreateCategory_UnknownParent(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.CreateCategory(context.Background(), &productsv1.CreateCategoryRequest{ParentId: 7, Name: "Shoes"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_UpdateCategory_MovesSubtree(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{
+		categoryRow(5, 1, "Shoes", "/1/5/"),
+		categoryRow(2, 0, "Sport", "/2/"),
+		categoryRow(5, 2, "Shoes", "/2/5/"),
+	}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.UpdateCategory(context.Background(), &productsv1.UpdateCategoryRequest{
+		Category:   &productsv1.Category{Id: 5, ParentId: 2},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"parent_id"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/2/5/", resp.GetCategory().GetPath())
+	assert.Equal(t, []string{"MoveCategoryDescendants"}, db.execs)
+	assert.True(t, db.committed)
+}
+
+func TestProductServiceHandler_UpdateCategory_RejectsCycle(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{
+		categoryRow(1, 0, "Clothing", "/1/"),
+		categoryRow(5, 1, "Shoes", "/1/5/"),
+	}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.UpdateCategory(context.Background(), &productsv1.UpdateCategoryRequest{
+		Category:   &productsv1.Category{Id: 1, ParentId: 5},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"parent_id"}},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, db.execs)
+	assert.True(t, db.rolledBack)
+}
+
+func TestProductServiceHandler_DeleteCategory_Misses(t *testing.T) {
+	tests := []struct {
+		name string
+		get  *fakeRow
+		want codes.Code
+	}{
+		{"not found", &fakeRow{err: pgx.ErrNoRows}, codes.NotFound},
+		{"has children", categoryRow(1, 0, "Clothing", "/1/"), codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.get}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.DeleteCategory(context.Background(), &productsv1.DeleteCategoryRequest{Id: 1})
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// formatETag renders a row version as the opaque etag exposed on Product.
+func formatETag(version int64) string {
+	return strconv.FormatInt(version, 10)
+}
+
+// parseETag converts a client supplied etag back into the expected row
+// version. An empty etag means the caller does not want a concurrency check.
+func parseETag(etag string) (pgtype.Int8, error) {
+	if etag == "" {
+		return pgtype.Int8{}, nil
+	}
+	version, err := strconv.ParseInt(etag, 10, 64)
+	if err != nil || version <= 0 {
+		return pgtype.Int8{}, validation.Invalid("etag", "is malformed: %q", etag)
+	}
+	return pgtype.Int8{Int64: version, Valid: true}, nil
+}
+
+// conditionalMissError explains why a version-guarded mutation touched no
+// rows: either the product does not exist or its etag no longer matches.
+// Inside a transaction q must be the transaction's store.
+func conditionalMissError(ctx context.Context, q store.ProductStore, id int64) error {
+	current, err := q.GetProductByID(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product %d not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("get product: %w", err)
+	}
+	return apierror.Errorf(codes.Aborted, apierror.ReasonEtagMismatch,
+		map[string]string{"product_id": strconv.FormatInt(id, 10), "current_etag": formatETag(current.Version)},
+		"etag mismatch for product %d: current etag is %q", id, formatETag(current.Version))
+}
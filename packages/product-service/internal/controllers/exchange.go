@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxRateDigits bounds the integer and fractional digits of a rate to what
+// exchange_rates.rate (DECIMAL(24, 12)) stores exactly.
+const maxRateDigits = 12
+
+func (c *ProductServiceHandler) UpsertExchangeRate(ctx context.Context, req *productsv1.UpsertExchangeRateRequest) (*productsv1.UpsertExchangeRateResponse, error) {
+	ctx, span := c.startSpan(ctx, "UpsertExchangeRate.Handler")
+	defer span.End()
+
+	const op = "upsert_exchange_rate"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	params, err := validateExchangeRate(req.GetRate())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	stored, err := c.store(ctx).UpsertExchangeRate(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to store exchange rate: %v", err)
+	}
+
+	rate, err := numericToRat(stored.Rate)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to read exchange rate: %v", err)
+	}
+
+	return &productsv1.UpsertExchangeRateResponse{
+		Rate: mapRateToProto(stored.BaseCurrency, stored.QuoteCurrency, rate, stored.EffectiveAt),
+	}, nil
+}
+
+// validateExchangeRate checks an exchange rate and converts it to query
+// parameters. A missing effective_at means now.
+func validateExchangeRate(r *productsv1.ExchangeRate) (repository.UpsertExchangeRateParams, error) {
+	var params repository.UpsertExchangeRateParams
+	if r == nil {
+		return params, validation.Invalid("rate", "is required")
+	}
+
+	if _, err := money.LookupCurrency(r.GetBaseCurrency()); err != nil {
+		return params, validation.Invalid("rate.base_currency", "%v", err)
+	}
+	if _, err := money.LookupCurrency(r.GetQuoteCurrency()); err != nil {
+		return params, validation.Invalid("rate.quote_currency", "%v", err)
+	}
+	if r.GetBaseCurrency() == r.GetQuoteCurrency() {
+		return params, validation.Invalid("rate.quote_currency", "must differ from rate.base_currency")
+	}
+
+	if _, err := money.ParseRate(r.GetRate()); err != nil {
+		return params, validation.Invalid("rate.rate", "%v", err)
+	}
+	whole, frac, _ := strings.Cut(r.GetRate(), ".")
+	if len(strings.TrimLeft(whole, "0")) > maxRateDigits || len(strings.TrimRight(frac, "0")) > maxRateDigits {
+		return params, validation.Invalid("rate.rate", "must have at most %d digits before and after the decimal point", maxRateDigits)
+	}
+	if err := params.Rate.Scan(r.GetRate()); err != nil {
+		return params, validation.Invalid("rate.rate", "%v", err)
+	}
+
+	params.EffectiveAt = time.Now()
+	if r.EffectiveAt != nil {
+		if err := r.GetEffectiveAt().CheckValid(); err != nil {
+			return params, validation.Invalid("rate.effective_at", "%v", err)
+		}
+		params.EffectiveAt = r.GetEffectiveAt().AsTime()
+	}
+
+	params.BaseCurrency = r.GetBaseCurrency()
+	params.QuoteCurrency = r.GetQuoteCurrency()
+	return params, nil
+}
+
+// priceConverter fills in Product.display_price for one request. Each rate
+// is looked up once, so a page of products in the same currency costs a
+// single query.
+type priceConverter struct {
+	c     *ProductServiceHandler
+	to    money.Currency
+	rates map[string]resolvedRate
+}
+
+// resolvedRate is the rate in force from one currency into the display
+// currency.
+type resolvedRate struct {
+	exact *big.Rat
+	proto *productsv1.ExchangeRate
+}
+
+// newPriceConverter validates a requested display currency. It returns a
+// nil converter, whose apply does nothing, when none was requested.
+func (c *ProductServiceHandler) newPriceConverter(displayCurrency string) (*priceConverter, error) {
+	if displayCurrency == "" {
+		return nil, nil
+	}
+	to, err := money.LookupCurrency(displayCurrency)
+	if err != nil {
+		return nil, validation.Invalid("display_currency", "%v", err)
+	}
+	return &priceConverter{
+		c:     c,
+		to:    to,
+		rates: map[string]resolvedRate{},
+	}, nil
+}
+
+// apply sets p.DisplayPrice to p's price in the display currency.
+func (pc *priceConverter) apply(ctx context.Context, p *productsv1.Product) error {
+	if pc == nil {
+		return nil
+	}
+
+	price := p.GetPrice()
+	if price.GetCurrencyCode() == pc.to.Code {
+		p.DisplayPrice = &productsv1.ConvertedPrice{Price: price}
+		return nil
+	}
+
+	from, err := money.LookupCurrency(price.GetCurrencyCode())
+	if err != nil {
+		return status.Errorf(codes.Internal, "product %d: %v", p.GetId(), err)
+	}
+	minor, err := money.ToMinor(price)
+	if err != nil {
+		return status.Errorf(codes.Internal, "product %d: %v", p.GetId(), err)
+	}
+
+	rate, err := pc.rate(ctx, from.Code)
+	if err != nil {
+		return err
+	}
+	converted, err := money.Convert(minor, from, pc.to, rate.exact, pc.c.rounding)
+	if err != nil {
+		return status.Errorf(codes.OutOfRange, "product %d: converting %s to %s: %v", p.GetId(), from.Code, pc.to.Code, err)
+	}
+
+	p.DisplayPrice = &productsv1.ConvertedPrice{
+		Price: money.FromMinor(pc.to.Code, converted),
+		Rate:  rate.proto,
+	}
+	return nil
+}
+
+// rate returns the rate in force from base into the display currency,
+// inverting the opposite direction when only that one is on record.
+func (pc *priceConverter) rate(ctx context.Context, base string) (resolvedRate, error) {
+	if r, ok := pc.rates[base]; ok {
+		return r, nil
+	}
+
+	stored, err := pc.c.store(ctx).GetExchangeRate(ctx, repository.GetExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: pc.to.Code,
+	})
+	inverse := false
+	if errors.Is(err, pgx.ErrNoRows) {
+		inverse = true
+		stored, err = pc.c.store(ctx).GetExchangeRate(ctx, repository.GetExchangeRateParams{
+			BaseCurrency:  pc.to.Code,
+			QuoteCurrency: base,
+		})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return resolvedRate{}, status.Errorf(codes.FailedPrecondition, "no exchange rate from %s to %s", base, pc.to.Code)
+	}
+	if err != nil {
+		return resolvedRate{}, status.Errorf(codes.Internal, "failed to get exchange rate: %v", err)
+	}
+
+	exact, err := numericToRat(stored.Rate)
+	if err != nil {
+		return resolvedRate{}, status.Errorf(codes.Internal, "failed to read exchange rate: %v", err)
+	}
+	if inverse {
+		exact.Inv(exact)
+	}
+
+	r := resolvedRate{exact: exact, proto: mapRateToProto(base, pc.to.Code, exact, stored.EffectiveAt)}
+	pc.rates[base] = r
+	return r, nil
+}
+
+// numericToRat converts a stored DECIMAL to an exact fraction.
+func numericToRat(n pgtype.Numeric) (*big.Rat, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return nil, errors.New("rate is not a finite number")
+	}
+	r := new(big.Rat).SetInt(n.Int)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(n.Exp))), nil)
+	if n.Exp < 0 {
+		return r.Quo(r, new(big.Rat).SetInt(scale)), nil
+	}
+	return r.Mul(r, new(big.Rat).SetInt(scale)), nil
+}
+
+func abs32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func mapRateToProto(base, quote string, rate *big.Rat, effectiveAt time.Time) *productsv1.ExchangeRate {
+	return &productsv1.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          money.FormatRate(rate, maxRateDigits),
+		EffectiveAt:   timestamppb.New(effectiveAt),
+	}
+}
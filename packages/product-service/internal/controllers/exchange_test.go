@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// rateRow is an exchange_rates row as scanned by GetExchangeRate. The rate
+// is given in units of 10^exp.
+func rateRow(base, quote string, rate int64, exp int32, effectiveAt time.Time) *fakeRow {
+	return &fakeRow{values: []any{
+		base, quote,
+		pgtype.Numeric{Int: big.NewInt(rate), Exp: exp, Valid: true},
+		effectiveAt, effectiveAt,
+	}}
+}
+
+func TestProductServiceHandler_GetProduct_DisplayCurrency(t *testing.T) {
+	effectiveAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	db := &fakeDB{queue: []*fakeRow{
+		{product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 1999, Currency: "USD"}},
+		rateRow("USD", "EUR", 92, -2, effectiveAt),
+	}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42, DisplayCurrency: "EUR"})
+	require.NoError(t, err)
+
+	p := resp.GetProduct()
+	assert.True(t, proto.Equal(usd(1999), p.GetPrice()), "original price is kept")
+	assert.Equal(t, "18.39", money.Format(p.GetDisplayPrice().GetPrice()))
+	assert.Equal(t, "EUR", p.GetDisplayPrice().GetPrice().GetCurrencyCode())
+	assert.Equal(t, "0.92", p.GetDisplayPrice().GetRate().GetRate())
+	assert.Equal(t, effectiveAt, p.GetDisplayPrice().GetRate().GetEffectiveAt().AsTime())
+}
+
+func TestProductServiceHandler_GetProduct_DisplayCurrencyInverseRate(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{
+		{product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 1999, Currency: "USD"}},
+		{err: pgx.ErrNoRows},
+		rateRow("EUR", "USD", 125, -2, time.Now()),
+	}}
+	handler := newTestHandler(t, db)
+	handler.rounding = money.RoundUp
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42, DisplayCurrency: "EUR"})
+	require.NoError(t, err)
+
+	display := resp.GetProduct().GetDisplayPrice()
+	assert.Equal(t, "16.00", money.Format(display.GetPrice()), "19.99 / 1.25 = 15.992 rounded up")
+	assert.Equal(t, "USD", display.GetRate().GetBaseCurrency())
+	assert.Equal(t, "0.8", display.GetRate().GetRate())
+}
+
+func TestProductServiceHandler_GetProduct_DisplayCurrencyErrors(t *testing.T) {
+	product := &fakeRow{product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 1999, Currency: "USD"}}
+
+	handler := newTestHandler(t, &fakeDB{queue: []*fakeRow{product, {err: pgx.ErrNoRows}, {err: pgx.ErrNoRows}}})
+	_, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42, DisplayCurrency: "EUR"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42, DisplayCurrency: "EURO"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_ListProducts_DisplayCurrency(t *testing.T) {
+	db := &fakeDB{
+		list: []repository.Product{
+			{ID: 1, Name: "One", PriceMinor: 100, Currency: "USD"},
+			{ID: 2, Name: "Two", PriceMinor: 250, Currency: "USD"},
+			{ID: 3, Name: "Three", PriceMinor: 500, Currency: "JPY"},
+		},
+		// One lookup serves every USD product; JPY needs no rate.
+		queue: []*fakeRow{rateRow("USD", "JPY", 150, 0, time.Now())},
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{DisplayCurrency: "JPY"})
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range resp.GetProducts() {
+		got = append(got, money.Format(p.GetDisplayPrice().GetPrice()))
+	}
+	assert.Equal(t, []string{"150", "375", "500"}, got)
+	assert.Nil(t, resp.GetProducts()[2].GetDisplayPrice().GetRate(), "no rate for products already in the display currency")
+}
+
+func TestProductServiceHandler_UpsertExchangeRate(t *testing.T) {
+	effectiveAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	db := &fakeDB{queue: []*fakeRow{rateRow("USD", "EUR", 9215, -4, effectiveAt)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.UpsertExchangeRate(context.Background(), &productsv1.UpsertExchangeRateRequest{
+		Rate: &productsv1.ExchangeRate{
+			BaseCurrency:  "USD",
+			QuoteCurrency: "EUR",
+			Rate:          "0.9215",
+			EffectiveAt:   timestamppb.New(effectiveAt),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "0.9215", resp.GetRate().GetRate())
+
+	// Arguments follow repository.UpsertExchangeRateParams field order.
+	require.Len(t, db.args, 4)
+	assert.Equal(t, "USD", db.args[0])
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(9215), Exp: -4, Valid: true}, db.args[2])
+	assert.Equal(t, effectiveAt, db.args[3])
+}
+
+func TestProductServiceHandler_UpsertExchangeRate_Invalid(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	rates := map[string]*productsv1.ExchangeRate{
+		"missing":           nil,
+		"unknown base":      {BaseCurrency: "XXX", QuoteCurrency: "EUR", Rate: "1"},
+		"same currency":     {BaseCurrency: "EUR", QuoteCurrency: "EUR", Rate: "1"},
+		"zero rate":         {BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "0"},
+		"not a decimal":     {BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "1/3"},
+		"too many decimals": {BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "0.0000000000001"},
+	}
+	for name, rate := range rates {
+		t.Run(name, func(t *testing.T) {
+			_, err := handler.UpsertExchangeRate(context.Background(), &productsv1.UpsertExchangeRateRequest{Rate: rate})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxReconcileResults caps the drift report of a single ReconcileStock call.
+const maxReconcileResults = uint32(1000)
+
+// movementReasons maps ledger reasons to their stock_movements.reason value.
+var movementReasons = map[productsv1.StockMovementReason]string{
+	productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT:    "receipt",
+	productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE:       "sale",
+	productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RETURN:     "return",
+	productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE:  "shrinkage",
+	productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_CORRECTION: "correction",
+}
+
+// validateMovement checks that delta has the sign its reason implies. That
+// both are set is left to the request's field rules.
+func validateMovement(reason productsv1.StockMovementReason, delta int32) error {
+	switch reason {
+	case productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT,
+		productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RETURN:
+		if delta < 0 {
+			return validation.Invalid("delta", "must be positive for %s", movementReasons[reason])
+		}
+	case productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE,
+		productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE:
+		if delta > 0 {
+			return validation.Invalid("delta", "must be negative for %s", movementReasons[reason])
+		}
+	}
+	return nil
+}
+
+func (c *ProductServiceHandler) AdjustStock(ctx context.Context, req *productsv1.AdjustStockRequest) (*productsv1.AdjustStockResponse, error) {
+	ctx, span := c.startSpan(ctx, "AdjustStock.Handler")
+	defer span.End()
+
+	const op = "adjust_stock"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if err := validateMovement(req.GetReason(), req.GetDelta()); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	id, err := c.ids.NextID()
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to generate movement ID: %v", err)
+	}
+
+	if req.GetVariantId() != 0 {
+		return c.adjustVariantStock(ctx, op, int64(id), req)
+	}
+
+	row, err := c.store(ctx).AdjustStock(ctx, repository.AdjustStockParams{
+		ID:        int64(id),
+		ProductID: req.GetProductId(),
+		Delta:     req.GetDelta(),
+		Reason:    movementReasons[req.GetReason()],
+		Reference: pgtype.Text{String: req.GetReference(), Valid: req.GetReference() != ""},
+		Note:      pgtype.Text{String: req.GetNote(), Valid: req.GetNote() != ""},
+		Actor:     actorFromContext(ctx),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.adjustMissError(ctx, req.GetProductId(), req.GetDelta())
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to adjust stock: %v", err)
+	}
+
+	return &productsv1.AdjustStockResponse{
+		Movement: mapMovementToProto(repository.StockMovement{
+			ID:        row.ID,
+			ProductID: row.ProductID,
+			Delta:     row.Delta,
+			Reason:    row.Reason,
+			Reference: row.Reference,
+			Note:      row.Note,
+			CreatedAt: row.CreatedAt,
+		}),
+		StockQuantity: uint32(row.StockQuantity),
+	}, nil
+}
+
+// adjustVariantStock is AdjustStock for a variant of the product.
+func (c *ProductServiceHandler) adjustVariantStock(ctx context.Context, op string, id int64, req *productsv1.AdjustStockRequest) (*productsv1.AdjustStockResponse, error) {
+	if req.GetVariantId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("variant_id", "is not a valid ID")
+	}
+
+	row, err := c.store(ctx).AdjustVariantStock(ctx, repository.AdjustVariantStockParams{
+		ID:        id,
+		VariantID: int64(req.GetVariantId()),
+		ProductID: req.GetProductId(),
+		Delta:     req.GetDelta(),
+		Reason:    movementReasons[req.GetReason()],
+		Reference: pgtype.Text{String: req.GetReference(), Valid: req.GetReference() != ""},
+		Note:      pgtype.Text{String: req.GetNote(), Valid: req.GetNote() != ""},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.variantStockMissError(ctx, req.GetProductId(), int64(req.GetVariantId()), "adjust stock",
+			fmt.Sprintf("delta %d", req.GetDelta()))
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to adjust stock: %v", err)
+	}
+
+	return &productsv1.AdjustStockResponse{
+		Movement: mapMovementToProto(repository.StockMovement{
+			ID:        row.ID,
+			ProductID: row.ProductID,
+			Delta:     row.Delta,
+			Reason:    row.Reason,
+			Reference: row.Reference,
+			Note:      row.Note,
+			CreatedAt: row.CreatedAt,
+			VariantID: row.VariantID,
+		}),
+		StockQuantity: uint32(row.StockQuantity),
+	}, nil
+}
+
+// variantStockMissError explains why a variant stock change matched no
+// row: the product or variant does not exist, the variant belongs to another
+// product, or it has too little stock. want describes the requested change.
+func (c *ProductServiceHandler) variantStockMissError(ctx context.Context, productID, variantID int64, action, want string) error {
+	if _, err := c.store(ctx).GetProductByID(ctx, productID); errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product with ID %d not found", productID)
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to %s: %v", action, err)
+	}
+	variant, err := c.store(ctx).GetVariant(ctx, variantID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && variant.ProductID != productID) {
+		return status.Errorf(codes.NotFound, "variant %d of product %d not found", variantID, productID)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to %s: %v", action, err)
+	}
+	return apierror.Errorf(codes.FailedPrecondition, apierror.ReasonInsufficientStock,
+		map[string]string{"variant_id": strconv.FormatInt(variantID, 10), "available": strconv.FormatInt(int64(variant.StockQuantity), 10)},
+		"insufficient stock for variant %d: %s, available %d", variantID, want, variant.StockQuantity)
+}
+
+// adjustMissError explains why AdjustStock matched no product row: either
+// the product does not exist or the change would make stock negative.
+func (c *ProductServiceHandler) adjustMissError(ctx context.Context, productID int64, delta int32) error {
+	product, err := c.store(ctx).GetProductByID(ctx, productID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product with ID %d not found", productID)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to adjust stock: %v", err)
+	}
+	return apierror.Errorf(codes.FailedPrecondition, apierror.ReasonInsufficientStock,
+		map[string]string{"product_id": strconv.FormatInt(productID, 10), "available": strconv.FormatInt(int64(product.StockQuantity), 10)},
+		"insufficient stock for product %d: delta %d, available %d", productID, delta, product.StockQuantity)
+}
+
+func (c *ProductServiceHandler) ListStockMovements(ctx context.Context, req *productsv1.ListStockMovementsRequest) (*productsv1.ListStockMovementsResponse, error) {
+	ctx, span := c.startSpan(ctx, "ListStockMovements.Handler")
+	defer span.End()
+
+	const op = "list_stock_movements"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	params := repository.ListStockMovementsParams{
+		ProductID: req.GetProductId(),
+		PageLimit: int32(pageSize) + 1,
+	}
+	fingerprint := url.Values{"product_id": {strconv.FormatInt(req.GetProductId(), 10)}}
+	if req.GetReason() != productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_UNSPECIFIED {
+		reason, ok := movementReasons[req.GetReason()]
+		if !ok {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("reason", "has unknown value %d", req.GetReason())
+		}
+		params.Reason = pgtype.Text{String: reason, Valid: true}
+		fingerprint.Set("reason", reason)
+	}
+	if v := req.GetVariantId(); v != 0 {
+		if v > math.MaxInt64 {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("variant_id", "is not a valid ID")
+		}
+		params.VariantID = pgtype.Int8{Int64: int64(v), Valid: true}
+		fingerprint.Set("variant_id", strconv.FormatUint(v, 10))
+	}
+
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := c.pageTokens.Decode(token, fingerprint.Encode())
+		var createdAt time.Time
+		if err == nil {
+			createdAt, err = time.Parse(time.RFC3339Nano, cursor.LastKey)
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("page_token", "%v", pagination.ErrMalformedToken)
+		}
+		params.BeforeCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.BeforeID = cursor.LastID
+	}
+
+	movements, err := c.store(ctx).ListStockMovements(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to list stock movements: %v", err)
+	}
+
+	resp := &productsv1.ListStockMovementsResponse{}
+	if len(movements) > int(pageSize) {
+		movements = movements[:pageSize]
+		last := movements[len(movements)-1]
+		resp.NextPageToken, err = c.pageTokens.Encode(pagination.Cursor{
+			LastID:  last.ID,
+			LastKey: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			Query:   fingerprint.Encode(),
+		})
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to build page token: %v", err)
+		}
+	}
+
+	resp.Movements = make([]*productsv1.StockMovement, 0, len(movements))
+	for _, m := range movements {
+		resp.Movements = append(resp.Movements, mapMovementToProto(m))
+	}
+
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) ReconcileStock(ctx context.Context, req *productsv1.ReconcileStockRequest) (*productsv1.ReconcileStockResponse, error) {
+	ctx, span := c.startSpan(ctx, "ReconcileStock.Handler")
+	defer span.End()
+
+	const op = "reconcile_stock"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	limit := req.GetMaxResults()
+	if limit == 0 || limit > maxReconcileResults {
+		limit = maxReconcileResults
+	}
+
+	rows, err := c.store(ctx).ReconcileStock(ctx, int32(limit))
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to reconcile stock: %v", err)
+	}
+
+	resp := &productsv1.ReconcileStockResponse{
+		Drifts: make([]*productsv1.StockDrift, 0, len(rows)),
+	}
+	for _, r := range rows {
+		resp.Drifts = append(resp.Drifts, &productsv1.StockDrift{
+			ProductId:      uint64(r.ProductID),
+			VariantId:      uint64(r.VariantID.Int64),
+			StockQuantity:  int64(r.StockQuantity),
+			HeldQuantity:   r.HeldQuantity,
+			LedgerQuantity: r.LedgerQuantity,
+			Drift:          int64(r.StockQuantity) + r.HeldQuantity - r.LedgerQuantity,
+		})
+	}
+	if len(rows) > 0 {
+		c.log.Warn("stock ledger drift detected", zap.Int("products", len(rows)))
+	}
+
+	return resp, nil
+}
+
+func mapMovementToProto(m repository.StockMovement) *productsv1.StockMovement {
+	var reason productsv1.StockMovementReason
+	for r, name := range movementReasons {
+		if name == m.Reason {
+			reason = r
+		}
+	}
+	return &productsv1.StockMovement{
+		Id:        uint64(m.ID),
+		ProductId: uint64(m.ProductID),
+		VariantId: uint64(m.VariantID.Int64),
+		Delta:     m.Delta,
+		Reason:    reason,
+		Reference: m.Reference.String,
+		Note:      m.Note.String,
+		CreatedAt: timestamppb.New(m.CreatedAt),
+	}
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// movementRow is a stock_movements row for product 1.
+func movementRow(id int64, delta int32, reason string, createdAt time.Time) *fakeRow {
+	return &fakeRow{values: []any{id, int64(1), delta, reason, pgtype.Text{}, pgtype.Text{}, createdAt, pgtype.Int8{}}}
+}
+
+func TestValidateMovement(t *testing.T) {
+	tests := []struct {
+		reason productsv1.StockMovementReason
+		delta  int32
+		ok     bool
+	}{
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT, 5, true},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT, -5, false},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RETURN, -1, false},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE, -2, true},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE, 2, false},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE, 1, false},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_CORRECTION, -3, true},
+		{productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_CORRECTION, 3, true},
+	}
+	for _, tt := range tests {
+		err := validateMovement(tt.reason, tt.delta)
+		if tt.ok {
+			assert.NoError(t, err, "%s %d", tt.reason, tt.delta)
+		} else {
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), "%s %d", tt.reason, tt.delta)
+		}
+	}
+}
+
+func TestProductServiceHandler_AdjustStock(t *testing.T) {
+	now := time.Now()
+	db := &fakeDB{queue: []*fakeRow{{values: []any{
+		int64(9), int64(1), int32(-2), "shrinkage", pgtype.Text{}, pgtype.Text{String: "damaged", Valid: true}, now, int32(8),
+	}}}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.AdjustStock(context.Background(), &productsv1.AdjustStockRequest{
+		ProductId: 1,
+		Delta:     -2,
+		Reason:    productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE,
+		Note:      "damaged",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(8), resp.GetStockQuantity())
+	assert.Equal(t, int32(-2), resp.GetMovement().GetDelta())
+	assert.Equal(t, productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SHRINKAGE, resp.GetMovement().GetReason())
+	assert.Equal(t, "damaged", resp.GetMovement().GetNote())
+
+	// Arguments follow repository.AdjustStockParams field order.
+	require.Len(t, db.args, 7)
+	assert.Equal(t, int32(-2), db.args[0])
+	assert.Equal(t, int64(1), db.args[1])
+	assert.Equal(t, "shrinkage", db.args[3])
+	assert.Equal(t, pgtype.Text{}, db.args[4], "empty reference is stored as NULL")
+	assert.Equal(t, unknownActor, db.args[6], "the stock revision records the actor")
+}
+
+func TestProductServiceHandler_AdjustStock_WouldGoNegative(t *testing.T) {
+	db := &fakeDB{
+		queue:   []*fakeRow{{err: pgx.ErrNoRows}},
+		product: repository.Product{ID: 1, StockQuantity: 1},
+	}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.AdjustStock(context.Background(), &productsv1.AdjustStockRequest{
+		ProductId: 1,
+		Delta:     -2,
+		Reason:    productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestProductServiceHandler_AdjustStock_ProductNotFound(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.AdjustStock(context.Background(), &productsv1.AdjustStockRequest{
+		ProductId: 1,
+		Delta:     4,
+		Reason:    productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestProductServiceHandler_ListStockMovements_Paginates(t *testing.T) {
+	now := time.Now()
+	db := &fakeDB{rows: []*fakeRow{
+		movementRow(3, -1, "sale", now),
+		movementRow(2, 4, "return", now.Add(-time.Minute)),
+		movementRow(1, 10, "receipt", now.Add(-time.Hour)),
+	}}
+	handler := newTestHandler(t, db)
+
+	req := &productsv1.ListStockMovementsRequest{ProductId: 1, PageSize: 2}
+	resp, err := handler.ListStockMovements(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.GetMovements(), 2)
+	assert.Equal(t, productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE, resp.GetMovements()[0].GetReason())
+	require.NotEmpty(t, resp.GetNextPageToken())
+
+	db.rows = []*fakeRow{movementRow(1, 10, "receipt", now.Add(-time.Hour))}
+	req.PageToken = resp.GetNextPageToken()
+	resp, err = handler.ListStockMovements(context.Background(), req)
+	require.NoError(t, err)
+	assert.Len(t, resp.GetMovements(), 1)
+	assert.Empty(t, resp.GetNextPageToken())
+
+	// product id, variant id, reason, before created_at, before id, limit
+	require.Len(t, db.args, 6)
+	assert.Equal(t, pgtype.Timestamptz{Time: now.Add(-time.Minute).UTC(), Valid: true}, db.args[3])
+	assert.Equal(t, int64(2), db.args[4])
+
+	// The token is bound to the reason filter it was issued for.
+	req.Reason = productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_SALE
+	_, err = handler.ListStockMovements(context.Background(), req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_ReconcileStock(t *testing.T) {
+	db := &fakeDB{rows: []*fakeRow{
+		{values: []any{int64(1), pgtype.Int8{}, int32(5), int64(2), int64(9)}},
+		{values: []any{int64(1), pgtype.Int8{Int64: 4, Valid: true}, int32(3), int64(0), int64(2)}},
+	}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.ReconcileStock(context.Background(), &productsv1.ReconcileStockRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetDrifts(), 2)
+	assert.Equal(t, int64(-2), resp.GetDrifts()[0].GetDrift())
+	assert.Zero(t, resp.GetDrifts()[0].GetVariantId())
+	assert.Equal(t, uint64(4), resp.GetDrifts()[1].GetVariantId())
+	assert.Equal(t, int64(1), resp.GetDrifts()[1].GetDrift())
+	assert.Equal(t, []interface{}{int32(maxReconcileResults)}, db.args)
+}
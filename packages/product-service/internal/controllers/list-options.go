@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+)
+
+var sortFields = map[string]repository.ProductSortField{
+	"id":         repository.ProductSortByID,
+	"price":      repository.ProductSortByPrice,
+	"name":       repository.ProductSortByName,
+	"created_at": repository.ProductSortByCreatedAt,
+}
+
+// listOptions is a validated ListProductsRequest.
+type listOptions struct {
+	params  repository.ListProductsParams
+	orderBy string
+}
+
+// parseListOptions validates the filter and order_by of a ListProductsRequest.
+func parseListOptions(req *productsv1.ListProductsRequest) (*listOptions, error) {
+	opts := &listOptions{}
+
+	if err := opts.parseOrderBy(req.GetOrderBy()); err != nil {
+		return nil, err
+	}
+
+	f := req.GetFilter()
+	if f == nil {
+		f = &productsv1.ProductFilter{}
+	}
+
+	opts.params.Currency = f.GetCurrency()
+	var err error
+	if opts.params.MinPrice, err = priceBound("filter.min_price", f.GetMinPrice(), &opts.params.Currency); err != nil {
+		return nil, err
+	}
+	if opts.params.MaxPrice, err = priceBound("filter.max_price", f.GetMaxPrice(), &opts.params.Currency); err != nil {
+		return nil, err
+	}
+	if opts.params.MinPrice != nil && opts.params.MaxPrice != nil && *opts.params.MinPrice > *opts.params.MaxPrice {
+		return nil, validation.Invalid("filter.min_price", "must not exceed filter.max_price")
+	}
+	// Like the price bounds, a price sort compares minor units, which only
+	// rank prices within one currency.
+	if opts.params.SortBy == repository.ProductSortByPrice && opts.params.Currency == "" {
+		return nil, validation.Invalid("order_by", "can only order by price within one currency; set filter.currency")
+	}
+
+	opts.params.NamePrefix = f.GetNamePrefix()
+	opts.params.InStockOnly = f.GetInStockOnly()
+	if f.GetCategoryId() > math.MaxInt64 {
+		return nil, validation.Invalid("filter.category_id", "is not a valid ID")
+	}
+	opts.params.CategoryID = int64(f.GetCategoryId())
+	opts.params.Tag = strings.ToLower(strings.TrimSpace(f.GetTag()))
+	opts.params.ShowDeleted = req.GetShowDeleted()
+
+	return opts, nil
+}
+
+// priceBound converts a price filter bound to minor units. Minor units are
+// only comparable within one currency, so the bound must agree with currency,
+// which it fills in when unset.
+func priceBound(field string, bound *productsv1.Money, currency *string) (*int64, error) {
+	if bound == nil {
+		return nil, nil
+	}
+	minor, err := money.ToMinor(bound)
+	if err != nil {
+		return nil, validation.Invalid(field, "%v", err)
+	}
+	if minor < 0 {
+		return nil, validation.Invalid(field, "must not be negative")
+	}
+	switch *currency {
+	case "":
+		*currency = bound.GetCurrencyCode()
+	case bound.GetCurrencyCode():
+	default:
+		return nil, validation.Invalid(field, "is in %s but filter.currency is %s", bound.GetCurrencyCode(), *currency)
+	}
+	return &minor, nil
+}
+
+func (o *listOptions) parseOrderBy(orderBy string) error {
+	parts := strings.Fields(strings.ToLower(orderBy))
+	if len(parts) == 0 {
+		parts = []string{"id"}
+	}
+	if len(parts) > 2 {
+		return validation.Invalid("order_by", "must be a single field optionally followed by asc or desc")
+	}
+
+	field, ok := sortFields[parts[0]]
+	if !ok {
+		return validation.Invalid("order_by", "cannot order by %q", parts[0])
+	}
+	o.params.SortBy = field
+
+	direction := "asc"
+	if len(parts) == 2 {
+		direction = parts[1]
+	}
+	switch direction {
+	case "asc":
+	case "desc":
+		o.params.Descending = true
+	default:
+		return validation.Invalid("order_by", "direction must be asc or desc")
+	}
+
+	o.orderBy = parts[0] + " " + direction
+	return nil
+}
+
+// fingerprint canonically identifies the filter and sort order so page
+// tokens cannot be replayed against a different query.
+func (o *listOptions) fingerprint() string {
+	v := url.Values{}
+	v.Set("order_by", o.orderBy)
+	if o.params.Currency != "" {
+		v.Set("currency", o.params.Currency)
+	}
+	if o.params.MinPrice != nil {
+		v.Set("min_price", strconv.FormatInt(*o.params.MinPrice, 10))
+	}
+	if o.params.MaxPrice != nil {
+		v.Set("max_price", strconv.FormatInt(*o.params.MaxPrice, 10))
+	}
+	if o.params.NamePrefix != "" {
+		v.Set("name_prefix", o.params.NamePrefix)
+	}
+	if o.params.InStockOnly {
+		v.Set("in_stock_only", "true")
+	}
+	if o.params.CategoryID != 0 {
+		v.Set("category_id", strconv.FormatInt(o.params.CategoryID, 10))
+	}
+	if o.params.Tag != "" {
+		v.Set("tag", o.params.Tag)
+	}
+	if o.params.ShowDeleted {
+		v.Set("show_deleted", "true")
+	}
+	return v.Encode()
+}
+
+// cursorFor builds the cursor that resumes after p.
+func (o *listOptions) cursorFor(p repository.Product) pagination.Cursor {
+	cur := pagination.Cursor{LastID: p.ID, Query: o.fingerprint()}
+	switch o.params.SortBy {
+	case repository.ProductSortByPrice:
+		cur.LastKey = strconv.FormatInt(p.PriceMinor, 10)
+	case repository.ProductSortByName:
+		cur.LastKey = p.Name
+	case repository.ProductSortByCreatedAt:
+		cur.LastKey = p.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return cur
+}
+
+// resumeFrom positions the query after the row described by cur.
+func (o *listOptions) resumeFrom(cur pagination.Cursor) error {
+	keyset := &repository.ProductKeyset{ID: cur.LastID}
+
+	switch o.params.SortBy {
+	case repository.ProductSortByPrice:
+		price, err := strconv.ParseInt(cur.LastKey, 10, 64)
+		if err != nil {
+			return pagination.ErrMalformedToken
+		}
+		keyset.Value = price
+	case repository.ProductSortByName:
+		keyset.Value = cur.LastKey
+	case repository.ProductSortByCreatedAt:
+		createdAt, err := time.Parse(time.RFC3339Nano, cur.LastKey)
+		if err != nil {
+			return pagination.ErrMalformedToken
+		}
+		keyset.Value = createdAt
+	}
+
+	o.params.After = keyset
+	return nil
+}
@@ -0,0 +1,401 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxProductMedia bounds the number of images of a single product.
+	maxProductMedia = 20
+
+	// defaultMediaBaseURL is where the gateway serves blobs from when
+	// media.base_url is not configured.
+	defaultMediaBaseURL = "/media/"
+
+	defaultPurgedMediaPageSize = 100
+)
+
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// mediaBaseURL normalizes media.base_url so that a storage key can be
+// appended to it.
+func mediaBaseURL(configured string) string {
+	if configured == "" {
+		return defaultMediaBaseURL
+	}
+	return strings.TrimSuffix(configured, "/") + "/"
+}
+
+// validateAddProductMedia checks the blob metadata of an AddProductMedia
+// request and converts it to query parameters.
+func validateAddProductMedia(req *productsv1.AddProductMediaRequest) (repository.AddProductMediaParams, error) {
+	var params repository.AddProductMediaParams
+	switch {
+	case req.GetProductId() == 0 || req.GetProductId() > math.MaxInt64:
+		return params, validation.Invalid("product_id", "is not a valid ID")
+	case !strings.HasPrefix(req.GetContentType(), "image/"):
+		return params, validation.Invalid("content_type", "must be an image type")
+	case !checksumPattern.MatchString(req.GetChecksumSha256()):
+		return params, validation.Invalid("checksum_sha256", "must be 64 lower-case hex digits")
+	case req.GetSizeBytes() == 0 || req.GetSizeBytes() > math.MaxInt64:
+		return params, validation.Invalid("size_bytes", "must be greater than 0")
+	case req.GetWidth() == 0 || req.GetWidth() > math.MaxInt32:
+		return params, validation.Invalid("width", "must be greater than 0")
+	case req.GetHeight() == 0 || req.GetHeight() > math.MaxInt32:
+		return params, validation.Invalid("height", "must be greater than 0")
+	}
+
+	return repository.AddProductMediaParams{
+		ProductID:      int64(req.GetProductId()),
+		StorageKey:     req.GetStorageKey(),
+		ContentType:    req.GetContentType(),
+		ChecksumSha256: req.GetChecksumSha256(),
+		SizeBytes:      int64(req.GetSizeBytes()),
+		Width:          int32(req.GetWidth()),
+		Height:         int32(req.GetHeight()),
+		AltText:        req.GetAltText(),
+		MaxMedia:       maxProductMedia,
+	}, nil
+}
+
+func (c *ProductServiceHandler) AddProductMedia(ctx context.Context, req *productsv1.AddProductMediaRequest) (*productsv1.AddProductMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "AddProductMedia.Handler")
+	defer span.End()
+
+	const op = "add_product_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	params, err := validateAddProductMedia(req)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	id, err := c.ids.NextID()
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to generate media ID: %v", err)
+	}
+	params.ID = int64(id)
+
+	media, err := c.store(ctx).AddProductMedia(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.addMediaMissError(ctx, params.ProductID)
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, status.Errorf(codes.AlreadyExists, "product %d already has an image with checksum %s", params.ProductID, params.ChecksumSha256)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to add product media: %v", err)
+	}
+
+	return &productsv1.AddProductMediaResponse{
+		Media: c.mapMediaToProto(media),
+	}, nil
+}
+
+// addMediaMissError explains why AddProductMedia inserted nothing: either
+// the product does not exist or it already has the maximum number of
+// images.
+func (c *ProductServiceHandler) addMediaMissError(ctx context.Context, productID int64) error {
+	if _, err := c.store(ctx).GetProductByID(ctx, productID); errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product with ID %d not found", productID)
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to add product media: %v", err)
+	}
+	return status.Errorf(codes.FailedPrecondition, "product %d already has the maximum of %d images", productID, maxProductMedia)
+}
+
+func (c *ProductServiceHandler) UpdateProductMedia(ctx context.Context, req *productsv1.UpdateProductMediaRequest) (*productsv1.UpdateProductMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "UpdateProductMedia.Handler")
+	defer span.End()
+
+	const op = "update_product_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	media := req.GetMedia()
+	if media.GetId() == 0 || media.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("media.id", "is not a valid ID")
+	}
+	if media.GetProductId() == 0 || media.GetProductId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("media.product_id", "is not a valid ID")
+	}
+	for _, path := range req.GetUpdateMask().GetPaths() {
+		if path != "alt_text" {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("update_mask", "names %q, which cannot be updated", path)
+		}
+	}
+
+	updated, err := c.store(ctx).UpdateProductMediaAltText(ctx, repository.UpdateProductMediaAltTextParams{
+		AltText:   media.GetAltText(),
+		ID:        int64(media.GetId()),
+		ProductID: int64(media.GetProductId()),
+	})
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "media %d of product %d not found", media.GetId(), media.GetProductId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update product media: %v", err)
+	}
+
+	return &productsv1.UpdateProductMediaResponse{
+		Media: c.mapMediaToProto(updated),
+	}, nil
+}
+
+func (c *ProductServiceHandler) ReorderProductMedia(ctx context.Context, req *productsv1.ReorderProductMediaRequest) (*productsv1.ReorderProductMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "ReorderProductMedia.Handler")
+	defer span.End()
+
+	const op = "reorder_product_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetProductId() == 0 || req.GetProductId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("product_id", "is not a valid ID")
+	}
+	productID := int64(req.GetProductId())
+
+	ids := make([]int64, 0, len(req.GetMediaIds()))
+	seen := make(map[uint64]bool, len(req.GetMediaIds()))
+	for i, id := range req.GetMediaIds() {
+		if id == 0 || id > math.MaxInt64 || seen[id] {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid(fmt.Sprintf("media_ids[%d]", i), "is not a valid ID or is repeated")
+		}
+		seen[id] = true
+		ids = append(ids, int64(id))
+	}
+
+	var media []repository.ProductMedia
+	err := c.inTx(ctx, func(q store.ProductStore) error {
+		if _, err := q.GetProductByID(ctx, productID); errors.Is(err, pgx.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "product %d not found", productID)
+		} else if err != nil {
+			return fmt.Errorf("get product: %w", err)
+		}
+
+		count, err := q.CountProductMedia(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("count product media: %w", err)
+		}
+		moved, err := q.ReorderProductMedia(ctx, repository.ReorderProductMediaParams{
+			MediaIds:  ids,
+			ProductID: productID,
+		})
+		if err != nil {
+			return fmt.Errorf("reorder product media: %w", err)
+		}
+		if moved != int64(len(ids)) || count != int64(len(ids)) {
+			return validation.Invalid("media_ids", "must list each of the %d images of product %d exactly once", count, productID)
+		}
+
+		media, err = q.ListProductMedia(ctx, []int64{productID})
+		if err != nil {
+			return fmt.Errorf("list product media: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	resp := &productsv1.ReorderProductMediaResponse{
+		Media: make([]*productsv1.ProductMedia, 0, len(media)),
+	}
+	for _, m := range media {
+		resp.Media = append(resp.Media, c.mapMediaToProto(m))
+	}
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) DeleteProductMedia(ctx context.Context, req *productsv1.DeleteProductMediaRequest) (*productsv1.DeleteProductMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "DeleteProductMedia.Handler")
+	defer span.End()
+
+	const op = "delete_product_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetProductId() == 0 || req.GetProductId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("product_id", "is not a valid ID")
+	}
+	if req.GetMediaId() == 0 || req.GetMediaId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("media_id", "is not a valid ID")
+	}
+
+	deleted, err := c.store(ctx).DeleteProductMedia(ctx, repository.DeleteProductMediaParams{
+		ID:        int64(req.GetMediaId()),
+		ProductID: int64(req.GetProductId()),
+	})
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "media %d of product %d not found", req.GetMediaId(), req.GetProductId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete product media: %v", err)
+	}
+
+	return &productsv1.DeleteProductMediaResponse{
+		Media: c.mapMediaToProto(deleted),
+	}, nil
+}
+
+// ListPurgedMedia lists the blobs the purge job left behind for the gateway
+// to delete.
+func (c *ProductServiceHandler) ListPurgedMedia(ctx context.Context, req *productsv1.ListPurgedMediaRequest) (*productsv1.ListPurgedMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "ListPurgedMedia.Handler")
+	defer span.End()
+
+	const op = "list_purged_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	pageSize := int32(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPurgedMediaPageSize
+	}
+
+	keys, err := c.store(ctx).ListPurgedMediaBlobs(ctx, pageSize)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to list purged media: %v", err)
+	}
+
+	return &productsv1.ListPurgedMediaResponse{StorageKeys: keys}, nil
+}
+
+// ConfirmPurgedMedia removes blobs the gateway has deleted from the list
+// ListPurgedMedia returns. Confirming a key twice is not an error.
+func (c *ProductServiceHandler) ConfirmPurgedMedia(ctx context.Context, req *productsv1.ConfirmPurgedMediaRequest) (*productsv1.ConfirmPurgedMediaResponse, error) {
+	ctx, span := c.startSpan(ctx, "ConfirmPurgedMedia.Handler")
+	defer span.End()
+
+	const op = "confirm_purged_media"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if len(req.GetStorageKeys()) == 0 {
+		return &productsv1.ConfirmPurgedMediaResponse{}, nil
+	}
+
+	n, err := c.store(ctx).DeletePurgedMediaBlobs(ctx, req.GetStorageKeys())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to confirm purged media: %v", err)
+	}
+
+	return &productsv1.ConfirmPurgedMediaResponse{Confirmed: uint64(n)}, nil
+}
+
+// loadMedia fills in the media of products with a single query, however
+// many products there are.
+func (c *ProductServiceHandler) loadMedia(ctx context.Context, q store.ProductStore, products ...*productsv1.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*productsv1.Product, len(products))
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		byID[int64(p.GetId())] = p
+		ids = append(ids, int64(p.GetId()))
+	}
+
+	media, err := q.ListProductMedia(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load product media: %w", err)
+	}
+	for _, m := range media {
+		if p, ok := byID[m.ProductID]; ok {
+			p.Media = append(p.Media, c.mapMediaToProto(m))
+		}
+	}
+	return nil
+}
+
+// loadRelations fills in everything a product response carries besides the
+// products row: its taxonomy and its media. It returns the errors of the
+// store wrapped, for callers outside a transaction to map with storeError.
+func (c *ProductServiceHandler) loadRelations(ctx context.Context, q store.ProductStore, products ...*productsv1.Product) error {
+	if err := loadTaxonomy(ctx, q, products...); err != nil {
+		return err
+	}
+	return c.loadMedia(ctx, q, products...)
+}
+
+func (c *ProductServiceHandler) mapMediaToProto(m repository.ProductMedia) *productsv1.ProductMedia {
+	return &productsv1.ProductMedia{
+		Id:             uint64(m.ID),
+		ProductId:      uint64(m.ProductID),
+		Url:            c.mediaBaseURL + m.StorageKey,
+		StorageKey:     m.StorageKey,
+		ContentType:    m.ContentType,
+		ChecksumSha256: m.ChecksumSha256,
+		SizeBytes:      uint64(m.SizeBytes),
+		Width:          uint32(m.Width),
+		Height:         uint32(m.Height),
+		AltText:        m.AltText,
+		Position:       uint32(m.Position),
+		CreatedAt:      timestamppb.New(m.CreatedAt),
+	}
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var testChecksum = strings.Repeat("ab", 32)
+
+// mediaRow is a product_media row of product 1.
+func mediaRow(id int64, position int32, key string) *fakeRow {
+	return &fakeRow{values: []any{
+		id, int64(1), position, key, "image/png", testChecksum, int64(2048), int32(640), int32(480), "Front", time.Now(),
+	}}
+}
+
+func validAddMediaRequest() *productsv1.AddProductMediaRequest {
+	return &productsv1.AddProductMediaRequest{
+		ProductId:      1,
+		StorageKey:     "products/1/" + testChecksum + ".png",
+		ContentType:    "image/png",
+		ChecksumSha256: testChecksum,
+		SizeBytes:      2048,
+		Width:          640,
+		Height:         480,
+		AltText:        "Front",
+	}
+}
+
+func TestValidateAddProductMedia(t *testing.T) {
+	params, err := validateAddProductMedia(validAddMediaRequest())
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxProductMedia), params.MaxMedia)
+
+	tests := []struct {
+		name   string
+		mutate func(*productsv1.AddProductMediaRequest)
+	}{
+		{"missing product", func(r *productsv1.AddProductMediaRequest) { r.ProductId = 0 }},
+		{"not an image", func(r *productsv1.AddProductMediaRequest) { r.ContentType = "text/plain" }},
+		{"upper-case checksum", func(r *productsv1.AddProductMediaRequest) { r.ChecksumSha256 = strings.ToUpper(testChecksum) }},
+		{"empty blob", func(r *productsv1.AddProductMediaRequest) { r.SizeBytes = 0 }},
+		{"no height", func(r *productsv1.AddProductMediaRequest) { r.Height = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validAddMediaRequest()
+			tt.mutate(req)
+
+			_, err := validateAddProductMedia(req)
+			assert.Error(t, err)
+		})
+	}
+
+	// The storage key and alt text are bounded by the request's field rules.
+	for name, mutate := range map[string]func(*productsv1.AddProductMediaRequest){
+		"missing key":   func(r *productsv1.AddProductMediaRequest) { r.StorageKey = "" },
+		"long alt text": func(r *productsv1.AddProductMediaRequest) { r.AltText = strings.Repeat("a", 513) },
+	} {
+		req := validAddMediaRequest()
+		mutate(req)
+		assert.Error(t, validation.Validate(req), name)
+	}
+}
+
+func TestMediaBaseURL(t *testing.T) {
+	assert.Equal(t, "/media/", mediaBaseURL(""))
+	assert.Equal(t, "https://cdn.example.com/media/", mediaBaseURL("https://cdn.example.com/media"))
+	assert.Equal(t, "https://cdn.example.com/media/", mediaBaseURL("https://cdn.example.com/media/"))
+}
+
+func TestProductServiceHandler_AddProductMedia(t *testing.T) {
+	key := "products/1/" + testChecksum + ".png"
+	db := &fakeDB{queue: []*fakeRow{mediaRow(1, 0, key)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.AddProductMedia(context.Background(), validAddMediaRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, "/media/"+key, resp.GetMedia().GetUrl())
+	assert.Equal(t, uint32(640), resp.GetMedia().GetWidth())
+	// id, product id, key, content type, checksum, size, width, height, alt text, max
+	require.Len(t, db.args, 10)
+	assert.Equal(t, int64(maxProductMedia), db.args[9])
+}
+
+func TestProductServiceHandler_AddProductMedia_Misses(t *testing.T) {
+	tests := []struct {
+		name    string
+		product *fakeRow
+		want    codes.Code
+	}{
+		{"product not found", &fakeRow{err: pgx.ErrNoRows}, codes.NotFound},
+		{"too many images", &fakeRow{product: repository.Product{ID: 1}}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.product}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.AddProductMedia(context.Background(), validAddMediaRequest())
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_AddProductMedia_Duplicate(t *testing.T) {
+	db := &fakeDB{err: &pgconn.PgError{Code: uniqueViolation, ConstraintName: "product_media_checksum_key"}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.AddProductMedia(context.Background(), validAddMediaRequest())
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
+
+func TestProductServiceHandler_UpdateProductMedia_UnknownField(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.UpdateProductMedia(context.Background(), &productsv1.UpdateProductMediaRequest{
+		Media:      &productsv1.ProductMedia{Id: 1, ProductId: 1},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"position"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_ReorderProductMedia(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []uint64
+		affected int64
+		want     codes.Code
+	}{
+		{"every image", []uint64{2, 1}, 2, codes.OK},
+		{"image missing", []uint64{2}, 1, codes.InvalidArgument},
+		{"repeated image", []uint64{2, 2}, 1, codes.InvalidArgument},
+		{"foreign image", []uint64{2, 9}, 1, codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{
+				queue:    []*fakeRow{{product: repository.Product{ID: 1}}, {values: []any{int64(2)}}},
+				affected: tt.affected,
+				lookups: map[string][]*fakeRow{
+					"ListProductMedia": {mediaRow(2, 0, "b.png"), mediaRow(1, 1, "a.png")},
+				},
+			}
+			handler := newTestHandler(t, db)
+
+			resp, err := handler.ReorderProductMedia(context.Background(), &productsv1.ReorderProductMediaRequest{
+				ProductId: 1,
+				MediaIds:  tt.ids,
+			})
+			require.Equal(t, tt.want, status.Code(err))
+			if tt.want == codes.OK {
+				require.Len(t, resp.GetMedia(), 2)
+				assert.Equal(t, uint64(2), resp.GetMedia()[0].GetId())
+				assert.True(t, db.committed)
+			}
+		})
+	}
+}
+
+func TestProductServiceHandler_GetProduct_LoadsMedia(t *testing.T) {
+	db := &fakeDB{
+		product: repository.Product{ID: 1, Name: "T-shirt", PriceMinor: 999, Currency: "USD"},
+		lookups: map[string][]*fakeRow{
+			"ListProductMedia": {mediaRow(3, 0, "front.png"), mediaRow(4, 1, "back.png")},
+		},
+	}
+	handler := newTestHandler(t, db)
+	handler.mediaBaseURL = mediaBaseURL("https://cdn.example.com/media")
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 1})
+	require.NoError(t, err)
+
+	media := resp.GetProduct().GetMedia()
+	require.Len(t, media, 2)
+	assert.Equal(t, "https://cdn.example.com/media/front.png", media[0].GetUrl())
+	assert.Equal(t, uint32(1), media[1].GetPosition())
+}
+
+func TestProductServiceHandler_PurgedMedia(t *testing.T) {
+	db := &fakeDB{rows: []*fakeRow{{values: []any{"products/1/a.png"}}}, affected: 1}
+	handler := newTestHandler(t, db)
+
+	listed, err := handler.ListPurgedMedia(context.Background(), &productsv1.ListPurgedMediaRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"products/1/a.png"}, listed.GetStorageKeys())
+	assert.Equal(t, []any{int32(defaultPurgedMediaPageSize)}, db.args)
+
+	confirmed, err := handler.ConfirmPurgedMedia(context.Background(), &productsv1.ConfirmPurgedMediaRequest{
+		StorageKeys: listed.GetStorageKeys(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), confirmed.GetConfirmed())
+	assert.Equal(t, []string{"DeletePurgedMediaBlobs"}, db.execs)
+}
@@ -2,14 +2,28 @@
 package controllers
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
 	metrics "github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/grpc-metrics"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/reservations"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/suggest"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/sonyflake"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -21,20 +35,33 @@ import (
 type Params struct {
 	fx.In
 
-	Logger      *zap.Logger
-	Queries     *repository.Queries
-	IDGenerator sonyflake.Generator
-	Tracer      trace.Tracer
-	AppMetrics  *metrics.AppMetrics
+	Logger       *zap.Logger
+	Store        store.ProductStore
+	IDGenerator  sonyflake.Generator
+	Tracer       trace.Tracer
+	AppMetrics   *metrics.AppMetrics
+	PageTokens   *pagination.Codec
+	Suggestions  *suggest.Index
+	Reservations *reservations.Policy
+	Config       *config.Config
 }
 
 type ProductServiceHandler struct {
 	productsv1.UnimplementedProductServiceServer
-	log     *zap.Logger
-	queries *repository.Queries
-	ids     sonyflake.Generator
-	tracer  trace.Tracer
-	metrics *metrics.AppMetrics
+	log          *zap.Logger
+	queries      store.ProductStore
+	ids          sonyflake.Generator
+	tracer       trace.Tracer
+	metrics      *metrics.AppMetrics
+	pageTokens   *pagination.Codec
+	suggestions  *suggest.Index
+	reservations *reservations.Policy
+	watch        watchOptions
+	rounding     money.RoundingMode
+	mediaBaseURL string
+	// backend is the configured database driver, the db label of the
+	// handler metrics.
+	backend string
 }
 
 var Module = fx.Module("controllers",
@@ -46,20 +73,60 @@ var Module = fx.Module("controllers",
 	),
 )
 
-const (
-	defaultPageSize  = uint32(10)
-	defaultPageToken = uint32(0)
-	dbBackend        = "postgres"
-)
+const defaultPageSize = uint32(10)
 
-func NewProductServiceHandler(p Params) *ProductServiceHandler {
-	return &ProductServiceHandler{
-		log:     p.Logger.Named("product_controller"),
-		queries: p.Queries,
-		ids:     p.IDGenerator,
-		tracer:  p.Tracer,
-		metrics: p.AppMetrics,
+// store returns the store a request runs its queries on: the transaction
+// the idempotency interceptor opened for it, if any, or c.queries.
+func (c *ProductServiceHandler) store(ctx context.Context) store.ProductStore {
+	return store.FromContext(ctx, c.queries)
+}
+
+// inTx runs fn with queries bound to a new transaction, or to a savepoint
+// of the request's, and commits it when fn succeeds. fn reports client
+// errors as status errors and returns the errors of its queries wrapped
+// with %w; inTx maps what remains with storeError.
+func (c *ProductServiceHandler) inTx(ctx context.Context, fn func(q store.ProductStore) error) error {
+	return storeError(c.store(ctx).InTx(ctx, fn))
+}
+
+// storeError turns an error of the store into a status error: a cancelled
+// or expired context becomes Canceled or DeadlineExceeded, status errors
+// are kept and anything else becomes Internal.
+func storeError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.Canceled):
+		return status.Errorf(codes.Canceled, "%v", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "%v", err)
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
 	}
+	return status.Errorf(codes.Internal, "failed to %v", err)
+}
+
+func NewProductServiceHandler(p Params) (*ProductServiceHandler, error) {
+	rounding, err := money.ParseRoundingMode(p.Config.ExchangeConfig.Rounding)
+	if err != nil {
+		return nil, fmt.Errorf("exchange.rounding: %w", err)
+	}
+
+	return &ProductServiceHandler{
+		log:          p.Logger.Named("product_controller"),
+		queries:      p.Store,
+		ids:          p.IDGenerator,
+		tracer:       p.Tracer,
+		metrics:      p.AppMetrics,
+		pageTokens:   p.PageTokens,
+		suggestions:  p.Suggestions,
+		reservations: p.Reservations,
+		watch:        newWatchOptions(p.Config.WatchConfig),
+		rounding:     rounding,
+		mediaBaseURL: mediaBaseURL(p.Config.MediaConfig.BaseURL),
+		backend:      cmp.Or(p.Config.DbConfig.Driver, database.DriverPostgres),
+	}, nil
 }
 
 func (c *ProductServiceHandler) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
@@ -75,52 +142,147 @@ func (c *ProductServiceHandler) CreateProduct(ctx context.Context, req *products
 
 	defer func() {
 		c.metrics.Duration.
-			WithLabelValues(op, dbBackend).
+			WithLabelValues(op, c.backend).
 			Observe(time.Since(timerStart).Seconds())
 	}()
 
-	if req.GetName() == "" {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
-		return nil, status.Errorf(codes.InvalidArgument, "name is required")
-	}
-	if req.GetCurrency() == "" {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
-		return nil, status.Errorf(codes.InvalidArgument, "currency is required")
+	priceMinor, err := priceToMinor(req.GetPrice())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
 	}
-	if req.GetPrice() <= 0 {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
-		return nil, status.Errorf(codes.InvalidArgument, "price must be greater than 0")
+	tax, err := parseTaxonomy(req)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
 	}
 
 	id, err := c.ids.NextID()
 	if err != nil {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
 		return nil, status.Errorf(codes.Internal, "failed to generate product ID: %v", err)
 	}
 
 	description := pgtype.Text{String: req.GetDescription(), Valid: req.GetDescription() != ""}
 
-	product, err := c.queries.CreateProduct(ctx, repository.CreateProductParams{
-		ID:            int64(id),
-		Name:          req.GetName(),
-		Description:   description,
-		Price:         req.GetPrice(),
-		Currency:      req.GetCurrency(),
-		StockQuantity: int32(req.GetStockQuantity()),
-	})
+	var product repository.CreateProductRow
+	create := func(q store.ProductStore) error {
+		product, err = q.CreateProduct(ctx, repository.CreateProductParams{
+			ID:            int64(id),
+			Name:          req.GetName(),
+			Description:   description,
+			PriceMinor:    priceMinor,
+			Currency:      req.GetPrice().GetCurrencyCode(),
+			StockQuantity: int32(req.GetStockQuantity()),
+			Actor:         actorFromContext(ctx),
+			CategoryIds:   tax.categoryIDs,
+			Tags:          tax.tags,
+		})
+		if err != nil {
+			return fmt.Errorf("create product: %w", err)
+		}
+		return setTaxonomy(ctx, q, product.ID, tax)
+	}
+	// The product and its links are only worth a transaction when there
+	// are links to write.
+	if tax.empty() {
+		err = storeError(create(c.store(ctx)))
+	} else {
+		err = c.inTx(ctx, create)
+	}
 	if err != nil {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
-		return nil, status.Errorf(codes.Internal, "failed to create product: %v", err)
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
 	}
 
+	c.suggestions.Put(product.ID, product.Name)
+
 	// Optional workflow stage update
 	c.metrics.Stage.Set(2)
 
+	created := mapDBToProto(repository.Product(product))
+	created.CategoryIds = toUint64s(tax.categoryIDs)
+	created.Tags = tax.tags
+
 	return &productsv1.CreateProductResponse{
-		Product: mapDBToProto(product),
+		Product: created,
 	}, nil
 }
 
+// priceToMinor validates a product price against the ISO 4217 rules of its
+// currency and converts it to minor units. That the price is set and
+// positive is left to the request's field rules.
+func priceToMinor(price *productsv1.Money) (int64, error) {
+	minor, err := money.ToMinor(price)
+	if err != nil {
+		return 0, validation.Invalid("price", "%v", err)
+	}
+	return minor, nil
+}
+
+func (c *ProductServiceHandler) GetProduct(ctx context.Context, req *productsv1.GetProductRequest) (*productsv1.GetProductResponse, error) {
+	ctx, span := c.startSpan(ctx, "GetProduct.Handler")
+	defer span.End()
+
+	const op = "get_product"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	converter, err := c.newPriceConverter(req.GetDisplayCurrency())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	if req.GetAsOf() != nil {
+		product, err := c.productAsOf(ctx, req.GetId(), req.GetAsOf())
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, err
+		}
+		resp := &productsv1.GetProductResponse{
+			Product: product.toProto(),
+		}
+		if err := converter.apply(ctx, resp.Product); err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	product, err := c.store(ctx).GetProductByID(ctx, req.GetId())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get product: %v", err)
+	}
+
+	resp := &productsv1.GetProductResponse{
+		Product: mapDBToProto(product),
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Product); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+	if resp.Product.Variants, err = loadVariants(ctx, c.store(ctx), product.ID); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+	if err := converter.apply(ctx, resp.Product); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (c *ProductServiceHandler) ListProducts(ctx context.Context, req *productsv1.ListProductsRequest) (*productsv1.ListProductsResponse, error) {
 	ctx, span := c.startSpan(ctx, "ListProducts.Handler")
 	defer span.End()
@@ -130,39 +292,196 @@ func (c *ProductServiceHandler) ListProducts(ctx context.Context, req *productsv
 
 	defer func() {
 		c.metrics.Duration.
-			WithLabelValues(op, dbBackend).
+			WithLabelValues(op, c.backend).
 			Observe(time.Since(timerStart).Seconds())
 	}()
 
 	pageSize := req.GetPageSize()
-	pageToken := req.GetPageToken()
-
 	if pageSize == 0 {
 		pageSize = defaultPageSize
 	}
-	if pageToken == 0 {
-		pageToken = defaultPageToken
+
+	opts, err := parseListOptions(req)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
 	}
 
-	products, err := c.queries.ListProducts(ctx, repository.ListProductsParams{
-		Limit:  int32(pageSize),
-		Offset: int32(pageToken),
-	})
+	converter, err := c.newPriceConverter(req.GetDisplayCurrency())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := c.pageTokens.Decode(token, opts.fingerprint())
+		if err == nil {
+			err = opts.resumeFrom(cursor)
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("page_token", "%v", err)
+		}
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	opts.params.Limit = int32(pageSize) + 1
+	products, err := c.store(ctx).ListProducts(ctx, opts.params)
 	if err != nil {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
 		return nil, status.Errorf(codes.Internal, "failed to list products: %v", err)
 	}
 
-	resp := &productsv1.ListProductsResponse{
-		Products: make([]*productsv1.Product, 0, len(products)),
+	resp := &productsv1.ListProductsResponse{}
+	if len(products) > int(pageSize) {
+		products = products[:pageSize]
+		resp.NextPageToken, err = c.pageTokens.Encode(opts.cursorFor(products[len(products)-1]))
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to build page token: %v", err)
+		}
 	}
+
+	resp.Products = make([]*productsv1.Product, 0, len(products))
 	for _, p := range products {
-		resp.Products = append(resp.Products, mapDBToProto(p))
+		product := mapDBToProto(p)
+		if err := converter.apply(ctx, product); err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, err
+		}
+		resp.Products = append(resp.Products, product)
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Products...); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
 	}
 
 	return resp, nil
 }
 
+// updatableFields lists the Product field mask paths an empty mask expands
+// to. UpdateProduct also accepts "category_ids" and "tags", which replace
+// whole lists and so are only touched when named.
+var updatableFields = []string{"name", "description", "price", "stock_quantity"}
+
+func (c *ProductServiceHandler) UpdateProduct(ctx context.Context, req *productsv1.UpdateProductRequest) (*productsv1.UpdateProductResponse, error) {
+	ctx, span := c.startSpan(ctx, "UpdateProduct.Handler")
+	defer span.End()
+
+	const op = "update_product"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	product := req.GetProduct()
+	expectedVersion, err := parseETag(product.GetEtag())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = updatableFields
+	}
+
+	params := repository.UpdateProductParams{
+		ID:              int64(product.GetId()),
+		ExpectedVersion: expectedVersion,
+		Actor:           actorFromContext(ctx),
+	}
+	// Set when the mask replaces the product's categories or tags.
+	var categoryIDs *[]int64
+	var tags *[]string
+	for _, path := range paths {
+		switch path {
+		case "name":
+			if product.GetName() == "" {
+				c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+				return nil, validation.Invalid("product.name", "is required")
+			}
+			params.SetName, params.Name = true, product.GetName()
+		case "description":
+			params.SetDescription = true
+			params.Description = pgtype.Text{String: product.GetDescription(), Valid: product.GetDescription() != ""}
+		case "price":
+			if product.GetPrice() == nil {
+				c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+				return nil, validation.Invalid("product.price", "is required")
+			}
+			minor, err := priceToMinor(product.GetPrice())
+			if err != nil {
+				c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+				return nil, validation.Nested("product", err)
+			}
+			params.SetPrice, params.PriceMinor = true, minor
+			params.Currency = product.GetPrice().GetCurrencyCode()
+		case "stock_quantity":
+			params.SetStockQuantity, params.StockQuantity = true, int32(product.GetStockQuantity())
+		case "category_ids":
+			ids, err := parseCategoryIDs("product.category_ids", product.GetCategoryIds())
+			if err != nil {
+				c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+				return nil, err
+			}
+			categoryIDs = &ids
+			params.CategoryIds = ids
+		case "tags":
+			parsed := parseTags(product.GetTags())
+			tags = &parsed
+			params.Tags = parsed
+		default:
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("update_mask", "names %q, which cannot be updated", path)
+		}
+	}
+
+	var updated *productsv1.Product
+	update := func(q store.ProductStore) error {
+		row, err := q.UpdateProduct(ctx, params)
+		if errors.Is(err, pgx.ErrNoRows) {
+			if expectedVersion.Valid {
+				return conditionalMissError(ctx, q, params.ID)
+			}
+			return status.Errorf(codes.NotFound, "product %d not found", product.GetId())
+		}
+		if err != nil {
+			return fmt.Errorf("update product: %w", err)
+		}
+		if categoryIDs != nil {
+			if err := setCategories(ctx, q, row.ID, *categoryIDs); err != nil {
+				return err
+			}
+		}
+		if tags != nil {
+			if err := setTags(ctx, q, row.ID, *tags); err != nil {
+				return err
+			}
+		}
+		updated = mapDBToProto(repository.Product(row))
+		return c.loadRelations(ctx, q, updated)
+	}
+	if categoryIDs == nil && tags == nil {
+		err = storeError(update(c.store(ctx)))
+	} else {
+		err = c.inTx(ctx, update)
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	c.suggestions.Put(int64(updated.GetId()), updated.GetName())
+
+	return &productsv1.UpdateProductResponse{
+		Product: updated,
+	}, nil
+}
+
 func (c *ProductServiceHandler) DeleteProduct(ctx context.Context, req *productsv1.DeleteProductRequest) (*productsv1.DeleteProductResponse, error) {
 	ctx, span := c.startSpan(ctx, "DeleteProduct.Handler")
 	defer span.End()
@@ -172,24 +491,109 @@ func (c *ProductServiceHandler) DeleteProduct(ctx context.Context, req *products
 
 	defer func() {
 		c.metrics.Duration.
-			WithLabelValues(op, dbBackend).
+			WithLabelValues(op, c.backend).
 			Observe(time.Since(timerStart).Seconds())
 	}()
 
-	if req.GetId() == 0 {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
-		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	expectedVersion, err := parseETag(req.GetEtag())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
 	}
 
-	err := c.queries.DeleteProduct(ctx, req.GetId())
+	deleted, err := c.store(ctx).DeleteProduct(ctx, repository.DeleteProductParams{
+		ID:              req.GetId(),
+		ExpectedVersion: expectedVersion,
+		Actor:           actorFromContext(ctx),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if expectedVersion.Valid {
+			return nil, storeError(conditionalMissError(ctx, c.store(ctx), req.GetId()))
+		}
+		return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetId())
+	}
 	if err != nil {
-		c.metrics.Errors.WithLabelValues(op, dbBackend).Inc()
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
 		return nil, status.Errorf(codes.Internal, "failed to delete product: %v", err)
 	}
 
-	return &productsv1.DeleteProductResponse{
+	c.suggestions.Remove(req.GetId())
+
+	resp := &productsv1.DeleteProductResponse{
 		Success: true,
-	}, nil
+		Product: mapDBToProto(repository.Product(deleted)),
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Product); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) UndeleteProduct(ctx context.Context, req *productsv1.UndeleteProductRequest) (*productsv1.UndeleteProductResponse, error) {
+	ctx, span := c.startSpan(ctx, "UndeleteProduct.Handler")
+	defer span.End()
+
+	const op = "undelete_product"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	expectedVersion, err := parseETag(req.GetEtag())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	restored, err := c.store(ctx).UndeleteProduct(ctx, repository.UndeleteProductParams{
+		ID:              req.GetId(),
+		ExpectedVersion: expectedVersion,
+		Actor:           actorFromContext(ctx),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.undeleteMissError(ctx, req.GetId())
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to undelete product: %v", err)
+	}
+
+	c.suggestions.Put(restored.ID, restored.Name)
+
+	resp := &productsv1.UndeleteProductResponse{
+		Product: mapDBToProto(repository.Product(restored)),
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Product); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+
+	return resp, nil
+}
+
+// undeleteMissError explains why UndeleteProduct touched no rows: the
+// product does not exist, is not deleted, or its etag no longer matches.
+func (c *ProductServiceHandler) undeleteMissError(ctx context.Context, id int64) error {
+	current, err := c.store(ctx).GetProductByIDWithDeleted(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product %d not found", id)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get product: %v", err)
+	}
+	if !current.DeletedAt.Valid {
+		return status.Errorf(codes.FailedPrecondition, "product %d is not deleted", id)
+	}
+	return apierror.Errorf(codes.Aborted, apierror.ReasonEtagMismatch,
+		map[string]string{"product_id": strconv.FormatInt(id, 10), "current_etag": formatETag(current.Version)},
+		"etag mismatch for product %d: current etag is %q", id, formatETag(current.Version))
 }
 
 func mapDBToProto(p repository.Product) *productsv1.Product {
@@ -197,10 +601,18 @@ func mapDBToProto(p repository.Product) *productsv1.Product {
 		Id:            uint64(p.ID),
 		Name:          p.Name,
 		Description:   p.Description.String,
-		Price:         p.Price,
-		Currency:      p.Currency,
+		Price:         money.FromMinor(p.Currency, p.PriceMinor),
 		StockQuantity: uint32(p.StockQuantity),
 		CreatedAt:     timestamppb.New(p.CreatedAt),
 		UpdatedAt:     timestamppb.New(p.UpdatedAt),
+		Etag:          formatETag(p.Version),
+		DeletedAt:     deletedAtToProto(p.DeletedAt),
+	}
+}
+
+func deletedAtToProto(t pgtype.Timestamptz) *timestamppb.Timestamp {
+	if !t.Valid {
+		return nil
 	}
+	return timestamppb.New(t.Time)
 }
@@ -2,27 +2,655 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	metrics "github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/grpc-metrics"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/reservations"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/suggest"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
 	"go.opentelemetry.io/otel/trace/noop"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
-func TestProductServiceHandler_GetProduct_InvalidID(t *testing.T) {
-	handler := &ProductServiceHandler{
-		log:    zap.NewNop(),
-		tracer: noop.NewTracerProvider().Tracer("test"),
+// fakeDB is a minimal repository.DBTX that serves canned rows, so handlers
+// can be exercised without a live database. Queued rows are returned first,
+// then every further QueryRow falls back to product/err. Query serves rows
+// once when set and otherwise emulates ListProducts over list. Taxonomy,
+// variant and media lookups and purge revisions are answered from lookups,
+// keyed by query name, without touching rows, sql or args.
+type fakeDB struct {
+	product repository.Product
+	err     error
+	queue   []*fakeRow
+	list    []repository.Product
+	rows    []*fakeRow
+	lookups map[string][]*fakeRow
+	sql     string
+	args    []interface{}
+	// clock is the time CurrentTime reports.
+	clock time.Time
+	// affected is the row count Exec reports.
+	affected int64
+	execs    []string
+	// execErrs holds the errors Exec reports, in turn, for a named
+	// statement before it falls back to err.
+	execErrs map[string][]error
+
+	batched    int
+	committed  bool
+	rolledBack bool
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	name := queryName(sql)
+	f.execs = append(f.execs, name)
+	if errs := f.execErrs[name]; len(errs) > 0 {
+		f.execErrs[name] = errs[1:]
+		return pgconn.CommandTag{}, errs[0]
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", f.affected)), f.err
+}
+
+// queryName returns the sqlc name of a generated statement.
+func queryName(sql string) string {
+	if name, ok := strings.CutPrefix(sql, "-- name: "); ok {
+		return strings.Fields(name)[0]
+	}
+	return ""
+}
+
+func (f *fakeDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	switch name := queryName(sql); name {
+	case "ListCategoryLinks", "ListProductTags", "ListVariants", "ListProductMedia", "ListProductPurges":
+		return &fakeRows{rows: f.lookups[name]}, nil
+	}
+
+	f.sql, f.args = sql, args
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	if f.rows != nil {
+		rows := f.rows
+		f.rows = []*fakeRow{}
+		return &fakeRows{rows: rows}, nil
+	}
+
+	// Emulate the id keyset of an unfiltered ListProducts: the limit is
+	// always the last argument and the after id precedes it.
+	limit := int(args[len(args)-1].(int32))
+	var afterID int64
+	if strings.Contains(sql, "id > $") {
+		afterID = args[len(args)-2].(int64)
+	}
+
+	rows := &fakeRows{}
+	for _, p := range f.list {
+		if p.ID > afterID && len(rows.rows) < limit {
+			rows.rows = append(rows.rows, &fakeRow{product: p})
+		}
+	}
+	return rows, nil
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	if queryName(sql) == "CurrentTime" {
+		return &fakeRow{values: []any{f.clock}}
+	}
+	f.args = args
+	if len(f.queue) > 0 {
+		row := f.queue[0]
+		f.queue = f.queue[1:]
+		return row
+	}
+	return &fakeRow{product: f.product, err: f.err}
+}
+
+// SendBatch answers each queued query from QueryRow.
+func (f *fakeDB) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	f.batched = b.Len()
+	return &fakeBatchResults{db: f}
+}
+
+func (f *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{db: f}, nil
+}
+
+type fakeBatchResults struct {
+	pgx.BatchResults
+	db *fakeDB
+}
+
+func (b *fakeBatchResults) QueryRow() pgx.Row { return b.db.QueryRow(context.Background(), "") }
+func (b *fakeBatchResults) Close() error      { return nil }
+
+// fakeTx runs statements against its fakeDB and records how it ended.
+type fakeTx struct {
+	pgx.Tx
+	db *fakeDB
+}
+
+func (tx *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx *fakeTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx *fakeTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx *fakeTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return tx.db.SendBatch(ctx, b)
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.db.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	if !tx.db.committed {
+		tx.db.rolledBack = true
 	}
+	return nil
+}
+
+// fakeRow scans either product or, when set, the raw column values.
+type fakeRow struct {
+	product repository.Product
+	values  []any
+	err     error
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.values != nil {
+		for i, v := range r.values {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+		}
+		return nil
+	}
+	p := r.product
+	*dest[0].(*int64) = p.ID
+	*dest[1].(*string) = p.Name
+	*dest[2].(*pgtype.Text) = p.Description
+	*dest[3].(*int64) = p.PriceMinor
+	*dest[4].(*string) = p.Currency
+	*dest[5].(*int32) = p.StockQuantity
+	*dest[6].(*time.Time) = p.CreatedAt
+	*dest[7].(*time.Time) = p.UpdatedAt
+	*dest[8].(*int64) = p.Version
+	if len(dest) > 9 {
+		if deletedAt, ok := dest[9].(*pgtype.Timestamptz); ok {
+			*deletedAt = p.DeletedAt
+		}
+	}
+	return nil
+}
+
+// fakeRows iterates over canned rows; only the methods sqlc uses are
+// meaningful.
+type fakeRows struct {
+	pgx.Rows
+	rows []*fakeRow
+	cur  *fakeRow
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	r.cur, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error { return r.cur.Scan(dest...) }
+func (r *fakeRows) Err() error             { return nil }
+func (r *fakeRows) Close()                 {}
+
+// fakeIDs hands out sequential ids.
+type fakeIDs struct{ next uint64 }
+
+func (g *fakeIDs) NextID() (uint64, error) {
+	g.next++
+	return g.next, nil
+}
+
+// usd returns an amount of US dollars given in cents.
+func usd(cents int64) *productsv1.Money {
+	return money.FromMinor("USD", cents)
+}
+
+func newTestHandler(t *testing.T, db repository.DBTX) *ProductServiceHandler {
+	t.Helper()
+
+	beginner, _ := db.(store.TxBeginner)
+	return &ProductServiceHandler{
+		log:          zap.NewNop(),
+		queries:      store.NewSQL(beginner, repository.New(db)),
+		tracer:       noop.NewTracerProvider().Tracer("test"),
+		metrics:      metrics.NewAppMetrics(metrics.AppMetricsParams{Registry: prometheus.NewRegistry()}).Metrics,
+		pageTokens:   pagination.NewCodec([]byte("test-secret")),
+		suggestions:  &suggest.Index{Trie: suggest.NewTrie(), MaxResults: 5},
+		reservations: &reservations.Policy{DefaultTTL: 15 * time.Minute, MaxTTL: time.Hour},
+		ids:          &fakeIDs{},
+		watch:        watchOptions{pollInterval: time.Millisecond, settle: time.Second},
+		mediaBaseURL: defaultMediaBaseURL,
+		backend:      database.DriverPostgres,
+	}
+}
+
+// withRetries makes handler run its transactions on a Transactor that
+// retries serialization failures after backoff.
+func withRetries(handler *ProductServiceHandler, db *fakeDB, backoff time.Duration) {
+	txs := database.NewTransactor(db, repository.New(db), config.TxRetryConfig{
+		MaxRetries: 3,
+		MinBackoff: backoff,
+		MaxBackoff: backoff,
+	}, prometheus.NewRegistry())
+	handler.queries = store.NewSQL(db, repository.New(db)).WithTransactor(txs)
+}
+
+func TestNewProductServiceHandler_LabelsMetricsWithDriver(t *testing.T) {
+	for driver, want := range map[string]string{
+		"":                      database.DriverPostgres,
+		database.DriverPostgres: database.DriverPostgres,
+		database.DriverSQLite:   database.DriverSQLite,
+	} {
+		handler, err := NewProductServiceHandler(Params{
+			Logger: zap.NewNop(),
+			Config: &config.Config{DbConfig: config.DbConfig{Driver: driver}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, want, handler.backend, "driver %q", driver)
+	}
+}
+
+// violatedFields lists the fields of the BadRequest detail of st.
+func violatedFields(st *status.Status) []string {
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+	return fields
+}
+
+// validated checks req against its field rules, as the server's validation
+// interceptor does, before handing it to call.
+func validated[Req proto.Message, Resp any](ctx context.Context, req Req, call func(context.Context, Req) (Resp, error)) (Resp, error) {
+	if err := validation.Validate(req); err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return call(ctx, req)
+}
+
+func TestProductServiceHandler_GetProduct_InvalidID(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
 
 	req := &productsv1.GetProductRequest{Id: 0}
-	_, err := handler.GetProduct(context.Background(), req)
-	
+	_, err := validated(context.Background(), req, handler.GetProduct)
+
 	assert.Error(t, err)
 	st, ok := status.FromError(err)
 	assert.True(t, ok)
 	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Zero(t, testutil.ToFloat64(handler.metrics.Errors.WithLabelValues("get_product", handler.backend)), "rejected before the handler")
+}
+
+func TestProductServiceHandler_GetProduct_Found(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	handler := newTestHandler(t, &fakeDB{product: repository.Product{
+		ID:            42,
+		Name:          "Widget",
+		Description:   pgtype.Text{String: "A widget", Valid: true},
+		PriceMinor:    999,
+		Currency:      "USD",
+		StockQuantity: 7,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}})
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42})
+	require.NoError(t, err)
+
+	p := resp.GetProduct()
+	assert.Equal(t, uint64(42), p.GetId())
+	assert.Equal(t, "Widget", p.GetName())
+	assert.Equal(t, "A widget", p.GetDescription())
+	assert.True(t, proto.Equal(usd(999), p.GetPrice()), "price = %v", p.GetPrice())
+	assert.Equal(t, uint32(7), p.GetStockQuantity())
+	assert.Equal(t, now, p.GetCreatedAt().AsTime())
+	assert.Equal(t, 0.0, testutil.ToFloat64(handler.metrics.Errors.WithLabelValues("get_product", handler.backend)))
+}
+
+func TestProductServiceHandler_GetProduct_NotFound(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Equal(t, 1.0, testutil.ToFloat64(handler.metrics.Errors.WithLabelValues("get_product", handler.backend)))
+}
+
+func TestProductServiceHandler_GetProduct_DatabaseError(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: errors.New("connection reset")})
+
+	_, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 42})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Internal, st.Code())
+}
+
+func TestProductServiceHandler_UpdateProduct_MaskedFieldsOnly(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 42, Name: "Widget", PriceMinor: 1250, Currency: "USD"}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Name: "ignored", Price: usd(1250)},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(usd(1250), resp.GetProduct().GetPrice()))
+
+	// Arguments follow repository.UpdateProductParams field order.
+	require.Len(t, db.args, 14)
+	assert.Equal(t, int64(42), db.args[0])
+	assert.Equal(t, false, db.args[1], "name must not be updated")
+	assert.Equal(t, true, db.args[5], "price must be updated")
+	assert.Equal(t, int64(1250), db.args[6])
+	assert.Equal(t, "USD", db.args[7])
+	assert.Nil(t, db.args[12], "categories are kept")
+	assert.Nil(t, db.args[13], "tags are kept")
+}
+
+func TestProductServiceHandler_UpdateProduct_InvalidMask(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"created_at"}},
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, []string{"update_mask"}, violatedFields(st))
+}
+
+func TestProductServiceHandler_UpdateProduct_NotFound(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Name: "Widget"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.NotFound, st.Code())
+}
+
+func TestProductServiceHandler_UpdateProduct_StaleETag(t *testing.T) {
+	db := &fakeDB{
+		queue:   []*fakeRow{{err: pgx.ErrNoRows}},
+		product: repository.Product{ID: 42, Name: "Widget", Version: 5},
+	}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Name: "Gadget", Etag: "4"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.Aborted, st.Code())
+	assert.Contains(t, st.Message(), `"5"`)
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, apierror.ReasonEtagMismatch, info.GetReason())
+	assert.Equal(t, "5", info.GetMetadata()["current_etag"])
+}
+
+func TestProductServiceHandler_UpdateProduct_MalformedETag(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Name: "Gadget", Etag: "abc"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestProductServiceHandler_ListProducts_Paginates(t *testing.T) {
+	db := &fakeDB{}
+	for id := int64(1); id <= 5; id++ {
+		db.list = append(db.list, repository.Product{ID: id, Name: "p"})
+	}
+	handler := newTestHandler(t, db)
+
+	var seen []uint64
+	token := ""
+	for page := 0; page < 5; page++ {
+		resp, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{
+			PageSize:  2,
+			PageToken: token,
+		})
+		require.NoError(t, err)
+		for _, p := range resp.GetProducts() {
+			seen = append(seen, p.GetId())
+		}
+		token = resp.GetNextPageToken()
+		if token == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, seen)
+}
+
+func TestProductServiceHandler_ListProducts_InvalidToken(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{PageToken: "bogus"})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestProductServiceHandler_ListProducts_FilterAndOrder(t *testing.T) {
+	db := &fakeDB{}
+	handler := newTestHandler(t, db)
+	_, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{
+		Filter:  &productsv1.ProductFilter{Currency: "USD", MinPrice: usd(1000), InStockOnly: true},
+		OrderBy: "price desc",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, db.sql, "currency = $1")
+	assert.Contains(t, db.sql, "price_minor >= $2")
+	assert.Contains(t, db.sql, "stock_quantity > 0")
+	assert.Contains(t, db.sql, "ORDER BY price_minor DESC, id DESC")
+	assert.Equal(t, []interface{}{"USD", int64(1000), int32(defaultPageSize + 1)}, db.args)
+}
+
+func TestProductServiceHandler_ListProducts_InvalidOptions(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+	tests := []struct {
+		name  string
+		req   *productsv1.ListProductsRequest
+		field string
+	}{
+		{"unknown field", &productsv1.ListProductsRequest{OrderBy: "stock_quantity"}, "order_by"},
+		{"bad direction", &productsv1.ListProductsRequest{OrderBy: "price sideways"}, "order_by"},
+		{"price order without currency", &productsv1.ListProductsRequest{OrderBy: "price desc"}, "order_by"},
+		{"inverted range", &productsv1.ListProductsRequest{Filter: &productsv1.ProductFilter{MinPrice: usd(2000), MaxPrice: usd(1000)}}, "filter.min_price"},
+		{"mixed currencies", &productsv1.ListProductsRequest{Filter: &productsv1.ProductFilter{Currency: "EUR", MinPrice: usd(1000)}}, "filter.min_price"},
+		{"sub-cent bound", &productsv1.ListProductsRequest{Filter: &productsv1.ProductFilter{MinPrice: &productsv1.Money{CurrencyCode: "USD", Nanos: 1}}}, "filter.min_price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.ListProducts(context.Background(), tt.req)
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.InvalidArgument, st.Code())
+			assert.Equal(t, []string{tt.field}, violatedFields(st))
+		})
+	}
+}
+
+func TestProductServiceHandler_ListProducts_TokenBoundToQuery(t *testing.T) {
+	db := &fakeDB{}
+	for id := int64(1); id <= 3; id++ {
+		db.list = append(db.list, repository.Product{ID: id, Name: "p"})
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{PageSize: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.GetNextPageToken())
+
+	_, err = handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{
+		PageSize:  1,
+		PageToken: resp.GetNextPageToken(),
+		OrderBy:   "name",
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestProductServiceHandler_UpdateProduct_RefreshesSuggestions(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 42, Name: "Gadget", PriceMinor: 100, Currency: "USD"}}
+	handler := newTestHandler(t, db)
+	handler.suggestions.Put(42, "Widget")
+
+	_, err := handler.UpdateProduct(context.Background(), &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: 42, Name: "Gadget"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, handler.suggestions.Suggest("wid", 10))
+	assert.Len(t, handler.suggestions.Suggest("gad", 10), 1)
+}
+
+func TestProductServiceHandler_DeleteProduct_SoftDeletes(t *testing.T) {
+	deletedAt := time.Now()
+	db := &fakeDB{product: repository.Product{
+		ID:        42,
+		Name:      "Widget",
+		Version:   3,
+		DeletedAt: pgtype.Timestamptz{Time: deletedAt, Valid: true},
+	}}
+	handler := newTestHandler(t, db)
+	handler.suggestions.Put(42, "Widget")
+
+	resp, err := handler.DeleteProduct(context.Background(), &productsv1.DeleteProductRequest{Id: 42})
+	require.NoError(t, err)
+
+	assert.True(t, resp.GetSuccess())
+	assert.Equal(t, deletedAt.UnixNano(), resp.GetProduct().GetDeletedAt().AsTime().UnixNano())
+	assert.Empty(t, handler.suggestions.Suggest("wid", 10))
+}
+
+func TestProductServiceHandler_DeleteProduct_UnknownID(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.DeleteProduct(context.Background(), &productsv1.DeleteProductRequest{Id: 42})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestProductServiceHandler_UndeleteProduct(t *testing.T) {
+	db := &fakeDB{product: repository.Product{ID: 42, Name: "Widget", Version: 4}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.UndeleteProduct(context.Background(), &productsv1.UndeleteProductRequest{Id: 42})
+	require.NoError(t, err)
+
+	assert.Nil(t, resp.GetProduct().GetDeletedAt())
+	assert.Len(t, handler.suggestions.Suggest("wid", 10), 1)
+}
+
+func TestProductServiceHandler_UndeleteProduct_Misses(t *testing.T) {
+	tests := map[string]struct {
+		current *fakeRow
+		code    codes.Code
+	}{
+		"unknown":     {current: &fakeRow{err: pgx.ErrNoRows}, code: codes.NotFound},
+		"not deleted": {current: &fakeRow{product: repository.Product{ID: 42, Version: 2}}, code: codes.FailedPrecondition},
+		"stale etag": {
+			current: &fakeRow{product: repository.Product{ID: 42, Version: 2, DeletedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}},
+			code:    codes.Aborted,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.current}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.UndeleteProduct(context.Background(), &productsv1.UndeleteProductRequest{Id: 42, Etag: formatETag(1)})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_ListProducts_ShowDeleted(t *testing.T) {
+	db := &fakeDB{}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, db.sql, "deleted_at IS NULL")
+
+	_, err = handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{ShowDeleted: true})
+	require.NoError(t, err)
+	assert.NotContains(t, db.sql, "deleted_at IS NULL")
 }
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Reservation states as stored in stock_reservations.status.
+const (
+	reservationPending   = "pending"
+	reservationCommitted = "committed"
+	reservationReleased  = "released"
+	reservationExpired   = "expired"
+)
+
+var reservationStatuses = map[string]productsv1.ReservationStatus{
+	reservationPending:   productsv1.ReservationStatus_RESERVATION_STATUS_PENDING,
+	reservationCommitted: productsv1.ReservationStatus_RESERVATION_STATUS_COMMITTED,
+	reservationReleased:  productsv1.ReservationStatus_RESERVATION_STATUS_RELEASED,
+	reservationExpired:   productsv1.ReservationStatus_RESERVATION_STATUS_EXPIRED,
+}
+
+func (c *ProductServiceHandler) ReserveStock(ctx context.Context, req *productsv1.ReserveStockRequest) (*productsv1.ReserveStockResponse, error) {
+	ctx, span := c.startSpan(ctx, "ReserveStock.Handler")
+	defer span.End()
+
+	const op = "reserve_stock"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetVariantId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("variant_id", "is not a valid ID")
+	}
+
+	var requested time.Duration
+	if req.GetTtl() != nil {
+		if err := req.GetTtl().CheckValid(); err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("ttl", "%v", err)
+		}
+		requested = req.GetTtl().AsDuration()
+	}
+	ttl, err := c.reservations.TTL(requested)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("ttl", "%v", err)
+	}
+
+	id, err := c.ids.NextID()
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to generate reservation ID: %v", err)
+	}
+
+	var reservation repository.StockReservation
+	if v := req.GetVariantId(); v != 0 {
+		reservation, err = c.store(ctx).ReserveVariantStock(ctx, repository.ReserveVariantStockParams{
+			ID:        int64(id),
+			Quantity:  int32(req.GetQuantity()),
+			Ttl:       pgtype.Interval{Microseconds: ttl.Microseconds(), Valid: true},
+			VariantID: int64(v),
+			ProductID: req.GetProductId(),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, c.variantStockMissError(ctx, req.GetProductId(), int64(v), "reserve stock",
+				fmt.Sprintf("requested %d", req.GetQuantity()))
+		}
+	} else {
+		reservation, err = c.store(ctx).ReserveStock(ctx, repository.ReserveStockParams{
+			ID:        int64(id),
+			Quantity:  int32(req.GetQuantity()),
+			Ttl:       pgtype.Interval{Microseconds: ttl.Microseconds(), Valid: true},
+			ProductID: req.GetProductId(),
+			Actor:     actorFromContext(ctx),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, c.reserveMissError(ctx, req.GetProductId(), req.GetQuantity())
+		}
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to reserve stock: %v", err)
+	}
+
+	c.log.Debug("reserved stock",
+		zap.Int64("reservation_id", reservation.ID),
+		zap.Int64("product_id", reservation.ProductID),
+		zap.Int64("variant_id", reservation.VariantID.Int64),
+		zap.Int32("quantity", reservation.Quantity),
+	)
+
+	return &productsv1.ReserveStockResponse{Reservation: mapReservationToProto(reservation)}, nil
+}
+
+// reserveMissError explains why ReserveStock matched no product row: either
+// the product does not exist or it has too little stock.
+func (c *ProductServiceHandler) reserveMissError(ctx context.Context, productID int64, quantity uint32) error {
+	product, err := c.store(ctx).GetProductByID(ctx, productID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product with ID %d not found", productID)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to reserve stock: %v", err)
+	}
+	return apierror.Errorf(codes.FailedPrecondition, apierror.ReasonInsufficientStock,
+		map[string]string{"product_id": strconv.FormatInt(productID, 10), "available": strconv.FormatInt(int64(product.StockQuantity), 10)},
+		"insufficient stock for product %d: requested %d, available %d", productID, quantity, product.StockQuantity)
+}
+
+func (c *ProductServiceHandler) CommitReservation(ctx context.Context, req *productsv1.CommitReservationRequest) (*productsv1.CommitReservationResponse, error) {
+	ctx, span := c.startSpan(ctx, "CommitReservation.Handler")
+	defer span.End()
+
+	const op = "commit_reservation"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	reservation, err := c.store(ctx).CommitStockReservation(ctx, req.GetReservationId())
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.reservationStateError(ctx, req.GetReservationId(), "commit")
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to commit reservation: %v", err)
+	}
+
+	return &productsv1.CommitReservationResponse{Reservation: mapReservationToProto(repository.StockReservation(reservation))}, nil
+}
+
+func (c *ProductServiceHandler) ReleaseReservation(ctx context.Context, req *productsv1.ReleaseReservationRequest) (*productsv1.ReleaseReservationResponse, error) {
+	ctx, span := c.startSpan(ctx, "ReleaseReservation.Handler")
+	defer span.End()
+
+	const op = "release_reservation"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	row, err := c.store(ctx).ReleaseStockReservation(ctx, repository.ReleaseStockReservationParams{
+		ID:    req.GetReservationId(),
+		Actor: actorFromContext(ctx),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.reservationStateError(ctx, req.GetReservationId(), "release")
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to release reservation: %v", err)
+	}
+
+	return &productsv1.ReleaseReservationResponse{
+		Reservation: mapReservationToProto(repository.StockReservation(row)),
+	}, nil
+}
+
+// reservationStateError explains why a commit or release matched no
+// pending reservation.
+func (c *ProductServiceHandler) reservationStateError(ctx context.Context, id int64, action string) error {
+	reservation, err := c.store(ctx).GetStockReservation(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "reservation with ID %d not found", id)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to %s reservation: %v", action, err)
+	}
+	if reservation.Status == reservationPending {
+		// Only commit is gated on expiry; the sweeper has not caught up yet.
+		return status.Errorf(codes.FailedPrecondition, "cannot %s reservation %d: reservation expired", action, id)
+	}
+	return status.Errorf(codes.FailedPrecondition, "cannot %s reservation %d: reservation is %s", action, id, reservation.Status)
+}
+
+func mapReservationToProto(r repository.StockReservation) *productsv1.StockReservation {
+	return &productsv1.StockReservation{
+		Id:        uint64(r.ID),
+		ProductId: uint64(r.ProductID),
+		VariantId: uint64(r.VariantID.Int64),
+		Quantity:  uint32(r.Quantity),
+		Status:    reservationStatuses[r.Status],
+		ExpiresAt: timestamppb.New(r.ExpiresAt),
+		CreatedAt: timestamppb.New(r.CreatedAt),
+		UpdatedAt: timestamppb.New(r.UpdatedAt),
+	}
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// reservationRow is a stock_reservations row for reservation 7 holding
+// three units of product 1.
+func reservationRow(state string) *fakeRow {
+	now := time.Now()
+	return &fakeRow{values: []any{int64(7), int64(1), int32(3), state, now.Add(15 * time.Minute), now, now, pgtype.Int8{}}}
+}
+
+func TestProductServiceHandler_ReserveStock(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{reservationRow(reservationPending)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{
+		ProductId: 1,
+		Quantity:  3,
+		Ttl:       durationpb.New(5 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(7), resp.GetReservation().GetId())
+	assert.Equal(t, productsv1.ReservationStatus_RESERVATION_STATUS_PENDING, resp.GetReservation().GetStatus())
+
+	// id, quantity, ttl, product id, actor
+	require.Len(t, db.args, 5)
+	assert.Equal(t, int32(3), db.args[1])
+	assert.Equal(t, pgtype.Interval{Microseconds: (5 * time.Minute).Microseconds(), Valid: true}, db.args[2])
+	assert.Equal(t, int64(1), db.args[3])
+	assert.Equal(t, unknownActor, db.args[4])
+}
+
+func TestProductServiceHandler_ReserveStock_DefaultTTL(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{reservationRow(reservationPending)}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{ProductId: 1, Quantity: 3})
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Interval{Microseconds: (15 * time.Minute).Microseconds(), Valid: true}, db.args[2])
+}
+
+func TestProductServiceHandler_ReserveStock_InvalidRequest(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	for name, req := range map[string]*productsv1.ReserveStockRequest{
+		"missing product":  {Quantity: 1},
+		"zero quantity":    {ProductId: 1},
+		"negative ttl":     {ProductId: 1, Quantity: 1, Ttl: durationpb.New(-time.Second)},
+		"ttl over maximum": {ProductId: 1, Quantity: 1, Ttl: durationpb.New(2 * time.Hour)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := validated(context.Background(), req, handler.ReserveStock)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_ReserveStock_InsufficientStock(t *testing.T) {
+	db := &fakeDB{
+		queue:   []*fakeRow{{err: pgx.ErrNoRows}},
+		product: repository.Product{ID: 1, Name: "Widget", StockQuantity: 2},
+	}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{ProductId: 1, Quantity: 3})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	assert.Contains(t, st.Message(), "available 2")
+}
+
+func TestProductServiceHandler_ReserveStock_ProductNotFound(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{err: pgx.ErrNoRows})
+
+	_, err := handler.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{ProductId: 1, Quantity: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestProductServiceHandler_CommitReservation(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{reservationRow(reservationCommitted)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.CommitReservation(context.Background(), &productsv1.CommitReservationRequest{ReservationId: 7})
+	require.NoError(t, err)
+	assert.Equal(t, productsv1.ReservationStatus_RESERVATION_STATUS_COMMITTED, resp.GetReservation().GetStatus())
+}
+
+func TestProductServiceHandler_CommitReservation_NotPending(t *testing.T) {
+	tests := map[string]struct {
+		current *fakeRow
+		code    codes.Code
+		message string
+	}{
+		"unknown":  {current: &fakeRow{err: pgx.ErrNoRows}, code: codes.NotFound, message: "not found"},
+		"released": {current: reservationRow(reservationReleased), code: codes.FailedPrecondition, message: "reservation is released"},
+		"expired":  {current: reservationRow(reservationExpired), code: codes.FailedPrecondition, message: "reservation is expired"},
+		"overdue":  {current: reservationRow(reservationPending), code: codes.FailedPrecondition, message: "reservation expired"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.current}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.CommitReservation(context.Background(), &productsv1.CommitReservationRequest{ReservationId: 7})
+			st, _ := status.FromError(err)
+			assert.Equal(t, tt.code, st.Code())
+			assert.Contains(t, st.Message(), tt.message)
+		})
+	}
+}
+
+func TestProductServiceHandler_ReleaseReservation(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{reservationRow(reservationReleased)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.ReleaseReservation(context.Background(), &productsv1.ReleaseReservationRequest{ReservationId: 7})
+	require.NoError(t, err)
+	assert.Equal(t, productsv1.ReservationStatus_RESERVATION_STATUS_RELEASED, resp.GetReservation().GetStatus())
+	assert.Equal(t, uint32(3), resp.GetReservation().GetQuantity())
+}
+
+func TestProductServiceHandler_ReleaseReservation_AlreadyCommitted(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, reservationRow(reservationCommitted)}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.ReleaseReservation(context.Background(), &productsv1.ReleaseReservationRequest{ReservationId: 7})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
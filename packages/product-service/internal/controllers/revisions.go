@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// actorMetadataKey is the incoming metadata key naming the caller, set by
+// the gateway.
+const actorMetadataKey = "user-id"
+
+// unknownActor is recorded for changes made without a user-id.
+const unknownActor = "unknown"
+
+// revisionActions maps product_revisions.action values to their enum.
+var revisionActions = map[string]productsv1.RevisionAction{
+	"create":   productsv1.RevisionAction_REVISION_ACTION_CREATE,
+	"update":   productsv1.RevisionAction_REVISION_ACTION_UPDATE,
+	"delete":   productsv1.RevisionAction_REVISION_ACTION_DELETE,
+	"undelete": productsv1.RevisionAction_REVISION_ACTION_UNDELETE,
+	"baseline": productsv1.RevisionAction_REVISION_ACTION_BASELINE,
+	"purge":    productsv1.RevisionAction_REVISION_ACTION_PURGE,
+	"stock":    productsv1.RevisionAction_REVISION_ACTION_STOCK,
+}
+
+// actorFromContext returns the user-id of the caller, which product
+// revisions record as their actor.
+func actorFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return unknownActor
+	}
+	if vs := md.Get(actorMetadataKey); len(vs) > 0 && vs[0] != "" {
+		return vs[0]
+	}
+	return unknownActor
+}
+
+func (c *ProductServiceHandler) ListProductRevisions(ctx context.Context, req *productsv1.ListProductRevisionsRequest) (*productsv1.ListProductRevisionsResponse, error) {
+	ctx, span := c.startSpan(ctx, "ListProductRevisions.Handler")
+	defer span.End()
+
+	const op = "list_product_revisions"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	params := repository.ListProductRevisionsParams{
+		ProductID: req.GetProductId(),
+		PageLimit: int32(pageSize) + 1,
+	}
+	fingerprint := url.Values{"product_id": {strconv.FormatInt(req.GetProductId(), 10)}}
+
+	if token := req.GetPageToken(); token != "" {
+		// Revisions page by version, which is unique per product.
+		cursor, err := c.pageTokens.Decode(token, fingerprint.Encode())
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("page_token", "%v", pagination.ErrMalformedToken)
+		}
+		params.BeforeVersion = pgtype.Int8{Int64: cursor.LastID, Valid: true}
+	}
+
+	revisions, err := c.store(ctx).ListProductRevisions(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to list product revisions: %v", err)
+	}
+
+	resp := &productsv1.ListProductRevisionsResponse{}
+	if len(revisions) > int(pageSize) {
+		revisions = revisions[:pageSize]
+		resp.NextPageToken, err = c.pageTokens.Encode(pagination.Cursor{
+			LastID: revisions[len(revisions)-1].Version,
+			Query:  fingerprint.Encode(),
+		})
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to build page token: %v", err)
+		}
+	}
+
+	resp.Revisions = make([]*productsv1.ProductRevision, 0, len(revisions))
+	for _, r := range revisions {
+		revision, err := mapRevisionToProto(r)
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to read revision %d: %v", r.ID, err)
+		}
+		resp.Revisions = append(resp.Revisions, revision)
+	}
+
+	return resp, nil
+}
+
+// productAsOf rebuilds a product from the last revision recorded at or
+// before asOf. A product that did not exist yet, or was deleted, at that
+// time is not found.
+func (c *ProductServiceHandler) productAsOf(ctx context.Context, id int64, asOf *timestamppb.Timestamp) (snapshot, error) {
+	if err := asOf.CheckValid(); err != nil {
+		return snapshot{}, validation.Invalid("as_of", "%v", err)
+	}
+
+	revision, err := c.store(ctx).GetProductRevisionAsOf(ctx, repository.GetProductRevisionAsOfParams{
+		ProductID: id,
+		AsOf:      asOf.AsTime(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return snapshot{}, status.Errorf(codes.NotFound, "product %d not found at %s", id, asOf.AsTime().Format(time.RFC3339))
+	}
+	if err != nil {
+		return snapshot{}, status.Errorf(codes.Internal, "failed to get product revision: %v", err)
+	}
+
+	product, err := decodeSnapshot(revision.Snapshot)
+	if err != nil {
+		return snapshot{}, status.Errorf(codes.Internal, "failed to read revision %d: %v", revision.ID, err)
+	}
+	if product.DeletedAt.Valid {
+		return snapshot{}, status.Errorf(codes.NotFound, "product %d not found at %s", id, asOf.AsTime().Format(time.RFC3339))
+	}
+	return product, nil
+}
+
+// snapshot is a product as the revision queries store it: the products row
+// with its categories and tags. CategoryIDs and Tags are nil in revisions
+// recorded before the taxonomy was kept.
+type snapshot struct {
+	repository.Product
+	CategoryIDs []int64  `json:"category_ids"`
+	Tags        []string `json:"tags"`
+}
+
+// decodeSnapshot reads a snapshot stored as JSON by the revision queries.
+func decodeSnapshot(data []byte) (snapshot, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return snapshot{}, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	return s, nil
+}
+
+func (s snapshot) toProto() *productsv1.Product {
+	p := mapDBToProto(s.Product)
+	p.CategoryIds = toUint64s(s.CategoryIDs)
+	p.Tags = s.Tags
+	return p
+}
+
+func mapRevisionToProto(r repository.ProductRevision) (*productsv1.ProductRevision, error) {
+	after, err := decodeSnapshot(r.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &productsv1.ProductRevision{
+		Id:            uint64(r.ID),
+		ProductId:     uint64(r.ProductID),
+		Etag:          formatETag(r.Version),
+		Action:        revisionActions[r.Action],
+		Actor:         r.Actor,
+		CreatedAt:     timestamppb.New(r.CreatedAt),
+		After:         after.toProto(),
+		ChangedFields: &fieldmaskpb.FieldMask{},
+	}
+	if r.Previous != nil {
+		before, err := decodeSnapshot(r.Previous)
+		if err != nil {
+			return nil, err
+		}
+		revision.Before = before.toProto()
+		revision.ChangedFields.Paths = changedFields(before, after)
+	}
+	return revision, nil
+}
+
+// changedFields lists the fields that differ between two states of a
+// product, named as in UpdateProduct's update_mask. Categories and tags are
+// only compared when both states recorded them.
+func changedFields(before, after snapshot) []string {
+	var paths []string
+	if before.Name != after.Name {
+		paths = append(paths, "name")
+	}
+	if before.Description != after.Description {
+		paths = append(paths, "description")
+	}
+	if before.PriceMinor != after.PriceMinor || before.Currency != after.Currency {
+		paths = append(paths, "price")
+	}
+	if before.StockQuantity != after.StockQuantity {
+		paths = append(paths, "stock_quantity")
+	}
+	if before.DeletedAt.Valid != after.DeletedAt.Valid || !before.DeletedAt.Time.Equal(after.DeletedAt.Time) {
+		paths = append(paths, "deleted_at")
+	}
+	if before.CategoryIDs != nil && after.CategoryIDs != nil && !slices.Equal(before.CategoryIDs, after.CategoryIDs) {
+		paths = append(paths, "category_ids")
+	}
+	if before.Tags != nil && after.Tags != nil && !slices.Equal(before.Tags, after.Tags) {
+		paths = append(paths, "tags")
+	}
+	return paths
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// encodeSnapshot encodes a product, or a snapshot with its taxonomy, the
+// way the revision queries store it.
+func encodeSnapshot(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// revisionRow is a product_revisions row of product 1.
+func revisionRow(version int64, action string, previous, snap []byte, createdAt time.Time) *fakeRow {
+	return &fakeRow{values: []any{
+		version * 10, int64(1), version, action, "alice", previous, snap, createdAt,
+	}}
+}
+
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, unknownActor, actorFromContext(context.Background()))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("user-id", "alice"))
+	assert.Equal(t, "alice", actorFromContext(ctx))
+}
+
+func TestChangedFields(t *testing.T) {
+	before := snapshot{
+		Product:     repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD", StockQuantity: 3, Version: 1},
+		CategoryIDs: []int64{7},
+		Tags:        []string{"sale"},
+	}
+	after := before
+	after.Name = "Gadget"
+	after.Currency = "EUR"
+	after.Version = 2
+	after.UpdatedAt = time.Now()
+
+	assert.Equal(t, []string{"name", "price"}, changedFields(before, after))
+
+	deleted := before
+	deleted.DeletedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	assert.Equal(t, []string{"deleted_at"}, changedFields(before, deleted))
+	assert.Empty(t, changedFields(before, before))
+
+	refiled := before
+	refiled.CategoryIDs = []int64{7, 8}
+	refiled.Tags = []string{}
+	assert.Equal(t, []string{"category_ids", "tags"}, changedFields(before, refiled))
+
+	// Revisions recorded before the taxonomy was kept have none to compare.
+	untracked := before
+	untracked.CategoryIDs, untracked.Tags = nil, nil
+	assert.Empty(t, changedFields(untracked, before))
+}
+
+func TestMapRevisionToProto_Taxonomy(t *testing.T) {
+	product := repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD", StockQuantity: 3, Version: 4}
+	before := snapshot{Product: product, CategoryIDs: []int64{3}, Tags: []string{"sale"}}
+	after := before
+	after.StockQuantity, after.Version = 2, 5
+
+	stock, err := mapRevisionToProto(repository.ProductRevision{
+		ProductID: 1, Version: 5, Action: "stock", Previous: encodeSnapshot(t, before), Snapshot: encodeSnapshot(t, after),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, productsv1.RevisionAction_REVISION_ACTION_STOCK, stock.GetAction())
+	assert.Equal(t, []string{"stock_quantity"}, stock.GetChangedFields().GetPaths())
+	assert.Equal(t, []uint64{3}, stock.GetAfter().GetCategoryIds())
+	assert.Equal(t, []string{"sale"}, stock.GetAfter().GetTags())
+
+	// A category-only update still shows what changed.
+	refiled := after
+	refiled.CategoryIDs, refiled.Version = []int64{3, 8}, 6
+	update, err := mapRevisionToProto(repository.ProductRevision{
+		ProductID: 1, Version: 6, Action: "update", Previous: encodeSnapshot(t, after), Snapshot: encodeSnapshot(t, refiled),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"category_ids"}, update.GetChangedFields().GetPaths())
+	assert.Equal(t, []uint64{3}, update.GetBefore().GetCategoryIds())
+	assert.Equal(t, []uint64{3, 8}, update.GetAfter().GetCategoryIds())
+}
+
+func TestProductServiceHandler_ListProductRevisions(t *testing.T) {
+	now := time.Now().UTC()
+	v1 := repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD", Version: 1, CreatedAt: now, UpdatedAt: now}
+	v2 := v1
+	v2.PriceMinor, v2.Version = 1299, 2
+	v3 := v2
+	v3.DeletedAt, v3.Version = pgtype.Timestamptz{Time: now, Valid: true}, 3
+
+	db := &fakeDB{rows: []*fakeRow{
+		revisionRow(3, "delete", encodeSnapshot(t, v2), encodeSnapshot(t, v3), now),
+		revisionRow(2, "update", encodeSnapshot(t, v1), encodeSnapshot(t, v2), now),
+		revisionRow(1, "create", nil, encodeSnapshot(t, v1), now),
+	}}
+	handler := newTestHandler(t, db)
+
+	req := &productsv1.ListProductRevisionsRequest{ProductId: 1, PageSize: 2}
+	resp, err := handler.ListProductRevisions(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.GetRevisions(), 2)
+
+	deleted := resp.GetRevisions()[0]
+	assert.Equal(t, productsv1.RevisionAction_REVISION_ACTION_DELETE, deleted.GetAction())
+	assert.Equal(t, "alice", deleted.GetActor())
+	assert.Equal(t, formatETag(3), deleted.GetEtag())
+	assert.NotNil(t, deleted.GetAfter().GetDeletedAt())
+	assert.Equal(t, []string{"deleted_at"}, deleted.GetChangedFields().GetPaths())
+
+	updated := resp.GetRevisions()[1]
+	assert.True(t, proto.Equal(usd(999), updated.GetBefore().GetPrice()))
+	assert.Equal(t, []string{"price"}, updated.GetChangedFields().GetPaths())
+	require.NotEmpty(t, resp.GetNextPageToken())
+
+	db.rows = []*fakeRow{revisionRow(1, "create", nil, encodeSnapshot(t, v1), now)}
+	req.PageToken = resp.GetNextPageToken()
+	resp, err = handler.ListProductRevisions(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, resp.GetRevisions(), 1)
+	assert.Nil(t, resp.GetRevisions()[0].GetBefore())
+	assert.Empty(t, resp.GetRevisions()[0].GetChangedFields().GetPaths())
+	assert.Empty(t, resp.GetNextPageToken())
+
+	// product id, before version, limit
+	require.Len(t, db.args, 3)
+	assert.Equal(t, pgtype.Int8{Int64: 2, Valid: true}, db.args[1])
+
+	// The token is bound to the product it was issued for.
+	req.ProductId = 2
+	_, err = handler.ListProductRevisions(context.Background(), req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_GetProduct_AsOf(t *testing.T) {
+	now := time.Now().UTC()
+	old := repository.Product{ID: 1, Name: "Widget", PriceMinor: 999, Currency: "USD", Version: 1, CreatedAt: now, UpdatedAt: now}
+	deleted := old
+	deleted.DeletedAt = pgtype.Timestamptz{Time: now, Valid: true}
+
+	tests := []struct {
+		name string
+		row  *fakeRow
+		want codes.Code
+	}{
+		{"existed", revisionRow(1, "create", nil, encodeSnapshot(t, old), now), codes.OK},
+		{"not created yet", &fakeRow{err: pgx.ErrNoRows}, codes.NotFound},
+		{"deleted", revisionRow(2, "delete", encodeSnapshot(t, old), encodeSnapshot(t, deleted), now), codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{tt.row}}
+			handler := newTestHandler(t, db)
+
+			resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{
+				Id:   1,
+				AsOf: timestamppb.New(now),
+			})
+			require.Equal(t, tt.want, status.Code(err))
+			if tt.want == codes.OK {
+				assert.Equal(t, "Widget", resp.GetProduct().GetName())
+				assert.Equal(t, formatETag(1), resp.GetProduct().GetEtag())
+				// product id, as_of
+				require.Len(t, db.args, 2)
+				assert.Equal(t, now, db.args[1])
+			}
+		})
+	}
+}
+
+func TestProductServiceHandler_GetProduct_InvalidAsOf(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{
+		Id:   1,
+		AsOf: &timestamppb.Timestamp{Nanos: -1},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
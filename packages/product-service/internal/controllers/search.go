@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// descriptionSnippetLength bounds the description excerpt in bytes.
+const descriptionSnippetLength = 160
+
+func (c *ProductServiceHandler) SearchProducts(ctx context.Context, req *productsv1.SearchProductsRequest) (*productsv1.SearchProductsResponse, error) {
+	ctx, span := c.startSpan(ctx, "SearchProducts.Handler")
+	defer span.End()
+
+	const op = "search_products"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	query := strings.TrimSpace(req.GetQuery())
+
+	pageSize := req.GetPageSize()
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	fingerprint := "q=" + query
+	params := repository.SearchProductsParams{
+		Query:     query,
+		PageLimit: int32(pageSize) + 1,
+	}
+	if token := req.GetPageToken(); token != "" {
+		cursor, err := c.pageTokens.Decode(token, fingerprint)
+		var score float64
+		if err == nil {
+			score, err = strconv.ParseFloat(cursor.LastKey, 64)
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, validation.Invalid("page_token", "%v", pagination.ErrMalformedToken)
+		}
+		params.AfterScore = pgtype.Float8{Float64: score, Valid: true}
+		params.AfterID = cursor.LastID
+	}
+
+	rows, err := c.store(ctx).SearchProducts(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to search products: %v", err)
+	}
+
+	resp := &productsv1.SearchProductsResponse{}
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		last := rows[len(rows)-1]
+		resp.NextPageToken, err = c.pageTokens.Encode(pagination.Cursor{
+			LastID:  last.ID,
+			LastKey: strconv.FormatFloat(last.Score, 'g', -1, 64),
+			Query:   fingerprint,
+		})
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, status.Errorf(codes.Internal, "failed to build page token: %v", err)
+		}
+	}
+
+	terms := searchTerms(query)
+	resp.Results = make([]*productsv1.SearchResult, 0, len(rows))
+	products := make([]*productsv1.Product, 0, len(rows))
+	for _, r := range rows {
+		product := mapDBToProto(repository.Product{
+			ID:            r.ID,
+			Name:          r.Name,
+			Description:   r.Description,
+			PriceMinor:    r.PriceMinor,
+			Currency:      r.Currency,
+			StockQuantity: r.StockQuantity,
+			CreatedAt:     r.CreatedAt,
+			UpdatedAt:     r.UpdatedAt,
+			Version:       r.Version,
+		})
+		products = append(products, product)
+		resp.Results = append(resp.Results, &productsv1.SearchResult{
+			Product:            product,
+			Score:              r.Score,
+			NameSnippet:        highlight(r.Name, terms, 0),
+			DescriptionSnippet: highlight(r.Description.String, terms, descriptionSnippetLength),
+		})
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), products...); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+
+	return resp, nil
+}
+
+// searchTerms splits a query into distinct lower-cased words.
+func searchTerms(query string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), isNotWordRune) {
+		if !seen[w] {
+			seen[w] = true
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// matchesTerm reports whether a word should be highlighted for any term.
+// Prefix matching in both directions approximates the stemming done by the
+// database ("running" matches "run" and vice versa).
+func matchesTerm(word string, terms []string) bool {
+	word = strings.ToLower(word)
+	for _, t := range terms {
+		if strings.HasPrefix(word, t) || (len(word) >= 3 && strings.HasPrefix(t, word)) {
+			return true
+		}
+	}
+	return false
+}
+
+type textRange struct{ start, end int }
+
+// highlight HTML-escapes text and wraps words matching terms in <mark>.
+// When maxLen > 0 the result is trimmed to a window of roughly maxLen bytes
+// of source text around the first match.
+func highlight(text string, terms []string, maxLen int) string {
+	var matches []textRange
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if isNotWordRune(r) {
+			i += size
+			continue
+		}
+		j := i
+		for j < len(text) {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if isNotWordRune(r) {
+				break
+			}
+			j += size
+		}
+		if matchesTerm(text[i:j], terms) {
+			matches = append(matches, textRange{i, j})
+		}
+		i = j
+	}
+
+	window := textRange{0, len(text)}
+	if maxLen > 0 && len(text) > maxLen {
+		start := 0
+		if len(matches) > 0 {
+			start = max(0, matches[0].start-maxLen/4)
+		}
+		end := min(len(text), start+maxLen)
+		start = max(0, end-maxLen)
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		window = textRange{start, end}
+	}
+
+	var b strings.Builder
+	if window.start > 0 {
+		b.WriteString("…")
+	}
+	pos := window.start
+	for _, m := range matches {
+		if m.start < window.start || m.end > window.end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:m.start]))
+		b.WriteString("<mark>" + html.EscapeString(text[m.start:m.end]) + "</mark>")
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(text[pos:window.end]))
+	if window.end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProductServiceHandler_SearchProducts_InvalidQuery(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	for _, query := range []string{"", "   ", strings.Repeat("a", 257)} {
+		_, err := validated(context.Background(), &productsv1.SearchProductsRequest{Query: query}, handler.SearchProducts)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	}
+}
+
+func TestProductServiceHandler_SearchProducts_TokenBoundToQuery(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	token, err := handler.pageTokens.Encode(pagination.Cursor{LastID: 1, LastKey: "0.5", Query: "q=shoes"})
+	require.NoError(t, err)
+
+	_, err = handler.SearchProducts(context.Background(), &productsv1.SearchProductsRequest{
+		Query:     "boots",
+		PageToken: token,
+	})
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestProductServiceHandler_SearchProducts_LoadsRelations(t *testing.T) {
+	db := &fakeDB{
+		rows: []*fakeRow{{product: repository.Product{ID: 42, Name: "Running shoes", PriceMinor: 999, Currency: "USD"}}},
+		lookups: map[string][]*fakeRow{
+			"ListCategoryLinks": {{values: []any{int64(42), int64(3)}}},
+			"ListProductTags":   {{values: []any{int64(42), "sale"}}},
+		},
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.SearchProducts(context.Background(), &productsv1.SearchProductsRequest{Query: "shoes"})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetResults(), 1)
+	product := resp.GetResults()[0].GetProduct()
+	assert.Equal(t, []uint64{3}, product.GetCategoryIds())
+	assert.Equal(t, []string{"sale"}, product.GetTags())
+}
+
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"red", "running", "shoes"}, searchTerms("Red running-shoes, red!"))
+}
+
+func TestHighlight(t *testing.T) {
+	terms := searchTerms("run shoe")
+
+	assert.Equal(t,
+		"<mark>Running</mark> <mark>Shoes</mark> &amp; socks",
+		highlight("Running Shoes & socks", terms, 0),
+	)
+	assert.Equal(t, "Plain text", highlight("Plain text", terms, 0))
+}
+
+func TestHighlight_Window(t *testing.T) {
+	text := strings.Repeat("filler ", 40) + "waterproof boots " + strings.Repeat("filler ", 40)
+
+	got := highlight(text, searchTerms("boots"), 60)
+
+	assert.True(t, strings.HasPrefix(got, "…"))
+	assert.True(t, strings.HasSuffix(got, "…"))
+	assert.Contains(t, got, "<mark>boots</mark>")
+	assert.LessOrEqual(t, len(got), 60+len("<mark></mark>")+2*len("…"))
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// newMemoryHandler returns a handler backed by an empty in-memory store.
+func newMemoryHandler(t *testing.T) *ProductServiceHandler {
+	t.Helper()
+
+	handler := newTestHandler(t, &fakeDB{})
+	handler.queries = store.NewMemory()
+	return handler
+}
+
+func TestProductServiceHandler_MemoryStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	handler := newMemoryHandler(t)
+
+	created, err := handler.CreateProduct(ctx, &productsv1.CreateProductRequest{
+		Name:          "Widget",
+		Price:         usd(999),
+		StockQuantity: 3,
+		Tags:          []string{"sale"},
+	})
+	require.NoError(t, err)
+
+	got, err := handler.GetProduct(ctx, &productsv1.GetProductRequest{Id: int64(created.GetProduct().GetId())})
+	require.NoError(t, err)
+	assert.Equal(t, "Widget", got.GetProduct().GetName())
+	assert.Equal(t, uint32(3), got.GetProduct().GetStockQuantity())
+	assert.Equal(t, []string{"sale"}, got.GetProduct().GetTags())
+	assert.Equal(t, "1", got.GetProduct().GetEtag())
+}
+
+func TestProductServiceHandler_MemoryStore_StaleETag(t *testing.T) {
+	ctx := context.Background()
+	handler := newMemoryHandler(t)
+
+	created, err := handler.CreateProduct(ctx, &productsv1.CreateProductRequest{Name: "Widget", Price: usd(999)})
+	require.NoError(t, err)
+	id := created.GetProduct().GetId()
+
+	_, err = handler.UpdateProduct(ctx, &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: id, Name: "Gadget", Etag: "1"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	require.NoError(t, err)
+
+	// A transactional update reports the miss from inside its transaction.
+	_, err = handler.UpdateProduct(ctx, &productsv1.UpdateProductRequest{
+		Product:    &productsv1.Product{Id: id, Name: "Gizmo", Tags: []string{"new"}, Etag: "1"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "tags"}},
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), `"2"`)
+}
+
+func TestProductServiceHandler_MemoryStore_VariantConflict(t *testing.T) {
+	ctx := context.Background()
+	handler := newMemoryHandler(t)
+
+	created, err := handler.CreateProduct(ctx, &productsv1.CreateProductRequest{Name: "Shirt", Price: usd(2500)})
+	require.NoError(t, err)
+	id := created.GetProduct().GetId()
+
+	_, err = handler.CreateVariant(ctx, &productsv1.CreateVariantRequest{ProductId: id, Sku: "SHIRT-S", Options: map[string]string{"size": "S"}})
+	require.NoError(t, err)
+
+	_, err = handler.CreateVariant(ctx, &productsv1.CreateVariantRequest{ProductId: id, Sku: "SHIRT-S", Options: map[string]string{"size": "M"}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = handler.CreateVariant(ctx, &productsv1.CreateVariantRequest{ProductId: id, Sku: "SHIRT-M", Options: map[string]string{"size": "S"}})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+)
+
+// suggestBackend labels metrics for requests served from the in-process index.
+const suggestBackend = "memory"
+
+func (c *ProductServiceHandler) SuggestProducts(ctx context.Context, req *productsv1.SuggestProductsRequest) (*productsv1.SuggestProductsResponse, error) {
+	_, span := c.startSpan(ctx, "SuggestProducts.Handler")
+	defer span.End()
+
+	const op = "suggest_products"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, suggestBackend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	prefix := strings.TrimSpace(req.GetPrefix())
+
+	limit := c.suggestions.MaxResults
+	if n := int(req.GetMaxResults()); n > 0 && n < limit {
+		limit = n
+	}
+
+	matches := c.suggestions.Suggest(prefix, limit)
+	resp := &productsv1.SuggestProductsResponse{
+		Suggestions: make([]*productsv1.ProductSuggestion, 0, len(matches)),
+	}
+	for _, m := range matches {
+		resp.Suggestions = append(resp.Suggestions, &productsv1.ProductSuggestion{
+			Id:   uint64(m.ID),
+			Name: m.Name,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestProductServiceHandler_SuggestProducts(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+	for id, name := range map[int64]string{1: "Widget", 2: "Wide Lens", 3: "Wicker Basket", 4: "Gadget"} {
+		handler.suggestions.Put(id, name)
+	}
+
+	resp, err := handler.SuggestProducts(context.Background(), &productsv1.SuggestProductsRequest{Prefix: "wid"})
+	require.NoError(t, err)
+
+	var names []string
+	for _, s := range resp.GetSuggestions() {
+		names = append(names, s.GetName())
+	}
+	assert.Equal(t, []string{"Wide Lens", "Widget"}, names)
+}
+
+func TestProductServiceHandler_SuggestProducts_MaxResults(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+	for id := int64(1); id <= 10; id++ {
+		handler.suggestions.Put(id, "item")
+	}
+
+	resp, err := handler.SuggestProducts(context.Background(), &productsv1.SuggestProductsRequest{Prefix: "it", MaxResults: 2})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetSuggestions(), 2)
+
+	// Requests above the configured cap are clamped.
+	resp, err = handler.SuggestProducts(context.Background(), &productsv1.SuggestProductsRequest{Prefix: "it", MaxResults: 50})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetSuggestions(), handler.suggestions.MaxResults)
+}
+
+func TestProductServiceHandler_SuggestProducts_EmptyPrefix(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := validated(context.Background(), &productsv1.SuggestProductsRequest{Prefix: " "}, handler.SuggestProducts)
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+)
+
+// taxonomy is the validated set of categories and tags of one product.
+type taxonomy struct {
+	categoryIDs []int64
+	tags        []string
+}
+
+// parseCategoryIDs validates category ids, dropping duplicates and sorting
+// them. Their number is bounded by the request's field rules.
+func parseCategoryIDs(field string, ids []uint64) ([]int64, error) {
+	out := make([]int64, 0, len(ids))
+	for i, id := range ids {
+		if id > math.MaxInt64 {
+			return nil, validation.Invalid(fmt.Sprintf("%s[%d]", field, i), "is not a valid ID")
+		}
+		out = append(out, int64(id))
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// parseTags normalizes tags to trimmed lower case, dropping duplicates and
+// sorting them. Their number and length are bounded by the request's field
+// rules.
+func parseTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		out = append(out, strings.ToLower(strings.TrimSpace(tag)))
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
+// parseTaxonomy validates the categories and tags of a CreateProductRequest.
+func parseTaxonomy(req *productsv1.CreateProductRequest) (taxonomy, error) {
+	var t taxonomy
+	var err error
+	if t.categoryIDs, err = parseCategoryIDs("category_ids", req.GetCategoryIds()); err != nil {
+		return t, err
+	}
+	t.tags = parseTags(req.GetTags())
+	return t, nil
+}
+
+func (t taxonomy) empty() bool {
+	return len(t.categoryIDs) == 0 && len(t.tags) == 0
+}
+
+// setCategories replaces the categories of a product. It fails with
+// INVALID_ARGUMENT when any id names a missing category.
+func setCategories(ctx context.Context, q store.ProductStore, productID int64, ids []int64) error {
+	if err := q.ClearProductCategories(ctx, productID); err != nil {
+		return fmt.Errorf("update product categories: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	linked, err := q.AddProductCategories(ctx, repository.AddProductCategoriesParams{
+		ProductID:   productID,
+		CategoryIds: ids,
+	})
+	if err != nil {
+		return fmt.Errorf("update product categories: %w", err)
+	}
+	if linked != int64(len(ids)) {
+		return validation.Invalid("category_ids", "name %d categories that do not exist", int64(len(ids))-linked)
+	}
+	return nil
+}
+
+// setTags replaces the tags of a product.
+func setTags(ctx context.Context, q store.ProductStore, productID int64, tags []string) error {
+	if err := q.ClearProductTags(ctx, productID); err != nil {
+		return fmt.Errorf("update product tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := q.AddProductTags(ctx, repository.AddProductTagsParams{ProductID: productID, Tags: tags}); err != nil {
+		return fmt.Errorf("update product tags: %w", err)
+	}
+	return nil
+}
+
+// setTaxonomy files a newly created product under its categories and tags.
+func setTaxonomy(ctx context.Context, q store.ProductStore, productID int64, t taxonomy) error {
+	if err := setCategories(ctx, q, productID, t.categoryIDs); err != nil {
+		return err
+	}
+	return setTags(ctx, q, productID, t.tags)
+}
+
+// loadTaxonomy fills in the category_ids and tags of products with one query
+// each, however many products there are.
+func loadTaxonomy(ctx context.Context, q store.ProductStore, products ...*productsv1.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*productsv1.Product, len(products))
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		byID[int64(p.GetId())] = p
+		ids = append(ids, int64(p.GetId()))
+	}
+
+	links, err := q.ListCategoryLinks(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load product categories: %w", err)
+	}
+	for _, l := range links {
+		if p, ok := byID[l.ProductID]; ok {
+			p.CategoryIds = append(p.CategoryIds, uint64(l.CategoryID))
+		}
+	}
+
+	tags, err := q.ListProductTags(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("load product tags: %w", err)
+	}
+	for _, t := range tags {
+		if p, ok := byID[t.ProductID]; ok {
+			p.Tags = append(p.Tags, t.Tag)
+		}
+	}
+	return nil
+}
+
+func toUint64s(ids []int64) []uint64 {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]uint64, len(ids))
+	for i, id := range ids {
+		out[i] = uint64(id)
+	}
+	return out
+}
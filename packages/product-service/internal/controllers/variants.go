@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxVariants bounds the number of variants of a single product.
+	maxVariants = 100
+	// maxVariantOptions and maxOptionLength bound the options of a variant.
+	maxVariantOptions = 8
+	maxOptionLength   = 64
+
+	// uniqueViolation is the SQLSTATE of a unique constraint violation.
+	uniqueViolation = "23505"
+	// variantOptionsConstraint keeps the option values of a product's
+	// variants distinct.
+	variantOptionsConstraint = "product_variants_options_key"
+)
+
+// updatableVariantFields lists the ProductVariant field mask paths accepted
+// by UpdateVariant.
+var updatableVariantFields = []string{"sku", "options", "price"}
+
+// variantOptions is the validated option map of a variant along with its
+// canonical encoding, which is stored both as the options document and as
+// the key that keeps a product's variants distinct.
+type variantOptions struct {
+	doc []byte
+	key string
+}
+
+// parseVariantOptions validates the options of a variant.
+func parseVariantOptions(field string, options map[string]string) (variantOptions, error) {
+	if len(options) > maxVariantOptions {
+		return variantOptions{}, validation.Invalid(field, "must have at most %d options", maxVariantOptions)
+	}
+	for name, value := range options {
+		if name == "" || value == "" {
+			return variantOptions{}, validation.Invalid(field, "must not have empty option names or values")
+		}
+		if len(name) > maxOptionLength || len(value) > maxOptionLength {
+			return variantOptions{}, validation.Invalid(fmt.Sprintf("%s[%q]", field, name), "must be at most %d bytes", maxOptionLength)
+		}
+	}
+	if options == nil {
+		options = map[string]string{}
+	}
+	// json.Marshal sorts map keys, so equal option sets encode identically.
+	doc, err := json.Marshal(options)
+	if err != nil {
+		return variantOptions{}, fmt.Errorf("%s: %w", field, err)
+	}
+	return variantOptions{doc: doc, key: string(doc)}, nil
+}
+
+// parseVariantPrice converts an optional price override to nullable query
+// parameters.
+func parseVariantPrice(field string, price *productsv1.Money) (pgtype.Int8, pgtype.Text, error) {
+	if price == nil {
+		return pgtype.Int8{}, pgtype.Text{}, nil
+	}
+	minor, err := money.ToMinor(price)
+	if err != nil {
+		return pgtype.Int8{}, pgtype.Text{}, validation.Invalid(field, "%v", err)
+	}
+	return pgtype.Int8{Int64: minor, Valid: true}, pgtype.Text{String: price.GetCurrencyCode(), Valid: true}, nil
+}
+
+// variantConflictError reports a unique violation on product_variants as
+// ALREADY_EXISTS. It returns nil for any other error.
+func variantConflictError(err error, sku string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return nil
+	}
+	if pgErr.ConstraintName == variantOptionsConstraint {
+		return status.Errorf(codes.AlreadyExists, "the product already has a variant with these options")
+	}
+	return status.Errorf(codes.AlreadyExists, "a variant with SKU %q already exists", sku)
+}
+
+func (c *ProductServiceHandler) CreateVariant(ctx context.Context, req *productsv1.CreateVariantRequest) (*productsv1.CreateVariantResponse, error) {
+	ctx, span := c.startSpan(ctx, "CreateVariant.Handler")
+	defer span.End()
+
+	const op = "create_variant"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetProductId() == 0 || req.GetProductId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("product_id", "is not a valid ID")
+	}
+	options, err := parseVariantOptions("options", req.GetOptions())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+	priceMinor, currency, err := parseVariantPrice("price", req.GetPrice())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+	if req.GetStockQuantity() > math.MaxInt32 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("stock_quantity", "is too large")
+	}
+
+	id, err := c.ids.NextID()
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to generate variant ID: %v", err)
+	}
+
+	row, err := c.store(ctx).CreateVariant(ctx, repository.CreateVariantParams{
+		ID:            int64(id),
+		Sku:           req.GetSku(),
+		Options:       options.doc,
+		OptionKey:     options.key,
+		PriceMinor:    priceMinor,
+		Currency:      currency,
+		StockQuantity: int32(req.GetStockQuantity()),
+		ProductID:     int64(req.GetProductId()),
+		MaxVariants:   maxVariants,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.createVariantMissError(ctx, int64(req.GetProductId()))
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if conflict := variantConflictError(err, req.GetSku()); conflict != nil {
+			return nil, conflict
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create variant: %v", err)
+	}
+
+	return &productsv1.CreateVariantResponse{
+		Variant: mapVariantToProto(repository.ProductVariant(row)),
+	}, nil
+}
+
+// createVariantMissError explains why CreateVariant inserted nothing: either
+// the product does not exist or it already has the maximum number of
+// variants.
+func (c *ProductServiceHandler) createVariantMissError(ctx context.Context, productID int64) error {
+	if _, err := c.store(ctx).GetProductByID(ctx, productID); errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "product with ID %d not found", productID)
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to create variant: %v", err)
+	}
+	return status.Errorf(codes.FailedPrecondition, "product %d already has the maximum of %d variants", productID, maxVariants)
+}
+
+func (c *ProductServiceHandler) ListVariants(ctx context.Context, req *productsv1.ListVariantsRequest) (*productsv1.ListVariantsResponse, error) {
+	ctx, span := c.startSpan(ctx, "ListVariants.Handler")
+	defer span.End()
+
+	const op = "list_variants"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetProductId() == 0 || req.GetProductId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("product_id", "is not a valid ID")
+	}
+
+	if _, err := c.store(ctx).GetProductByID(ctx, int64(req.GetProductId())); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "product %d not found", req.GetProductId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get product: %v", err)
+	}
+
+	variants, err := loadVariants(ctx, c.store(ctx), int64(req.GetProductId()))
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, err
+	}
+
+	return &productsv1.ListVariantsResponse{Variants: variants}, nil
+}
+
+func (c *ProductServiceHandler) GetVariantBySku(ctx context.Context, req *productsv1.GetVariantBySkuRequest) (*productsv1.GetVariantBySkuResponse, error) {
+	ctx, span := c.startSpan(ctx, "GetVariantBySku.Handler")
+	defer span.End()
+
+	const op = "get_variant_by_sku"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	variant, err := c.store(ctx).GetVariantBySKU(ctx, req.GetSku())
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "variant with SKU %q not found", req.GetSku())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get variant: %v", err)
+	}
+
+	// Variants of soft-deleted products are hidden along with the product.
+	product, err := c.store(ctx).GetProductByID(ctx, variant.ProductID)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "variant with SKU %q not found", req.GetSku())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get product: %v", err)
+	}
+
+	resp := &productsv1.GetVariantBySkuResponse{
+		Variant: mapVariantToProto(variant),
+		Product: mapDBToProto(product),
+	}
+	if err := c.loadRelations(ctx, c.store(ctx), resp.Product); err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, storeError(err)
+	}
+
+	return resp, nil
+}
+
+func (c *ProductServiceHandler) UpdateVariant(ctx context.Context, req *productsv1.UpdateVariantRequest) (*productsv1.UpdateVariantResponse, error) {
+	ctx, span := c.startSpan(ctx, "UpdateVariant.Handler")
+	defer span.End()
+
+	const op = "update_variant"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	variant := req.GetVariant()
+	if variant.GetId() == 0 || variant.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("variant.id", "is not a valid ID")
+	}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = updatableVariantFields
+	}
+
+	params := repository.UpdateVariantParams{ID: int64(variant.GetId())}
+	for _, path := range paths {
+		var err error
+		switch path {
+		case "sku":
+			if variant.GetSku() == "" {
+				err = validation.Invalid("variant.sku", "is required")
+			}
+			params.SetSku, params.Sku = true, variant.GetSku()
+		case "options":
+			var options variantOptions
+			options, err = parseVariantOptions("variant.options", variant.GetOptions())
+			params.SetOptions, params.Options, params.OptionKey = true, options.doc, options.key
+		case "price":
+			params.SetPrice = true
+			params.PriceMinor, params.Currency, err = parseVariantPrice("variant.price", variant.GetPrice())
+		default:
+			err = validation.Invalid("update_mask", "names %q, which cannot be updated", path)
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return nil, err
+		}
+	}
+
+	updated, err := c.store(ctx).UpdateVariant(ctx, params)
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "variant %d not found", variant.GetId())
+		}
+		if conflict := variantConflictError(err, variant.GetSku()); conflict != nil {
+			return nil, conflict
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update variant: %v", err)
+	}
+
+	return &productsv1.UpdateVariantResponse{
+		Variant: mapVariantToProto(updated),
+	}, nil
+}
+
+func (c *ProductServiceHandler) DeleteVariant(ctx context.Context, req *productsv1.DeleteVariantRequest) (*productsv1.DeleteVariantResponse, error) {
+	ctx, span := c.startSpan(ctx, "DeleteVariant.Handler")
+	defer span.End()
+
+	const op = "delete_variant"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.Duration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	if req.GetId() == 0 || req.GetId() > math.MaxInt64 {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, validation.Invalid("id", "is not a valid ID")
+	}
+
+	deleted, err := c.store(ctx).DeleteVariant(ctx, int64(req.GetId()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, c.deleteVariantMissError(ctx, int64(req.GetId()))
+	}
+	if err != nil {
+		c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+		return nil, status.Errorf(codes.Internal, "failed to delete variant: %v", err)
+	}
+
+	return &productsv1.DeleteVariantResponse{
+		Variant: mapVariantToProto(deleted),
+	}, nil
+}
+
+// deleteVariantMissError explains why DeleteVariant matched no row: either
+// the variant does not exist or it still has stock or pending holds.
+func (c *ProductServiceHandler) deleteVariantMissError(ctx context.Context, id int64) error {
+	variant, err := c.store(ctx).GetVariant(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "variant %d not found", id)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to delete variant: %v", err)
+	}
+	if variant.StockQuantity > 0 {
+		return status.Errorf(codes.FailedPrecondition, "variant %d still has %d units in stock", id, variant.StockQuantity)
+	}
+	return status.Errorf(codes.FailedPrecondition, "variant %d has pending reservations", id)
+}
+
+// loadVariants returns the variants of a product in creation order.
+func loadVariants(ctx context.Context, q store.ProductStore, productID int64) ([]*productsv1.ProductVariant, error) {
+	rows, err := q.ListVariants(ctx, productID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load product variants: %v", err)
+	}
+	variants := make([]*productsv1.ProductVariant, 0, len(rows))
+	for _, v := range rows {
+		variants = append(variants, mapVariantToProto(v))
+	}
+	return variants, nil
+}
+
+func mapVariantToProto(v repository.ProductVariant) *productsv1.ProductVariant {
+	variant := &productsv1.ProductVariant{
+		Id:            uint64(v.ID),
+		ProductId:     uint64(v.ProductID),
+		Sku:           v.Sku,
+		StockQuantity: uint32(v.StockQuantity),
+		CreatedAt:     timestamppb.New(v.CreatedAt),
+		UpdatedAt:     timestamppb.New(v.UpdatedAt),
+	}
+	// options is written by parseVariantOptions, so it always decodes.
+	_ = json.Unmarshal(v.Options, &variant.Options)
+	if v.PriceMinor.Valid && v.Currency.Valid {
+		variant.Price = money.FromMinor(v.Currency.String, v.PriceMinor.Int64)
+	}
+	return variant
+}
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// variantRow is a product_variants row of product 1 without a price
+// override.
+func variantRow(id int64, sku, options string, stock int32) *fakeRow {
+	now := time.Now()
+	return &fakeRow{values: []any{
+		id, int64(1), sku, []byte(options), options, pgtype.Int8{}, pgtype.Text{}, stock, now, now,
+	}}
+}
+
+func TestParseVariantOptions(t *testing.T) {
+	a, err := parseVariantOptions("options", map[string]string{"size": "M", "color": "red"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"color":"red","size":"M"}`, a.key)
+
+	empty, err := parseVariantOptions("options", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", empty.key)
+
+	tooMany := map[string]string{}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"} {
+		tooMany[k] = "x"
+	}
+	for _, options := range []map[string]string{
+		{"size": ""},
+		{"": "M"},
+		{"size": string(make([]byte, maxOptionLength+1))},
+		tooMany,
+	} {
+		_, err := parseVariantOptions("options", options)
+		assert.Error(t, err, "options %v", options)
+	}
+}
+
+func TestProductServiceHandler_CreateVariant(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{variantRow(5, "TS-RED-M", `{"color":"red","size":"M"}`, 10)}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.CreateVariant(context.Background(), &productsv1.CreateVariantRequest{
+		ProductId:     1,
+		Sku:           "TS-RED-M",
+		Options:       map[string]string{"size": "M", "color": "red"},
+		Price:         usd(1299),
+		StockQuantity: 10,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"color": "red", "size": "M"}, resp.GetVariant().GetOptions())
+	assert.Equal(t, uint32(10), resp.GetVariant().GetStockQuantity())
+	// id, sku, options, option key, price, currency, stock, product id, max
+	require.Len(t, db.args, 9)
+	assert.Equal(t, `{"color":"red","size":"M"}`, db.args[3])
+	assert.Equal(t, pgtype.Int8{Int64: 1299, Valid: true}, db.args[4])
+	assert.Equal(t, pgtype.Text{String: "USD", Valid: true}, db.args[5])
+}
+
+func TestProductServiceHandler_CreateVariant_InvalidArgument(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *productsv1.CreateVariantRequest
+	}{
+		{"missing product", &productsv1.CreateVariantRequest{Sku: "A1"}},
+		{"empty sku", &productsv1.CreateVariantRequest{ProductId: 1}},
+		{"bad sku", &productsv1.CreateVariantRequest{ProductId: 1, Sku: "-A1"}},
+		{"empty option", &productsv1.CreateVariantRequest{ProductId: 1, Sku: "A1", Options: map[string]string{"size": ""}}},
+		{"zero price", &productsv1.CreateVariantRequest{ProductId: 1, Sku: "A1", Price: usd(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(t, &fakeDB{})
+
+			_, err := validated(context.Background(), tt.req, handler.CreateVariant)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_CreateVariant_Conflicts(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+	}{
+		{"sku", "product_variants_sku_key", `SKU "A1"`},
+		{"options", variantOptionsConstraint, "these options"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{err: &pgconn.PgError{Code: uniqueViolation, ConstraintName: tt.constraint}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.CreateVariant(context.Background(), &productsv1.CreateVariantRequest{ProductId: 1, Sku: "A1"})
+			assert.Equal(t, codes.AlreadyExists, status.Code(err))
+			assert.Contains(t, status.Convert(err).Message(), tt.want)
+		})
+	}
+}
+
+func TestProductServiceHandler_CreateVariant_Misses(t *testing.T) {
+	tests := []struct {
+		name    string
+		product *fakeRow
+		want    codes.Code
+	}{
+		{"product not found", &fakeRow{err: pgx.ErrNoRows}, codes.NotFound},
+		{"too many variants", &fakeRow{product: repository.Product{ID: 1}}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.product}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.CreateVariant(context.Background(), &productsv1.CreateVariantRequest{ProductId: 1, Sku: "A1"})
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestProductServiceHandler_GetProduct_LoadsVariants(t *testing.T) {
+	db := &fakeDB{
+		product: repository.Product{ID: 1, Name: "T-shirt", PriceMinor: 999, Currency: "USD"},
+		lookups: map[string][]*fakeRow{
+			"ListVariants": {variantRow(5, "TS-S", `{"size":"S"}`, 3), variantRow(6, "TS-M", `{"size":"M"}`, 0)},
+		},
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.GetProduct(context.Background(), &productsv1.GetProductRequest{Id: 1})
+	require.NoError(t, err)
+
+	variants := resp.GetProduct().GetVariants()
+	require.Len(t, variants, 2)
+	assert.Equal(t, "TS-S", variants[0].GetSku())
+	assert.Equal(t, map[string]string{"size": "M"}, variants[1].GetOptions())
+	assert.Nil(t, variants[0].GetPrice())
+}
+
+func TestProductServiceHandler_GetVariantBySku(t *testing.T) {
+	db := &fakeDB{
+		queue:   []*fakeRow{variantRow(5, "TS-S", `{"size":"S"}`, 3)},
+		product: repository.Product{ID: 1, Name: "T-shirt", PriceMinor: 999, Currency: "USD"},
+	}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.GetVariantBySku(context.Background(), &productsv1.GetVariantBySkuRequest{Sku: "TS-S"})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(5), resp.GetVariant().GetId())
+	assert.Equal(t, "T-shirt", resp.GetProduct().GetName())
+}
+
+func TestProductServiceHandler_UpdateVariant_ClearsPrice(t *testing.T) {
+	db := &fakeDB{queue: []*fakeRow{variantRow(5, "TS-S", `{"size":"S"}`, 3)}}
+	handler := newTestHandler(t, db)
+
+	_, err := handler.UpdateVariant(context.Background(), &productsv1.UpdateVariantRequest{
+		Variant:    &productsv1.ProductVariant{Id: 5},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"price"}},
+	})
+	require.NoError(t, err)
+
+	// set_sku, sku, set_options, options, option_key, set_price, price, currency, id
+	require.Len(t, db.args, 9)
+	assert.Equal(t, false, db.args[0])
+	assert.Equal(t, true, db.args[5])
+	assert.Equal(t, pgtype.Int8{}, db.args[6])
+}
+
+func TestProductServiceHandler_UpdateVariant_UnknownField(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	_, err := handler.UpdateVariant(context.Background(), &productsv1.UpdateVariantRequest{
+		Variant:    &productsv1.ProductVariant{Id: 5},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"stock_quantity"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProductServiceHandler_DeleteVariant_Misses(t *testing.T) {
+	tests := []struct {
+		name string
+		get  *fakeRow
+		want string
+	}{
+		{"has stock", variantRow(5, "TS-S", `{"size":"S"}`, 3), "in stock"},
+		{"has holds", variantRow(5, "TS-S", `{"size":"S"}`, 0), "pending reservations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{{err: pgx.ErrNoRows}, tt.get}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.DeleteVariant(context.Background(), &productsv1.DeleteVariantRequest{Id: 5})
+			assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+			assert.Contains(t, status.Convert(err).Message(), tt.want)
+		})
+	}
+}
+
+func TestProductServiceHandler_AdjustStock_Variant(t *testing.T) {
+	now := time.Now()
+	db := &fakeDB{queue: []*fakeRow{{values: []any{
+		int64(9), int64(1), int32(4), "receipt", pgtype.Text{}, pgtype.Text{}, now,
+		pgtype.Int8{Int64: 5, Valid: true}, int32(7),
+	}}}}
+	handler := newTestHandler(t, db)
+
+	resp, err := handler.AdjustStock(context.Background(), &productsv1.AdjustStockRequest{
+		ProductId: 1,
+		VariantId: 5,
+		Delta:     4,
+		Reason:    productsv1.StockMovementReason_STOCK_MOVEMENT_REASON_RECEIPT,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(5), resp.GetMovement().GetVariantId())
+	assert.Equal(t, uint32(7), resp.GetStockQuantity())
+}
+
+func TestProductServiceHandler_ReserveStock_VariantMisses(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant *fakeRow
+		want    codes.Code
+	}{
+		{"unknown variant", &fakeRow{err: pgx.ErrNoRows}, codes.NotFound},
+		{"other product's variant", &fakeRow{values: []any{int64(5), int64(2)}}, codes.NotFound},
+		{"insufficient stock", variantRow(5, "TS-S", `{"size":"S"}`, 1), codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{queue: []*fakeRow{
+				{err: pgx.ErrNoRows},
+				{product: repository.Product{ID: 1}},
+				tt.variant,
+			}}
+			handler := newTestHandler(t, db)
+
+			_, err := handler.ReserveStock(context.Background(), &productsv1.ReserveStockRequest{
+				ProductId: 1,
+				VariantId: 5,
+				Quantity:  2,
+			})
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultWatchPollInterval = time.Second
+	defaultWatchSettle       = time.Second
+	// watchBatchSize bounds the changes read per query while catching up.
+	watchBatchSize = 100
+	// watchCursorQuery fingerprints watch cursors so list page tokens
+	// cannot be replayed as one.
+	watchCursorQuery = "watch"
+)
+
+// watchOptions controls how WatchProducts polls for changes.
+type watchOptions struct {
+	pollInterval time.Duration
+	settle       time.Duration
+}
+
+func newWatchOptions(cfg config.WatchConfig) watchOptions {
+	opts := watchOptions{pollInterval: cfg.PollInterval, settle: cfg.Settle}
+	if opts.pollInterval <= 0 {
+		opts.pollInterval = defaultWatchPollInterval
+	}
+	if opts.settle <= 0 {
+		opts.settle = defaultWatchSettle
+	}
+	return opts
+}
+
+// watchPosition is the (time, id) of the last change sent: the updated_at
+// of a products row, or the created_at of a purge revision.
+type watchPosition struct {
+	updatedAt time.Time
+	id        int64
+}
+
+func (p watchPosition) compare(o watchPosition) int {
+	return cmp.Or(p.updatedAt.Compare(o.updatedAt), cmp.Compare(p.id, o.id))
+}
+
+// productChange is an event waiting to be sent at its position.
+type productChange struct {
+	pos     watchPosition
+	typ     productsv1.ProductEventType
+	product repository.Product
+}
+
+// WatchProducts streams product changes. Changes are read from the products
+// table in (updated_at, id) order, which soft delete makes sufficient to
+// report deletions as well. Purged rows are gone from the table, so their
+// purge revisions are merged into the stream in the same order.
+func (c *ProductServiceHandler) WatchProducts(req *productsv1.WatchProductsRequest, stream grpc.ServerStreamingServer[productsv1.ProductEvent]) error {
+	ctx, span := c.startSpan(stream.Context(), "WatchProducts.Handler")
+	defer span.End()
+
+	const op = "watch_products"
+	timerStart := time.Now()
+
+	defer func() {
+		c.metrics.StreamDuration.
+			WithLabelValues(op, c.backend).
+			Observe(time.Since(timerStart).Seconds())
+	}()
+
+	var pos watchPosition
+	if token := req.GetCursor(); token != "" {
+		cursor, err := c.pageTokens.Decode(token, watchCursorQuery)
+		if err == nil {
+			pos.updatedAt, err = time.Parse(time.RFC3339Nano, cursor.LastKey)
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return validation.Invalid("cursor", "%v", pagination.ErrMalformedToken)
+		}
+		pos.id = cursor.LastID
+	} else {
+		// updated_at is stamped by the database, so a watch starts from
+		// its clock; this server's may be ahead or behind.
+		now, err := c.store(ctx).CurrentTime(ctx)
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return status.Errorf(codes.Internal, "failed to read the database time: %v", err)
+		}
+		pos.updatedAt = now
+	}
+
+	ticker := time.NewTicker(c.watch.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		pos, err = c.sendProductChanges(ctx, stream, pos)
+		if ctx.Err() != nil {
+			// The client went away; there is nobody left to report to.
+			return nil
+		}
+		if err != nil {
+			c.metrics.Errors.WithLabelValues(op, c.backend).Inc()
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendProductChanges streams every settled change after pos and returns the
+// new position.
+func (c *ProductServiceHandler) sendProductChanges(ctx context.Context, stream grpc.ServerStreamingServer[productsv1.ProductEvent], pos watchPosition) (watchPosition, error) {
+	settle := pgtype.Interval{Microseconds: c.watch.settle.Microseconds(), Valid: true}
+	for {
+		products, err := c.store(ctx).ListProductChanges(ctx, repository.ListProductChangesParams{
+			AfterUpdatedAt: pos.updatedAt,
+			AfterID:        pos.id,
+			Settle:         settle,
+			PageLimit:      watchBatchSize,
+		})
+		if err != nil {
+			return pos, status.Errorf(codes.Internal, "failed to read product changes: %v", err)
+		}
+		purges, err := c.store(ctx).ListProductPurges(ctx, repository.ListProductPurgesParams{
+			AfterCreatedAt: pos.updatedAt,
+			AfterProductID: pos.id,
+			Settle:         settle,
+			PageLimit:      watchBatchSize,
+		})
+		if err != nil {
+			return pos, status.Errorf(codes.Internal, "failed to read product purges: %v", err)
+		}
+
+		changes := make([]productChange, 0, len(products)+len(purges))
+		for _, p := range products {
+			changes = append(changes, productChange{
+				pos:     watchPosition{updatedAt: p.UpdatedAt, id: p.ID},
+				typ:     productEventType(p),
+				product: p,
+			})
+		}
+		for _, r := range purges {
+			p, err := decodeSnapshot(r.Snapshot)
+			if err != nil {
+				return pos, status.Errorf(codes.Internal, "failed to read revision %d: %v", r.ID, err)
+			}
+			changes = append(changes, productChange{
+				pos:     watchPosition{updatedAt: r.CreatedAt, id: r.ProductID},
+				typ:     productsv1.ProductEventType_PRODUCT_EVENT_TYPE_PURGED,
+				product: p.Product,
+			})
+		}
+		slices.SortFunc(changes, func(a, b productChange) int { return a.pos.compare(b.pos) })
+
+		// Each source returned its first batch after pos, so the first
+		// batch of the merged changes is complete; anything past it is
+		// read again on the next round.
+		more := len(products) == watchBatchSize || len(purges) == watchBatchSize
+		if len(changes) > watchBatchSize {
+			changes = changes[:watchBatchSize]
+			more = true
+		}
+
+		for _, change := range changes {
+			cursor, err := c.pageTokens.Encode(pagination.Cursor{
+				LastID:  change.pos.id,
+				LastKey: change.pos.updatedAt.UTC().Format(time.RFC3339Nano),
+				Query:   watchCursorQuery,
+			})
+			if err != nil {
+				return pos, status.Errorf(codes.Internal, "failed to build cursor: %v", err)
+			}
+
+			if err := stream.Send(&productsv1.ProductEvent{
+				Type:    change.typ,
+				Product: mapDBToProto(change.product),
+				Cursor:  cursor,
+			}); err != nil {
+				return pos, err
+			}
+			pos = change.pos
+		}
+
+		if !more {
+			return pos, nil
+		}
+	}
+}
+
+// productEventType infers what happened to a product from its current row.
+func productEventType(p repository.Product) productsv1.ProductEventType {
+	switch {
+	case p.DeletedAt.Valid:
+		return productsv1.ProductEventType_PRODUCT_EVENT_TYPE_DELETED
+	case p.Version == 1:
+		return productsv1.ProductEventType_PRODUCT_EVENT_TYPE_CREATED
+	default:
+		return productsv1.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED
+	}
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeWatchStream collects sent events and ends the stream once want
+// events have arrived.
+type fakeWatchStream struct {
+	grpc.ServerStreamingServer[productsv1.ProductEvent]
+	ctx    context.Context
+	cancel context.CancelFunc
+	events []*productsv1.ProductEvent
+	want   int
+}
+
+func newFakeWatchStream(want int) *fakeWatchStream {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	return &fakeWatchStream{ctx: ctx, cancel: cancel, want: want}
+}
+
+func (s *fakeWatchStream) Context() context.Context { return s.ctx }
+
+func (s *fakeWatchStream) Send(ev *productsv1.ProductEvent) error {
+	s.events = append(s.events, ev)
+	if len(s.events) == s.want {
+		s.cancel()
+	}
+	return nil
+}
+
+func TestProductEventType(t *testing.T) {
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_CREATED, productEventType(repository.Product{Version: 1}))
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED, productEventType(repository.Product{Version: 2}))
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_DELETED, productEventType(repository.Product{
+		Version:   3,
+		DeletedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}))
+}
+
+func TestProductServiceHandler_WatchProducts_StreamsAndResumes(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{rows: []*fakeRow{
+		{product: repository.Product{ID: 1, Name: "One", Version: 1, UpdatedAt: base}},
+		{product: repository.Product{ID: 2, Name: "Two", Version: 2, UpdatedAt: base.Add(time.Second)}},
+	}}
+	handler := newTestHandler(t, db)
+
+	stream := newFakeWatchStream(2)
+	require.NoError(t, handler.WatchProducts(&productsv1.WatchProductsRequest{}, stream))
+
+	require.Len(t, stream.events, 2)
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_CREATED, stream.events[0].GetType())
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED, stream.events[1].GetType())
+
+	// Resuming from the first event's cursor restarts right after it.
+	resumeFrom := stream.events[0].GetCursor()
+	db.rows = []*fakeRow{{product: repository.Product{ID: 2, Name: "Two", Version: 2, UpdatedAt: base.Add(time.Second)}}}
+	stream = newFakeWatchStream(1)
+	require.NoError(t, handler.WatchProducts(&productsv1.WatchProductsRequest{Cursor: resumeFrom}, stream))
+
+	require.Len(t, stream.events, 1)
+	assert.Equal(t, base, db.args[0])
+	assert.Equal(t, int64(1), db.args[1])
+}
+
+func TestProductServiceHandler_WatchProducts_StartsAtDatabaseTime(t *testing.T) {
+	clock := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{clock: clock, rows: []*fakeRow{
+		{product: repository.Product{ID: 1, Name: "One", Version: 1, UpdatedAt: clock.Add(time.Second)}},
+	}}
+	handler := newTestHandler(t, db)
+
+	stream := newFakeWatchStream(1)
+	require.NoError(t, handler.WatchProducts(&productsv1.WatchProductsRequest{}, stream))
+
+	assert.Equal(t, clock, db.args[0])
+	assert.Equal(t, int64(0), db.args[1])
+	assert.Equal(t, 1, testutil.CollectAndCount(handler.metrics.StreamDuration))
+	assert.Equal(t, 0, testutil.CollectAndCount(handler.metrics.Duration))
+}
+
+func TestProductServiceHandler_WatchProducts_ReportsPurges(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	snapshot, err := json.Marshal(repository.Product{ID: 7, Name: "Gone", Version: 4, UpdatedAt: base.Add(time.Second)})
+	require.NoError(t, err)
+	db := &fakeDB{
+		rows: []*fakeRow{
+			{product: repository.Product{ID: 1, Name: "One", Version: 1, UpdatedAt: base}},
+			{product: repository.Product{ID: 2, Name: "Two", Version: 2, UpdatedAt: base.Add(2 * time.Second)}},
+		},
+		lookups: map[string][]*fakeRow{"ListProductPurges": {
+			{values: []any{int64(70), int64(7), int64(4), "purge", "system", []byte(nil), snapshot, base.Add(time.Second)}},
+		}},
+	}
+	handler := newTestHandler(t, db)
+
+	stream := newFakeWatchStream(3)
+	require.NoError(t, handler.WatchProducts(&productsv1.WatchProductsRequest{}, stream))
+
+	require.Len(t, stream.events, 3)
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_CREATED, stream.events[0].GetType())
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_PURGED, stream.events[1].GetType())
+	assert.Equal(t, uint64(7), stream.events[1].GetProduct().GetId())
+	assert.Equal(t, productsv1.ProductEventType_PRODUCT_EVENT_TYPE_UPDATED, stream.events[2].GetType())
+}
+
+func TestProductServiceHandler_WatchProducts_InvalidCursor(t *testing.T) {
+	handler := newTestHandler(t, &fakeDB{})
+
+	for _, cursor := range []string{"garbage", mustListToken(t, handler)} {
+		err := handler.WatchProducts(&productsv1.WatchProductsRequest{Cursor: cursor}, newFakeWatchStream(1))
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code(), cursor)
+	}
+}
+
+// mustListToken returns a ListProducts page token, which must not be
+// accepted as a watch cursor.
+func mustListToken(t *testing.T, handler *ProductServiceHandler) string {
+	t.Helper()
+
+	handler.queries = store.NewSQL(nil, repository.New(&fakeDB{list: []repository.Product{{ID: 1}, {ID: 2}}}))
+	resp, err := handler.ListProducts(context.Background(), &productsv1.ListProductsRequest{PageSize: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.GetNextPageToken())
+	return resp.GetNextPageToken()
+}
@@ -8,7 +8,10 @@ import (
 type AppMetrics struct {
 	Stage    prometheus.Gauge
 	Duration *prometheus.HistogramVec
-	Errors   *prometheus.CounterVec
+	// StreamDuration is how long server streams stay open. It is kept
+	// apart from Duration, whose buckets suit unary calls.
+	StreamDuration *prometheus.HistogramVec
+	Errors         *prometheus.CounterVec
 }
 
 type AppMetricsResult struct {
@@ -31,13 +34,19 @@ func NewAppMetrics(p AppMetricsParams) AppMetricsResult {
 			Namespace: "myapp",
 			Name:      "request_duration_seconds",
 		}, []string{"op", "db"}),
+		StreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "myapp",
+			Name:      "stream_duration_seconds",
+			// From a second to about three days.
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"op", "db"}),
 		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "myapp",
 			Name:      "errors_total",
 		}, []string{"op", "db"}),
 	}
 
-	p.Registry.MustRegister(m.Stage, m.Duration, m.Errors)
+	p.Registry.MustRegister(m.Stage, m.Duration, m.StreamDuration, m.Errors)
 
 	return AppMetricsResult{Metrics: m}
 }
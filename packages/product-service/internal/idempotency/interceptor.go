@@ -0,0 +1,287 @@
+// Package idempotency makes mutating RPCs safe to retry. A request carrying
+// an idempotency key is executed once; repeats with the same key replay the
+// stored response.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// MetadataKey is the gRPC metadata key the gateway forwards the
+	// Idempotency-Key header as.
+	MetadataKey = "idempotency-key"
+	// ReplayedHeader is set on responses replayed from a previous request.
+	ReplayedHeader = "idempotent-replayed"
+	// maxKeyLength bounds client supplied keys.
+	maxKeyLength = 255
+
+	defaultTTL           = 24 * time.Hour
+	defaultSweepInterval = time.Hour
+	sweepBatchSize       = 1000
+	// retryDelay is suggested to clients whose key is still in flight.
+	retryDelay = time.Second
+)
+
+// mutatingMethods are the RPCs an idempotency key applies to. Keys sent
+// with read-only RPCs are ignored, as are those sent with
+// ConfirmPurgedMedia: confirming a blob twice deletes nothing more.
+var mutatingMethods = map[string]bool{
+	productsv1.ProductService_CreateProduct_FullMethodName:       true,
+	productsv1.ProductService_BatchCreateProducts_FullMethodName: true,
+	productsv1.ProductService_UpdateProduct_FullMethodName:       true,
+	productsv1.ProductService_DeleteProduct_FullMethodName:       true,
+	productsv1.ProductService_UndeleteProduct_FullMethodName:     true,
+	productsv1.ProductService_ReserveStock_FullMethodName:        true,
+	productsv1.ProductService_CommitReservation_FullMethodName:   true,
+	productsv1.ProductService_ReleaseReservation_FullMethodName:  true,
+	productsv1.ProductService_AdjustStock_FullMethodName:         true,
+	productsv1.ProductService_CreateCategory_FullMethodName:      true,
+	productsv1.ProductService_UpdateCategory_FullMethodName:      true,
+	productsv1.ProductService_DeleteCategory_FullMethodName:      true,
+	productsv1.ProductService_CreateVariant_FullMethodName:       true,
+	productsv1.ProductService_UpdateVariant_FullMethodName:       true,
+	productsv1.ProductService_DeleteVariant_FullMethodName:       true,
+	productsv1.ProductService_AddProductMedia_FullMethodName:     true,
+	productsv1.ProductService_UpdateProductMedia_FullMethodName:  true,
+	productsv1.ProductService_ReorderProductMedia_FullMethodName: true,
+	productsv1.ProductService_DeleteProductMedia_FullMethodName:  true,
+	productsv1.ProductService_UpsertExchangeRate_FullMethodName:  true,
+}
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Store     store.ProductStore
+}
+
+// Interceptor stores and replays responses of mutating RPCs by key.
+type Interceptor struct {
+	queries store.ProductStore
+	log     *zap.Logger
+	ttl     time.Duration
+}
+
+// Module exports the idempotency interceptor provider
+// Expired keys are swept while the app runs
+var Module = fx.Module("idempotency",
+	fx.Provide(NewInterceptor),
+)
+
+func NewInterceptor(p Params) *Interceptor {
+	cfg := p.Config.IdempotencyConfig
+	i := &Interceptor{queries: p.Store, log: p.Logger.Named("idempotency"), ttl: cfg.TTL}
+	if i.ttl <= 0 {
+		i.ttl = defaultTTL
+	}
+	interval := cfg.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if _, err := i.queries.DeleteExpiredIdempotencyKeys(ctx, sweepBatchSize); err != nil {
+							i.log.Warn("deleting expired idempotency keys failed", zap.Error(err))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return i
+}
+
+// Unary returns the server interceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := keyFromContext(ctx)
+		if key == "" || !mutatingMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if len(key) > maxKeyLength {
+			return nil, validation.Invalid(MetadataKey, "must be at most %d bytes", maxKeyLength)
+		}
+
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+		hash, err := requestHash(msg)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to hash request: %v", err)
+		}
+
+		// The claim, the mutation and the stored response commit together,
+		// so a crash never leaves a mutation without its response or a
+		// key claimed by a request that did not happen. The handler joins
+		// the transaction through the context.
+		var (
+			claimed    bool
+			resp       any
+			handlerErr error
+		)
+		err = i.queries.InTx(ctx, func(tx store.ProductStore) error {
+			// A retried transaction starts over.
+			claimed, resp, handlerErr = false, nil, nil
+			_, err := tx.ClaimIdempotencyKey(ctx, repository.ClaimIdempotencyKeyParams{
+				Method:         info.FullMethod,
+				IdempotencyKey: key,
+				RequestHash:    hash,
+				Ttl:            pgtype.Interval{Microseconds: i.ttl.Microseconds(), Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("record idempotency key: %w", err)
+			}
+			claimed = true
+
+			resp, handlerErr = handler(store.NewContext(ctx, tx), req)
+			if handlerErr != nil {
+				return handlerErr
+			}
+			return i.complete(ctx, tx, info.FullMethod, key, resp)
+		})
+		switch {
+		case handlerErr != nil:
+			return nil, handlerErr
+		case !claimed && errors.Is(err, pgx.ErrNoRows):
+			return i.replay(ctx, info.FullMethod, key, hash)
+		case err != nil:
+			return nil, status.Errorf(codes.Internal, "failed to %v", err)
+		}
+		return resp, nil
+	}
+}
+
+// replay answers a repeated key from the stored response.
+func (i *Interceptor) replay(ctx context.Context, method, key string, hash []byte) (any, error) {
+	stored, err := i.queries.GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{
+		Method:         method,
+		IdempotencyKey: key,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The first request failed, which rolled its claim back, in between.
+		return nil, apierror.Retry(apierror.Errorf(codes.Unavailable, apierror.ReasonRequestInProgress, nil,
+			"request with idempotency key %q was retried concurrently, try again", key), retryDelay)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load idempotency key: %v", err)
+	}
+
+	if !bytes.Equal(stored.RequestHash, hash) {
+		return nil, apierror.Errorf(codes.InvalidArgument, apierror.ReasonIdempotencyKeyReused, nil,
+			"idempotency key %q was already used with a different request", key)
+	}
+	if stored.Response == nil {
+		return nil, apierror.Retry(apierror.Errorf(codes.Unavailable, apierror.ReasonRequestInProgress, nil,
+			"request with idempotency key %q is still in progress", key), retryDelay)
+	}
+
+	var envelope anypb.Any
+	if err := proto.Unmarshal(stored.Response, &envelope); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode stored response: %v", err)
+	}
+	resp, err := envelope.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode stored response: %v", err)
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(ReplayedHeader, "true")); err != nil {
+		i.log.Debug("failed to set replay header", zap.Error(err))
+	}
+	return resp, nil
+}
+
+// complete stores the response for replay. Responses that are not
+// protobuf messages are not stored, which leaves the key in flight until
+// it expires.
+func (i *Interceptor) complete(ctx context.Context, tx store.ProductStore, method, key string, resp any) error {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	envelope, err := anypb.New(msg)
+	if err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	stored, err := proto.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	if err := tx.CompleteIdempotencyKey(ctx, repository.CompleteIdempotencyKeyParams{
+		Response:       stored,
+		Method:         method,
+		IdempotencyKey: key,
+	}); err != nil {
+		return fmt.Errorf("store response: %w", err)
+	}
+	return nil
+}
+
+func keyFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if vs := md.Get(MetadataKey); len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+func requestHash(req proto.Message) ([]byte, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	return sum[:], nil
+}
@@ -0,0 +1,231 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var createInfo = &grpc.UnaryServerInfo{FullMethod: productsv1.ProductService_CreateProduct_FullMethodName}
+
+func withKey(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, key))
+}
+
+// countingHandler creates a product per call so replays are detectable.
+func countingHandler(calls *int) grpc.UnaryHandler {
+	return func(_ context.Context, req any) (any, error) {
+		*calls++
+		return &productsv1.CreateProductResponse{Product: &productsv1.Product{
+			Id:   uint64(*calls),
+			Name: req.(*productsv1.CreateProductRequest).GetName(),
+		}}, nil
+	}
+}
+
+func newTestInterceptor(s store.ProductStore) *Interceptor {
+	return &Interceptor{queries: s, log: zap.NewNop(), ttl: defaultTTL}
+}
+
+func TestUnary_ReplaysStoredResponse(t *testing.T) {
+	i := newTestInterceptor(store.NewMemory())
+	unary := i.Unary()
+	req := &productsv1.CreateProductRequest{Name: "Widget"}
+
+	calls := 0
+	first, err := unary(withKey("k1"), req, createInfo, countingHandler(&calls))
+	if err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	second, err := unary(withKey("k1"), req, createInfo, countingHandler(&calls))
+	if err != nil {
+		t.Fatalf("second call: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	if !proto.Equal(first.(proto.Message), second.(proto.Message)) {
+		t.Errorf("replayed %v, want %v", second, first)
+	}
+}
+
+func TestUnary_RejectsKeyReuseWithDifferentRequest(t *testing.T) {
+	unary := newTestInterceptor(store.NewMemory()).Unary()
+
+	calls := 0
+	if _, err := unary(withKey("k1"), &productsv1.CreateProductRequest{Name: "Widget"}, createInfo, countingHandler(&calls)); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	_, err := unary(withKey("k1"), &productsv1.CreateProductRequest{Name: "Gadget"}, createInfo, countingHandler(&calls))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestUnary_InFlightKeyIsUnavailable(t *testing.T) {
+	m := store.NewMemory()
+	unary := newTestInterceptor(m).Unary()
+	req := &productsv1.CreateProductRequest{Name: "Widget"}
+	hash, err := requestHash(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A key claimed without a stored response, as a request still running
+	// elsewhere leaves it.
+	if _, err := m.ClaimIdempotencyKey(context.Background(), repository.ClaimIdempotencyKeyParams{
+		Method:         createInfo.FullMethod,
+		IdempotencyKey: "k1",
+		RequestHash:    hash,
+		Ttl:            pgtype.Interval{Microseconds: defaultTTL.Microseconds(), Valid: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	_, err = unary(withKey("k1"), req, createInfo, countingHandler(&calls))
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("code = %v, want Unavailable", status.Code(err))
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times, want 0", calls)
+	}
+}
+
+func TestUnary_FailedRequestRollsBackClaim(t *testing.T) {
+	unary := newTestInterceptor(store.NewMemory()).Unary()
+	req := &productsv1.CreateProductRequest{Name: "Widget"}
+
+	_, err := unary(withKey("k1"), req, createInfo, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Internal, "boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %v, want Internal", status.Code(err))
+	}
+
+	calls := 0
+	if _, err := unary(withKey("k1"), req, createInfo, countingHandler(&calls)); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times on retry, want 1", calls)
+	}
+}
+
+// creatingHandler creates a product through the store of the request, then
+// fails with fail if it is set.
+func creatingHandler(fail error) grpc.UnaryHandler {
+	return func(ctx context.Context, _ any) (any, error) {
+		_, err := store.FromContext(ctx, nil).CreateProduct(ctx, repository.CreateProductParams{
+			ID:         1,
+			Name:       "Widget",
+			PriceMinor: 100,
+			Currency:   "USD",
+			Actor:      "test",
+		})
+		if err != nil {
+			return nil, err
+		}
+		if fail != nil {
+			return nil, fail
+		}
+		return &productsv1.CreateProductResponse{Product: &productsv1.Product{Id: 1}}, nil
+	}
+}
+
+func TestUnary_HandlerJoinsTransaction(t *testing.T) {
+	ctx := context.Background()
+	m := store.NewMemory()
+	unary := newTestInterceptor(m).Unary()
+	req := &productsv1.CreateProductRequest{Name: "Widget"}
+
+	_, err := unary(withKey("k1"), req, createInfo, creatingHandler(status.Error(codes.Internal, "boom")))
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("code = %v, want Internal", status.Code(err))
+	}
+	if _, err := m.GetProductByID(ctx, 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("product of the failed request: error = %v, want pgx.ErrNoRows", err)
+	}
+
+	if _, err := unary(withKey("k1"), req, createInfo, creatingHandler(nil)); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if _, err := m.GetProductByID(ctx, 1); err != nil {
+		t.Errorf("product of the retry: %v", err)
+	}
+	stored, err := m.GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{Method: createInfo.FullMethod, IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("GetIdempotencyKey: %v", err)
+	}
+	if stored.Response == nil {
+		t.Error("the response was not stored with the product")
+	}
+}
+
+func TestUnary_CoversExchangeRates(t *testing.T) {
+	unary := newTestInterceptor(store.NewMemory()).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: productsv1.ProductService_UpsertExchangeRate_FullMethodName}
+	req := &productsv1.UpsertExchangeRateRequest{Rate: &productsv1.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "EUR"}}
+
+	calls := 0
+	handler := func(context.Context, any) (any, error) {
+		calls++
+		return &productsv1.UpsertExchangeRateResponse{}, nil
+	}
+	for range 2 {
+		if _, err := unary(withKey("k1"), req, info, handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestUnary_IgnoresReadsAndMissingKeys(t *testing.T) {
+	m := store.NewMemory()
+	unary := newTestInterceptor(m).Unary()
+	req := &productsv1.CreateProductRequest{Name: "Widget"}
+
+	calls := 0
+	for range 2 {
+		if _, err := unary(context.Background(), req, createInfo, countingHandler(&calls)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	getInfo := &grpc.UnaryServerInfo{FullMethod: productsv1.ProductService_GetProduct_FullMethodName}
+	if _, err := unary(withKey("k1"), req, getInfo, countingHandler(&calls)); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3", calls)
+	}
+	_, err := m.GetIdempotencyKey(context.Background(), repository.GetIdempotencyKeyParams{Method: getInfo.FullMethod, IdempotencyKey: "k1"})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetIdempotencyKey error = %v, want no key stored", err)
+	}
+}
+
+func TestUnary_RejectsLongKeys(t *testing.T) {
+	unary := newTestInterceptor(store.NewMemory()).Unary()
+
+	calls := 0
+	_, err := unary(withKey(strings.Repeat("k", maxKeyLength+1)), &productsv1.CreateProductRequest{}, createInfo, countingHandler(&calls))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument", status.Code(err))
+	}
+}
@@ -0,0 +1,61 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	token, err := codec.Encode(Cursor{LastID: 12345, Query: "order_by=id"})
+	require.NoError(t, err)
+
+	cur, err := codec.Decode(token, "order_by=id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(12345), cur.LastID)
+}
+
+func TestCodec_RejectsTamperedToken(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	token, err := codec.Encode(Cursor{LastID: 12345})
+	require.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	raw[3] ^= 0xff
+
+	_, err = codec.Decode(base64.RawURLEncoding.EncodeToString(raw), "")
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestCodec_RejectsForeignSecret(t *testing.T) {
+	token, err := NewCodec([]byte("secret")).Encode(Cursor{LastID: 1})
+	require.NoError(t, err)
+
+	_, err = NewCodec([]byte("other")).Decode(token, "")
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestCodec_RejectsQueryMismatch(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	token, err := codec.Encode(Cursor{LastID: 1, Query: "order_by=id"})
+	require.NoError(t, err)
+
+	_, err = codec.Decode(token, "order_by=price")
+	assert.ErrorIs(t, err, ErrQueryMismatch)
+}
+
+func TestCodec_RejectsGarbage(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+
+	for _, token := range []string{"", "not base64!", "AAAA"} {
+		_, err := codec.Decode(token, "")
+		assert.ErrorIs(t, err, ErrMalformedToken, token)
+	}
+}
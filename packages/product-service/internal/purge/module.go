@@ -0,0 +1,123 @@
+// Package purge runs the background job that permanently removes products
+// whose soft delete is older than the configured retention.
+package purge
+
+import (
+	"context"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetention = 30 * 24 * time.Hour
+	defaultInterval  = time.Hour
+	defaultBatchSize = 500
+)
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Store     store.ProductStore
+}
+
+// Job hard deletes soft deleted products past their retention.
+type Job struct {
+	queries   store.ProductStore
+	retention time.Duration
+	batchSize int32
+}
+
+// Module registers the purge job
+// It runs on a fixed interval for as long as the app is up
+var Module = fx.Module("purge",
+	fx.Provide(NewJob),
+	fx.Invoke(func(*Job) {}),
+)
+
+func NewJob(p Params) *Job {
+	cfg := p.Config.PurgeConfig
+	job := &Job{queries: p.Store, retention: cfg.Retention, batchSize: cfg.BatchSize}
+	if job.retention <= 0 {
+		job.retention = defaultRetention
+	}
+	if job.batchSize <= 0 {
+		job.batchSize = defaultBatchSize
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	log := p.Logger.Named("purge")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			log.Info("purge job scheduled",
+				zap.Duration("retention", job.retention),
+				zap.Duration("interval", interval),
+			)
+
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						n, err := job.Run(ctx, time.Now())
+						if err != nil {
+							log.Warn("purging deleted products failed", zap.Error(err))
+						}
+						if n > 0 {
+							log.Info("purged deleted products", zap.Int64("count", n))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return job
+}
+
+// Run removes every product deleted more than the retention before now, in
+// batches, and returns how many were removed.
+func (j *Job) Run(ctx context.Context, now time.Time) (int64, error) {
+	params := repository.PurgeDeletedProductsParams{
+		DeletedBefore: now.Add(-j.retention),
+		BatchSize:     j.batchSize,
+	}
+
+	var total int64
+	for {
+		n, err := j.queries.PurgeDeletedProducts(ctx, params)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(j.batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package purge
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+)
+
+// fakeDB answers each purge statement with the next queued row count.
+type fakeDB struct {
+	repository.DBTX
+	deleted []int64
+	args    [][]any
+}
+
+func (f *fakeDB) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	f.args = append(f.args, args)
+	n := f.deleted[0]
+	f.deleted = f.deleted[1:]
+	return pgconn.NewCommandTag("DELETE " + strconv.FormatInt(n, 10)), nil
+}
+
+func TestJobRun_PurgesInBatches(t *testing.T) {
+	db := &fakeDB{deleted: []int64{2, 2, 1}}
+	job := &Job{queries: store.NewSQL(nil, repository.New(db)), retention: time.Hour, batchSize: 2}
+
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	n, err := job.Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n != 5 {
+		t.Errorf("purged %d products, want 5", n)
+	}
+	if len(db.args) != 3 {
+		t.Fatalf("ran %d statements, want 3", len(db.args))
+	}
+	if got := db.args[0][0].(time.Time); !got.Equal(now.Add(-time.Hour)) {
+		t.Errorf("deleted_before = %v, want %v", got, now.Add(-time.Hour))
+	}
+}
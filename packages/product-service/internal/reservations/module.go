@@ -0,0 +1,134 @@
+// Package reservations holds the stock reservation policy and the
+// background sweeper that returns expired holds to inventory.
+package reservations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTTL            = 15 * time.Minute
+	defaultMaxTTL         = 24 * time.Hour
+	defaultSweepInterval  = 30 * time.Second
+	defaultSweepBatchSize = 500
+)
+
+// ErrTTLTooLong is returned for requested holds longer than the policy allows.
+var ErrTTLTooLong = errors.New("reservation ttl exceeds maximum")
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Store     store.ProductStore
+}
+
+// Policy bounds how long stock may be held.
+type Policy struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// Module exports the reservation policy provider
+// Expired reservations are swept back into stock while the app runs
+var Module = fx.Module("reservations",
+	fx.Provide(NewPolicy),
+)
+
+func NewPolicy(p Params) *Policy {
+	cfg := p.Config.StockConfig
+	policy := &Policy{DefaultTTL: cfg.ReservationTTL, MaxTTL: cfg.MaxReservationTTL}
+	if policy.DefaultTTL <= 0 {
+		policy.DefaultTTL = defaultTTL
+	}
+	if policy.MaxTTL <= 0 {
+		policy.MaxTTL = defaultMaxTTL
+	}
+	interval := cfg.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+	batchSize := cfg.SweepBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultSweepBatchSize
+	}
+
+	log := p.Logger.Named("reservations")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						n, err := Sweep(ctx, p.Store, batchSize)
+						if err != nil {
+							log.Warn("expiring stock reservations failed", zap.Error(err))
+						}
+						if n > 0 {
+							log.Info("expired stock reservations", zap.Int64("count", n))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return policy
+}
+
+// TTL resolves the hold duration for a request; zero selects the default.
+func (p *Policy) TTL(requested time.Duration) (time.Duration, error) {
+	if requested == 0 {
+		return p.DefaultTTL, nil
+	}
+	if requested < 0 {
+		return 0, fmt.Errorf("reservation ttl must be positive")
+	}
+	if requested > p.MaxTTL {
+		return 0, fmt.Errorf("%w of %s", ErrTTLTooLong, p.MaxTTL)
+	}
+	return requested, nil
+}
+
+// Sweep expires overdue reservations in batches until none remain and
+// returns how many were expired.
+func Sweep(ctx context.Context, queries store.ProductStore, batchSize int32) (int64, error) {
+	var total int64
+	for {
+		n, err := queries.ExpireStockReservations(ctx, batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
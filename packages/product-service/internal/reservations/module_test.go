@@ -0,0 +1,24 @@
+package reservations
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyTTL(t *testing.T) {
+	p := &Policy{DefaultTTL: 15 * time.Minute, MaxTTL: time.Hour}
+
+	if got, err := p.TTL(0); err != nil || got != 15*time.Minute {
+		t.Fatalf("TTL(0) = %v, %v; want default", got, err)
+	}
+	if got, err := p.TTL(time.Hour); err != nil || got != time.Hour {
+		t.Fatalf("TTL(1h) = %v, %v; want 1h", got, err)
+	}
+	if _, err := p.TTL(-time.Second); err == nil {
+		t.Fatal("TTL(-1s) succeeded; want error")
+	}
+	if _, err := p.TTL(2 * time.Hour); !errors.Is(err, ErrTTLTooLong) {
+		t.Fatalf("TTL(2h) error = %v; want ErrTTLTooLong", err)
+	}
+}
@@ -10,8 +10,11 @@ import (
 	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/idempotency"
 
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/validation"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -31,6 +34,7 @@ type Params struct {
 	Config         *config.Config
 	ProductService productsv1.ProductServiceServer
 	Metrics        *grpcprom.ServerMetrics
+	Idempotency    *idempotency.Interceptor
 }
 
 // Module exports the gRPC server provider
@@ -59,6 +63,24 @@ func loggingUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
 	}
 }
 
+// loggingStreamInterceptor logs only streams that end with an error
+func loggingStreamInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+
+		if err != nil {
+			st, _ := status.FromError(err)
+			logger.Error("gRPC stream failed",
+				zap.String("method", info.FullMethod),
+				zap.String("error", err.Error()),
+				zap.String("code", st.Code().String()),
+			)
+		}
+
+		return err
+	}
+}
+
 func NewServer(p Params) *grpc.Server {
 
 	// handle Panics
@@ -66,14 +88,30 @@ func NewServer(p Params) *grpc.Server {
 		return status.Errorf(codes.Internal, "panic: %v\n%s", pan, debug.Stack())
 
 	}
+	logger := p.Logger.Named("grpc_server")
+
 	// Create server with logging interceptor
 	s := grpc.NewServer(
 		// otelgrpc stats
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
 		grpc.ChainUnaryInterceptor(
-			loggingUnaryInterceptor(p.Logger.Named("grpc_server")),
+			loggingUnaryInterceptor(logger),
 			p.Metrics.UnaryServerInterceptor(),
+			// Outside recovery so recovered panics get error details too
+			apierror.UnaryServerInterceptor(),
 			recovery.UnaryServerInterceptor(recovery.WithRecoveryHandler(panicHandler)),
+			// Rejects requests breaking the rules declared in products.proto
+			validation.UnaryServerInterceptor(),
+			// Innermost so replayed responses are still logged and measured
+			p.Idempotency.Unary(),
+		),
+		// Same chain for streaming RPCs such as WatchProducts
+		grpc.ChainStreamInterceptor(
+			loggingStreamInterceptor(logger),
+			p.Metrics.StreamServerInterceptor(),
+			apierror.StreamServerInterceptor(),
+			recovery.StreamServerInterceptor(recovery.WithRecoveryHandler(panicHandler)),
+			validation.StreamServerInterceptor(),
 		),
 	)
 
@@ -0,0 +1,107 @@
+package suggest
+
+import (
+	"context"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMaxResults      = 10
+	defaultRefreshInterval = 5 * time.Minute
+)
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Store     store.ProductStore
+}
+
+// Index is the product name prefix index together with its serving limits.
+type Index struct {
+	*Trie
+	// MaxResults caps the number of suggestions per request.
+	MaxResults int
+}
+
+// Module exports the suggestion index provider
+// The index is loaded on start and periodically rebuilt from the database
+var Module = fx.Module("suggest",
+	fx.Provide(NewIndex),
+)
+
+func NewIndex(p Params) *Index {
+	cfg := p.Config.SuggestConfig
+	idx := &Index{Trie: NewTrie(), MaxResults: cfg.MaxResults}
+	if idx.MaxResults <= 0 {
+		idx.MaxResults = defaultMaxResults
+	}
+	interval := cfg.RefreshInterval
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	log := p.Logger.Named("suggest")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(startCtx context.Context) error {
+			// A cold index only degrades suggestions, so don't block startup.
+			if err := idx.Refresh(startCtx, p.Store); err != nil {
+				log.Warn("initial suggestion index load failed", zap.Error(err))
+			}
+
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if err := idx.Refresh(ctx, p.Store); err != nil {
+							log.Warn("suggestion index refresh failed", zap.Error(err))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+
+	return idx
+}
+
+// Refresh rebuilds the index from the products table, keeping the writes
+// the handlers made while the table was being read.
+func (idx *Index) Refresh(ctx context.Context, queries store.ProductStore) error {
+	return idx.Reload(func() ([]Suggestion, error) {
+		rows, err := queries.ListProductNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		products := make([]Suggestion, 0, len(rows))
+		for _, r := range rows {
+			products = append(products, Suggestion{ID: r.ID, Name: r.Name})
+		}
+		return products, nil
+	})
+}
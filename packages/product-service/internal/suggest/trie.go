@@ -0,0 +1,237 @@
+// Package suggest serves product name completions from an in-process
+// prefix index kept warm from the products table.
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Suggestion is a product whose name starts with the requested prefix.
+type Suggestion struct {
+	ID   int64
+	Name string
+}
+
+type node struct {
+	children map[rune]*node
+	// ids of the products whose normalized name ends at this node.
+	ids map[int64]struct{}
+}
+
+func newNode() *node {
+	return &node{children: map[rune]*node{}}
+}
+
+// Trie is a thread-safe prefix index over product names. Matching is
+// case-insensitive; results are ordered by name, then id.
+type Trie struct {
+	mu    sync.RWMutex
+	root  *node
+	names map[int64]string
+
+	// reloads counts the Reload calls in flight. While there are any, every
+	// Put and Remove is also appended to pending so that a reload can replay
+	// the writes made after its snapshot was read.
+	reloads int
+	pending []write
+}
+
+// write is a Put, or a Remove when removed is set, recorded during a reload.
+type write struct {
+	id      int64
+	name    string
+	removed bool
+}
+
+// NewTrie returns an empty index.
+func NewTrie() *Trie {
+	return &Trie{root: newNode(), names: map[int64]string{}}
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Put inserts or renames a product.
+func (t *Trie) Put(id int64, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.record(write{id: id, name: name})
+	t.put(id, name)
+}
+
+func (t *Trie) put(id int64, name string) {
+	t.remove(id)
+	t.names[id] = name
+
+	n := t.root
+	for _, r := range normalize(name) {
+		child, ok := n.children[r]
+		if !ok {
+			child = newNode()
+			n.children[r] = child
+		}
+		n = child
+	}
+	if n.ids == nil {
+		n.ids = map[int64]struct{}{}
+	}
+	n.ids[id] = struct{}{}
+}
+
+// Replace atomically swaps the whole index for the given products.
+func (t *Trie) Replace(products []Suggestion) {
+	_ = t.Reload(func() ([]Suggestion, error) { return products, nil })
+}
+
+// Reload atomically swaps the whole index for the products load returns.
+// Puts and Removes made while load runs are applied on top of them, so a
+// snapshot read before a concurrent write does not undo it. The index is
+// left untouched when load fails.
+func (t *Trie) Reload(load func() ([]Suggestion, error)) error {
+	t.mu.Lock()
+	t.reloads++
+	from := len(t.pending)
+	t.mu.Unlock()
+
+	products, err := load()
+
+	fresh := NewTrie()
+	if err == nil {
+		for _, p := range products {
+			fresh.put(p.ID, p.Name)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err == nil {
+		for _, w := range t.pending[from:] {
+			if w.removed {
+				fresh.remove(w.id)
+			} else {
+				fresh.put(w.id, w.name)
+			}
+		}
+		t.root, t.names = fresh.root, fresh.names
+	}
+	if t.reloads--; t.reloads == 0 {
+		t.pending = nil
+	}
+	return err
+}
+
+// Remove deletes a product from the index; unknown ids are ignored.
+func (t *Trie) Remove(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.record(write{id: id, removed: true})
+	t.remove(id)
+}
+
+// record logs w for the reloads in flight. Callers hold mu.
+func (t *Trie) record(w write) {
+	if t.reloads > 0 {
+		t.pending = append(t.pending, w)
+	}
+}
+
+func (t *Trie) remove(id int64) {
+	name, ok := t.names[id]
+	if !ok {
+		return
+	}
+	delete(t.names, id)
+
+	// Walk down recording the path so empty branches can be pruned.
+	path := []*node{t.root}
+	key := []rune(normalize(name))
+	n := t.root
+	for _, r := range key {
+		n = n.children[r]
+		if n == nil {
+			return
+		}
+		path = append(path, n)
+	}
+	delete(n.ids, id)
+
+	for i := len(key) - 1; i >= 0; i-- {
+		child := path[i+1]
+		if len(child.ids) > 0 || len(child.children) > 0 {
+			break
+		}
+		delete(path[i].children, key[i])
+	}
+}
+
+// Len returns the number of indexed products.
+func (t *Trie) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.names)
+}
+
+// Suggest returns up to limit products whose name starts with prefix.
+func (t *Trie) Suggest(prefix string, limit int) []Suggestion {
+	if limit <= 0 {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	n := t.root
+	for _, r := range normalize(prefix) {
+		n = n.children[r]
+		if n == nil {
+			return nil
+		}
+	}
+
+	out := make([]Suggestion, 0, limit)
+	t.collect(n, limit, &out)
+	return out
+}
+
+// collect walks the subtree in rune order so shorter and alphabetically
+// earlier names come first.
+func (t *Trie) collect(n *node, limit int, out *[]Suggestion) {
+	if len(n.ids) > 0 {
+		ids := make([]int64, 0, len(n.ids))
+		for id := range n.ids {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if t.names[ids[i]] != t.names[ids[j]] {
+				return t.names[ids[i]] < t.names[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+		for _, id := range ids {
+			if len(*out) == limit {
+				return
+			}
+			*out = append(*out, Suggestion{ID: id, Name: t.names[id]})
+		}
+	}
+
+	keys := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		keys = append(keys, r)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, r := range keys {
+		if len(*out) == limit {
+			return
+		}
+		t.collect(n.children[r], limit, out)
+	}
+}
@@ -0,0 +1,111 @@
+package suggest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrie_Suggest(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(3, "Red Shoes")
+	trie.Put(1, "red scarf")
+	trie.Put(2, "Blue Shoes")
+	trie.Put(4, "Red")
+
+	assert.Equal(t, []Suggestion{
+		{ID: 4, Name: "Red"},
+		{ID: 1, Name: "red scarf"},
+		{ID: 3, Name: "Red Shoes"},
+	}, trie.Suggest("RED", 10))
+
+	assert.Equal(t, []Suggestion{{ID: 4, Name: "Red"}}, trie.Suggest("re", 1))
+	assert.Empty(t, trie.Suggest("green", 10))
+	assert.Empty(t, trie.Suggest("red", 0))
+}
+
+func TestTrie_PutRenamesAndRemovePrunes(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(1, "Widget")
+	trie.Put(1, "Gadget")
+
+	assert.Empty(t, trie.Suggest("wid", 10))
+	assert.Equal(t, []Suggestion{{ID: 1, Name: "Gadget"}}, trie.Suggest("gad", 10))
+
+	trie.Remove(1)
+	trie.Remove(42) // unknown ids are ignored
+
+	assert.Equal(t, 0, trie.Len())
+	assert.Empty(t, trie.root.children)
+}
+
+func TestTrie_SameNameDifferentIDs(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(2, "Lamp")
+	trie.Put(1, "Lamp")
+
+	assert.Equal(t, []Suggestion{{ID: 1, Name: "Lamp"}, {ID: 2, Name: "Lamp"}}, trie.Suggest("la", 10))
+
+	trie.Remove(1)
+	assert.Equal(t, []Suggestion{{ID: 2, Name: "Lamp"}}, trie.Suggest("la", 10))
+}
+
+func TestTrie_Replace(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(1, "Old")
+
+	trie.Replace([]Suggestion{{ID: 2, Name: "New"}})
+
+	assert.Empty(t, trie.Suggest("old", 10))
+	assert.Equal(t, []Suggestion{{ID: 2, Name: "New"}}, trie.Suggest("new", 10))
+}
+
+func TestTrie_ReloadKeepsConcurrentWrites(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(1, "Lamp")
+	trie.Put(2, "Ladder")
+
+	err := trie.Reload(func() ([]Suggestion, error) {
+		snapshot := []Suggestion{{ID: 1, Name: "Lamp"}, {ID: 2, Name: "Ladder"}}
+		// Written after the snapshot was read.
+		trie.Put(3, "Lantern")
+		trie.Remove(2)
+		return snapshot, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{ID: 1, Name: "Lamp"}, {ID: 3, Name: "Lantern"}}, trie.Suggest("la", 10))
+}
+
+func TestTrie_ReloadFailureKeepsIndex(t *testing.T) {
+	trie := NewTrie()
+	trie.Put(1, "Lamp")
+
+	err := trie.Reload(func() ([]Suggestion, error) { return nil, errors.New("boom") })
+
+	assert.Error(t, err)
+	assert.Equal(t, []Suggestion{{ID: 1, Name: "Lamp"}}, trie.Suggest("la", 10))
+}
+
+func TestTrie_ConcurrentAccess(t *testing.T) {
+	trie := NewTrie()
+	var wg sync.WaitGroup
+
+	for i := int64(0); i < 50; i++ {
+		wg.Add(2)
+		go func(id int64) {
+			defer wg.Done()
+			trie.Put(id, "item")
+		}(i)
+		go func() {
+			defer wg.Done()
+			trie.Suggest("it", 5)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, trie.Len())
+}
@@ -5,10 +5,17 @@ import (
 
 	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/controllers"
 	grpcmetrics "github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/grpc-metrics"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/idempotency"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/pagination"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/purge"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/reservations"
 	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/server"
+	"github.com/yaninyzwitty/go-fx-v1/packages/product-service/internal/suggest"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/outbox"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/sonyflake"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/telemetry"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -27,11 +34,20 @@ func main() {
 		// Shared modules (order matters: config first, then dependencies)
 		config.Module,
 		database.Module,
+		store.Module,
 		sonyflake.Module,
 		telemetry.Module,
 		grpcmetrics.Module,
 
 		// Product service modules
+		pagination.Module,
+		suggest.Module,
+		reservations.Module,
+		purge.Module,
+		outbox.Module,
+		// Domain events are logged until a message broker is configured.
+		fx.Provide(fx.Annotate(outbox.NewLogPublisher, fx.As(new(outbox.Publisher)))),
+		idempotency.Module,
 		controllers.Module,
 		server.Module,
 
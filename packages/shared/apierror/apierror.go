@@ -0,0 +1,154 @@
+// Package apierror attaches google.rpc error details to gRPC status errors:
+// an ErrorInfo with a stable reason code on every error, and RetryInfo on
+// errors worth retrying. The gateway turns them into problem responses.
+package apierror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of errors raised by the product service.
+const Domain = "products.v1"
+
+// TraceIDKey is the ErrorInfo metadata key holding the trace id of the
+// failed call.
+const TraceIDKey = "trace_id"
+
+// Reasons for ErrorInfo.reason. Clients switch on them, so they must not
+// change once released. Errors without a more specific reason use the
+// upper-case name of their status code, e.g. NOT_FOUND.
+const (
+	ReasonValidationFailed     = "VALIDATION_FAILED"
+	ReasonEtagMismatch         = "ETAG_MISMATCH"
+	ReasonInsufficientStock    = "INSUFFICIENT_STOCK"
+	ReasonRequestInProgress    = "REQUEST_IN_PROGRESS"
+	ReasonIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// defaultRetryDelay is suggested for UNAVAILABLE errors that carry no
+// RetryInfo of their own.
+const defaultRetryDelay = time.Second
+
+// Errorf returns a status error carrying an ErrorInfo with reason and the
+// given metadata, which may be nil.
+func Errorf(code codes.Code, reason string, metadata map[string]string, format string, args ...any) error {
+	st := status.New(code, fmt.Sprintf(format, args...))
+	return withDetails(st, &errdetails.ErrorInfo{Reason: reason, Domain: Domain, Metadata: metadata}).Err()
+}
+
+// Retry adds a RetryInfo suggesting the client waits delay before retrying.
+// Errors that are not status errors are returned unchanged.
+func Retry(err error, delay time.Duration) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return withDetails(st, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}).Err()
+}
+
+// ReasonFor returns the default reason of a status code.
+func ReasonFor(code codes.Code) string {
+	var b strings.Builder
+	for i, r := range code.String() {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// Enrich completes the details of a status error: it adds an ErrorInfo
+// with the default reason when there is none, records the trace id of ctx
+// in it, and suggests a retry delay for UNAVAILABLE errors.
+func Enrich(ctx context.Context, err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+
+	sp := st.Proto()
+	var info *errdetails.ErrorInfo
+	infoAt, hasRetry := -1, false
+	for i, d := range sp.GetDetails() {
+		switch {
+		case d.MessageIs(&errdetails.ErrorInfo{}):
+			info = &errdetails.ErrorInfo{}
+			if d.UnmarshalTo(info) == nil {
+				infoAt = i
+			}
+		case d.MessageIs(&errdetails.RetryInfo{}):
+			hasRetry = true
+		}
+	}
+
+	if infoAt < 0 {
+		info = &errdetails.ErrorInfo{Reason: ReasonFor(st.Code()), Domain: Domain}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		if info.Metadata == nil {
+			info.Metadata = map[string]string{}
+		}
+		info.Metadata[TraceIDKey] = sc.TraceID().String()
+	}
+	packed, perr := anypb.New(info)
+	if perr != nil {
+		return err
+	}
+	if infoAt < 0 {
+		sp.Details = append(sp.Details, packed)
+	} else {
+		sp.Details[infoAt] = packed
+	}
+
+	if !hasRetry && st.Code() == codes.Unavailable {
+		retry, perr := anypb.New(&errdetails.RetryInfo{RetryDelay: durationpb.New(defaultRetryDelay)})
+		if perr == nil {
+			sp.Details = append(sp.Details, retry)
+		}
+	}
+	return status.FromProto(sp).Err()
+}
+
+// UnaryServerInterceptor enriches the errors of unary handlers.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, Enrich(ctx, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor enriches the errors that end streams.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return Enrich(ss.Context(), err)
+		}
+		return nil
+	}
+}
+
+// withDetails appends details to st, keeping st when they cannot be
+// encoded.
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) *status.Status {
+	if detailed, err := st.WithDetails(details...); err == nil {
+		return detailed
+	}
+	return st
+}
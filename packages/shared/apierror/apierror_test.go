@@ -0,0 +1,101 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// details splits the details of a status error by type.
+func details(t *testing.T, err error) (*errdetails.ErrorInfo, *errdetails.RetryInfo) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("%v is not a status error", err)
+	}
+	var info *errdetails.ErrorInfo
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	return info, retry
+}
+
+func TestReasonFor(t *testing.T) {
+	tests := map[codes.Code]string{
+		codes.NotFound:           "NOT_FOUND",
+		codes.InvalidArgument:    "INVALID_ARGUMENT",
+		codes.FailedPrecondition: "FAILED_PRECONDITION",
+		codes.Internal:           "INTERNAL",
+	}
+	for code, want := range tests {
+		if got := ReasonFor(code); got != want {
+			t.Errorf("ReasonFor(%v) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestEnrich_AddsDefaultReasonAndTraceID(t *testing.T) {
+	traceID := trace.TraceID{1, 2, 3}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{1},
+	}))
+
+	err := Enrich(ctx, status.Error(codes.NotFound, "product 1 not found"))
+
+	if status.Code(err) != codes.NotFound || status.Convert(err).Message() != "product 1 not found" {
+		t.Fatalf("Enrich changed the status: %v", err)
+	}
+	info, retry := details(t, err)
+	if info.GetReason() != "NOT_FOUND" || info.GetDomain() != Domain {
+		t.Errorf("ErrorInfo = %v, want reason NOT_FOUND in %s", info, Domain)
+	}
+	if got := info.GetMetadata()[TraceIDKey]; got != traceID.String() {
+		t.Errorf("trace id = %q, want %q", got, traceID.String())
+	}
+	if retry != nil {
+		t.Errorf("RetryInfo = %v, want none", retry)
+	}
+}
+
+func TestEnrich_KeepsSpecificReason(t *testing.T) {
+	err := Errorf(codes.Aborted, ReasonEtagMismatch, map[string]string{"etag": "3"}, "etag mismatch")
+
+	info, _ := details(t, Enrich(context.Background(), err))
+	if info.GetReason() != ReasonEtagMismatch || info.GetMetadata()["etag"] != "3" {
+		t.Errorf("ErrorInfo = %v, want the original one", info)
+	}
+}
+
+func TestEnrich_SuggestsRetryForUnavailable(t *testing.T) {
+	_, retry := details(t, Enrich(context.Background(), status.Error(codes.Unavailable, "try again")))
+	if retry.GetRetryDelay().AsDuration() != defaultRetryDelay {
+		t.Errorf("retry delay = %v, want %v", retry.GetRetryDelay().AsDuration(), defaultRetryDelay)
+	}
+
+	err := Retry(Errorf(codes.Unavailable, ReasonRequestInProgress, nil, "in progress"), 5*time.Second)
+	_, retry = details(t, Enrich(context.Background(), err))
+	if retry.GetRetryDelay().AsDuration() != 5*time.Second {
+		t.Errorf("retry delay = %v, want 5s", retry.GetRetryDelay().AsDuration())
+	}
+}
+
+func TestEnrich_IgnoresOtherErrors(t *testing.T) {
+	plain := errors.New("boom")
+	if err := Enrich(context.Background(), plain); err != plain {
+		t.Errorf("Enrich(%v) = %v, want it unchanged", plain, err)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -10,17 +11,46 @@ import (
 )
 
 type Config struct {
-	DbConfig     DbConfig     `yaml:"database"`
-	ServerConfig ServerConfig `yaml:"server"`
+	DbConfig          DbConfig          `yaml:"database"`
+	ServerConfig      ServerConfig      `yaml:"server"`
+	SuggestConfig     SuggestConfig     `yaml:"suggest"`
+	StockConfig       StockConfig       `yaml:"stock"`
+	PurgeConfig       PurgeConfig       `yaml:"purge"`
+	WatchConfig       WatchConfig       `yaml:"watch"`
+	IdempotencyConfig IdempotencyConfig `yaml:"idempotency"`
+	ExchangeConfig    ExchangeConfig    `yaml:"exchange"`
+	MediaConfig       MediaConfig       `yaml:"media"`
+	OutboxConfig      OutboxConfig      `yaml:"outbox"`
 }
 
 type DbConfig struct {
+	// Driver selects the storage backend: "postgres" (the default) for
+	// CockroachDB, or "sqlite" for a single file database during local
+	// development.
+	Driver   string `yaml:"driver"`
 	Username string `yaml:"username"`
 	Host     string `yaml:"host"`
 	Database string `yaml:"database"`
 	Port     int    `yaml:"port"`
 	SslMode  string `yaml:"sslmode"`
 	Password string `yaml:"password"`
+	// Path is the SQLite database file, created on first start. Only used
+	// by the sqlite driver.
+	Path string `yaml:"path"`
+	// TxRetry bounds how transactions CockroachDB aborts with a
+	// serialization failure are retried.
+	TxRetry TxRetryConfig `yaml:"tx_retry"`
+}
+
+type TxRetryConfig struct {
+	// MaxRetries is how many times a transaction is retried after its
+	// first attempt. Zero uses the default; a negative value disables
+	// retries.
+	MaxRetries int `yaml:"max_retries"`
+	// MinBackoff and MaxBackoff bound the jittered exponential delay
+	// between attempts.
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
 }
 
 type ServerConfig struct {
@@ -29,6 +59,90 @@ type ServerConfig struct {
 	GatewayPort        int    `yaml:"gateway_port"`
 	ProductServicePort int    `yaml:"product_service_port"`
 	PromHTTPAddr       int    `yaml:"prom_http_addr"`
+	PageTokenSecret    string `yaml:"page_token_secret"`
+}
+
+type SuggestConfig struct {
+	// MaxResults caps the number of suggestions returned per request.
+	MaxResults int `yaml:"max_results"`
+	// RefreshInterval is how often the prefix index is rebuilt from the
+	// database to pick up changes made by other replicas.
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+type StockConfig struct {
+	// ReservationTTL is the hold duration used when a request omits one.
+	ReservationTTL time.Duration `yaml:"reservation_ttl"`
+	// MaxReservationTTL caps client requested hold durations.
+	MaxReservationTTL time.Duration `yaml:"max_reservation_ttl"`
+	// SweepInterval is how often expired reservations are released.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+	// SweepBatchSize bounds the reservations expired per statement.
+	SweepBatchSize int32 `yaml:"sweep_batch_size"`
+}
+
+type PurgeConfig struct {
+	// Retention is how long soft deleted products can be undeleted before
+	// they are removed for good.
+	Retention time.Duration `yaml:"retention"`
+	// Interval is how often the purge job runs.
+	Interval time.Duration `yaml:"interval"`
+	// BatchSize bounds the products removed per statement.
+	BatchSize int32 `yaml:"batch_size"`
+}
+
+type WatchConfig struct {
+	// PollInterval is how often WatchProducts streams look for changes.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// Settle holds back changes younger than this so that transactions
+	// committing out of order are not skipped.
+	Settle time.Duration `yaml:"settle"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a stored response is replayed for its key.
+	TTL time.Duration `yaml:"ttl"`
+	// SweepInterval is how often expired keys are deleted.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+type ExchangeConfig struct {
+	// Rounding decides how converted display prices that fall between two
+	// minor units are rounded: half_even (default), half_up, down or up.
+	Rounding string `yaml:"rounding"`
+}
+
+type MediaConfig struct {
+	// Storage selects the blob storage uploads are written to. Only "local"
+	// (the default) is supported.
+	Storage string `yaml:"storage"`
+	// LocalDir is the directory local storage keeps blobs in.
+	LocalDir string `yaml:"local_dir"`
+	// MaxUploadBytes caps the size of a single upload.
+	MaxUploadBytes int64 `yaml:"max_upload_bytes"`
+	// BaseURL is prefixed to a blob's storage key to form its URL in
+	// product responses.
+	BaseURL string `yaml:"base_url"`
+	// SweepInterval is how often the gateway deletes the blobs of media
+	// that went with a purged product.
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+type OutboxConfig struct {
+	// PollInterval is how often the relay looks for pending events.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// BatchSize bounds the events published per poll.
+	BatchSize int32 `yaml:"batch_size"`
+	// MinBackoff and MaxBackoff bound the exponential delay before a
+	// failed event is retried.
+	MinBackoff time.Duration `yaml:"min_backoff"`
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+	// Retention is how long published events are kept.
+	Retention time.Duration `yaml:"retention"`
+	// LeaseTTL is how long the lease a relay takes before publishing
+	// lasts. Another relay takes over once a stopped relay's lease
+	// expires.
+	LeaseTTL time.Duration `yaml:"lease_ttl"`
 }
 
 // Module exports the configuration provider
@@ -78,6 +192,7 @@ func NewConfig(log *zap.Logger) (*Config, error) {
 
 	log.Info("configuration loaded successfully",
 		zap.String("path", configPath),
+		zap.String("database_driver", conf.DbConfig.Driver),
 		zap.String("database_host", conf.DbConfig.Host),
 		zap.Int("product_service_port", conf.ServerConfig.ProductServicePort),
 	)
@@ -0,0 +1,29 @@
+-- schemas/ holds the current table definitions that sqlc generates code
+-- from. The files here upgrade a database created from an earlier version of
+-- those schemas, and are applied once, in order.
+
+-- Moves products.price from FLOAT8 to INT8 minor units and validates
+-- currency codes against ISO 4217. Apply schemas/currencies.sql first.
+--
+-- Products whose currency is not a known ISO 4217 code keep a NULL
+-- price_minor, so SET NOT NULL below fails instead of dropping their price;
+-- fix their currency and re-run from the UPDATE.
+
+ALTER TABLE products ADD COLUMN price_minor INT8;
+
+UPDATE products
+SET price_minor = round(products.price * 10 ^ currencies.minor_units)::INT8
+FROM currencies
+WHERE currencies.code = products.currency;
+
+ALTER TABLE products ALTER COLUMN price_minor SET NOT NULL;
+ALTER TABLE products ADD CONSTRAINT products_price_minor_check CHECK (price_minor >= 0);
+
+ALTER TABLE products DROP COLUMN price;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS check_currency;
+ALTER TABLE products ADD CONSTRAINT products_currency_fkey
+  FOREIGN KEY (currency) REFERENCES currencies (code);
+
+CREATE INDEX products_currency_price_idx ON products (currency, price_minor, id);
+CREATE INDEX products_price_idx ON products (price_minor, id);
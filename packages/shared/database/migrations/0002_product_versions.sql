@@ -0,0 +1,22 @@
+-- Adds the version that optimistic concurrency checks, soft deletion, and
+-- the indexes behind listing, search and the change feed to products.
+--
+-- Products with negative stock fail the check below; correct their
+-- stock_quantity and re-run from it.
+
+ALTER TABLE products ADD COLUMN version INT8 NOT NULL DEFAULT 1;
+ALTER TABLE products ADD COLUMN deleted_at TIMESTAMPTZ;
+
+ALTER TABLE products ADD CONSTRAINT check_stock_quantity CHECK (stock_quantity >= 0);
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX products_name_idx ON products (name, id);
+CREATE INDEX products_created_at_idx ON products (created_at, id);
+CREATE INDEX products_in_stock_idx ON products (id) WHERE stock_quantity > 0;
+CREATE INDEX products_updated_at_idx ON products (updated_at, id);
+CREATE INDEX products_deleted_at_idx ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX products_search_idx ON products
+  USING GIN (to_tsvector('english', name || ' ' || COALESCE(description, '')));
+CREATE INDEX products_name_trgm_idx ON products USING GIN (name gin_trgm_ops);
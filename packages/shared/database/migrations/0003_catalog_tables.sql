@@ -0,0 +1,190 @@
+-- Creates the tables for categories and tags, variants, the inventory
+-- ledger, reservations, media, revisions, the outbox, idempotency keys and
+-- exchange rates; schemas/ documents each.
+--
+-- Existing stock is booked in the ledger as an opening balance, and each
+-- existing product gets a baseline revision.
+
+CREATE TABLE categories (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  parent_id INT8 REFERENCES categories (id),
+  name STRING NOT NULL CHECK (name <> ''),
+  path STRING NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX categories_parent_name_idx ON categories (parent_id, name, id);
+
+CREATE TABLE product_categories (
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  category_id INT8 NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+  PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX product_categories_category_idx ON product_categories (category_id, product_id);
+
+CREATE TABLE product_tags (
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  tag STRING NOT NULL CHECK (tag <> '' AND tag = lower(tag)),
+  PRIMARY KEY (product_id, tag)
+);
+
+CREATE INDEX product_tags_tag_idx ON product_tags (tag, product_id);
+
+CREATE TABLE product_variants (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  sku STRING NOT NULL UNIQUE CHECK (sku ~ '^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$'),
+  options JSONB NOT NULL DEFAULT '{}',
+  option_key STRING NOT NULL,
+  price_minor INT8 CHECK (price_minor > 0),
+  currency STRING REFERENCES currencies (code),
+  stock_quantity INT4 NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT product_variants_price_check CHECK ((price_minor IS NULL) = (currency IS NULL)),
+  CONSTRAINT product_variants_options_key UNIQUE (product_id, option_key)
+);
+
+CREATE TABLE stock_movements (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  product_id INT8 NOT NULL,
+  delta INT4 NOT NULL CHECK (delta <> 0),
+  reason STRING NOT NULL
+    CHECK (reason IN ('receipt', 'sale', 'return', 'shrinkage', 'correction')),
+  reference STRING,
+  note STRING,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  variant_id INT8 REFERENCES product_variants (id) ON DELETE SET NULL
+);
+
+CREATE INDEX stock_movements_product_created_idx ON stock_movements (product_id, created_at DESC, id DESC);
+CREATE INDEX stock_movements_variant_idx ON stock_movements (variant_id) WHERE variant_id IS NOT NULL;
+
+CREATE TABLE stock_reservations (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  quantity INT4 NOT NULL CHECK (quantity > 0),
+  status STRING NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'committed', 'released', 'expired')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  variant_id INT8 REFERENCES product_variants (id) ON DELETE SET NULL
+);
+
+CREATE INDEX stock_reservations_pending_expiry_idx ON stock_reservations (expires_at)
+  WHERE status = 'pending';
+CREATE INDEX stock_reservations_variant_pending_idx ON stock_reservations (variant_id)
+  WHERE variant_id IS NOT NULL AND status = 'pending';
+
+CREATE TABLE product_media (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  position INT4 NOT NULL CHECK (position >= 0),
+  storage_key STRING NOT NULL,
+  content_type STRING NOT NULL,
+  checksum_sha256 STRING NOT NULL CHECK (checksum_sha256 ~ '^[0-9a-f]{64}$'),
+  size_bytes INT8 NOT NULL CHECK (size_bytes > 0),
+  width INT4 NOT NULL CHECK (width > 0),
+  height INT4 NOT NULL CHECK (height > 0),
+  alt_text STRING NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT product_media_checksum_key UNIQUE (product_id, checksum_sha256)
+);
+
+CREATE INDEX product_media_product_position_idx ON product_media (product_id, position, id);
+
+CREATE TABLE purged_media_blobs (
+  storage_key STRING PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE product_revisions (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  product_id INT8 NOT NULL,
+  version INT8 NOT NULL,
+  action STRING NOT NULL
+    CHECK (action IN ('create', 'update', 'delete', 'undelete', 'baseline', 'purge', 'stock')),
+  actor STRING NOT NULL,
+  previous JSONB,
+  snapshot JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT product_revisions_version_key UNIQUE (product_id, version)
+);
+
+CREATE INDEX product_revisions_product_created_idx ON product_revisions (product_id, created_at DESC, version DESC);
+CREATE INDEX product_revisions_purge_idx ON product_revisions (created_at, product_id)
+  WHERE action = 'purge';
+
+CREATE FUNCTION product_snapshot(product JSONB, category_ids INT8[], tags STRING[])
+RETURNS JSONB STABLE LANGUAGE SQL AS $$
+  SELECT product || jsonb_build_object(
+    'category_ids', COALESCE(to_jsonb(category_ids), (
+      SELECT COALESCE(jsonb_agg(pc.category_id ORDER BY pc.category_id), '[]')
+      FROM product_categories pc
+      WHERE pc.product_id = (product->>'id')::INT8)),
+    'tags', COALESCE(to_jsonb(tags), (
+      SELECT COALESCE(jsonb_agg(pt.tag ORDER BY pt.tag), '[]')
+      FROM product_tags pt
+      WHERE pt.product_id = (product->>'id')::INT8)))
+$$;
+
+CREATE TABLE outbox (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  aggregate_type STRING NOT NULL,
+  aggregate_id INT8 NOT NULL,
+  aggregate_version INT8 NOT NULL,
+  event_type STRING NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  attempts INT4 NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error STRING,
+  published_at TIMESTAMPTZ,
+  CONSTRAINT outbox_aggregate_version_key UNIQUE (aggregate_type, aggregate_id, aggregate_version)
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (aggregate_type, aggregate_id, aggregate_version)
+  STORING (created_at, next_attempt_at)
+  WHERE published_at IS NULL;
+CREATE INDEX outbox_published_idx ON outbox (published_at)
+  WHERE published_at IS NOT NULL;
+
+CREATE TABLE outbox_leases (
+  name STRING PRIMARY KEY,
+  holder STRING NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE idempotency_keys (
+  method STRING NOT NULL,
+  idempotency_key STRING NOT NULL,
+  request_hash BYTEA NOT NULL,
+  response BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (method, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+CREATE TABLE exchange_rates (
+  base_currency STRING NOT NULL REFERENCES currencies (code),
+  quote_currency STRING NOT NULL REFERENCES currencies (code),
+  rate DECIMAL(24, 12) NOT NULL CHECK (rate > 0),
+  effective_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (base_currency, quote_currency, effective_at),
+  CHECK (base_currency <> quote_currency)
+);
+
+INSERT INTO stock_movements (product_id, delta, reason, note)
+SELECT id, stock_quantity, 'correction', 'opening balance'
+FROM products
+WHERE stock_quantity > 0;
+
+INSERT INTO product_revisions (product_id, version, action, actor, snapshot, created_at)
+SELECT id, version, 'baseline', 'system', product_snapshot(to_jsonb(products), NULL, NULL), updated_at
+FROM products;
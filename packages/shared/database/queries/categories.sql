@@ -0,0 +1,84 @@
+-- name: CreateCategory :one
+-- Returns no row when parent_id names a missing category.
+INSERT INTO categories (id, parent_id, name, path)
+SELECT
+  sqlc.arg(id)::int8,
+  sqlc.narg(parent_id)::int8,
+  sqlc.arg(name)::text,
+  COALESCE((SELECT parent.path FROM categories parent WHERE parent.id = sqlc.narg(parent_id)::int8), '/')
+    || sqlc.arg(id)::int8::text || '/'
+WHERE sqlc.narg(parent_id)::int8 IS NULL
+   OR EXISTS (SELECT 1 FROM categories parent WHERE parent.id = sqlc.narg(parent_id)::int8)
+RETURNING *;
+
+-- name: GetCategory :one
+SELECT * FROM categories
+WHERE id = $1;
+
+-- name: GetCategoryForUpdate :one
+SELECT * FROM categories
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListCategories :many
+-- Children of parent_id (roots when NULL) in (name, id) order.
+SELECT * FROM categories
+WHERE parent_id IS NOT DISTINCT FROM sqlc.narg(parent_id)::int8
+  AND (sqlc.narg(after_name)::text IS NULL
+       OR (name, id) > (sqlc.narg(after_name)::text, sqlc.arg(after_id)::int8))
+ORDER BY name, id
+LIMIT sqlc.arg(page_limit);
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET name       = sqlc.arg(name),
+    parent_id  = sqlc.narg(parent_id),
+    path       = sqlc.arg(path),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: MoveCategoryDescendants :execrows
+-- Rewrites the paths below a moved category from old_path to new_path.
+UPDATE categories
+SET path       = sqlc.arg(new_path)::text || substr(path, length(sqlc.arg(old_path)::text) + 1),
+    updated_at = now()
+WHERE path LIKE sqlc.arg(old_path)::text || '%'
+  AND path <> sqlc.arg(old_path)::text;
+
+-- name: DeleteCategory :one
+-- Only leaf categories can be deleted; their product links go with them.
+DELETE FROM categories
+WHERE categories.id = $1
+  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = $1)
+RETURNING *;
+
+-- name: ClearProductCategories :exec
+DELETE FROM product_categories
+WHERE product_id = $1;
+
+-- name: AddProductCategories :execrows
+-- Ids of missing categories are skipped; callers compare the row count
+-- with the number of ids to detect them.
+INSERT INTO product_categories (product_id, category_id)
+SELECT sqlc.arg(product_id), categories.id
+FROM categories
+WHERE categories.id = ANY(sqlc.arg(category_ids)::int8[]);
+
+-- name: ClearProductTags :exec
+DELETE FROM product_tags
+WHERE product_id = $1;
+
+-- name: AddProductTags :exec
+INSERT INTO product_tags (product_id, tag)
+SELECT sqlc.arg(product_id), unnest(sqlc.arg(tags)::text[]);
+
+-- name: ListCategoryLinks :many
+SELECT product_id, category_id FROM product_categories
+WHERE product_id = ANY(sqlc.arg(product_ids)::int8[])
+ORDER BY product_id, category_id;
+
+-- name: ListProductTags :many
+SELECT product_id, tag FROM product_tags
+WHERE product_id = ANY(sqlc.arg(product_ids)::int8[])
+ORDER BY product_id, tag;
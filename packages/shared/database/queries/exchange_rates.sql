@@ -0,0 +1,16 @@
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (base_currency, quote_currency, effective_at) DO UPDATE
+SET rate = excluded.rate,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetExchangeRate :one
+-- The rate in force now for converting base_currency into quote_currency.
+SELECT * FROM exchange_rates
+WHERE base_currency = $1
+  AND quote_currency = $2
+  AND effective_at <= now()
+ORDER BY effective_at DESC
+LIMIT 1;
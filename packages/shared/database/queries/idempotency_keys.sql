@@ -0,0 +1,29 @@
+-- name: ClaimIdempotencyKey :one
+-- Records a new in-flight request. An expired key is reclaimed; a live one
+-- is left alone and no row is returned.
+INSERT INTO idempotency_keys (method, idempotency_key, request_hash, expires_at)
+VALUES (sqlc.arg(method), sqlc.arg(idempotency_key), sqlc.arg(request_hash), now() + sqlc.arg(ttl)::interval)
+ON CONFLICT (method, idempotency_key) DO UPDATE
+SET request_hash = excluded.request_hash,
+    response     = NULL,
+    created_at   = now(),
+    expires_at   = excluded.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING idempotency_key;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE method = $1 AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response = sqlc.arg(response)
+WHERE method = sqlc.arg(method) AND idempotency_key = sqlc.arg(idempotency_key);
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE (method, idempotency_key) IN (
+  SELECT method, idempotency_key FROM idempotency_keys
+  WHERE expires_at <= now()
+  LIMIT sqlc.arg(batch_size)
+);
@@ -0,0 +1,65 @@
+-- Events are written by the product mutations in products.sql and the
+-- stock changes in stock_movements.sql and stock_reservations.sql.
+
+-- name: ListPendingOutboxEvents :many
+-- Pending events of the aggregates whose oldest pending event is due, in
+-- publish order: aggregates by the age of that event, then each
+-- aggregate's events by version.
+WITH heads AS (
+  SELECT DISTINCT ON (aggregate_type, aggregate_id) aggregate_type, aggregate_id, created_at, next_attempt_at
+  FROM outbox
+  WHERE published_at IS NULL
+  ORDER BY aggregate_type, aggregate_id, aggregate_version
+)
+SELECT outbox.* FROM outbox
+JOIN heads ON heads.aggregate_type = outbox.aggregate_type AND heads.aggregate_id = outbox.aggregate_id
+WHERE outbox.published_at IS NULL
+  AND heads.next_attempt_at <= sqlc.arg(now)::timestamptz
+ORDER BY heads.created_at, outbox.aggregate_type, outbox.aggregate_id, outbox.aggregate_version
+LIMIT sqlc.arg(batch_size);
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = now()
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts        = attempts + 1,
+    last_error      = sqlc.arg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id);
+
+-- name: GetOutboxBacklog :one
+-- The number of pending events and the creation time of the oldest; oldest
+-- is now when nothing is pending.
+SELECT count(*) AS pending,
+       COALESCE(min(created_at), sqlc.arg(now)::timestamptz)::timestamptz AS oldest
+FROM outbox
+WHERE published_at IS NULL;
+
+-- name: AcquireOutboxLease :execrows
+-- Takes the relay lease for holder, or renews it, until ttl from now. No
+-- row is affected while another holder's lease is unexpired.
+INSERT INTO outbox_leases (name, holder, expires_at)
+VALUES ('relay', sqlc.arg(holder), now() + sqlc.arg(ttl)::interval)
+ON CONFLICT (name) DO UPDATE
+SET holder     = excluded.holder,
+    expires_at = excluded.expires_at
+WHERE outbox_leases.holder = excluded.holder OR outbox_leases.expires_at <= now();
+
+-- name: ReleaseOutboxLease :exec
+-- Gives up the relay lease if holder has it, so that another relay can
+-- take it without waiting for it to expire.
+DELETE FROM outbox_leases
+WHERE name = 'relay' AND holder = sqlc.arg(holder);
+
+-- name: DeletePublishedOutboxEvents :execrows
+-- Removes up to batch_size events published before published_before.
+DELETE FROM outbox
+WHERE id IN (
+  SELECT id FROM outbox
+  WHERE published_at < sqlc.arg(published_before)::timestamptz
+  ORDER BY published_at
+  LIMIT sqlc.arg(batch_size)
+);
@@ -0,0 +1,50 @@
+-- name: AddProductMedia :one
+-- Appends media after the product's existing media. No row is returned when
+-- the product is missing or already has max_media items.
+INSERT INTO product_media (id, product_id, position, storage_key, content_type, checksum_sha256, size_bytes, width, height, alt_text)
+SELECT sqlc.arg(id), products.id,
+       (SELECT COALESCE(max(pm.position) + 1, 0) FROM product_media pm WHERE pm.product_id = sqlc.arg(product_id)),
+       sqlc.arg(storage_key), sqlc.arg(content_type), sqlc.arg(checksum_sha256), sqlc.arg(size_bytes),
+       sqlc.arg(width), sqlc.arg(height), sqlc.arg(alt_text)
+FROM products
+WHERE products.id = sqlc.arg(product_id)
+  AND products.deleted_at IS NULL
+  AND (SELECT count(*) FROM product_media pm WHERE pm.product_id = sqlc.arg(product_id)) < sqlc.arg(max_media)::int8
+RETURNING *;
+
+-- name: ListProductMedia :many
+SELECT * FROM product_media
+WHERE product_id = ANY(sqlc.arg(product_ids)::int8[])
+ORDER BY product_id, position, id;
+
+-- name: CountProductMedia :one
+SELECT count(*) FROM product_media
+WHERE product_id = $1;
+
+-- name: UpdateProductMediaAltText :one
+UPDATE product_media
+SET alt_text = sqlc.arg(alt_text)
+WHERE id = sqlc.arg(id) AND product_id = sqlc.arg(product_id)
+RETURNING *;
+
+-- name: ReorderProductMedia :execrows
+-- Sets each media's position to its index in media_ids.
+UPDATE product_media
+SET position = array_position(sqlc.arg(media_ids)::int8[], id) - 1
+WHERE product_id = sqlc.arg(product_id)
+  AND id = ANY(sqlc.arg(media_ids)::int8[]);
+
+-- name: DeleteProductMedia :one
+DELETE FROM product_media
+WHERE id = sqlc.arg(id) AND product_id = sqlc.arg(product_id)
+RETURNING *;
+
+-- name: ListPurgedMediaBlobs :many
+-- Oldest first.
+SELECT storage_key FROM purged_media_blobs
+ORDER BY created_at, storage_key
+LIMIT sqlc.arg(page_limit);
+
+-- name: DeletePurgedMediaBlobs :execrows
+DELETE FROM purged_media_blobs
+WHERE storage_key = ANY(sqlc.arg(storage_keys)::string[]);
@@ -0,0 +1,27 @@
+-- Revisions are written by the product mutations in products.sql.
+
+-- name: ListProductRevisions :many
+-- Newest first; keyset pagination runs on version.
+SELECT * FROM product_revisions
+WHERE product_id = sqlc.arg(product_id)
+  AND (sqlc.narg(before_version)::int8 IS NULL OR version < sqlc.narg(before_version)::int8)
+ORDER BY version DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetProductRevisionAsOf :one
+-- The last revision of a product recorded at or before as_of.
+SELECT * FROM product_revisions
+WHERE product_id = sqlc.arg(product_id)
+  AND created_at <= sqlc.arg(as_of)::timestamptz
+ORDER BY created_at DESC, version DESC
+LIMIT 1;
+
+-- name: ListProductPurges :many
+-- Purge revisions after the (created_at, product_id) cursor, oldest first,
+-- held back by the settle window like ListProductChanges.
+SELECT * FROM product_revisions
+WHERE action = 'purge'
+  AND (created_at, product_id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_product_id)::int8)
+  AND created_at <= now() - sqlc.arg(settle)::interval
+ORDER BY created_at, product_id
+LIMIT sqlc.arg(page_limit);
@@ -1,30 +1,235 @@
--- name: ListProducts :many
-SELECT * FROM products
-ORDER BY id
-LIMIT $1 OFFSET $2;
+-- ListProducts is built dynamically in repository/products_list.go because
+-- its filters and sort order vary per request.
 
 -- name: GetProductByID :one
 SELECT * FROM products
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetProductByIDWithDeleted :one
+SELECT * FROM products
 WHERE id = $1;
 
 -- name: CreateProduct :one
-INSERT INTO products (id, name, description, price, currency, stock_quantity)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING *;
+-- Initial stock is booked in the ledger as a receipt, and the product's
+-- first revision and its product.created event are recorded.
+WITH created AS (
+  INSERT INTO products (id, name, description, price_minor, currency, stock_quantity)
+  VALUES (sqlc.arg(id), sqlc.arg(name), sqlc.arg(description), sqlc.arg(price_minor), sqlc.arg(currency), sqlc.arg(stock_quantity))
+  RETURNING *
+), opening AS (
+  INSERT INTO stock_movements (product_id, delta, reason, note)
+  SELECT created.id, created.stock_quantity, 'receipt', 'initial stock'
+  FROM created
+  WHERE created.stock_quantity > 0
+  RETURNING stock_movements.id
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, snapshot)
+  SELECT created.id, created.version, 'create', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(created), sqlc.narg(category_ids)::int8[], sqlc.narg(tags)::string[])
+  FROM created
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.created',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT * FROM created;
 
--- name: DeleteProduct :exec
-DELETE FROM products
-WHERE id = $1;
+-- name: GetProductsByIDs :many
+SELECT * FROM products
+WHERE id = ANY(sqlc.arg(ids)::int8[])
+  AND deleted_at IS NULL;
+
+-- name: DeleteProduct :one
+-- Soft delete; the row is kept until PurgeDeletedProducts removes it.
+WITH previous AS (
+  SELECT * FROM products
+  WHERE products.id = sqlc.arg(id)
+), deleted AS (
+  UPDATE products
+  SET deleted_at = now(),
+      updated_at = now(),
+      version    = version + 1
+  WHERE id = sqlc.arg(id)
+    AND deleted_at IS NULL
+    AND (sqlc.narg(expected_version)::int8 IS NULL OR version = sqlc.narg(expected_version))
+  RETURNING *
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT deleted.id, deleted.version, 'delete', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(deleted), NULL, NULL)
+  FROM deleted
+  JOIN previous ON previous.id = deleted.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.deleted',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT * FROM deleted;
+
+-- name: UndeleteProduct :one
+WITH previous AS (
+  SELECT * FROM products
+  WHERE products.id = sqlc.arg(id)
+), restored AS (
+  UPDATE products
+  SET deleted_at = NULL,
+      updated_at = now(),
+      version    = version + 1
+  WHERE id = sqlc.arg(id)
+    AND deleted_at IS NOT NULL
+    AND (sqlc.narg(expected_version)::int8 IS NULL OR version = sqlc.narg(expected_version))
+  RETURNING *
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT restored.id, restored.version, 'undelete', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(restored), NULL, NULL)
+  FROM restored
+  JOIN previous ON previous.id = restored.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.undeleted',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT * FROM restored;
+
+-- name: PurgeDeletedProducts :execrows
+-- Hard deletes up to batch_size products soft deleted before deleted_before
+-- and records a purge revision for each, which WatchProducts reports, and
+-- its product.purged event.
+-- Their reservations, variants, media, categories and tags go with them;
+-- their ledger entries and revisions are kept. The blobs of their media are
+-- queued in purged_media_blobs for the gateway to delete.
+WITH purged AS (
+  DELETE FROM products
+  WHERE id IN (
+    SELECT id FROM products
+    WHERE deleted_at < sqlc.arg(deleted_before)::timestamptz
+    ORDER BY deleted_at
+    LIMIT sqlc.arg(batch_size)
+  )
+  RETURNING *
+), blobs AS (
+  INSERT INTO purged_media_blobs (storage_key)
+  SELECT storage_key FROM product_media
+  WHERE product_id IN (SELECT id FROM purged)
+  ON CONFLICT (storage_key) DO NOTHING
+  RETURNING purged_media_blobs.storage_key
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT purged.id, purged.version + 1, 'purge', 'system',
+         product_snapshot(to_jsonb(purged), NULL, NULL),
+         product_snapshot(to_jsonb(purged), NULL, NULL) || jsonb_build_object('updated_at', now(), 'version', purged.version + 1)
+  FROM purged
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+)
+INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+SELECT 'product', revision.product_id, revision.version, 'product.purged',
+       jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+FROM revision;
+
+-- name: ListProductNames :many
+SELECT id, name FROM products
+WHERE deleted_at IS NULL
+ORDER BY id;
 
 -- name: FindProductWithStockInfo :one
 SELECT 
   p.id                AS product_id,
   p.name              AS product_name,
   p.description       AS product_description,
-  p.price             AS product_price,
+  p.price_minor       AS product_price_minor,
   p.currency          AS product_currency,
   p.stock_quantity    AS product_stock_quantity,
   p.created_at        AS product_created_at,
   p.updated_at        AS product_updated_at
 FROM products p
 WHERE p.id = $1;
+
+-- name: UpdateProduct :one
+-- Stock set through an update is booked in the ledger as a correction.
+WITH previous AS (
+  SELECT * FROM products
+  WHERE products.id = sqlc.arg(id)
+), updated AS (
+  UPDATE products
+  SET
+    name           = CASE WHEN sqlc.arg(set_name)::boolean THEN sqlc.arg(name)::text ELSE name END,
+    description    = CASE WHEN sqlc.arg(set_description)::boolean THEN sqlc.narg(description)::text ELSE description END,
+    price_minor    = CASE WHEN sqlc.arg(set_price)::boolean THEN sqlc.arg(price_minor)::int8 ELSE price_minor END,
+    currency       = CASE WHEN sqlc.arg(set_price)::boolean THEN sqlc.arg(currency)::text ELSE currency END,
+    stock_quantity = CASE WHEN sqlc.arg(set_stock_quantity)::boolean THEN sqlc.arg(stock_quantity)::int4 ELSE stock_quantity END,
+    updated_at     = now(),
+    version        = version + 1
+  WHERE id = sqlc.arg(id)
+    AND deleted_at IS NULL
+    AND (sqlc.narg(expected_version)::int8 IS NULL OR version = sqlc.narg(expected_version))
+  RETURNING *
+), corrected AS (
+  INSERT INTO stock_movements (product_id, delta, reason, note)
+  SELECT updated.id, updated.stock_quantity - previous.stock_quantity, 'correction', 'stock set by update'
+  FROM updated
+  JOIN previous ON previous.id = updated.id
+  WHERE updated.stock_quantity <> previous.stock_quantity
+  RETURNING stock_movements.id
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT updated.id, updated.version, 'update', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(updated), sqlc.narg(category_ids)::int8[], sqlc.narg(tags)::string[])
+  FROM updated
+  JOIN previous ON previous.id = updated.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.updated',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT * FROM updated;
+
+-- name: ListProductChanges :many
+-- Products changed after the (updated_at, id) cursor, oldest first. Rows
+-- newer than the settle window are held back so that a transaction
+-- committing late with an earlier updated_at is not skipped.
+SELECT * FROM products
+WHERE (updated_at, id) > (sqlc.arg(after_updated_at)::timestamptz, sqlc.arg(after_id)::int8)
+  AND updated_at <= now() - sqlc.arg(settle)::interval
+ORDER BY updated_at, id
+LIMIT sqlc.arg(page_limit);
+
+-- name: CurrentTime :one
+-- The database clock. Watch positions are compared with updated_at, which
+-- the database sets, so a new watch starts from this time rather than the
+-- server's.
+SELECT now()::timestamptz;
+
+-- name: SearchProducts :many
+-- Ranks by full-text relevance plus trigram similarity of the name so that
+-- misspelled queries still match. Keyset pagination runs on (score, id).
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, score
+FROM (
+  SELECT
+    p.*,
+    (ts_rank(to_tsvector('english', p.name || ' ' || COALESCE(p.description, '')), plainto_tsquery('english', sqlc.arg(query)::text))
+      + similarity(p.name, sqlc.arg(query)::text))::float8 AS score
+  FROM products p
+  WHERE p.deleted_at IS NULL
+    AND (to_tsvector('english', p.name || ' ' || COALESCE(p.description, '')) @@ plainto_tsquery('english', sqlc.arg(query)::text)
+         OR p.name % sqlc.arg(query)::text)
+) ranked
+WHERE sqlc.narg(after_score)::float8 IS NULL
+   OR (score, id) < (sqlc.narg(after_score)::float8, sqlc.arg(after_id)::int8)
+ORDER BY score DESC, id DESC
+LIMIT sqlc.arg(page_limit);
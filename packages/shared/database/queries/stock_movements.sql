@@ -0,0 +1,100 @@
+-- name: AdjustStock :one
+-- Applies a signed stock change and records it in the ledger and as a
+-- stock revision with its product.stock_changed event in one statement. No
+-- row is returned when the product is missing or the change would take
+-- stock below zero.
+WITH previous AS (
+  SELECT * FROM products
+  WHERE products.id = sqlc.arg(product_id)
+), adjusted AS (
+  UPDATE products
+  SET stock_quantity = stock_quantity + sqlc.arg(delta)::int4,
+      updated_at     = now(),
+      version        = version + 1
+  WHERE products.id = sqlc.arg(product_id)
+    AND products.deleted_at IS NULL
+    AND stock_quantity + sqlc.arg(delta)::int4 >= 0
+  RETURNING *
+), movement AS (
+  INSERT INTO stock_movements (id, product_id, delta, reason, reference, note)
+  SELECT sqlc.arg(id), adjusted.id, sqlc.arg(delta)::int4, sqlc.arg(reason), sqlc.narg(reference), sqlc.narg(note)
+  FROM adjusted
+  RETURNING *
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT adjusted.id, adjusted.version, 'stock', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(adjusted), NULL, NULL)
+  FROM adjusted
+  JOIN previous ON previous.id = adjusted.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.stock_changed',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT movement.id, movement.product_id, movement.delta, movement.reason, movement.reference, movement.note, movement.created_at,
+       adjusted.stock_quantity
+FROM movement
+JOIN adjusted ON adjusted.id = movement.product_id;
+
+-- name: ListStockMovements :many
+-- Newest first; keyset pagination runs on (created_at, id).
+SELECT * FROM stock_movements
+WHERE product_id = sqlc.arg(product_id)
+  AND (sqlc.narg(variant_id)::int8 IS NULL OR variant_id = sqlc.narg(variant_id)::int8)
+  AND (sqlc.narg(reason)::text IS NULL OR reason = sqlc.narg(reason)::text)
+  AND (sqlc.narg(before_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(before_created_at)::timestamptz, sqlc.arg(before_id)::int8))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: ReconcileStock :many
+-- Reports products and variants whose stored quantity plus pending holds
+-- disagrees with the ledger. Product rows only count entries and holds
+-- without a variant.
+SELECT
+  p.id                              AS product_id,
+  NULL::int8                        AS variant_id,
+  p.stock_quantity,
+  COALESCE(h.quantity, 0)::int8     AS held_quantity,
+  COALESCE(l.quantity, 0)::int8     AS ledger_quantity
+FROM products p
+LEFT JOIN (
+  SELECT product_id, sum(delta) AS quantity
+  FROM stock_movements
+  WHERE variant_id IS NULL
+  GROUP BY product_id
+) l ON l.product_id = p.id
+LEFT JOIN (
+  SELECT product_id, sum(quantity) AS quantity
+  FROM stock_reservations
+  WHERE status = 'pending' AND variant_id IS NULL
+  GROUP BY product_id
+) h ON h.product_id = p.id
+WHERE p.stock_quantity + COALESCE(h.quantity, 0) <> COALESCE(l.quantity, 0)
+UNION ALL
+SELECT
+  v.product_id,
+  v.id,
+  v.stock_quantity,
+  COALESCE(h.quantity, 0)::int8,
+  COALESCE(l.quantity, 0)::int8
+FROM product_variants v
+LEFT JOIN (
+  SELECT variant_id, sum(delta) AS quantity
+  FROM stock_movements
+  WHERE variant_id IS NOT NULL
+  GROUP BY variant_id
+) l ON l.variant_id = v.id
+LEFT JOIN (
+  SELECT variant_id, sum(quantity) AS quantity
+  FROM stock_reservations
+  WHERE status = 'pending' AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) h ON h.variant_id = v.id
+WHERE v.stock_quantity + COALESCE(h.quantity, 0) <> COALESCE(l.quantity, 0)
+ORDER BY product_id, variant_id NULLS FIRST
+LIMIT sqlc.arg(max_results);
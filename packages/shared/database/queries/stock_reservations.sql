@@ -0,0 +1,162 @@
+-- name: ReserveStock :one
+-- Decrements stock and records the hold and a stock revision with its
+-- product.stock_changed event in one statement. No row is returned when
+-- the product is missing or has insufficient stock.
+WITH previous AS (
+  SELECT * FROM products
+  WHERE products.id = sqlc.arg(product_id)
+), reserved AS (
+  UPDATE products
+  SET stock_quantity = stock_quantity - sqlc.arg(quantity)::int4,
+      updated_at     = now(),
+      version        = version + 1
+  WHERE products.id = sqlc.arg(product_id)
+    AND products.deleted_at IS NULL
+    AND stock_quantity >= sqlc.arg(quantity)::int4
+  RETURNING *
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT reserved.id, reserved.version, 'stock', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(reserved), NULL, NULL)
+  FROM reserved
+  JOIN previous ON previous.id = reserved.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.stock_changed',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+INSERT INTO stock_reservations (id, product_id, quantity, expires_at)
+SELECT sqlc.arg(id), reserved.id, sqlc.arg(quantity)::int4, now() + sqlc.arg(ttl)::interval
+FROM reserved
+RETURNING *;
+
+-- name: GetStockReservation :one
+SELECT * FROM stock_reservations
+WHERE id = $1;
+
+-- name: CommitStockReservation :one
+-- The held stock leaves inventory for good, so the sale is booked in the
+-- ledger.
+WITH committed AS (
+  UPDATE stock_reservations
+  SET status = 'committed', updated_at = now()
+  WHERE stock_reservations.id = $1
+    AND status = 'pending'
+    AND expires_at > now()
+  RETURNING *
+), sold AS (
+  INSERT INTO stock_movements (product_id, variant_id, delta, reason, reference)
+  SELECT committed.product_id, committed.variant_id, -committed.quantity, 'sale', 'reservation:' || committed.id::text
+  FROM committed
+  RETURNING stock_movements.id
+)
+SELECT * FROM committed;
+
+-- name: ReleaseStockReservation :one
+-- Returns the held quantity to the product, or to the variant it was taken
+-- from, and marks the hold released. Stock returned to the product is
+-- recorded as a stock revision with its product.stock_changed event.
+WITH released AS (
+  UPDATE stock_reservations
+  SET status = 'released', updated_at = now()
+  WHERE stock_reservations.id = sqlc.arg(id)
+    AND status = 'pending'
+  RETURNING *
+), previous AS (
+  SELECT * FROM products
+  WHERE products.id IN (SELECT product_id FROM released WHERE variant_id IS NULL)
+), restocked AS (
+  UPDATE products
+  SET stock_quantity = products.stock_quantity + released.quantity,
+      updated_at     = now(),
+      version        = products.version + 1
+  FROM released
+  WHERE products.id = released.product_id
+    AND released.variant_id IS NULL
+  RETURNING products.id, products.name, products.description, products.price_minor, products.currency, products.stock_quantity, products.created_at, products.updated_at, products.version, products.deleted_at
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT restocked.id, restocked.version, 'stock', sqlc.arg(actor)::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(restocked), NULL, NULL)
+  FROM restocked
+  JOIN previous ON previous.id = restocked.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.stock_changed',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+), restocked_variants AS (
+  UPDATE product_variants
+  SET stock_quantity = product_variants.stock_quantity + released.quantity,
+      updated_at     = now()
+  FROM released
+  WHERE product_variants.id = released.variant_id
+  RETURNING product_variants.id
+)
+SELECT id, product_id, quantity, status, expires_at, created_at, updated_at, variant_id FROM released;
+
+-- name: ExpireStockReservations :one
+-- Releases up to batch_size overdue holds and returns how many expired.
+-- Stock returned to a product is recorded as a stock revision by system
+-- with its product.stock_changed event.
+WITH expired AS (
+  UPDATE stock_reservations
+  SET status = 'expired', updated_at = now()
+  WHERE stock_reservations.id IN (
+    SELECT id FROM stock_reservations
+    WHERE status = 'pending' AND expires_at <= now()
+    ORDER BY expires_at
+    LIMIT sqlc.arg(batch_size)
+  )
+  RETURNING product_id, variant_id, quantity
+), totals AS (
+  SELECT product_id, sum(quantity)::int4 AS quantity
+  FROM expired
+  WHERE variant_id IS NULL
+  GROUP BY product_id
+), variant_totals AS (
+  SELECT variant_id, sum(quantity)::int4 AS quantity
+  FROM expired
+  WHERE variant_id IS NOT NULL
+  GROUP BY variant_id
+), restocked_variants AS (
+  UPDATE product_variants
+  SET stock_quantity = product_variants.stock_quantity + variant_totals.quantity,
+      updated_at     = now()
+  FROM variant_totals
+  WHERE product_variants.id = variant_totals.variant_id
+  RETURNING product_variants.id
+), previous AS (
+  SELECT * FROM products
+  WHERE products.id IN (SELECT product_id FROM totals)
+), restocked AS (
+  UPDATE products
+  SET stock_quantity = products.stock_quantity + totals.quantity,
+      updated_at     = now(),
+      version        = products.version + 1
+  FROM totals
+  WHERE products.id = totals.product_id
+  RETURNING products.id, products.name, products.description, products.price_minor, products.currency, products.stock_quantity, products.created_at, products.updated_at, products.version, products.deleted_at
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT restocked.id, restocked.version, 'stock', 'system',
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(restocked), NULL, NULL)
+  FROM restocked
+  JOIN previous ON previous.id = restocked.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.stock_changed',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT count(*) FROM expired;
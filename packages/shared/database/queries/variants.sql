@@ -0,0 +1,100 @@
+-- name: CreateVariant :one
+-- Initial stock is booked in the ledger as a receipt. No row is returned
+-- when the product is missing or already has max_variants variants.
+WITH created AS (
+  INSERT INTO product_variants (id, product_id, sku, options, option_key, price_minor, currency, stock_quantity)
+  SELECT sqlc.arg(id), products.id, sqlc.arg(sku), sqlc.arg(options), sqlc.arg(option_key),
+         sqlc.narg(price_minor), sqlc.narg(currency), sqlc.arg(stock_quantity)
+  FROM products
+  WHERE products.id = sqlc.arg(product_id)
+    AND products.deleted_at IS NULL
+    AND (SELECT count(*) FROM product_variants pv WHERE pv.product_id = sqlc.arg(product_id)) < sqlc.arg(max_variants)::int8
+  RETURNING *
+), opening AS (
+  INSERT INTO stock_movements (product_id, variant_id, delta, reason, note)
+  SELECT created.product_id, created.id, created.stock_quantity, 'receipt', 'initial stock'
+  FROM created
+  WHERE created.stock_quantity > 0
+  RETURNING stock_movements.id
+)
+SELECT * FROM created;
+
+-- name: GetVariant :one
+SELECT * FROM product_variants
+WHERE id = $1;
+
+-- name: GetVariantBySKU :one
+SELECT * FROM product_variants
+WHERE sku = $1;
+
+-- name: ListVariants :many
+SELECT * FROM product_variants
+WHERE product_id = $1
+ORDER BY id;
+
+-- name: CountVariants :one
+SELECT count(*) FROM product_variants
+WHERE product_id = $1;
+
+-- name: UpdateVariant :one
+-- set_price with a NULL price_minor clears the override.
+UPDATE product_variants
+SET
+  sku         = CASE WHEN sqlc.arg(set_sku)::boolean THEN sqlc.arg(sku)::text ELSE sku END,
+  options     = CASE WHEN sqlc.arg(set_options)::boolean THEN sqlc.arg(options)::jsonb ELSE options END,
+  option_key  = CASE WHEN sqlc.arg(set_options)::boolean THEN sqlc.arg(option_key)::text ELSE option_key END,
+  price_minor = CASE WHEN sqlc.arg(set_price)::boolean THEN sqlc.narg(price_minor)::int8 ELSE price_minor END,
+  currency    = CASE WHEN sqlc.arg(set_price)::boolean THEN sqlc.narg(currency)::text ELSE currency END,
+  updated_at  = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteVariant :one
+-- Only variants without stock or pending holds can be deleted.
+DELETE FROM product_variants
+WHERE product_variants.id = $1
+  AND stock_quantity = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM stock_reservations r
+    WHERE r.variant_id = $1 AND r.status = 'pending'
+  )
+RETURNING *;
+
+-- name: AdjustVariantStock :one
+-- AdjustStock for a single variant of a product.
+WITH adjusted AS (
+  UPDATE product_variants
+  SET stock_quantity = stock_quantity + sqlc.arg(delta)::int4,
+      updated_at     = now()
+  WHERE product_variants.id = sqlc.arg(variant_id)
+    AND product_variants.product_id = sqlc.arg(product_id)
+    AND EXISTS (SELECT 1 FROM products p WHERE p.id = sqlc.arg(product_id) AND p.deleted_at IS NULL)
+    AND stock_quantity + sqlc.arg(delta)::int4 >= 0
+  RETURNING product_variants.id, product_variants.product_id, product_variants.stock_quantity
+), movement AS (
+  INSERT INTO stock_movements (id, product_id, variant_id, delta, reason, reference, note)
+  SELECT sqlc.arg(id), adjusted.product_id, adjusted.id, sqlc.arg(delta)::int4, sqlc.arg(reason), sqlc.narg(reference), sqlc.narg(note)
+  FROM adjusted
+  RETURNING *
+)
+SELECT movement.id, movement.product_id, movement.delta, movement.reason, movement.reference, movement.note, movement.created_at,
+       movement.variant_id, adjusted.stock_quantity
+FROM movement
+JOIN adjusted ON adjusted.id = movement.variant_id;
+
+-- name: ReserveVariantStock :one
+-- ReserveStock for a single variant of a product.
+WITH reserved AS (
+  UPDATE product_variants
+  SET stock_quantity = stock_quantity - sqlc.arg(quantity)::int4,
+      updated_at     = now()
+  WHERE product_variants.id = sqlc.arg(variant_id)
+    AND product_variants.product_id = sqlc.arg(product_id)
+    AND EXISTS (SELECT 1 FROM products p WHERE p.id = sqlc.arg(product_id) AND p.deleted_at IS NULL)
+    AND stock_quantity >= sqlc.arg(quantity)::int4
+  RETURNING product_variants.id, product_variants.product_id
+)
+INSERT INTO stock_reservations (id, product_id, variant_id, quantity, expires_at)
+SELECT sqlc.arg(id), reserved.product_id, reserved.id, sqlc.arg(quantity)::int4, now() + sqlc.arg(ttl)::interval
+FROM reserved
+RETURNING *;
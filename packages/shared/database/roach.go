@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/url"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
 	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
 	"go.uber.org/fx"
@@ -21,15 +23,56 @@ type Params struct {
 	Lifecycle fx.Lifecycle
 	Cfg       *config.Config
 	Log       *zap.Logger
+	Registry  *prometheus.Registry
 }
 
+// Drivers accepted by DbConfig.Driver. An empty driver means postgres.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
 // Module exports the database providers
-// It provides both the connection pool and the queries repository
+// It opens the database of the configured driver; the store package runs
+// the queries on top of it
 var Module = fx.Module("database",
-	fx.Provide(NewPool),
-	fx.Provide(NewQueries),
+	fx.Provide(Open),
 )
 
+// Result is what Module provides. Only the connection of the configured
+// driver is set; the other one is nil, as is Transactor with SQLite.
+type Result struct {
+	fx.Out
+
+	Pool       *pgxpool.Pool
+	SQLite     *sql.DB
+	Transactor *Transactor
+}
+
+// Open connects to the database selected by DbConfig.Driver.
+func Open(p Params) (Result, error) {
+	switch driver := p.Cfg.DbConfig.Driver; driver {
+	case "", DriverPostgres:
+		pool, err := NewPool(p)
+		if err != nil {
+			return Result{}, err
+		}
+		queries := NewQueries(pool)
+		return Result{
+			Pool:       pool,
+			Transactor: NewTransactor(pool, queries, p.Cfg.DbConfig.TxRetry, p.Registry),
+		}, nil
+	case DriverSQLite:
+		db, err := NewSQLite(p)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{SQLite: db}, nil
+	default:
+		return Result{}, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
+
 func NewPool(p Params) (*pgxpool.Pool, error) {
 	config := p.Cfg
 
@@ -0,0 +1,30 @@
+-- categories is a tree for browsing products. path materializes the chain of
+-- ids from the root down to the category itself, e.g. '/12/34/', so that a
+-- subtree is every row whose path starts with its root's path.
+CREATE TABLE categories (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  parent_id INT8 REFERENCES categories (id),
+  name STRING NOT NULL CHECK (name <> ''),
+  path STRING NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX categories_parent_name_idx ON categories (parent_id, name, id);
+
+CREATE TABLE product_categories (
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  category_id INT8 NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+  PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX product_categories_category_idx ON product_categories (category_id, product_id);
+
+-- Free-form labels. Tags are stored lower-cased.
+CREATE TABLE product_tags (
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  tag STRING NOT NULL CHECK (tag <> '' AND tag = lower(tag)),
+  PRIMARY KEY (product_id, tag)
+);
+
+CREATE INDEX product_tags_tag_idx ON product_tags (tag, product_id);
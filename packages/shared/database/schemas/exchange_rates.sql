@@ -0,0 +1,13 @@
+-- Effective-dated exchange rates used to show prices in other currencies.
+-- The rate in force at a given time is the one with the latest effective_at
+-- not after it, so future rates can be loaded ahead of time.
+CREATE TABLE exchange_rates (
+  base_currency STRING NOT NULL REFERENCES currencies (code),
+  quote_currency STRING NOT NULL REFERENCES currencies (code),
+  -- Units of quote_currency per unit of base_currency.
+  rate DECIMAL(24, 12) NOT NULL CHECK (rate > 0),
+  effective_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (base_currency, quote_currency, effective_at),
+  CHECK (base_currency <> quote_currency)
+);
@@ -0,0 +1,17 @@
+-- idempotency_keys remembers the outcome of mutating RPCs sent with an
+-- Idempotency-Key so that retries replay the first response instead of
+-- repeating the mutation.
+CREATE TABLE idempotency_keys (
+  method STRING NOT NULL,
+  idempotency_key STRING NOT NULL,
+  -- SHA-256 of the deterministically marshaled request.
+  request_hash BYTEA NOT NULL,
+  -- Marshaled google.protobuf.Any of the response; NULL while the first
+  -- request is still in flight.
+  response BYTEA,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (method, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
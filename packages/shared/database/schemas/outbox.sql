@@ -0,0 +1,40 @@
+-- outbox holds domain events written in the same statement as the change
+-- they describe, until the outbox relay publishes them. Product events are
+-- written alongside their revision by the product mutations in
+-- products.sql and the stock changes in stock_movements.sql and
+-- stock_reservations.sql; variants, media and categories have none of
+-- their own. Events of one aggregate are published in aggregate_version
+-- order.
+CREATE TABLE outbox (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  aggregate_type STRING NOT NULL,
+  aggregate_id INT8 NOT NULL,
+  -- The aggregate's version after the change.
+  aggregate_version INT8 NOT NULL,
+  event_type STRING NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  -- Failed publish attempts so far; the next is not made before
+  -- next_attempt_at.
+  attempts INT4 NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error STRING,
+  published_at TIMESTAMPTZ,
+  CONSTRAINT outbox_aggregate_version_key UNIQUE (aggregate_type, aggregate_id, aggregate_version)
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (aggregate_type, aggregate_id, aggregate_version)
+  STORING (created_at, next_attempt_at)
+  WHERE published_at IS NULL;
+
+CREATE INDEX outbox_published_idx ON outbox (published_at)
+  WHERE published_at IS NOT NULL;
+
+-- outbox_leases holds the lease a relay takes before publishing, so that
+-- a single relay publishes at a time. A lease not renewed by expires_at
+-- may be taken by another relay.
+CREATE TABLE outbox_leases (
+  name STRING PRIMARY KEY,
+  holder STRING NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL
+);
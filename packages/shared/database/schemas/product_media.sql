@@ -0,0 +1,27 @@
+-- product_media describes the images of a product. The blobs themselves live
+-- in the gateway's blob storage under storage_key; position orders a
+-- product's media, starting at 0.
+CREATE TABLE product_media (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  position INT4 NOT NULL CHECK (position >= 0),
+  storage_key STRING NOT NULL,
+  content_type STRING NOT NULL,
+  checksum_sha256 STRING NOT NULL CHECK (checksum_sha256 ~ '^[0-9a-f]{64}$'),
+  size_bytes INT8 NOT NULL CHECK (size_bytes > 0),
+  width INT4 NOT NULL CHECK (width > 0),
+  height INT4 NOT NULL CHECK (height > 0),
+  alt_text STRING NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  -- The same image can only be attached to a product once.
+  CONSTRAINT product_media_checksum_key UNIQUE (product_id, checksum_sha256)
+);
+
+CREATE INDEX product_media_product_position_idx ON product_media (product_id, position, id);
+
+-- purged_media_blobs queues the blobs of media that went with a purged
+-- product. The gateway deletes the blobs and then the rows.
+CREATE TABLE purged_media_blobs (
+  storage_key STRING PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,45 @@
+-- product_revisions is the change history of products. Each create,
+-- update, delete, undelete and purge records a revision in the same statement as
+-- the change, and so does stock changed through the ledger or reservations,
+-- as a stock revision: every version of a product has one. previous and
+-- snapshot hold the products row before and after the change, keyed by
+-- column name, with the product's category_ids and tags, as built by
+-- product_snapshot; previous is NULL for the first revision. Like the
+-- ledger, the history is kept when a product is purged, so product_id is
+-- not a foreign key.
+CREATE TABLE product_revisions (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  product_id INT8 NOT NULL,
+  -- The product's version after the change.
+  version INT8 NOT NULL,
+  action STRING NOT NULL
+    CHECK (action IN ('create', 'update', 'delete', 'undelete', 'baseline', 'purge', 'stock')),
+  actor STRING NOT NULL,
+  previous JSONB,
+  snapshot JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT product_revisions_version_key UNIQUE (product_id, version)
+);
+
+CREATE INDEX product_revisions_product_created_idx ON product_revisions (product_id, created_at DESC, version DESC);
+
+-- Lets WatchProducts page through purges without scanning the history.
+CREATE INDEX product_revisions_purge_idx ON product_revisions (created_at, product_id)
+  WHERE action = 'purge';
+
+-- product_snapshot builds the previous and snapshot of a revision from a
+-- products row passed through to_jsonb. category_ids and tags default to
+-- the product's current ones; pass them when the same statement replaces
+-- them, since it cannot read its own writes.
+CREATE FUNCTION product_snapshot(product JSONB, category_ids INT8[], tags STRING[])
+RETURNS JSONB STABLE LANGUAGE SQL AS $$
+  SELECT product || jsonb_build_object(
+    'category_ids', COALESCE(to_jsonb(category_ids), (
+      SELECT COALESCE(jsonb_agg(pc.category_id ORDER BY pc.category_id), '[]')
+      FROM product_categories pc
+      WHERE pc.product_id = (product->>'id')::INT8)),
+    'tags', COALESCE(to_jsonb(tags), (
+      SELECT COALESCE(jsonb_agg(pt.tag ORDER BY pt.tag), '[]')
+      FROM product_tags pt
+      WHERE pt.product_id = (product->>'id')::INT8)))
+$$;
@@ -1,12 +1,34 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE products (
   id INT8 PRIMARY KEY DEFAULT unique_rowid(),
   name STRING NOT NULL,
   description STRING,
-  price FLOAT8 NOT NULL,
-  currency STRING NOT NULL CHECK (char_length(currency) = 3),
-  stock_quantity INT4 NOT NULL DEFAULT 0,
+  -- Price in minor units of currency, e.g. cents for USD.
+  price_minor INT8 NOT NULL CHECK (price_minor >= 0),
+  currency STRING NOT NULL REFERENCES currencies (code),
+  stock_quantity INT4 NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  version INT8 NOT NULL DEFAULT 1,
+  -- Set when the product is soft deleted; the purge job removes the row
+  -- once the retention period has passed.
+  deleted_at TIMESTAMPTZ
 );
 
+-- Supporting indexes for ListProducts filters and sort orders. Each ends in
+-- id so keyset pagination on (sort column, id) can seek directly.
+CREATE INDEX products_currency_price_idx ON products (currency, price_minor, id);
+CREATE INDEX products_price_idx ON products (price_minor, id);
+CREATE INDEX products_name_idx ON products (name, id);
+CREATE INDEX products_created_at_idx ON products (created_at, id);
+CREATE INDEX products_in_stock_idx ON products (id) WHERE stock_quantity > 0;
+-- Change feed for WatchProducts.
+CREATE INDEX products_updated_at_idx ON products (updated_at, id);
+CREATE INDEX products_deleted_at_idx ON products (deleted_at) WHERE deleted_at IS NOT NULL;
 
+-- Full-text and trigram indexes backing SearchProducts. The tsvector
+-- expression must match the one used in queries/products.sql exactly.
+CREATE INDEX products_search_idx ON products
+  USING GIN (to_tsvector('english', name || ' ' || COALESCE(description, '')));
+CREATE INDEX products_name_trgm_idx ON products USING GIN (name gin_trgm_ops);
@@ -0,0 +1,16 @@
+-- stock_movements is the append-only inventory ledger. The sum of a
+-- product's deltas is its on-hand quantity: products.stock_quantity plus
+-- any stock held by pending reservations. product_id is deliberately not a
+-- foreign key: the ledger outlives the products that are purged.
+CREATE TABLE stock_movements (
+  id INT8 PRIMARY KEY DEFAULT unique_rowid(),
+  product_id INT8 NOT NULL,
+  delta INT4 NOT NULL CHECK (delta <> 0),
+  reason STRING NOT NULL
+    CHECK (reason IN ('receipt', 'sale', 'return', 'shrinkage', 'correction')),
+  reference STRING,
+  note STRING,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX stock_movements_product_created_idx ON stock_movements (product_id, created_at DESC, id DESC);
@@ -0,0 +1,14 @@
+CREATE TABLE stock_reservations (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  quantity INT4 NOT NULL CHECK (quantity > 0),
+  status STRING NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'committed', 'released', 'expired')),
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Lets the expiry sweeper find due holds without scanning settled rows.
+CREATE INDEX stock_reservations_pending_expiry_idx ON stock_reservations (expires_at)
+  WHERE status = 'pending';
@@ -0,0 +1,30 @@
+-- product_variants are the purchasable versions of a product, e.g. one per
+-- size and colour. Each has its own SKU and stock; price_minor and currency
+-- override the product's price when set.
+CREATE TABLE product_variants (
+  id INT8 PRIMARY KEY,
+  product_id INT8 NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  sku STRING NOT NULL UNIQUE CHECK (sku ~ '^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$'),
+  options JSONB NOT NULL DEFAULT '{}',
+  -- Canonical encoding of options; no two variants of a product may have
+  -- the same option values.
+  option_key STRING NOT NULL,
+  price_minor INT8 CHECK (price_minor > 0),
+  currency STRING REFERENCES currencies (code),
+  stock_quantity INT4 NOT NULL DEFAULT 0 CHECK (stock_quantity >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT product_variants_price_check CHECK ((price_minor IS NULL) = (currency IS NULL)),
+  CONSTRAINT product_variants_options_key UNIQUE (product_id, option_key)
+);
+
+-- Ledger entries and holds of a variant move its stock rather than the
+-- product's. A variant can only be deleted once its stock is zero and no
+-- holds are pending; its entries and settled holds are kept and lose the
+-- variant. Their deltas sum to zero, so the product's ledger still balances.
+ALTER TABLE stock_movements ADD COLUMN variant_id INT8 REFERENCES product_variants (id) ON DELETE SET NULL;
+ALTER TABLE stock_reservations ADD COLUMN variant_id INT8 REFERENCES product_variants (id) ON DELETE SET NULL;
+
+CREATE INDEX stock_movements_variant_idx ON stock_movements (variant_id) WHERE variant_id IS NOT NULL;
+CREATE INDEX stock_reservations_variant_pending_idx ON stock_reservations (variant_id)
+  WHERE variant_id IS NOT NULL AND status = 'pending';
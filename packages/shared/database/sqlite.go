@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	// Registers the pure Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates every table the queries need. It is idempotent and
+// runs on every start.
+//
+//go:embed sqlite/schema.sql
+var sqliteSchema string
+
+// NewSQLite opens the SQLite database at DbConfig.Path, creating the file
+// and its schema on first start.
+func NewSQLite(p Params) (*sql.DB, error) {
+	path := p.Cfg.DbConfig.Path
+	if path == "" {
+		return nil, fmt.Errorf("database.path must be set for the %s driver", DriverSQLite)
+	}
+
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Opaque:   path,
+		RawQuery: "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)",
+	}).String()
+
+	db, err := sql.Open(DriverSQLite, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; one connection turns lock contention
+	// into queueing in the pool.
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		_ = db.Close()
+		p.Log.Error("Failed to apply sqlite schema", zap.Error(err))
+		return nil, fmt.Errorf("failed to apply sqlite schema: %w", err)
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return db.Close()
+		},
+	})
+	return db, nil
+}
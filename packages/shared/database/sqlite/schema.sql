@@ -0,0 +1,252 @@
+-- SQLite equivalent of the tables in schemas/, for running product-service
+-- from a single file database during local development. It is applied on
+-- every start, so each statement must be idempotent; keep it in step with
+-- schemas/ when either changes. Changed table definitions only take effect
+-- in a new database file, since CREATE TABLE IF NOT EXISTS leaves existing
+-- tables alone.
+--
+-- Differences from CockroachDB:
+--   * Timestamps are INTEGER Unix microseconds, the precision of
+--     TIMESTAMPTZ, so they compare and sort numerically.
+--   * JSONB columns hold JSON text and DECIMAL columns decimal text.
+--   * Regular expression checks are spelled with GLOB.
+--   * Ids without a caller supplied value come from the rowid.
+-- Constraints keep their CockroachDB names so that violations are reported
+-- alike by both backends.
+
+CREATE TABLE IF NOT EXISTS currencies (
+  code TEXT PRIMARY KEY,
+  minor_units INTEGER NOT NULL,
+  CONSTRAINT currencies_code_check CHECK (length(code) = 3 AND code NOT GLOB '*[^A-Z]*'),
+  CONSTRAINT currencies_minor_units_check CHECK (minor_units BETWEEN 0 AND 4)
+);
+
+INSERT OR IGNORE INTO currencies (code, minor_units) VALUES
+  ('AED', 2), ('AFN', 2), ('ALL', 2), ('AMD', 2), ('ANG', 2), ('AOA', 2), ('ARS', 2), ('AUD', 2),
+  ('AWG', 2), ('AZN', 2), ('BAM', 2), ('BBD', 2), ('BDT', 2), ('BGN', 2), ('BHD', 3), ('BIF', 0),
+  ('BMD', 2), ('BND', 2), ('BOB', 2), ('BOV', 2), ('BRL', 2), ('BSD', 2), ('BTN', 2), ('BWP', 2),
+  ('BYN', 2), ('BZD', 2), ('CAD', 2), ('CDF', 2), ('CHE', 2), ('CHF', 2), ('CHW', 2), ('CLF', 4),
+  ('CLP', 0), ('CNY', 2), ('COP', 2), ('COU', 2), ('CRC', 2), ('CUP', 2), ('CVE', 2), ('CZK', 2),
+  ('DJF', 0), ('DKK', 2), ('DOP', 2), ('DZD', 2), ('EGP', 2), ('ERN', 2), ('ETB', 2), ('EUR', 2),
+  ('FJD', 2), ('FKP', 2), ('GBP', 2), ('GEL', 2), ('GHS', 2), ('GIP', 2), ('GMD', 2), ('GNF', 0),
+  ('GTQ', 2), ('GYD', 2), ('HKD', 2), ('HNL', 2), ('HTG', 2), ('HUF', 2), ('IDR', 2), ('ILS', 2),
+  ('INR', 2), ('IQD', 3), ('IRR', 2), ('ISK', 0), ('JMD', 2), ('JOD', 3), ('JPY', 0), ('KES', 2),
+  ('KGS', 2), ('KHR', 2), ('KMF', 0), ('KPW', 2), ('KRW', 0), ('KWD', 3), ('KYD', 2), ('KZT', 2),
+  ('LAK', 2), ('LBP', 2), ('LKR', 2), ('LRD', 2), ('LSL', 2), ('LYD', 3), ('MAD', 2), ('MDL', 2),
+  ('MGA', 2), ('MKD', 2), ('MMK', 2), ('MNT', 2), ('MOP', 2), ('MRU', 2), ('MUR', 2), ('MVR', 2),
+  ('MWK', 2), ('MXN', 2), ('MXV', 2), ('MYR', 2), ('MZN', 2), ('NAD', 2), ('NGN', 2), ('NIO', 2),
+  ('NOK', 2), ('NPR', 2), ('NZD', 2), ('OMR', 3), ('PAB', 2), ('PEN', 2), ('PGK', 2), ('PHP', 2),
+  ('PKR', 2), ('PLN', 2), ('PYG', 0), ('QAR', 2), ('RON', 2), ('RSD', 2), ('RUB', 2), ('RWF', 0),
+  ('SAR', 2), ('SBD', 2), ('SCR', 2), ('SDG', 2), ('SEK', 2), ('SGD', 2), ('SHP', 2), ('SLE', 2),
+  ('SOS', 2), ('SRD', 2), ('SSP', 2), ('STN', 2), ('SVC', 2), ('SYP', 2), ('SZL', 2), ('THB', 2),
+  ('TJS', 2), ('TMT', 2), ('TND', 3), ('TOP', 2), ('TRY', 2), ('TTD', 2), ('TWD', 2), ('TZS', 2),
+  ('UAH', 2), ('UGX', 0), ('USD', 2), ('USN', 2), ('UYI', 0), ('UYU', 2), ('UYW', 4), ('UZS', 2),
+  ('VED', 2), ('VES', 2), ('VND', 0), ('VUV', 0), ('WST', 2), ('XAF', 0), ('XCD', 2), ('XCG', 2),
+  ('XOF', 0), ('XPF', 0), ('YER', 2), ('ZAR', 2), ('ZMW', 2), ('ZWG', 2);
+
+CREATE TABLE IF NOT EXISTS products (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  description TEXT,
+  -- Price in minor units of currency, e.g. cents for USD.
+  price_minor INTEGER NOT NULL,
+  currency TEXT NOT NULL REFERENCES currencies (code),
+  stock_quantity INTEGER NOT NULL DEFAULT 0,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  deleted_at INTEGER,
+  CONSTRAINT products_price_minor_check CHECK (price_minor >= 0),
+  CONSTRAINT products_stock_quantity_check CHECK (stock_quantity >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS products_currency_price_idx ON products (currency, price_minor, id);
+CREATE INDEX IF NOT EXISTS products_price_idx ON products (price_minor, id);
+CREATE INDEX IF NOT EXISTS products_name_idx ON products (name, id);
+CREATE INDEX IF NOT EXISTS products_created_at_idx ON products (created_at, id);
+CREATE INDEX IF NOT EXISTS products_updated_at_idx ON products (updated_at, id);
+CREATE INDEX IF NOT EXISTS products_deleted_at_idx ON products (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS categories (
+  id INTEGER PRIMARY KEY,
+  parent_id INTEGER REFERENCES categories (id),
+  name TEXT NOT NULL,
+  path TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  CONSTRAINT categories_name_check CHECK (name <> ''),
+  CONSTRAINT categories_path_key UNIQUE (path)
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_name_idx ON categories (parent_id, name, id);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  category_id INTEGER NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+  PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_idx ON product_categories (category_id, product_id);
+
+CREATE TABLE IF NOT EXISTS product_tags (
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  tag TEXT NOT NULL,
+  PRIMARY KEY (product_id, tag),
+  CONSTRAINT product_tags_tag_check CHECK (tag <> '' AND tag = lower(tag))
+);
+
+CREATE INDEX IF NOT EXISTS product_tags_tag_idx ON product_tags (tag, product_id);
+
+CREATE TABLE IF NOT EXISTS product_revisions (
+  id INTEGER PRIMARY KEY,
+  -- Not a foreign key: kept when the product is purged.
+  product_id INTEGER NOT NULL,
+  version INTEGER NOT NULL,
+  action TEXT NOT NULL,
+  actor TEXT NOT NULL,
+  previous TEXT,
+  snapshot TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  CONSTRAINT product_revisions_action_check
+    CHECK (action IN ('create', 'update', 'delete', 'undelete', 'baseline', 'purge', 'stock')),
+  CONSTRAINT product_revisions_version_key UNIQUE (product_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS product_revisions_product_created_idx ON product_revisions (product_id, created_at DESC, version DESC);
+CREATE INDEX IF NOT EXISTS product_revisions_purge_idx ON product_revisions (created_at, product_id)
+  WHERE action = 'purge';
+
+CREATE TABLE IF NOT EXISTS outbox (
+  id INTEGER PRIMARY KEY,
+  aggregate_type TEXT NOT NULL,
+  aggregate_id INTEGER NOT NULL,
+  aggregate_version INTEGER NOT NULL,
+  event_type TEXT NOT NULL,
+  payload TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at INTEGER NOT NULL,
+  last_error TEXT,
+  published_at INTEGER,
+  CONSTRAINT outbox_aggregate_version_key UNIQUE (aggregate_type, aggregate_id, aggregate_version)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, aggregate_version)
+  WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON outbox (published_at)
+  WHERE published_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS outbox_leases (
+  name TEXT PRIMARY KEY,
+  holder TEXT NOT NULL,
+  expires_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+  method TEXT NOT NULL,
+  idempotency_key TEXT NOT NULL,
+  request_hash BLOB NOT NULL,
+  response BLOB,
+  created_at INTEGER NOT NULL,
+  expires_at INTEGER NOT NULL,
+  PRIMARY KEY (method, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+CREATE TABLE IF NOT EXISTS product_media (
+  id INTEGER PRIMARY KEY,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  storage_key TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  checksum_sha256 TEXT NOT NULL,
+  size_bytes INTEGER NOT NULL,
+  width INTEGER NOT NULL,
+  height INTEGER NOT NULL,
+  alt_text TEXT NOT NULL DEFAULT '',
+  created_at INTEGER NOT NULL,
+  CONSTRAINT product_media_position_check CHECK (position >= 0),
+  CONSTRAINT product_media_checksum_sha256_check
+    CHECK (length(checksum_sha256) = 64 AND checksum_sha256 NOT GLOB '*[^0-9a-f]*'),
+  CONSTRAINT product_media_size_bytes_check CHECK (size_bytes > 0),
+  CONSTRAINT product_media_width_check CHECK (width > 0),
+  CONSTRAINT product_media_height_check CHECK (height > 0),
+  CONSTRAINT product_media_checksum_key UNIQUE (product_id, checksum_sha256)
+);
+
+CREATE INDEX IF NOT EXISTS product_media_product_position_idx ON product_media (product_id, position, id);
+
+CREATE TABLE IF NOT EXISTS purged_media_blobs (
+  storage_key TEXT PRIMARY KEY,
+  created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+  id INTEGER PRIMARY KEY,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  sku TEXT NOT NULL,
+  options TEXT NOT NULL DEFAULT '{}',
+  option_key TEXT NOT NULL,
+  price_minor INTEGER,
+  currency TEXT REFERENCES currencies (code),
+  stock_quantity INTEGER NOT NULL DEFAULT 0,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  CONSTRAINT product_variants_sku_check
+    CHECK (length(sku) BETWEEN 1 AND 64 AND sku GLOB '[A-Za-z0-9]*' AND sku NOT GLOB '*[^A-Za-z0-9._-]*'),
+  CONSTRAINT product_variants_price_minor_check CHECK (price_minor > 0),
+  CONSTRAINT product_variants_stock_quantity_check CHECK (stock_quantity >= 0),
+  CONSTRAINT product_variants_price_check CHECK ((price_minor IS NULL) = (currency IS NULL)),
+  CONSTRAINT product_variants_sku_key UNIQUE (sku),
+  CONSTRAINT product_variants_options_key UNIQUE (product_id, option_key)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id INTEGER PRIMARY KEY,
+  -- Not a foreign key: kept when the product is purged.
+  product_id INTEGER NOT NULL,
+  delta INTEGER NOT NULL,
+  reason TEXT NOT NULL,
+  reference TEXT,
+  note TEXT,
+  created_at INTEGER NOT NULL,
+  variant_id INTEGER REFERENCES product_variants (id) ON DELETE SET NULL,
+  CONSTRAINT stock_movements_delta_check CHECK (delta <> 0),
+  CONSTRAINT stock_movements_reason_check
+    CHECK (reason IN ('receipt', 'sale', 'return', 'shrinkage', 'correction'))
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_created_idx ON stock_movements (product_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS stock_movements_variant_idx ON stock_movements (variant_id) WHERE variant_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS stock_reservations (
+  id INTEGER PRIMARY KEY,
+  product_id INTEGER NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  quantity INTEGER NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  expires_at INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  variant_id INTEGER REFERENCES product_variants (id) ON DELETE SET NULL,
+  CONSTRAINT stock_reservations_quantity_check CHECK (quantity > 0),
+  CONSTRAINT stock_reservations_status_check
+    CHECK (status IN ('pending', 'committed', 'released', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS stock_reservations_pending_expiry_idx ON stock_reservations (expires_at)
+  WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS stock_reservations_variant_pending_idx ON stock_reservations (variant_id)
+  WHERE variant_id IS NOT NULL AND status = 'pending';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  base_currency TEXT NOT NULL REFERENCES currencies (code),
+  quote_currency TEXT NOT NULL REFERENCES currencies (code),
+  -- Units of quote_currency per unit of base_currency, as decimal text.
+  rate TEXT NOT NULL,
+  effective_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  PRIMARY KEY (base_currency, quote_currency, effective_at),
+  CONSTRAINT exchange_rates_rate_check CHECK (CAST(rate AS REAL) > 0),
+  CONSTRAINT exchange_rates_check CHECK (base_currency <> quote_currency)
+);
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const (
+	defaultTxMaxRetries = 5
+	defaultTxMinBackoff = 10 * time.Millisecond
+	defaultTxMaxBackoff = time.Second
+
+	// serializationFailure is the SQLSTATE CockroachDB aborts a
+	// transaction with when it conflicts with a concurrent one. The client
+	// is expected to run the transaction again.
+	serializationFailure = "40001"
+)
+
+// TxBeginner starts the transactions a Transactor runs. *pgxpool.Pool and
+// *pgx.Conn implement it.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Transactor runs transactions, retrying the ones that fail with a
+// serialization failure. A retry runs the whole transaction again, so the
+// function it runs may be called more than once and must not leave side
+// effects outside the transaction behind when it fails.
+type Transactor struct {
+	db         TxBeginner
+	queries    *repository.Queries
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	metrics    *txMetrics
+}
+
+type txMetrics struct {
+	retries   prometheus.Counter
+	exhausted prometheus.Counter
+}
+
+func newTxMetrics(registry prometheus.Registerer) *txMetrics {
+	m := &txMetrics{
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "myapp",
+			Subsystem: "database",
+			Name:      "tx_retries_total",
+			Help:      "Transactions run again after a serialization failure.",
+		}),
+		exhausted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "myapp",
+			Subsystem: "database",
+			Name:      "tx_retries_exhausted_total",
+			Help:      "Transactions that still failed with a serialization failure after the last retry.",
+		}),
+	}
+	registry.MustRegister(m.retries, m.exhausted)
+	return m
+}
+
+func NewTransactor(db TxBeginner, queries *repository.Queries, cfg config.TxRetryConfig, registry prometheus.Registerer) *Transactor {
+	t := &Transactor{
+		db:         db,
+		queries:    queries,
+		maxRetries: max(cfg.MaxRetries, 0),
+		minBackoff: cfg.MinBackoff,
+		maxBackoff: cfg.MaxBackoff,
+		metrics:    newTxMetrics(registry),
+	}
+	if cfg.MaxRetries == 0 {
+		t.maxRetries = defaultTxMaxRetries
+	}
+	if t.minBackoff <= 0 {
+		t.minBackoff = defaultTxMinBackoff
+	}
+	if t.maxBackoff < t.minBackoff {
+		t.maxBackoff = max(defaultTxMaxBackoff, t.minBackoff)
+	}
+	return t
+}
+
+// WithTx runs fn with queries bound to a new transaction and commits it
+// when fn succeeds. The error of fn is returned unchanged unless it is a
+// serialization failure, which runs the transaction again.
+func (t *Transactor) WithTx(ctx context.Context, fn func(*repository.Queries) error) error {
+	return t.RunTx(ctx, func(tx pgx.Tx) error {
+		return fn(t.queries.WithTx(tx))
+	})
+}
+
+// RunTx is WithTx for callers that need the transaction itself, for
+// example to bind their own queries or to nest savepoints.
+func (t *Transactor) RunTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	for attempt := 0; ; attempt++ {
+		err := t.runTx(ctx, fn)
+		if !IsSerializationFailure(err) {
+			return err
+		}
+		if attempt == t.maxRetries {
+			t.metrics.exhausted.Inc()
+			return err
+		}
+
+		timer := time.NewTimer(t.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry transaction: %w: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+		t.metrics.retries.Inc()
+	}
+}
+
+func (t *Transactor) runTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		// A no-op once the transaction has been committed.
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// backoff returns the delay before the retry that follows attempt: an
+// exponentially growing ceiling, of which a random half is slept so that
+// conflicting transactions do not retry in lockstep.
+func (t *Transactor) backoff(attempt int) time.Duration {
+	ceiling := t.minBackoff
+	for range attempt {
+		if ceiling >= t.maxBackoff/2 {
+			ceiling = t.maxBackoff
+			break
+		}
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, t.maxBackoff)
+	return ceiling/2 + rand.N(ceiling/2+1)
+}
+
+// IsSerializationFailure reports whether err is a transaction abort the
+// client should retry.
+func IsSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == serializationFailure
+}
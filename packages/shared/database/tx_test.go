@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+var errSerialization = &pgconn.PgError{Code: "40001", Message: "restart transaction"}
+
+// fakeDB begins fakeTxs whose commits fail with the queued errors.
+type fakeDB struct {
+	begins     int
+	commitErrs []error
+}
+
+func (db *fakeDB) Begin(context.Context) (pgx.Tx, error) {
+	db.begins++
+	return &fakeTx{db: db}, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	if len(tx.db.commitErrs) == 0 {
+		return nil
+	}
+	err := tx.db.commitErrs[0]
+	tx.db.commitErrs = tx.db.commitErrs[1:]
+	return err
+}
+
+func (tx *fakeTx) Rollback(context.Context) error { return nil }
+
+func newTestTransactor(db TxBeginner, cfg config.TxRetryConfig) *Transactor {
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = time.Microsecond
+	}
+	return NewTransactor(db, repository.New(nil), cfg, prometheus.NewRegistry())
+}
+
+func TestTransactor_RetriesSerializationFailure(t *testing.T) {
+	db := &fakeDB{commitErrs: []error{errSerialization, errSerialization}}
+	tx := newTestTransactor(db, config.TxRetryConfig{})
+
+	calls := 0
+	err := tx.WithTx(context.Background(), func(q *repository.Queries) error {
+		if q == nil {
+			t.Error("WithTx passed nil queries")
+		}
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx error = %v", err)
+	}
+	if calls != 3 || db.begins != 3 {
+		t.Errorf("fn ran %d times in %d transactions, want 3 and 3", calls, db.begins)
+	}
+	if got := testutil.ToFloat64(tx.metrics.retries); got != 2 {
+		t.Errorf("retries = %v, want 2", got)
+	}
+}
+
+func TestTransactor_GivesUpAfterMaxRetries(t *testing.T) {
+	db := &fakeDB{commitErrs: []error{errSerialization, errSerialization, errSerialization, errSerialization}}
+	tx := newTestTransactor(db, config.TxRetryConfig{MaxRetries: 2})
+
+	err := tx.WithTx(context.Background(), func(*repository.Queries) error { return nil })
+	if !IsSerializationFailure(err) {
+		t.Fatalf("WithTx error = %v, want a serialization failure", err)
+	}
+	if db.begins != 3 {
+		t.Errorf("ran %d transactions, want 3", db.begins)
+	}
+	if got := testutil.ToFloat64(tx.metrics.exhausted); got != 1 {
+		t.Errorf("exhausted = %v, want 1", got)
+	}
+}
+
+func TestTransactor_DoesNotRetryOtherErrors(t *testing.T) {
+	boom := errors.New("boom")
+
+	tests := []struct {
+		name string
+		cfg  config.TxRetryConfig
+		fn   func(*repository.Queries) error
+		db   *fakeDB
+		want error
+	}{
+		{
+			name: "fn error",
+			fn:   func(*repository.Queries) error { return boom },
+			db:   &fakeDB{},
+			want: boom,
+		},
+		{
+			name: "retries disabled",
+			cfg:  config.TxRetryConfig{MaxRetries: -1},
+			fn:   func(*repository.Queries) error { return nil },
+			db:   &fakeDB{commitErrs: []error{errSerialization}},
+			want: errSerialization,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestTransactor(tt.db, tt.cfg).WithTx(context.Background(), tt.fn)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("WithTx error = %v, want %v", err, tt.want)
+			}
+			if tt.db.begins != 1 {
+				t.Errorf("ran %d transactions, want 1", tt.db.begins)
+			}
+		})
+	}
+}
+
+func TestTransactor_HonorsContextCancellation(t *testing.T) {
+	db := &fakeDB{commitErrs: []error{errSerialization}}
+	tx := newTestTransactor(db, config.TxRetryConfig{MinBackoff: time.Hour, MaxBackoff: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := tx.RunTx(ctx, func(pgx.Tx) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || !IsSerializationFailure(err) {
+		t.Errorf("RunTx error = %v, want the cancellation and the serialization failure", err)
+	}
+	if db.begins != 1 {
+		t.Errorf("ran %d transactions, want 1", db.begins)
+	}
+}
+
+func TestTransactor_Backoff(t *testing.T) {
+	tx := newTestTransactor(&fakeDB{}, config.TxRetryConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+
+	for attempt, ceiling := range []time.Duration{10, 20, 40, 50, 50} {
+		ceiling *= time.Millisecond
+		for range 20 {
+			if d := tx.backoff(attempt); d < ceiling/2 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, ceiling/2, ceiling)
+			}
+		}
+	}
+}
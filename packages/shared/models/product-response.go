@@ -4,30 +4,40 @@ import (
 	"time"
 
 	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
 )
 
+// ProductResponse renders Price as an exact decimal string with the
+// currency's minor-unit digits, e.g. "12.30" for USD or "1200" for JPY.
 type ProductResponse struct {
-	ID            uint64    `json:"id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Price         float64   `json:"price"`
-	Currency      string    `json:"currency"`
-	StockQuantity uint32    `json:"stock_quantity"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uint64     `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Price         string     `json:"price"`
+	Currency      string     `json:"currency"`
+	StockQuantity uint32     `json:"stock_quantity"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Map from gRPC Product to REST-friendly ProductResponse
 func FromProtoProduct(p *productsv1.Product) ProductResponse {
+	var deletedAt *time.Time
+	if p.GetDeletedAt() != nil {
+		t := p.GetDeletedAt().AsTime()
+		deletedAt = &t
+	}
 	return ProductResponse{
 		ID:            p.GetId(),
 		Name:          p.GetName(),
 		Description:   p.GetDescription(),
-		Price:         p.GetPrice(),
-		Currency:      p.GetCurrency(),
+		Price:         money.Format(p.GetPrice()),
+		Currency:      p.GetPrice().GetCurrencyCode(),
 		StockQuantity: p.GetStockQuantity(),
 		CreatedAt:     p.GetCreatedAt().AsTime(),
 		UpdatedAt:     p.GetUpdatedAt().AsTime(),
+		DeletedAt:     deletedAt,
 	}
 }
 
@@ -44,7 +54,7 @@ type ListResponse[T any] struct {
 	Success       bool   `json:"success"`
 	Message       string `json:"message,omitempty"`
 	Data          []T    `json:"data"`
-	NextPageToken uint32 `json:"next_page_token,omitempty"`
+	NextPageToken string `json:"next_page_token,omitempty"`
 	Error         string `json:"error,omitempty"`
 }
 
@@ -64,7 +74,7 @@ func ErrorResponse[T any](errMsg string) *APIResponse[T] {
 	}
 }
 
-func ListSuccessResponse[T any](data []T, nextPageToken uint32, msg string) *ListResponse[T] {
+func ListSuccessResponse[T any](data []T, nextPageToken string, msg string) *ListResponse[T] {
 	return &ListResponse[T]{
 		Success:       true,
 		Message:       msg,
@@ -0,0 +1,115 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode decides how a converted amount that falls between two minor
+// units is rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest minor unit, ties to even
+	// (banker's rounding).
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest minor unit, ties away from zero.
+	RoundHalfUp
+	// RoundDown truncates toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+var roundingModes = map[string]RoundingMode{
+	"half_even": RoundHalfEven,
+	"half_up":   RoundHalfUp,
+	"down":      RoundDown,
+	"up":        RoundUp,
+}
+
+// ParseRoundingMode parses a configured rounding mode. An empty string
+// selects RoundHalfEven.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	if s == "" {
+		return RoundHalfEven, nil
+	}
+	mode, ok := roundingModes[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown rounding mode %q: want half_even, half_up, down or up", s)
+	}
+	return mode, nil
+}
+
+// ParseRate parses a positive exchange rate written as a decimal string,
+// e.g. "0.9215". Rates are kept as exact fractions.
+func ParseRate(s string) (*big.Rat, error) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || (hasFrac && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: rate %q is not a decimal number", ErrInvalidAmount, s)
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: rate must be greater than 0", ErrInvalidAmount)
+	}
+	return rate, nil
+}
+
+// Convert converts an amount in minor units of from into minor units of to,
+// where rate is the number of to units per from unit.
+func Convert(minor int64, from, to Currency, rate *big.Rat, mode RoundingMode) (int64, error) {
+	v := new(big.Rat).SetInt64(minor)
+	v.Mul(v, rate)
+	v.Mul(v, new(big.Rat).SetFrac64(to.scale(), from.scale()))
+
+	n := round(v, mode)
+	if !n.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return n.Int64(), nil
+}
+
+// round rounds v to an integer.
+func round(v *big.Rat, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// away moves q one step away from zero, in the direction of v.
+	away := func() *big.Int {
+		return q.Add(q, big.NewInt(int64(v.Sign())))
+	}
+
+	switch mode {
+	case RoundDown:
+		return q
+	case RoundUp:
+		return away()
+	}
+
+	// Compare the discarded fraction with one half: 2|r| against denom.
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	switch twice.Cmp(v.Denom()) {
+	case -1:
+		return q
+	case 1:
+		return away()
+	}
+	if mode == RoundHalfEven && q.Bit(0) == 0 {
+		return q
+	}
+	return away()
+}
+
+// FormatRate renders an exchange rate as a decimal string with at most
+// digits fractional digits, trimming trailing zeros.
+func FormatRate(rate *big.Rat, digits int) string {
+	s := rate.FloatString(digits)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	usd, _ := LookupCurrency("USD")
+	jpy, _ := LookupCurrency("JPY")
+	kwd, _ := LookupCurrency("KWD")
+
+	tests := []struct {
+		name     string
+		minor    int64
+		from, to Currency
+		rate     string
+		mode     RoundingMode
+		want     int64
+	}{
+		{"exact", 1000, usd, jpy, "150", RoundHalfEven, 1500},
+		{"scales minor units", 1500, jpy, usd, "0.0066", RoundHalfEven, 990},
+		{"half even rounds ties to even", 1, usd, usd, "2.5", RoundHalfEven, 2},
+		{"half even odd tie", 1, usd, usd, "3.5", RoundHalfEven, 4},
+		{"half up", 1, usd, usd, "2.5", RoundHalfUp, 3},
+		{"down", 199, usd, jpy, "0.999", RoundDown, 1},
+		{"up", 101, usd, jpy, "1", RoundUp, 2},
+		{"more minor digits", 1999, usd, kwd, "0.3075", RoundHalfEven, 6147},
+		{"negative half up", -1, usd, usd, "2.5", RoundHalfUp, -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Convert(tt.minor, tt.from, tt.to, rate, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%d) = %d, want %d", tt.minor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert_Overflow(t *testing.T) {
+	usd, _ := LookupCurrency("USD")
+	if _, err := Convert(1<<62, usd, usd, big.NewRat(4, 1), RoundHalfEven); err != ErrOverflow {
+		t.Errorf("error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestParseRate_Invalid(t *testing.T) {
+	for _, s := range []string{"", "0", "0.000", "-1", "1/3", "1e3", "1."} {
+		if _, err := ParseRate(s); err == nil {
+			t.Errorf("ParseRate(%q) succeeded", s)
+		}
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	if mode, err := ParseRoundingMode(""); err != nil || mode != RoundHalfEven {
+		t.Errorf("default = %v, %v; want RoundHalfEven", mode, err)
+	}
+	if mode, err := ParseRoundingMode("HALF_UP"); err != nil || mode != RoundHalfUp {
+		t.Errorf("HALF_UP = %v, %v; want RoundHalfUp", mode, err)
+	}
+	if _, err := ParseRoundingMode("nearest"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	for s, want := range map[string]string{"0.921500": "0.9215", "150": "150", "1.000000000001": "1.000000000001"} {
+		rate, _ := ParseRate(s)
+		if got := FormatRate(rate, 12); got != want {
+			t.Errorf("FormatRate(%s) = %q, want %q", s, got, want)
+		}
+	}
+}
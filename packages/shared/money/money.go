@@ -0,0 +1,176 @@
+// Package money converts between the Money message and the integer minor
+// units prices are stored in, following the ISO 4217 minor unit of each
+// currency.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+)
+
+const nanosPerUnit = 1_000_000_000
+
+var (
+	// ErrUnknownCurrency is returned for codes that are not active ISO 4217
+	// currencies.
+	ErrUnknownCurrency = errors.New("unknown ISO 4217 currency")
+	// ErrInvalidAmount is returned for malformed amounts, such as nanos out
+	// of range or with a sign different from units.
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrTooPrecise is returned for amounts finer than the currency's minor
+	// unit, e.g. fractions of a cent.
+	ErrTooPrecise = errors.New("amount is more precise than the currency's minor unit")
+	// ErrOverflow is returned for amounts that do not fit in int64 minor units.
+	ErrOverflow = errors.New("amount out of range")
+)
+
+// Currency is an ISO 4217 currency.
+type Currency struct {
+	Code string
+	// MinorUnits is the number of decimal digits of the minor unit, e.g. 2
+	// for USD cents and 0 for JPY.
+	MinorUnits int
+}
+
+// LookupCurrency returns the currency for an upper-case ISO 4217 code.
+func LookupCurrency(code string) (Currency, error) {
+	digits, ok := minorUnits[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return Currency{Code: code, MinorUnits: digits}, nil
+}
+
+// scale is the number of minor units in one whole unit.
+func (c Currency) scale() int64 {
+	return pow10(c.MinorUnits)
+}
+
+// nanosPerMinor is the number of nanos in one minor unit.
+func (c Currency) nanosPerMinor() int64 {
+	return pow10(9 - c.MinorUnits)
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// ToMinor validates m and converts it to an integer number of minor units
+// of its currency.
+func ToMinor(m *productsv1.Money) (int64, error) {
+	c, err := LookupCurrency(m.GetCurrencyCode())
+	if err != nil {
+		return 0, err
+	}
+
+	units, nanos := m.GetUnits(), int64(m.GetNanos())
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit {
+		return 0, fmt.Errorf("%w: nanos must be between -999999999 and 999999999", ErrInvalidAmount)
+	}
+	if (units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return 0, fmt.Errorf("%w: units and nanos must have the same sign", ErrInvalidAmount)
+	}
+	if nanos%c.nanosPerMinor() != 0 {
+		return 0, fmt.Errorf("%w: %s has %d decimal places", ErrTooPrecise, c.Code, c.MinorUnits)
+	}
+
+	scale := c.scale()
+	if units > math.MaxInt64/scale-1 || units < math.MinInt64/scale+1 {
+		return 0, ErrOverflow
+	}
+	return units*scale + nanos/c.nanosPerMinor(), nil
+}
+
+// FromMinor builds a Money from an amount in minor units of code. Codes
+// missing from the ISO 4217 table are treated as having no minor unit; the
+// database only holds known codes.
+func FromMinor(code string, minor int64) *productsv1.Money {
+	c := Currency{Code: code, MinorUnits: minorUnits[code]}
+	scale := c.scale()
+	return &productsv1.Money{
+		CurrencyCode: code,
+		Units:        minor / scale,
+		Nanos:        int32(minor % scale * c.nanosPerMinor()),
+	}
+}
+
+// Format renders m as an exact decimal string with the currency's number of
+// decimal places, e.g. "12.30" for USD or "1200" for JPY. Amounts finer than
+// the minor unit keep the digits needed to show them exactly.
+func Format(m *productsv1.Money) string {
+	units, nanos := m.GetUnits(), int64(m.GetNanos())
+
+	var b strings.Builder
+	if units < 0 || nanos < 0 {
+		b.WriteByte('-')
+	}
+	// Negate via uint64 so math.MinInt64 does not overflow.
+	abs := uint64(units)
+	if units < 0 {
+		abs = -abs
+	}
+	b.WriteString(strconv.FormatUint(abs, 10))
+
+	if nanos < 0 {
+		nanos = -nanos
+	}
+	digits := minorUnits[m.GetCurrencyCode()]
+	frac := fmt.Sprintf("%09d", nanos)
+	trimmed := strings.TrimRight(frac, "0")
+	if len(trimmed) > digits {
+		digits = len(trimmed)
+	}
+	if digits > 0 {
+		b.WriteByte('.')
+		b.WriteString(frac[:digits])
+	}
+	return b.String()
+}
+
+// Parse converts a decimal string such as "12.34" into a Money of the given
+// currency, rejecting more decimal places than the currency allows.
+func Parse(code, s string) (*productsv1.Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	whole, frac, hasFrac := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	if whole == "" || (hasFrac && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: %q is not a decimal number", ErrInvalidAmount, s)
+	}
+	if len(strings.TrimRight(frac, "0")) > c.MinorUnits {
+		return nil, fmt.Errorf("%w: %s has %d decimal places", ErrTooPrecise, c.Code, c.MinorUnits)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return nil, ErrOverflow
+	}
+	frac = strings.TrimRight(frac, "0")
+	nanos, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 32)
+	if negative {
+		units, nanos = -units, -nanos
+	}
+
+	return &productsv1.Money{CurrencyCode: c.Code, Units: units, Nanos: int32(nanos)}, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package money
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestToMinor(t *testing.T) {
+	tests := []struct {
+		money *productsv1.Money
+		want  int64
+	}{
+		{&productsv1.Money{CurrencyCode: "USD", Units: 12, Nanos: 340_000_000}, 1234},
+		{&productsv1.Money{CurrencyCode: "USD", Units: -1, Nanos: -500_000_000}, -150},
+		{&productsv1.Money{CurrencyCode: "JPY", Units: 1200}, 1200},
+		{&productsv1.Money{CurrencyCode: "BHD", Units: 1, Nanos: 5_000_000}, 1005},
+		{&productsv1.Money{CurrencyCode: "CLF", Nanos: 100_000}, 1},
+	}
+	for _, tt := range tests {
+		got, err := ToMinor(tt.money)
+		if err != nil {
+			t.Errorf("ToMinor(%v): %v", tt.money, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ToMinor(%v) = %d, want %d", tt.money, got, tt.want)
+		}
+		if back := FromMinor(tt.money.GetCurrencyCode(), got); !proto.Equal(back, tt.money) {
+			t.Errorf("FromMinor(%d) = %v, want %v", got, back, tt.money)
+		}
+	}
+}
+
+func TestToMinor_Invalid(t *testing.T) {
+	tests := []struct {
+		money *productsv1.Money
+		want  error
+	}{
+		{&productsv1.Money{CurrencyCode: "usd", Units: 1}, ErrUnknownCurrency},
+		{&productsv1.Money{CurrencyCode: "XAU", Units: 1}, ErrUnknownCurrency},
+		{&productsv1.Money{CurrencyCode: "USD", Units: 1, Nanos: -1}, ErrInvalidAmount},
+		{&productsv1.Money{CurrencyCode: "USD", Nanos: 1_000_000_000}, ErrInvalidAmount},
+		{&productsv1.Money{CurrencyCode: "USD", Nanos: 5_000_000}, ErrTooPrecise},
+		{&productsv1.Money{CurrencyCode: "JPY", Units: 1, Nanos: 500_000_000}, ErrTooPrecise},
+		{&productsv1.Money{CurrencyCode: "USD", Units: 1 << 62}, ErrOverflow},
+	}
+	for _, tt := range tests {
+		if _, err := ToMinor(tt.money); !errors.Is(err, tt.want) {
+			t.Errorf("ToMinor(%v) error = %v, want %v", tt.money, err, tt.want)
+		}
+	}
+}
+
+func TestFormatAndParse(t *testing.T) {
+	tests := []struct {
+		code, text string
+		money      *productsv1.Money
+	}{
+		{"USD", "12.30", &productsv1.Money{CurrencyCode: "USD", Units: 12, Nanos: 300_000_000}},
+		{"USD", "-0.05", &productsv1.Money{CurrencyCode: "USD", Nanos: -50_000_000}},
+		{"JPY", "1200", &productsv1.Money{CurrencyCode: "JPY", Units: 1200}},
+		{"KWD", "0.125", &productsv1.Money{CurrencyCode: "KWD", Nanos: 125_000_000}},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.money); got != tt.text {
+			t.Errorf("Format(%v) = %q, want %q", tt.money, got, tt.text)
+		}
+		got, err := Parse(tt.code, tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q, %q): %v", tt.code, tt.text, err)
+			continue
+		}
+		if !proto.Equal(got, tt.money) {
+			t.Errorf("Parse(%q, %q) = %v, want %v", tt.code, tt.text, got, tt.money)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		code, text string
+		want       error
+	}{
+		{"USD", "12.345", ErrTooPrecise},
+		{"JPY", "1.5", ErrTooPrecise},
+		{"USD", "1e3", ErrInvalidAmount},
+		{"USD", "12.", ErrInvalidAmount},
+		{"USD", "", ErrInvalidAmount},
+		{"USD", "99999999999999999999", ErrOverflow},
+		{"ABC", "1", ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.code, tt.text); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q, %q) error = %v, want %v", tt.code, tt.text, err, tt.want)
+		}
+	}
+}
+
+// TestCurrenciesMatchSchema guards against the Go table and the database
+// seed drifting apart.
+func TestCurrenciesMatchSchema(t *testing.T) {
+	schema, err := os.ReadFile("../database/schemas/currencies.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded := map[string]int{}
+	for _, m := range regexp.MustCompile(`\('([A-Z]{3})', (\d)\)`).FindAllStringSubmatch(string(schema), -1) {
+		seeded[m[1]], _ = strconv.Atoi(m[2])
+	}
+
+	if len(seeded) != len(minorUnits) {
+		t.Errorf("schema seeds %d currencies, Go table has %d", len(seeded), len(minorUnits))
+	}
+	for code, digits := range minorUnits {
+		if got, ok := seeded[code]; !ok || got != digits {
+			t.Errorf("%s: schema has %d minor units (present %v), Go table has %d", code, got, ok, digits)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event is a domain event read from the outbox table.
+type Event struct {
+	// ID is unique per event; consumers deduplicate redeliveries by it.
+	ID            int64
+	AggregateType string
+	AggregateID   int64
+	// AggregateVersion orders the events of one aggregate.
+	AggregateVersion int64
+	// Type names the change, e.g. "product.updated".
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// Publisher delivers events to other services. The relay calls Publish for
+// one event at a time and only publishes an event once every earlier event
+// of its aggregate has been published. Delivery is at least once: an event
+// is published again if the relay fails to record that it was published.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// LogPublisher writes events to the log. It stands in for a message broker
+// during development.
+type LogPublisher struct {
+	log *zap.Logger
+}
+
+func NewLogPublisher(logger *zap.Logger) *LogPublisher {
+	return &LogPublisher{log: logger.Named("outbox")}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event Event) error {
+	p.log.Info("published event",
+		zap.Int64("id", event.ID),
+		zap.String("type", event.Type),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.Int64("aggregate_id", event.AggregateID),
+		zap.Int64("aggregate_version", event.AggregateVersion),
+		zap.ByteString("payload", event.Payload),
+	)
+	return nil
+}
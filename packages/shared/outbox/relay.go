@@ -0,0 +1,326 @@
+// Package outbox relays domain events from the transactional outbox table
+// to a Publisher. Events are written in the same statement as the change
+// they describe, so a crash between the change and its publication delays
+// the event instead of losing it.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/store"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMinBackoff   = time.Second
+	defaultMaxBackoff   = 5 * time.Minute
+	defaultRetention    = 7 * 24 * time.Hour
+	defaultLeaseTTL     = 30 * time.Second
+
+	// maxErrorBytes caps the publish error stored with a failed event.
+	maxErrorBytes = 1024
+)
+
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Logger    *zap.Logger
+	Config    *config.Config
+	Store     store.ProductStore
+	Publisher Publisher
+	Registry  *prometheus.Registry
+}
+
+// Module registers the outbox relay. The application provides the
+// Publisher events are relayed to.
+//
+// Relays publishing concurrently could deliver the events of an aggregate
+// out of order, so a relay only publishes while it holds the lease in
+// outbox_leases. Further relays on the same database stand by and take
+// over once the lease expires.
+var Module = fx.Module("outbox",
+	fx.Provide(NewRelay),
+	fx.Invoke(func(*Relay) {}),
+)
+
+// Relay publishes pending outbox events in order per aggregate, retrying
+// failed events with exponential backoff. A failed event holds back the
+// later events of its aggregate until it is published.
+type Relay struct {
+	queries    store.OutboxStore
+	publisher  Publisher
+	log        *zap.Logger
+	metrics    *relayMetrics
+	batchSize  int32
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	retention  time.Duration
+	holder     string
+	leaseTTL   time.Duration
+}
+
+type relayMetrics struct {
+	pending   prometheus.Gauge
+	lag       prometheus.Gauge
+	published *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	delivery  prometheus.Histogram
+}
+
+func newRelayMetrics(registry prometheus.Registerer) *relayMetrics {
+	m := &relayMetrics{
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "myapp",
+			Subsystem: "outbox",
+			Name:      "pending_events",
+			Help:      "Events waiting to be published.",
+		}),
+		lag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "myapp",
+			Subsystem: "outbox",
+			Name:      "lag_seconds",
+			Help:      "Age of the oldest event waiting to be published.",
+		}),
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "myapp",
+			Subsystem: "outbox",
+			Name:      "published_total",
+			Help:      "Events published.",
+		}, []string{"event_type"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "myapp",
+			Subsystem: "outbox",
+			Name:      "publish_failures_total",
+			Help:      "Failed publish attempts.",
+		}, []string{"event_type"}),
+		delivery: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "myapp",
+			Subsystem: "outbox",
+			Name:      "delivery_seconds",
+			Help:      "Time from writing an event to publishing it.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+		}),
+	}
+	registry.MustRegister(m.pending, m.lag, m.published, m.failures, m.delivery)
+	return m
+}
+
+func NewRelay(p Params) *Relay {
+	cfg := p.Config.OutboxConfig
+	relay := &Relay{
+		queries:    p.Store,
+		publisher:  p.Publisher,
+		log:        p.Logger.Named("outbox"),
+		metrics:    newRelayMetrics(p.Registry),
+		batchSize:  cfg.BatchSize,
+		minBackoff: cfg.MinBackoff,
+		maxBackoff: cfg.MaxBackoff,
+		retention:  cfg.Retention,
+		holder:     rand.Text(),
+		leaseTTL:   cfg.LeaseTTL,
+	}
+	if relay.batchSize <= 0 {
+		relay.batchSize = defaultBatchSize
+	}
+	if relay.minBackoff <= 0 {
+		relay.minBackoff = defaultMinBackoff
+	}
+	if relay.maxBackoff < relay.minBackoff {
+		relay.maxBackoff = max(defaultMaxBackoff, relay.minBackoff)
+	}
+	if relay.retention <= 0 {
+		relay.retention = defaultRetention
+	}
+	if relay.leaseTTL <= 0 {
+		relay.leaseTTL = defaultLeaseTTL
+	}
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			relay.log.Info("outbox relay started", zap.Duration("interval", interval))
+
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						now := time.Now()
+						if _, err := relay.Run(ctx, now); err != nil && ctx.Err() == nil {
+							relay.log.Warn("relaying outbox events failed", zap.Error(err))
+						}
+						if err := relay.Cleanup(ctx, now); err != nil && ctx.Err() == nil {
+							relay.log.Warn("removing published outbox events failed", zap.Error(err))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			if err := relay.queries.ReleaseOutboxLease(stopCtx, relay.holder); err != nil {
+				relay.log.Warn("releasing outbox lease failed", zap.Error(err))
+			}
+			return nil
+		},
+	})
+
+	return relay
+}
+
+type aggregateKey struct {
+	typ string
+	id  int64
+}
+
+// Run takes or renews the relay lease, publishes the events due at now,
+// updates the lag metrics and returns how many events were published. It
+// publishes nothing while another relay holds the lease.
+func (r *Relay) Run(ctx context.Context, now time.Time) (int, error) {
+	held, err := r.queries.AcquireOutboxLease(ctx, repository.AcquireOutboxLeaseParams{
+		Holder: r.holder,
+		Ttl:    pgtype.Interval{Microseconds: r.leaseTTL.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if held == 0 {
+		return 0, nil
+	}
+	// Publishing stops halfway through the lease; the other half covers
+	// the time taken to acquire it and the drift between the clocks.
+	leaseCtx, cancel := context.WithTimeout(ctx, r.leaseTTL/2)
+	defer cancel()
+
+	rows, err := r.queries.ListPendingOutboxEvents(ctx, repository.ListPendingOutboxEventsParams{
+		Now:       now,
+		BatchSize: r.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[aggregateKey]bool)
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+		if leaseCtx.Err() != nil {
+			break
+		}
+		key := aggregateKey{row.AggregateType, row.AggregateID}
+		if blocked[key] {
+			continue
+		}
+		if row.NextAttemptAt.After(now) {
+			blocked[key] = true
+			continue
+		}
+
+		if err := r.publisher.Publish(leaseCtx, eventFromRow(row)); err != nil {
+			if leaseCtx.Err() != nil {
+				break
+			}
+			blocked[key] = true
+			r.metrics.failures.WithLabelValues(row.EventType).Inc()
+			r.log.Warn("publishing outbox event failed",
+				zap.Int64("id", row.ID),
+				zap.String("type", row.EventType),
+				zap.Int32("attempts", row.Attempts+1),
+				zap.Error(err),
+			)
+			if err := r.queries.MarkOutboxEventFailed(ctx, repository.MarkOutboxEventFailedParams{
+				ID:            row.ID,
+				LastError:     pgtype.Text{String: truncate(err.Error(), maxErrorBytes), Valid: true},
+				NextAttemptAt: now.Add(r.backoff(row.Attempts)),
+			}); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.queries.MarkOutboxEventPublished(ctx, row.ID); err != nil {
+			return published, err
+		}
+		published++
+		r.metrics.published.WithLabelValues(row.EventType).Inc()
+		r.metrics.delivery.Observe(now.Sub(row.CreatedAt).Seconds())
+	}
+
+	backlog, err := r.queries.GetOutboxBacklog(ctx, now)
+	if err != nil {
+		return published, err
+	}
+	r.metrics.pending.Set(float64(backlog.Pending))
+	r.metrics.lag.Set(max(now.Sub(backlog.Oldest), 0).Seconds())
+
+	return published, nil
+}
+
+// Cleanup removes up to one batch of events published more than the
+// retention before now.
+func (r *Relay) Cleanup(ctx context.Context, now time.Time) error {
+	_, err := r.queries.DeletePublishedOutboxEvents(ctx, repository.DeletePublishedOutboxEventsParams{
+		PublishedBefore: now.Add(-r.retention),
+		BatchSize:       r.batchSize,
+	})
+	return err
+}
+
+// backoff returns the delay before retrying an event that has already
+// failed attempts times.
+func (r *Relay) backoff(attempts int32) time.Duration {
+	delay := r.minBackoff
+	for range attempts {
+		if delay >= r.maxBackoff/2 {
+			return r.maxBackoff
+		}
+		delay *= 2
+	}
+	return min(delay, r.maxBackoff)
+}
+
+func eventFromRow(row repository.Outbox) Event {
+	return Event{
+		ID:               row.ID,
+		AggregateType:    row.AggregateType,
+		AggregateID:      row.AggregateID,
+		AggregateVersion: row.AggregateVersion,
+		Type:             row.EventType,
+		Payload:          row.Payload,
+		CreatedAt:        row.CreatedAt,
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,235 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"go.uber.org/zap"
+)
+
+// fakeDB serves pending events and records the statements the relay runs.
+// The relay lease is taken by another relay when leased is set.
+type fakeDB struct {
+	repository.DBTX
+	leased  bool
+	pending []repository.Outbox
+	oldest  time.Time
+	execs   []string
+	args    [][]any
+}
+
+func (f *fakeDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &fakeRows{rows: f.pending}, nil
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, _ string, args ...any) pgx.Row {
+	oldest := f.oldest
+	if oldest.IsZero() {
+		oldest = args[0].(time.Time)
+	}
+	return fakeBacklog{pending: int64(len(f.pending)), oldest: oldest}
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	name := strings.Fields(strings.TrimPrefix(sql, "-- name: "))[0]
+	f.execs = append(f.execs, name)
+	f.args = append(f.args, args)
+	if name == "AcquireOutboxLease" && f.leased {
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+type fakeBacklog struct {
+	pending int64
+	oldest  time.Time
+}
+
+func (b fakeBacklog) Scan(dest ...any) error {
+	*dest[0].(*int64) = b.pending
+	*dest[1].(*time.Time) = b.oldest
+	return nil
+}
+
+type fakeRows struct {
+	pgx.Rows
+	rows []repository.Outbox
+	cur  repository.Outbox
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	r.cur, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	v := reflect.ValueOf(r.cur)
+	for i := range dest {
+		reflect.ValueOf(dest[i]).Elem().Set(v.Field(i))
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error { return nil }
+func (r *fakeRows) Close()     {}
+
+// fakePublisher fails the events whose ids are in fail.
+type fakePublisher struct {
+	fail      map[int64]bool
+	published []int64
+}
+
+func (p *fakePublisher) Publish(_ context.Context, e Event) error {
+	if p.fail[e.ID] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, e.ID)
+	return nil
+}
+
+func newTestRelay(db *fakeDB, publisher Publisher) *Relay {
+	return &Relay{
+		queries:    repository.New(db),
+		publisher:  publisher,
+		log:        zap.NewNop(),
+		metrics:    newRelayMetrics(prometheus.NewRegistry()),
+		batchSize:  100,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		retention:  time.Hour,
+		holder:     "relay-1",
+		leaseTTL:   time.Minute,
+	}
+}
+
+func event(id, productID, version int64, due time.Time) repository.Outbox {
+	return repository.Outbox{
+		ID:               id,
+		AggregateType:    "product",
+		AggregateID:      productID,
+		AggregateVersion: version,
+		EventType:        "product.updated",
+		Payload:          []byte(`{}`),
+		CreatedAt:        due.Add(-time.Minute),
+		NextAttemptAt:    due,
+	}
+}
+
+func TestRelayRun_FailureHoldsBackLaterEventsOfTheAggregate(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{
+		pending: []repository.Outbox{
+			event(1, 7, 1, now),
+			event(2, 7, 2, now),
+			event(3, 8, 1, now),
+			event(4, 8, 2, now),
+		},
+		oldest: now.Add(-time.Minute),
+	}
+	publisher := &fakePublisher{fail: map[int64]bool{3: true}}
+	relay := newTestRelay(db, publisher)
+
+	n, err := relay.Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("published %d events, want 2", n)
+	}
+	if !reflect.DeepEqual(publisher.published, []int64{1, 2}) {
+		t.Errorf("published %v, want [1 2]", publisher.published)
+	}
+
+	want := []string{"AcquireOutboxLease", "MarkOutboxEventPublished", "MarkOutboxEventPublished", "MarkOutboxEventFailed"}
+	if !reflect.DeepEqual(db.execs, want) {
+		t.Fatalf("ran %v, want %v", db.execs, want)
+	}
+	if db.args[0][0] != "relay-1" {
+		t.Errorf("took the lease for %v, want relay-1", db.args[0][0])
+	}
+	failed := db.args[3]
+	if failed[0] != (pgtype.Text{String: "broker unavailable", Valid: true}) {
+		t.Errorf("recorded error %v", failed[0])
+	}
+	if failed[1] != now.Add(time.Second) {
+		t.Errorf("next attempt at %v, want %v", failed[1], now.Add(time.Second))
+	}
+	if failed[2] != int64(3) {
+		t.Errorf("marked event %v failed, want 3", failed[2])
+	}
+
+	if got := testutil.ToFloat64(relay.metrics.lag); got != 60 {
+		t.Errorf("lag = %v, want 60", got)
+	}
+	if got := testutil.ToFloat64(relay.metrics.failures.WithLabelValues("product.updated")); got != 1 {
+		t.Errorf("failures = %v, want 1", got)
+	}
+}
+
+func TestRelayRun_SkipsAggregateWaitingForRetry(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{pending: []repository.Outbox{
+		event(1, 7, 1, now.Add(time.Minute)),
+		event(2, 7, 2, now),
+	}}
+	publisher := &fakePublisher{}
+	relay := newTestRelay(db, publisher)
+
+	if _, err := relay.Run(context.Background(), now); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("published %v, want nothing", publisher.published)
+	}
+}
+
+func TestRelayRun_StandsByWhileAnotherRelayHoldsTheLease(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	db := &fakeDB{leased: true, pending: []repository.Outbox{event(1, 7, 1, now)}}
+	publisher := &fakePublisher{}
+	relay := newTestRelay(db, publisher)
+
+	n, err := relay.Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if n != 0 || len(publisher.published) != 0 {
+		t.Errorf("published %v, want nothing", publisher.published)
+	}
+	if want := []string{"AcquireOutboxLease"}; !reflect.DeepEqual(db.execs, want) {
+		t.Errorf("ran %v, want %v", db.execs, want)
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := newTestRelay(&fakeDB{}, &fakePublisher{})
+
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{5, 32 * time.Second},
+		{6, time.Minute},
+		{1000, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := relay.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
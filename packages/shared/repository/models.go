@@ -10,13 +10,141 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Category struct {
+	ID        int64       `json:"id"`
+	ParentID  pgtype.Int8 `json:"parent_id"`
+	Name      string      `json:"name"`
+	Path      string      `json:"path"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type Currency struct {
+	Code       string `json:"code"`
+	MinorUnits int16  `json:"minor_units"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string         `json:"base_currency"`
+	QuoteCurrency string         `json:"quote_currency"`
+	Rate          pgtype.Numeric `json:"rate"`
+	EffectiveAt   time.Time      `json:"effective_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Method         string    `json:"method"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	RequestHash    []byte    `json:"request_hash"`
+	Response       []byte    `json:"response"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type Outbox struct {
+	ID               int64              `json:"id"`
+	AggregateType    string             `json:"aggregate_type"`
+	AggregateID      int64              `json:"aggregate_id"`
+	AggregateVersion int64              `json:"aggregate_version"`
+	EventType        string             `json:"event_type"`
+	Payload          []byte             `json:"payload"`
+	CreatedAt        time.Time          `json:"created_at"`
+	Attempts         int32              `json:"attempts"`
+	NextAttemptAt    time.Time          `json:"next_attempt_at"`
+	LastError        pgtype.Text        `json:"last_error"`
+	PublishedAt      pgtype.Timestamptz `json:"published_at"`
+}
+
+type OutboxLease struct {
+	Name      string    `json:"name"`
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type Product struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	PriceMinor    int64              `json:"price_minor"`
+	Currency      string             `json:"currency"`
+	StockQuantity int32              `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int64              `json:"version"`
+	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
+}
+
+type ProductCategory struct {
+	ProductID  int64 `json:"product_id"`
+	CategoryID int64 `json:"category_id"`
+}
+
+type ProductMedia struct {
+	ID             int64     `json:"id"`
+	ProductID      int64     `json:"product_id"`
+	Position       int32     `json:"position"`
+	StorageKey     string    `json:"storage_key"`
+	ContentType    string    `json:"content_type"`
+	ChecksumSha256 string    `json:"checksum_sha256"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Width          int32     `json:"width"`
+	Height         int32     `json:"height"`
+	AltText        string    `json:"alt_text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ProductRevision struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	Version   int64     `json:"version"`
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	Previous  []byte    `json:"previous"`
+	Snapshot  []byte    `json:"snapshot"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ProductTag struct {
+	ProductID int64  `json:"product_id"`
+	Tag       string `json:"tag"`
+}
+
+type ProductVariant struct {
 	ID            int64       `json:"id"`
-	Name          string      `json:"name"`
-	Description   pgtype.Text `json:"description"`
-	Price         float64     `json:"price"`
-	Currency      string      `json:"currency"`
+	ProductID     int64       `json:"product_id"`
+	Sku           string      `json:"sku"`
+	Options       []byte      `json:"options"`
+	OptionKey     string      `json:"option_key"`
+	PriceMinor    pgtype.Int8 `json:"price_minor"`
+	Currency      pgtype.Text `json:"currency"`
 	StockQuantity int32       `json:"stock_quantity"`
 	CreatedAt     time.Time   `json:"created_at"`
 	UpdatedAt     time.Time   `json:"updated_at"`
 }
+
+type PurgedMediaBlob struct {
+	StorageKey string    `json:"storage_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type StockMovement struct {
+	ID        int64       `json:"id"`
+	ProductID int64       `json:"product_id"`
+	Delta     int32       `json:"delta"`
+	Reason    string      `json:"reason"`
+	Reference pgtype.Text `json:"reference"`
+	Note      pgtype.Text `json:"note"`
+	CreatedAt time.Time   `json:"created_at"`
+	VariantID pgtype.Int8 `json:"variant_id"`
+}
+
+type StockReservation struct {
+	ID        int64       `json:"id"`
+	ProductID int64       `json:"product_id"`
+	Quantity  int32       `json:"quantity"`
+	Status    string      `json:"status"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	VariantID pgtype.Int8 `json:"variant_id"`
+}
@@ -13,51 +13,168 @@ import (
 )
 
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (id, name, description, price, currency, stock_quantity)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, name, description, price, currency, stock_quantity, created_at, updated_at
+WITH created AS (
+  INSERT INTO products (id, name, description, price_minor, currency, stock_quantity)
+  VALUES ($1, $2, $3, $4, $5, $6)
+  RETURNING id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at
+), opening AS (
+  INSERT INTO stock_movements (product_id, delta, reason, note)
+  SELECT created.id, created.stock_quantity, 'receipt', 'initial stock'
+  FROM created
+  WHERE created.stock_quantity > 0
+  RETURNING stock_movements.id
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, snapshot)
+  SELECT created.id, created.version, 'create', $7::text,
+         product_snapshot(to_jsonb(created), $8::int8[], $9::string[])
+  FROM created
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.created',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM created
 `
 
 type CreateProductParams struct {
 	ID            int64       `json:"id"`
 	Name          string      `json:"name"`
 	Description   pgtype.Text `json:"description"`
-	Price         float64     `json:"price"`
+	PriceMinor    int64       `json:"price_minor"`
 	Currency      string      `json:"currency"`
 	StockQuantity int32       `json:"stock_quantity"`
+	Actor         string      `json:"actor"`
+	CategoryIds   []int64     `json:"category_ids"`
+	Tags          []string    `json:"tags"`
+}
+
+type CreateProductRow struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	PriceMinor    int64              `json:"price_minor"`
+	Currency      string             `json:"currency"`
+	StockQuantity int32              `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int64              `json:"version"`
+	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
 }
 
-func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
+// Initial stock is booked in the ledger as a receipt, and the product's
+// first revision and its product.created event are recorded.
+func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (CreateProductRow, error) {
 	row := q.db.QueryRow(ctx, createProduct,
 		arg.ID,
 		arg.Name,
 		arg.Description,
-		arg.Price,
+		arg.PriceMinor,
 		arg.Currency,
 		arg.StockQuantity,
+		arg.Actor,
+		arg.CategoryIds,
+		arg.Tags,
 	)
-	var i Product
+	var i CreateProductRow
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Description,
-		&i.Price,
+		&i.PriceMinor,
 		&i.Currency,
 		&i.StockQuantity,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const deleteProduct = `-- name: DeleteProduct :exec
-DELETE FROM products
-WHERE id = $1
+const currentTime = `-- name: CurrentTime :one
+SELECT now()::timestamptz
+`
+
+// The database clock. Watch positions are compared with updated_at, which
+// the database sets, so a new watch starts from this time rather than the
+// server's.
+func (q *Queries) CurrentTime(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRow(ctx, currentTime)
+	var now time.Time
+	err := row.Scan(&now)
+	return now, err
+}
+
+const deleteProduct = `-- name: DeleteProduct :one
+WITH previous AS (
+  SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+  WHERE products.id = $1
+), deleted AS (
+  UPDATE products
+  SET deleted_at = now(),
+      updated_at = now(),
+      version    = version + 1
+  WHERE id = $1
+    AND deleted_at IS NULL
+    AND ($2::int8 IS NULL OR version = $2)
+  RETURNING id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT deleted.id, deleted.version, 'delete', $3::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(deleted), NULL, NULL)
+  FROM deleted
+  JOIN previous ON previous.id = deleted.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.deleted',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM deleted
 `
 
-func (q *Queries) DeleteProduct(ctx context.Context, id int64) error {
-	_, err := q.db.Exec(ctx, deleteProduct, id)
-	return err
+type DeleteProductParams struct {
+	ID              int64       `json:"id"`
+	ExpectedVersion pgtype.Int8 `json:"expected_version"`
+	Actor           string      `json:"actor"`
+}
+
+type DeleteProductRow struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	PriceMinor    int64              `json:"price_minor"`
+	Currency      string             `json:"currency"`
+	StockQuantity int32              `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int64              `json:"version"`
+	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
+}
+
+// Soft delete; the row is kept until PurgeDeletedProducts removes it.
+func (q *Queries) DeleteProduct(ctx context.Context, arg DeleteProductParams) (DeleteProductRow, error) {
+	row := q.db.QueryRow(ctx, deleteProduct, arg.ID, arg.ExpectedVersion, arg.Actor)
+	var i DeleteProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
 const findProductWithStockInfo = `-- name: FindProductWithStockInfo :one
@@ -65,7 +182,7 @@ SELECT
   p.id                AS product_id,
   p.name              AS product_name,
   p.description       AS product_description,
-  p.price             AS product_price,
+  p.price_minor       AS product_price_minor,
   p.currency          AS product_currency,
   p.stock_quantity    AS product_stock_quantity,
   p.created_at        AS product_created_at,
@@ -78,7 +195,7 @@ type FindProductWithStockInfoRow struct {
 	ProductID            int64       `json:"product_id"`
 	ProductName          string      `json:"product_name"`
 	ProductDescription   pgtype.Text `json:"product_description"`
-	ProductPrice         float64     `json:"product_price"`
+	ProductPriceMinor    int64       `json:"product_price_minor"`
 	ProductCurrency      string      `json:"product_currency"`
 	ProductStockQuantity int32       `json:"product_stock_quantity"`
 	ProductCreatedAt     time.Time   `json:"product_created_at"`
@@ -92,7 +209,7 @@ func (q *Queries) FindProductWithStockInfo(ctx context.Context, id int64) (FindP
 		&i.ProductID,
 		&i.ProductName,
 		&i.ProductDescription,
-		&i.ProductPrice,
+		&i.ProductPriceMinor,
 		&i.ProductCurrency,
 		&i.ProductStockQuantity,
 		&i.ProductCreatedAt,
@@ -102,10 +219,13 @@ func (q *Queries) FindProductWithStockInfo(ctx context.Context, id int64) (FindP
 }
 
 const getProductByID = `-- name: GetProductByID :one
-SELECT id, name, description, price, currency, stock_quantity, created_at, updated_at FROM products
-WHERE id = $1
+
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+WHERE id = $1 AND deleted_at IS NULL
 `
 
+// ListProducts is built dynamically in repository/products_list.go because
+// its filters and sort order vary per request.
 func (q *Queries) GetProductByID(ctx context.Context, id int64) (Product, error) {
 	row := q.db.QueryRow(ctx, getProductByID, id)
 	var i Product
@@ -113,28 +233,48 @@ func (q *Queries) GetProductByID(ctx context.Context, id int64) (Product, error)
 		&i.ID,
 		&i.Name,
 		&i.Description,
-		&i.Price,
+		&i.PriceMinor,
 		&i.Currency,
 		&i.StockQuantity,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
-const listProducts = `-- name: ListProducts :many
-SELECT id, name, description, price, currency, stock_quantity, created_at, updated_at FROM products
-ORDER BY id
-LIMIT $1 OFFSET $2
+const getProductByIDWithDeleted = `-- name: GetProductByIDWithDeleted :one
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+WHERE id = $1
 `
 
-type ListProductsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+func (q *Queries) GetProductByIDWithDeleted(ctx context.Context, id int64) (Product, error) {
+	row := q.db.QueryRow(ctx, getProductByIDWithDeleted, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
-func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error) {
-	rows, err := q.db.Query(ctx, listProducts, arg.Limit, arg.Offset)
+const getProductsByIDs = `-- name: GetProductsByIDs :many
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+WHERE id = ANY($1::int8[])
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) GetProductsByIDs(ctx context.Context, ids []int64) ([]Product, error) {
+	rows, err := q.db.Query(ctx, getProductsByIDs, ids)
 	if err != nil {
 		return nil, err
 	}
@@ -146,11 +286,13 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 			&i.ID,
 			&i.Name,
 			&i.Description,
-			&i.Price,
+			&i.PriceMinor,
 			&i.Currency,
 			&i.StockQuantity,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -161,3 +303,388 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 	}
 	return items, nil
 }
+
+const listProductChanges = `-- name: ListProductChanges :many
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+WHERE (updated_at, id) > ($1::timestamptz, $2::int8)
+  AND updated_at <= now() - $3::interval
+ORDER BY updated_at, id
+LIMIT $4
+`
+
+type ListProductChangesParams struct {
+	AfterUpdatedAt time.Time       `json:"after_updated_at"`
+	AfterID        int64           `json:"after_id"`
+	Settle         pgtype.Interval `json:"settle"`
+	PageLimit      int32           `json:"page_limit"`
+}
+
+// Products changed after the (updated_at, id) cursor, oldest first. Rows
+// newer than the settle window are held back so that a transaction
+// committing late with an earlier updated_at is not skipped.
+func (q *Queries) ListProductChanges(ctx context.Context, arg ListProductChangesParams) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProductChanges,
+		arg.AfterUpdatedAt,
+		arg.AfterID,
+		arg.Settle,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.StockQuantity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductNames = `-- name: ListProductNames :many
+SELECT id, name FROM products
+WHERE deleted_at IS NULL
+ORDER BY id
+`
+
+type ListProductNamesRow struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) ListProductNames(ctx context.Context) ([]ListProductNamesRow, error) {
+	rows, err := q.db.Query(ctx, listProductNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProductNamesRow
+	for rows.Next() {
+		var i ListProductNamesRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedProducts = `-- name: PurgeDeletedProducts :execrows
+WITH purged AS (
+  DELETE FROM products
+  WHERE id IN (
+    SELECT id FROM products
+    WHERE deleted_at < $1::timestamptz
+    ORDER BY deleted_at
+    LIMIT $2
+  )
+  RETURNING id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at
+), blobs AS (
+  INSERT INTO purged_media_blobs (storage_key)
+  SELECT storage_key FROM product_media
+  WHERE product_id IN (SELECT id FROM purged)
+  ON CONFLICT (storage_key) DO NOTHING
+  RETURNING purged_media_blobs.storage_key
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT purged.id, purged.version + 1, 'purge', 'system',
+         product_snapshot(to_jsonb(purged), NULL, NULL),
+         product_snapshot(to_jsonb(purged), NULL, NULL) || jsonb_build_object('updated_at', now(), 'version', purged.version + 1)
+  FROM purged
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+)
+INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+SELECT 'product', revision.product_id, revision.version, 'product.purged',
+       jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+FROM revision
+`
+
+type PurgeDeletedProductsParams struct {
+	DeletedBefore time.Time `json:"deleted_before"`
+	BatchSize     int32     `json:"batch_size"`
+}
+
+// Hard deletes up to batch_size products soft deleted before deleted_before
+// and records a purge revision for each, which WatchProducts reports, and
+// its product.purged event.
+// Their reservations, variants, media, categories and tags go with them;
+// their ledger entries and revisions are kept. The blobs of their media are
+// queued in purged_media_blobs for the gateway to delete.
+func (q *Queries) PurgeDeletedProducts(ctx context.Context, arg PurgeDeletedProductsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedProducts, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchProducts = `-- name: SearchProducts :many
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, score
+FROM (
+  SELECT
+    p.id, p.name, p.description, p.price_minor, p.currency, p.stock_quantity, p.created_at, p.updated_at, p.version, p.deleted_at,
+    (ts_rank(to_tsvector('english', p.name || ' ' || COALESCE(p.description, '')), plainto_tsquery('english', $1::text))
+      + similarity(p.name, $1::text))::float8 AS score
+  FROM products p
+  WHERE p.deleted_at IS NULL
+    AND (to_tsvector('english', p.name || ' ' || COALESCE(p.description, '')) @@ plainto_tsquery('english', $1::text)
+         OR p.name % $1::text)
+) ranked
+WHERE $2::float8 IS NULL
+   OR (score, id) < ($2::float8, $3::int8)
+ORDER BY score DESC, id DESC
+LIMIT $4
+`
+
+type SearchProductsParams struct {
+	Query      string        `json:"query"`
+	AfterScore pgtype.Float8 `json:"after_score"`
+	AfterID    int64         `json:"after_id"`
+	PageLimit  int32         `json:"page_limit"`
+}
+
+type SearchProductsRow struct {
+	ID            int64       `json:"id"`
+	Name          string      `json:"name"`
+	Description   pgtype.Text `json:"description"`
+	PriceMinor    int64       `json:"price_minor"`
+	Currency      string      `json:"currency"`
+	StockQuantity int32       `json:"stock_quantity"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Version       int64       `json:"version"`
+	Score         float64     `json:"score"`
+}
+
+// Ranks by full-text relevance plus trigram similarity of the name so that
+// misspelled queries still match. Keyset pagination runs on (score, id).
+func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error) {
+	rows, err := q.db.Query(ctx, searchProducts,
+		arg.Query,
+		arg.AfterScore,
+		arg.AfterID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProductsRow
+	for rows.Next() {
+		var i SearchProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.StockQuantity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const undeleteProduct = `-- name: UndeleteProduct :one
+WITH previous AS (
+  SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+  WHERE products.id = $1
+), restored AS (
+  UPDATE products
+  SET deleted_at = NULL,
+      updated_at = now(),
+      version    = version + 1
+  WHERE id = $1
+    AND deleted_at IS NOT NULL
+    AND ($2::int8 IS NULL OR version = $2)
+  RETURNING id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT restored.id, restored.version, 'undelete', $3::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(restored), NULL, NULL)
+  FROM restored
+  JOIN previous ON previous.id = restored.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.undeleted',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM restored
+`
+
+type UndeleteProductParams struct {
+	ID              int64       `json:"id"`
+	ExpectedVersion pgtype.Int8 `json:"expected_version"`
+	Actor           string      `json:"actor"`
+}
+
+type UndeleteProductRow struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	PriceMinor    int64              `json:"price_minor"`
+	Currency      string             `json:"currency"`
+	StockQuantity int32              `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int64              `json:"version"`
+	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
+}
+
+func (q *Queries) UndeleteProduct(ctx context.Context, arg UndeleteProductParams) (UndeleteProductRow, error) {
+	row := q.db.QueryRow(ctx, undeleteProduct, arg.ID, arg.ExpectedVersion, arg.Actor)
+	var i UndeleteProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+WITH previous AS (
+  SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM products
+  WHERE products.id = $1
+), updated AS (
+  UPDATE products
+  SET
+    name           = CASE WHEN $2::boolean THEN $3::text ELSE name END,
+    description    = CASE WHEN $4::boolean THEN $5::text ELSE description END,
+    price_minor    = CASE WHEN $6::boolean THEN $7::int8 ELSE price_minor END,
+    currency       = CASE WHEN $6::boolean THEN $8::text ELSE currency END,
+    stock_quantity = CASE WHEN $9::boolean THEN $10::int4 ELSE stock_quantity END,
+    updated_at     = now(),
+    version        = version + 1
+  WHERE id = $1
+    AND deleted_at IS NULL
+    AND ($11::int8 IS NULL OR version = $11)
+  RETURNING id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at
+), corrected AS (
+  INSERT INTO stock_movements (product_id, delta, reason, note)
+  SELECT updated.id, updated.stock_quantity - previous.stock_quantity, 'correction', 'stock set by update'
+  FROM updated
+  JOIN previous ON previous.id = updated.id
+  WHERE updated.stock_quantity <> previous.stock_quantity
+  RETURNING stock_movements.id
+), revision AS (
+  INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot)
+  SELECT updated.id, updated.version, 'update', $12::text,
+         product_snapshot(to_jsonb(previous), NULL, NULL),
+         product_snapshot(to_jsonb(updated), $13::int8[], $14::string[])
+  FROM updated
+  JOIN previous ON previous.id = updated.id
+  RETURNING product_revisions.product_id, product_revisions.version, product_revisions.actor, product_revisions.snapshot
+), event AS (
+  INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload)
+  SELECT 'product', revision.product_id, revision.version, 'product.updated',
+         jsonb_build_object('actor', revision.actor, 'product', revision.snapshot)
+  FROM revision
+  RETURNING outbox.id
+)
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at FROM updated
+`
+
+type UpdateProductParams struct {
+	ID               int64       `json:"id"`
+	SetName          bool        `json:"set_name"`
+	Name             string      `json:"name"`
+	SetDescription   bool        `json:"set_description"`
+	Description      pgtype.Text `json:"description"`
+	SetPrice         bool        `json:"set_price"`
+	PriceMinor       int64       `json:"price_minor"`
+	Currency         string      `json:"currency"`
+	SetStockQuantity bool        `json:"set_stock_quantity"`
+	StockQuantity    int32       `json:"stock_quantity"`
+	ExpectedVersion  pgtype.Int8 `json:"expected_version"`
+	Actor            string      `json:"actor"`
+	CategoryIds      []int64     `json:"category_ids"`
+	Tags             []string    `json:"tags"`
+}
+
+type UpdateProductRow struct {
+	ID            int64              `json:"id"`
+	Name          string             `json:"name"`
+	Description   pgtype.Text        `json:"description"`
+	PriceMinor    int64              `json:"price_minor"`
+	Currency      string             `json:"currency"`
+	StockQuantity int32              `json:"stock_quantity"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	Version       int64              `json:"version"`
+	DeletedAt     pgtype.Timestamptz `json:"deleted_at"`
+}
+
+// Stock set through an update is booked in the ledger as a correction.
+func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (UpdateProductRow, error) {
+	row := q.db.QueryRow(ctx, updateProduct,
+		arg.ID,
+		arg.SetName,
+		arg.Name,
+		arg.SetDescription,
+		arg.Description,
+		arg.SetPrice,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.SetStockQuantity,
+		arg.StockQuantity,
+		arg.ExpectedVersion,
+		arg.Actor,
+		arg.CategoryIds,
+		arg.Tags,
+	)
+	var i UpdateProductRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+	)
+	return i, err
+}
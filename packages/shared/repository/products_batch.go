@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// BatchCreateProducts pipelines CreateProduct for many products in one
+// round trip. sqlc's :batchone output would widen DBTX for every query, so
+// the batch is written by hand and needs a connection that can send
+// batches: *pgxpool.Pool, *pgx.Conn and pgx.Tx all can.
+
+var (
+	ErrBatchAlreadyClosed = errors.New("batch already closed")
+	ErrBatchNotSupported  = errors.New("connection cannot send batches")
+)
+
+// batchSender is the part of a pgx connection BatchCreateProducts needs
+// beyond DBTX.
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type BatchCreateProductsBatchResults struct {
+	br     pgx.BatchResults
+	tot    int
+	closed bool
+	err    error
+}
+
+// BatchCreateProductsParams and BatchCreateProductsRow are those of
+// CreateProduct, whose statement every batch element runs.
+type (
+	BatchCreateProductsParams CreateProductParams
+	BatchCreateProductsRow    CreateProductRow
+)
+
+// BatchCreateProducts queues one CreateProduct per element of arg. Callers
+// run it inside a transaction so a batch is created all-or-nothing.
+func (q *Queries) BatchCreateProducts(ctx context.Context, arg []BatchCreateProductsParams) *BatchCreateProductsBatchResults {
+	sender, ok := q.db.(batchSender)
+	if !ok {
+		return &BatchCreateProductsBatchResults{tot: len(arg), err: ErrBatchNotSupported}
+	}
+
+	batch := &pgx.Batch{}
+	for _, a := range arg {
+		batch.Queue(createProduct,
+			a.ID,
+			a.Name,
+			a.Description,
+			a.PriceMinor,
+			a.Currency,
+			a.StockQuantity,
+			a.Actor,
+			a.CategoryIds,
+			a.Tags,
+		)
+	}
+	br := sender.SendBatch(ctx, batch)
+	return &BatchCreateProductsBatchResults{br: br, tot: len(arg)}
+}
+
+// QueryRow reports the result of every queued insert to f, in order.
+func (b *BatchCreateProductsBatchResults) QueryRow(f func(int, BatchCreateProductsRow, error)) {
+	if b.err != nil {
+		for t := 0; t < b.tot; t++ {
+			if f != nil {
+				f(t, BatchCreateProductsRow{}, b.err)
+			}
+		}
+		return
+	}
+
+	defer b.br.Close()
+	for t := 0; t < b.tot; t++ {
+		var i BatchCreateProductsRow
+		if b.closed {
+			if f != nil {
+				f(t, i, ErrBatchAlreadyClosed)
+			}
+			continue
+		}
+		row := b.br.QueryRow()
+		err := row.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.StockQuantity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		)
+		if f != nil {
+			f(t, i, err)
+		}
+	}
+}
+
+func (b *BatchCreateProductsBatchResults) Close() error {
+	b.closed = true
+	if b.br == nil {
+		return nil
+	}
+	return b.br.Close()
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ListProducts cannot be expressed as a static sqlc query because its
+// filters and sort order vary per request. The statement is assembled from
+// fixed SQL fragments only; every caller supplied value is sent as a bind
+// parameter.
+
+// ProductSortField is a column ListProducts can order by.
+type ProductSortField int
+
+const (
+	ProductSortByID ProductSortField = iota
+	ProductSortByPrice
+	ProductSortByName
+	ProductSortByCreatedAt
+)
+
+var productSortColumns = map[ProductSortField]string{
+	ProductSortByID:        "id",
+	ProductSortByPrice:     "price_minor",
+	ProductSortByName:      "name",
+	ProductSortByCreatedAt: "created_at",
+}
+
+const productColumns = "id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at"
+
+// ProductKeyset is the (sort value, id) position of the last row of the
+// previous page. Value must have the Go type of the sort column and is
+// ignored when sorting by id.
+type ProductKeyset struct {
+	ID    int64
+	Value any
+}
+
+type ListProductsParams struct {
+	Currency string
+	// MinPrice and MaxPrice bound price_minor. Minor units are only
+	// comparable within one currency, so callers set Currency with them.
+	MinPrice    *int64
+	MaxPrice    *int64
+	NamePrefix  string
+	InStockOnly bool
+	// CategoryID matches products in the category or any of its
+	// descendants; zero matches everything.
+	CategoryID int64
+	Tag        string
+	// ShowDeleted includes soft deleted products.
+	ShowDeleted bool
+	// SortBy orders by a column. Ordering by price_minor, like bounding
+	// it, needs Currency.
+	SortBy     ProductSortField
+	Descending bool
+	After      *ProductKeyset
+	Limit      int32
+}
+
+// queryBuilder accumulates WHERE conditions and their bind parameters.
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg registers a bind parameter and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+// escapeLike escapes LIKE wildcards so a prefix is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func buildListProducts(arg ListProductsParams) (string, []any) {
+	var b queryBuilder
+
+	if arg.Currency != "" {
+		b.where("currency = " + b.arg(arg.Currency))
+	}
+	if arg.MinPrice != nil {
+		b.where("price_minor >= " + b.arg(*arg.MinPrice))
+	}
+	if arg.MaxPrice != nil {
+		b.where("price_minor <= " + b.arg(*arg.MaxPrice))
+	}
+	if arg.NamePrefix != "" {
+		b.where("name LIKE " + b.arg(escapeLike(arg.NamePrefix)+"%"))
+	}
+	if arg.InStockOnly {
+		b.where("stock_quantity > 0")
+	}
+	if arg.CategoryID != 0 {
+		// A category's subtree is every category whose path extends its own.
+		b.where("id IN (SELECT pc.product_id FROM product_categories pc" +
+			" JOIN categories c ON c.id = pc.category_id" +
+			" WHERE c.path LIKE (SELECT path FROM categories WHERE id = " + b.arg(arg.CategoryID) + ") || '%')")
+	}
+	if arg.Tag != "" {
+		b.where("id IN (SELECT product_id FROM product_tags WHERE tag = " + b.arg(arg.Tag) + ")")
+	}
+	if !arg.ShowDeleted {
+		b.where("deleted_at IS NULL")
+	}
+
+	column, ok := productSortColumns[arg.SortBy]
+	if !ok {
+		column = "id"
+	}
+	direction, cmp := "ASC", ">"
+	if arg.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if arg.After != nil {
+		if column == "id" {
+			b.where("id " + cmp + " " + b.arg(arg.After.ID))
+		} else {
+			b.where("(" + column + ", id) " + cmp + " (" + b.arg(arg.After.Value) + ", " + b.arg(arg.After.ID) + ")")
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString("SELECT " + productColumns + " FROM products")
+	if len(b.conds) > 0 {
+		sql.WriteString("\nWHERE " + strings.Join(b.conds, "\n  AND "))
+	}
+	if column == "id" {
+		sql.WriteString("\nORDER BY id " + direction)
+	} else {
+		sql.WriteString("\nORDER BY " + column + " " + direction + ", id " + direction)
+	}
+	sql.WriteString("\nLIMIT " + b.arg(arg.Limit))
+
+	return sql.String(), b.args
+}
+
+func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error) {
+	query, args := buildListProducts(arg)
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.StockQuantity,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildListProducts_Defaults(t *testing.T) {
+	sql, args := buildListProducts(ListProductsParams{Limit: 11})
+
+	want := "SELECT " + productColumns + " FROM products\nWHERE deleted_at IS NULL\nORDER BY id ASC\nLIMIT $1"
+	if sql != want {
+		t.Errorf("unexpected SQL:\n%s\nwant:\n%s", sql, want)
+	}
+	if !reflect.DeepEqual(args, []any{int32(11)}) {
+		t.Errorf("unexpected args: %v", args)
+	}
+}
+
+func TestBuildListProducts_FiltersAndKeyset(t *testing.T) {
+	minPrice, maxPrice := int64(1000), int64(2000)
+	sql, args := buildListProducts(ListProductsParams{
+		Currency:    "USD",
+		MinPrice:    &minPrice,
+		MaxPrice:    &maxPrice,
+		NamePrefix:  "50%_off",
+		InStockOnly: true,
+		SortBy:      ProductSortByPrice,
+		Descending:  true,
+		After:       &ProductKeyset{ID: 7, Value: int64(1500)},
+		Limit:       3,
+	})
+
+	for _, fragment := range []string{
+		"currency = $1",
+		"price_minor >= $2",
+		"price_minor <= $3",
+		"name LIKE $4",
+		"stock_quantity > 0",
+		"(price_minor, id) < ($5, $6)",
+		"ORDER BY price_minor DESC, id DESC",
+		"LIMIT $7",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("SQL %q does not contain %q", sql, fragment)
+		}
+	}
+
+	want := []any{"USD", int64(1000), int64(2000), `50\%\_off%`, int64(1500), int64(7), int32(3)}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("unexpected args: %v, want %v", args, want)
+	}
+}
+
+func TestBuildListProducts_ShowDeleted(t *testing.T) {
+	sql, _ := buildListProducts(ListProductsParams{ShowDeleted: true, Limit: 1})
+
+	if strings.Contains(sql, "WHERE") {
+		t.Errorf("SQL %q must not filter deleted products", sql)
+	}
+}
+
+func TestBuildListProducts_CategoryAndTag(t *testing.T) {
+	sql, args := buildListProducts(ListProductsParams{CategoryID: 42, Tag: "sale", ShowDeleted: true, Limit: 5})
+
+	if !strings.Contains(sql, "c.path LIKE (SELECT path FROM categories WHERE id = $1) || '%'") {
+		t.Errorf("SQL %q does not match the category subtree", sql)
+	}
+	if !strings.Contains(sql, "FROM product_tags WHERE tag = $2") {
+		t.Errorf("SQL %q does not filter by tag", sql)
+	}
+	want := []any{int64(42), "sale", int32(5)}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("unexpected args: %v, want %v", args, want)
+	}
+}
+
+func TestBuildListProducts_NeverInlinesValues(t *testing.T) {
+	injection := "x'; DROP TABLE products; --"
+	sql, _ := buildListProducts(ListProductsParams{Currency: injection, NamePrefix: injection, Limit: 1})
+
+	if strings.Contains(sql, "DROP TABLE") {
+		t.Errorf("caller supplied value leaked into SQL: %s", sql)
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Querier is the query set of Queries without its pgx specifics, WithTx
+// and the BatchCreateProducts pipeline. It is what the jobs and
+// interceptors outside the product handler depend on, so that they run on
+// either the CockroachDB queries or the SQLite ones in repository/sqlite.
+type Querier interface {
+	AcquireOutboxLease(ctx context.Context, arg AcquireOutboxLeaseParams) (int64, error)
+	AddProductCategories(ctx context.Context, arg AddProductCategoriesParams) (int64, error)
+	AddProductMedia(ctx context.Context, arg AddProductMediaParams) (ProductMedia, error)
+	AddProductTags(ctx context.Context, arg AddProductTagsParams) error
+	AdjustStock(ctx context.Context, arg AdjustStockParams) (AdjustStockRow, error)
+	AdjustVariantStock(ctx context.Context, arg AdjustVariantStockParams) (AdjustVariantStockRow, error)
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (string, error)
+	ClearProductCategories(ctx context.Context, productID int64) error
+	ClearProductTags(ctx context.Context, productID int64) error
+	CommitStockReservation(ctx context.Context, id int64) (CommitStockReservationRow, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error
+	CountProductMedia(ctx context.Context, productID int64) (int64, error)
+	CountVariants(ctx context.Context, productID int64) (int64, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateProduct(ctx context.Context, arg CreateProductParams) (CreateProductRow, error)
+	CreateVariant(ctx context.Context, arg CreateVariantParams) (CreateVariantRow, error)
+	CurrentTime(ctx context.Context) (time.Time, error)
+	DeleteCategory(ctx context.Context, id int64) (Category, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int32) (int64, error)
+	DeleteProduct(ctx context.Context, arg DeleteProductParams) (DeleteProductRow, error)
+	DeleteProductMedia(ctx context.Context, arg DeleteProductMediaParams) (ProductMedia, error)
+	DeletePublishedOutboxEvents(ctx context.Context, arg DeletePublishedOutboxEventsParams) (int64, error)
+	DeletePurgedMediaBlobs(ctx context.Context, storageKeys []string) (int64, error)
+	DeleteVariant(ctx context.Context, id int64) (ProductVariant, error)
+	ExpireStockReservations(ctx context.Context, batchSize int32) (int64, error)
+	FindProductWithStockInfo(ctx context.Context, id int64) (FindProductWithStockInfoRow, error)
+	GetCategory(ctx context.Context, id int64) (Category, error)
+	GetCategoryForUpdate(ctx context.Context, id int64) (Category, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetOutboxBacklog(ctx context.Context, now time.Time) (GetOutboxBacklogRow, error)
+	GetProductByID(ctx context.Context, id int64) (Product, error)
+	GetProductByIDWithDeleted(ctx context.Context, id int64) (Product, error)
+	GetProductRevisionAsOf(ctx context.Context, arg GetProductRevisionAsOfParams) (ProductRevision, error)
+	GetProductsByIDs(ctx context.Context, ids []int64) ([]Product, error)
+	GetStockReservation(ctx context.Context, id int64) (StockReservation, error)
+	GetVariant(ctx context.Context, id int64) (ProductVariant, error)
+	GetVariantBySKU(ctx context.Context, sku string) (ProductVariant, error)
+	ListCategories(ctx context.Context, arg ListCategoriesParams) ([]Category, error)
+	ListCategoryLinks(ctx context.Context, productIds []int64) ([]ProductCategory, error)
+	ListPendingOutboxEvents(ctx context.Context, arg ListPendingOutboxEventsParams) ([]Outbox, error)
+	ListProductChanges(ctx context.Context, arg ListProductChangesParams) ([]Product, error)
+	ListProductMedia(ctx context.Context, productIds []int64) ([]ProductMedia, error)
+	ListProductNames(ctx context.Context) ([]ListProductNamesRow, error)
+	ListProductPurges(ctx context.Context, arg ListProductPurgesParams) ([]ProductRevision, error)
+	ListProductRevisions(ctx context.Context, arg ListProductRevisionsParams) ([]ProductRevision, error)
+	ListProductTags(ctx context.Context, productIds []int64) ([]ProductTag, error)
+	ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error)
+	ListPurgedMediaBlobs(ctx context.Context, pageLimit int32) ([]string, error)
+	ListStockMovements(ctx context.Context, arg ListStockMovementsParams) ([]StockMovement, error)
+	ListVariants(ctx context.Context, productID int64) ([]ProductVariant, error)
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MoveCategoryDescendants(ctx context.Context, arg MoveCategoryDescendantsParams) (int64, error)
+	PurgeDeletedProducts(ctx context.Context, arg PurgeDeletedProductsParams) (int64, error)
+	ReconcileStock(ctx context.Context, maxResults int32) ([]ReconcileStockRow, error)
+	ReleaseOutboxLease(ctx context.Context, holder string) error
+	ReleaseStockReservation(ctx context.Context, arg ReleaseStockReservationParams) (ReleaseStockReservationRow, error)
+	ReorderProductMedia(ctx context.Context, arg ReorderProductMediaParams) (int64, error)
+	ReserveStock(ctx context.Context, arg ReserveStockParams) (StockReservation, error)
+	ReserveVariantStock(ctx context.Context, arg ReserveVariantStockParams) (StockReservation, error)
+	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error)
+	UndeleteProduct(ctx context.Context, arg UndeleteProductParams) (UndeleteProductRow, error)
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateProduct(ctx context.Context, arg UpdateProductParams) (UpdateProductRow, error)
+	UpdateProductMediaAltText(ctx context.Context, arg UpdateProductMediaAltTextParams) (ProductMedia, error)
+	UpdateVariant(ctx context.Context, arg UpdateVariantParams) (ProductVariant, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,253 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const categoryColumns = "id, parent_id, name, path, created_at, updated_at"
+
+func scanCategory(row scanner, i *repository.Category) error {
+	return row.Scan(
+		&i.ID,
+		&i.ParentID,
+		&i.Name,
+		&i.Path,
+		timestamp{&i.CreatedAt},
+		timestamp{&i.UpdatedAt},
+	)
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (id, parent_id, name, path, created_at, updated_at)
+SELECT
+  ?1,
+  ?2,
+  ?3,
+  COALESCE((SELECT parent.path FROM categories parent WHERE parent.id = ?2), '/') || ?1 || '/',
+  ?4,
+  ?4
+WHERE ?2 IS NULL
+   OR EXISTS (SELECT 1 FROM categories parent WHERE parent.id = ?2)
+RETURNING ` + categoryColumns
+
+// CreateCategory returns no row when parent_id names a missing category.
+func (q *Queries) CreateCategory(ctx context.Context, arg repository.CreateCategoryParams) (repository.Category, error) {
+	row := q.db.QueryRowContext(ctx, createCategory, arg.ID, arg.ParentID, arg.Name, micros(now()))
+	var i repository.Category
+	err := scanCategory(row, &i)
+	return i, pgError(err)
+}
+
+const getCategory = `-- name: GetCategory :one
+SELECT ` + categoryColumns + ` FROM categories
+WHERE id = ?1
+`
+
+func (q *Queries) GetCategory(ctx context.Context, id int64) (repository.Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, id)
+	var i repository.Category
+	err := scanCategory(row, &i)
+	return i, pgError(err)
+}
+
+// GetCategoryForUpdate is GetCategory: SQLite has no row locks, and a write
+// transaction already excludes every other writer.
+func (q *Queries) GetCategoryForUpdate(ctx context.Context, id int64) (repository.Category, error) {
+	return q.GetCategory(ctx, id)
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT ` + categoryColumns + ` FROM categories
+WHERE parent_id IS ?1
+  AND (?2 IS NULL OR (name, id) > (?2, ?3))
+ORDER BY name, id
+LIMIT ?4
+`
+
+// ListCategories returns the children of parent_id (roots when NULL) in
+// (name, id) order.
+func (q *Queries) ListCategories(ctx context.Context, arg repository.ListCategoriesParams) ([]repository.Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories, arg.ParentID, arg.AfterName, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.Category
+	for rows.Next() {
+		var i repository.Category
+		if err := scanCategory(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET name       = ?1,
+    parent_id  = ?2,
+    path       = ?3,
+    updated_at = ?5
+WHERE id = ?4
+RETURNING ` + categoryColumns
+
+func (q *Queries) UpdateCategory(ctx context.Context, arg repository.UpdateCategoryParams) (repository.Category, error) {
+	row := q.db.QueryRowContext(ctx, updateCategory, arg.Name, arg.ParentID, arg.Path, arg.ID, micros(now()))
+	var i repository.Category
+	err := scanCategory(row, &i)
+	return i, pgError(err)
+}
+
+const moveCategoryDescendants = `-- name: MoveCategoryDescendants :execrows
+UPDATE categories
+SET path       = ?1 || substr(path, length(?2) + 1),
+    updated_at = ?3
+WHERE substr(path, 1, length(?2)) = ?2
+  AND path <> ?2
+`
+
+// MoveCategoryDescendants rewrites the paths below a moved category from
+// old_path to new_path.
+func (q *Queries) MoveCategoryDescendants(ctx context.Context, arg repository.MoveCategoryDescendantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveCategoryDescendants, arg.NewPath, arg.OldPath, micros(now()))
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return result.RowsAffected()
+}
+
+const deleteCategory = `-- name: DeleteCategory :one
+DELETE FROM categories
+WHERE categories.id = ?1
+  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = ?1)
+RETURNING ` + categoryColumns
+
+// DeleteCategory only deletes leaf categories; their product links go with
+// them.
+func (q *Queries) DeleteCategory(ctx context.Context, id int64) (repository.Category, error) {
+	row := q.db.QueryRowContext(ctx, deleteCategory, id)
+	var i repository.Category
+	err := scanCategory(row, &i)
+	return i, pgError(err)
+}
+
+const clearProductCategories = `-- name: ClearProductCategories :exec
+DELETE FROM product_categories
+WHERE product_id = ?1
+`
+
+func (q *Queries) ClearProductCategories(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, clearProductCategories, productID)
+	return err
+}
+
+const addProductCategories = `-- name: AddProductCategories :execrows
+INSERT INTO product_categories (product_id, category_id)
+SELECT ?1, categories.id
+FROM categories
+WHERE categories.id IN (SELECT value FROM json_each(?2))
+`
+
+// AddProductCategories skips the ids of missing categories; callers compare
+// the row count with the number of ids to detect them.
+func (q *Queries) AddProductCategories(ctx context.Context, arg repository.AddProductCategoriesParams) (int64, error) {
+	categoryIDs, err := jsonArray(arg.CategoryIds)
+	if err != nil {
+		return 0, err
+	}
+	result, err := q.db.ExecContext(ctx, addProductCategories, arg.ProductID, categoryIDs)
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return result.RowsAffected()
+}
+
+const clearProductTags = `-- name: ClearProductTags :exec
+DELETE FROM product_tags
+WHERE product_id = ?1
+`
+
+func (q *Queries) ClearProductTags(ctx context.Context, productID int64) error {
+	_, err := q.db.ExecContext(ctx, clearProductTags, productID)
+	return err
+}
+
+const addProductTags = `-- name: AddProductTags :exec
+INSERT INTO product_tags (product_id, tag)
+SELECT ?1, value FROM json_each(?2)
+`
+
+func (q *Queries) AddProductTags(ctx context.Context, arg repository.AddProductTagsParams) error {
+	tags, err := jsonArray(arg.Tags)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.ExecContext(ctx, addProductTags, arg.ProductID, tags)
+	return pgError(err)
+}
+
+const listCategoryLinks = `-- name: ListCategoryLinks :many
+SELECT product_id, category_id FROM product_categories
+WHERE product_id IN (SELECT value FROM json_each(?1))
+ORDER BY product_id, category_id
+`
+
+func (q *Queries) ListCategoryLinks(ctx context.Context, productIds []int64) ([]repository.ProductCategory, error) {
+	ids, err := jsonArray(productIds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.db.QueryContext(ctx, listCategoryLinks, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductCategory
+	for rows.Next() {
+		var i repository.ProductCategory
+		if err := rows.Scan(&i.ProductID, &i.CategoryID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductTags = `-- name: ListProductTags :many
+SELECT product_id, tag FROM product_tags
+WHERE product_id IN (SELECT value FROM json_each(?1))
+ORDER BY product_id, tag
+`
+
+func (q *Queries) ListProductTags(ctx context.Context, productIds []int64) ([]repository.ProductTag, error) {
+	ids, err := jsonArray(productIds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.db.QueryContext(ctx, listProductTags, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductTag
+	for rows.Next() {
+		var i repository.ProductTag
+		if err := rows.Scan(&i.ProductID, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,225 @@
+// Package sqlite is the SQLite counterpart of package repository: the same
+// query set, written by hand for database/sql against the schema in
+// database/sqlite/schema.sql. It lets product-service run from a single file
+// database during local development.
+//
+// The queries take and return the repository types and fail like the pgx
+// ones do: a :one query that matches no row fails with pgx.ErrNoRows, and a
+// constraint violation with a *pgconn.PgError carrying the SQLSTATE and
+// constraint name. SQLite cannot modify data inside a CTE, so the queries
+// that write several tables in one CockroachDB statement run their
+// statements in a transaction instead.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+func New(db DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+type Queries struct {
+	db DBTX
+}
+
+var _ repository.Querier = (*Queries)(nil)
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		db: tx,
+	}
+}
+
+// beginner is implemented by *sql.DB.
+type beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// atomic runs the statements of one query so that they apply together or
+// not at all: in a new transaction when q is bound to the database, or in a
+// savepoint of the caller's transaction.
+func (q *Queries) atomic(ctx context.Context, fn func(*Queries) error) error {
+	db, ok := q.db.(beginner)
+	if !ok {
+		if _, err := q.db.ExecContext(ctx, "SAVEPOINT atomic_query"); err != nil {
+			return err
+		}
+		if err := fn(q); err != nil {
+			_, _ = q.db.ExecContext(ctx, "ROLLBACK TO atomic_query")
+			_, _ = q.db.ExecContext(ctx, "RELEASE atomic_query")
+			return err
+		}
+		_, err := q.db.ExecContext(ctx, "RELEASE atomic_query")
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// A no-op once the transaction has been committed.
+		_ = tx.Rollback()
+	}()
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// now is the time a query runs at. Timestamps are stored as Unix
+// microseconds, the precision of TIMESTAMPTZ.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// CurrentTime returns the clock the queries stamp rows with.
+func (q *Queries) CurrentTime(context.Context) (time.Time, error) {
+	return now(), nil
+}
+
+func micros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+func nullMicros(t pgtype.Timestamptz) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time.UnixMicro()
+}
+
+// intervalMicros converts an interval argument to microseconds, counting a
+// month as 30 days as CockroachDB does when adding it to a timestamp.
+func intervalMicros(i pgtype.Interval) int64 {
+	const day = int64(24 * time.Hour / time.Microsecond)
+	return i.Microseconds + int64(i.Days)*day + int64(i.Months)*30*day
+}
+
+// timestamp scans a Unix microsecond column into a time.Time.
+type timestamp struct{ t *time.Time }
+
+func (ts timestamp) Scan(src any) error {
+	v, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+	*ts.t = time.UnixMicro(v)
+	return nil
+}
+
+// nullTimestamp scans a nullable Unix microsecond column.
+type nullTimestamp struct{ t *pgtype.Timestamptz }
+
+func (ts nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*ts.t = pgtype.Timestamptz{}
+		return nil
+	}
+	v, ok := src.(int64)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into a timestamp", src)
+	}
+	*ts.t = pgtype.Timestamptz{Time: time.UnixMicro(v), Valid: true}
+	return nil
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: true}
+}
+
+// jsonArray encodes an array argument for json_each, which stands in for
+// = ANY($1::int8[]).
+func jsonArray[T any](values []T) (string, error) {
+	if values == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
+
+// pgError maps SQLite errors onto the ones the pgx queries fail with.
+func pgError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+	msg := err.Error()
+	for _, v := range violations {
+		i := strings.Index(msg, v.prefix)
+		if i < 0 {
+			continue
+		}
+		detail := msg[i+len(v.prefix):]
+		// Drivers append the extended result code, e.g. " (2067)".
+		if j := strings.Index(detail, " ("); j >= 0 {
+			detail = detail[:j]
+		}
+		pgErr := &pgconn.PgError{Severity: "ERROR", Code: v.code, Message: msg}
+		if v.constraint != nil {
+			pgErr.ConstraintName = v.constraint(detail)
+		}
+		if table, _, ok := strings.Cut(detail, "."); ok {
+			pgErr.TableName = table
+		}
+		return pgErr
+	}
+	return err
+}
+
+var violations = []struct {
+	prefix     string
+	code       string
+	constraint func(detail string) string
+}{
+	{"UNIQUE constraint failed: ", "23505", func(columns string) string { return uniqueConstraints[columns] }},
+	{"CHECK constraint failed: ", "23514", func(name string) string { return name }},
+	{"NOT NULL constraint failed: ", "23502", nil},
+	// SQLite does not report which foreign key failed.
+	{"FOREIGN KEY constraint failed", "23503", nil},
+}
+
+// uniqueConstraints names the unique constraints by the columns SQLite
+// reports for them.
+var uniqueConstraints = map[string]string{
+	"currencies.code":    "currencies_pkey",
+	"products.id":        "products_pkey",
+	"categories.id":      "categories_pkey",
+	"categories.path":    "categories_path_key",
+	"product_media.id":   "product_media_pkey",
+	"outbox.id":          "outbox_pkey",
+	"outbox_leases.name": "outbox_leases_pkey",
+	"stock_movements.id": "stock_movements_pkey",
+	"product_categories.product_id, product_categories.category_id":                            "product_categories_pkey",
+	"product_tags.product_id, product_tags.tag":                                                "product_tags_pkey",
+	"product_media.product_id, product_media.checksum_sha256":                                  "product_media_checksum_key",
+	"product_revisions.id":                                                                     "product_revisions_pkey",
+	"product_revisions.product_id, product_revisions.version":                                  "product_revisions_version_key",
+	"outbox.aggregate_type, outbox.aggregate_id, outbox.aggregate_version":                     "outbox_aggregate_version_key",
+	"idempotency_keys.method, idempotency_keys.idempotency_key":                                "idempotency_keys_pkey",
+	"product_variants.id":                                                                      "product_variants_pkey",
+	"product_variants.sku":                                                                     "product_variants_sku_key",
+	"product_variants.product_id, product_variants.option_key":                                 "product_variants_options_key",
+	"stock_reservations.id":                                                                    "stock_reservations_pkey",
+	"exchange_rates.base_currency, exchange_rates.quote_currency, exchange_rates.effective_at": "exchange_rates_pkey",
+}
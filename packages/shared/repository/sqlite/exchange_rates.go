@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const exchangeRateColumns = "base_currency, quote_currency, rate, effective_at, updated_at"
+
+func scanExchangeRate(row scanner, i *repository.ExchangeRate) error {
+	return row.Scan(
+		&i.BaseCurrency,
+		&i.QuoteCurrency,
+		&i.Rate,
+		timestamp{&i.EffectiveAt},
+		timestamp{&i.UpdatedAt},
+	)
+}
+
+const upsertExchangeRate = `-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (base_currency, quote_currency, effective_at) DO UPDATE
+SET rate = excluded.rate,
+    updated_at = excluded.updated_at
+RETURNING ` + exchangeRateColumns
+
+func (q *Queries) UpsertExchangeRate(ctx context.Context, arg repository.UpsertExchangeRateParams) (repository.ExchangeRate, error) {
+	row := q.db.QueryRowContext(ctx, upsertExchangeRate,
+		arg.BaseCurrency,
+		arg.QuoteCurrency,
+		arg.Rate,
+		micros(arg.EffectiveAt),
+		micros(now()),
+	)
+	var i repository.ExchangeRate
+	err := scanExchangeRate(row, &i)
+	return i, pgError(err)
+}
+
+const getExchangeRate = `-- name: GetExchangeRate :one
+SELECT ` + exchangeRateColumns + ` FROM exchange_rates
+WHERE base_currency = ?1
+  AND quote_currency = ?2
+  AND effective_at <= ?3
+ORDER BY effective_at DESC
+LIMIT 1
+`
+
+// GetExchangeRate returns the rate in force now for converting
+// base_currency into quote_currency.
+func (q *Queries) GetExchangeRate(ctx context.Context, arg repository.GetExchangeRateParams) (repository.ExchangeRate, error) {
+	row := q.db.QueryRowContext(ctx, getExchangeRate, arg.BaseCurrency, arg.QuoteCurrency, micros(now()))
+	var i repository.ExchangeRate
+	err := scanExchangeRate(row, &i)
+	return i, pgError(err)
+}
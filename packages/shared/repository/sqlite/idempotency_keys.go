@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (method, idempotency_key, request_hash, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (method, idempotency_key) DO UPDATE
+SET request_hash = excluded.request_hash,
+    response     = NULL,
+    created_at   = excluded.created_at,
+    expires_at   = excluded.expires_at
+WHERE idempotency_keys.expires_at <= excluded.created_at
+RETURNING idempotency_key
+`
+
+// ClaimIdempotencyKey records a new in-flight request. An expired key is
+// reclaimed; a live one is left alone and no row is returned.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg repository.ClaimIdempotencyKeyParams) (string, error) {
+	at := micros(now())
+	row := q.db.QueryRowContext(ctx, claimIdempotencyKey,
+		arg.Method,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		at,
+		at+intervalMicros(arg.Ttl),
+	)
+	var idempotency_key string
+	err := row.Scan(&idempotency_key)
+	return idempotency_key, pgError(err)
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT method, idempotency_key, request_hash, response, created_at, expires_at FROM idempotency_keys
+WHERE method = ?1 AND idempotency_key = ?2
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg repository.GetIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.Method, arg.IdempotencyKey)
+	var i repository.IdempotencyKey
+	err := row.Scan(
+		&i.Method,
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.Response,
+		timestamp{&i.CreatedAt},
+		timestamp{&i.ExpiresAt},
+	)
+	return i, pgError(err)
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response = ?1
+WHERE method = ?2 AND idempotency_key = ?3
+`
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg repository.CompleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, completeIdempotencyKey, arg.Response, arg.Method, arg.IdempotencyKey)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE (method, idempotency_key) IN (
+  SELECT method, idempotency_key FROM idempotency_keys
+  WHERE expires_at <= ?1
+  LIMIT ?2
+)
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, micros(now()), batchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
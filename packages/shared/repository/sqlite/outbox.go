@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// Events are written by the product mutations in products.go, including
+// the stock changes of stock_movements.go and stock_reservations.go.
+
+const listPendingOutboxEvents = `-- name: ListPendingOutboxEvents :many
+WITH heads AS (
+  SELECT o.aggregate_type, o.aggregate_id, o.created_at, o.next_attempt_at
+  FROM outbox o
+  WHERE o.published_at IS NULL
+    AND o.aggregate_version = (
+      SELECT min(p.aggregate_version) FROM outbox p
+      WHERE p.published_at IS NULL
+        AND p.aggregate_type = o.aggregate_type
+        AND p.aggregate_id = o.aggregate_id
+    )
+)
+SELECT outbox.id, outbox.aggregate_type, outbox.aggregate_id, outbox.aggregate_version, outbox.event_type, outbox.payload,
+       outbox.created_at, outbox.attempts, outbox.next_attempt_at, outbox.last_error, outbox.published_at
+FROM outbox
+JOIN heads ON heads.aggregate_type = outbox.aggregate_type AND heads.aggregate_id = outbox.aggregate_id
+WHERE outbox.published_at IS NULL
+  AND heads.next_attempt_at <= ?1
+ORDER BY heads.created_at, outbox.aggregate_type, outbox.aggregate_id, outbox.aggregate_version
+LIMIT ?2
+`
+
+// ListPendingOutboxEvents returns the pending events of the aggregates
+// whose oldest pending event is due, in publish order: aggregates by the
+// age of that event, then each aggregate's events by version.
+func (q *Queries) ListPendingOutboxEvents(ctx context.Context, arg repository.ListPendingOutboxEventsParams) ([]repository.Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingOutboxEvents, micros(arg.Now), arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.Outbox
+	for rows.Next() {
+		var i repository.Outbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.AggregateVersion,
+			&i.EventType,
+			&i.Payload,
+			timestamp{&i.CreatedAt},
+			&i.Attempts,
+			timestamp{&i.NextAttemptAt},
+			&i.LastError,
+			nullTimestamp{&i.PublishedAt},
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = ?2
+WHERE id = ?1
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventPublished, id, micros(now()))
+	return err
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts        = attempts + 1,
+    last_error      = ?1,
+    next_attempt_at = ?2
+WHERE id = ?3
+`
+
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg repository.MarkOutboxEventFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventFailed, arg.LastError, micros(arg.NextAttemptAt), arg.ID)
+	return err
+}
+
+const getOutboxBacklog = `-- name: GetOutboxBacklog :one
+SELECT count(*) AS pending,
+       COALESCE(min(created_at), ?1) AS oldest
+FROM outbox
+WHERE published_at IS NULL
+`
+
+// GetOutboxBacklog returns the number of pending events and the creation
+// time of the oldest; oldest is now when nothing is pending.
+func (q *Queries) GetOutboxBacklog(ctx context.Context, now time.Time) (repository.GetOutboxBacklogRow, error) {
+	row := q.db.QueryRowContext(ctx, getOutboxBacklog, micros(now))
+	var i repository.GetOutboxBacklogRow
+	err := row.Scan(&i.Pending, timestamp{&i.Oldest})
+	return i, err
+}
+
+const deletePublishedOutboxEvents = `-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE id IN (
+  SELECT id FROM outbox
+  WHERE published_at < ?1
+  ORDER BY published_at
+  LIMIT ?2
+)
+`
+
+// DeletePublishedOutboxEvents removes up to batch_size events published
+// before published_before.
+func (q *Queries) DeletePublishedOutboxEvents(ctx context.Context, arg repository.DeletePublishedOutboxEventsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePublishedOutboxEvents, micros(arg.PublishedBefore), arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const acquireOutboxLease = `-- name: AcquireOutboxLease :execrows
+INSERT INTO outbox_leases (name, holder, expires_at)
+VALUES ('relay', ?1, ?2 + ?3)
+ON CONFLICT (name) DO UPDATE
+SET holder     = excluded.holder,
+    expires_at = excluded.expires_at
+WHERE outbox_leases.holder = excluded.holder OR outbox_leases.expires_at <= ?2
+`
+
+// AcquireOutboxLease takes the relay lease for holder, or renews it, until
+// ttl from now. No row is affected while another holder's lease is
+// unexpired.
+func (q *Queries) AcquireOutboxLease(ctx context.Context, arg repository.AcquireOutboxLeaseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, acquireOutboxLease, arg.Holder, micros(now()), intervalMicros(arg.Ttl))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const releaseOutboxLease = `-- name: ReleaseOutboxLease :exec
+DELETE FROM outbox_leases
+WHERE name = 'relay' AND holder = ?1
+`
+
+// ReleaseOutboxLease gives up the relay lease if holder has it, so that
+// another relay can take it without waiting for it to expire.
+func (q *Queries) ReleaseOutboxLease(ctx context.Context, holder string) error {
+	_, err := q.db.ExecContext(ctx, releaseOutboxLease, holder)
+	return err
+}
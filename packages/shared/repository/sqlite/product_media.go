@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const productMediaColumns = "id, product_id, position, storage_key, content_type, checksum_sha256, size_bytes, width, height, alt_text, created_at"
+
+func scanProductMedia(row scanner, i *repository.ProductMedia) error {
+	return row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Position,
+		&i.StorageKey,
+		&i.ContentType,
+		&i.ChecksumSha256,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.AltText,
+		timestamp{&i.CreatedAt},
+	)
+}
+
+const addProductMedia = `-- name: AddProductMedia :one
+INSERT INTO product_media (id, product_id, position, storage_key, content_type, checksum_sha256, size_bytes, width, height, alt_text, created_at)
+SELECT ?1, products.id,
+       (SELECT COALESCE(max(pm.position) + 1, 0) FROM product_media pm WHERE pm.product_id = ?2),
+       ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?11
+FROM products
+WHERE products.id = ?2
+  AND products.deleted_at IS NULL
+  AND (SELECT count(*) FROM product_media pm WHERE pm.product_id = ?2) < ?10
+RETURNING ` + productMediaColumns
+
+// AddProductMedia appends media after the product's existing media. No row
+// is returned when the product is missing or already has max_media items.
+func (q *Queries) AddProductMedia(ctx context.Context, arg repository.AddProductMediaParams) (repository.ProductMedia, error) {
+	row := q.db.QueryRowContext(ctx, addProductMedia,
+		arg.ID,
+		arg.ProductID,
+		arg.StorageKey,
+		arg.ContentType,
+		arg.ChecksumSha256,
+		arg.SizeBytes,
+		arg.Width,
+		arg.Height,
+		arg.AltText,
+		arg.MaxMedia,
+		micros(now()),
+	)
+	var i repository.ProductMedia
+	err := scanProductMedia(row, &i)
+	return i, pgError(err)
+}
+
+const listProductMedia = `-- name: ListProductMedia :many
+SELECT ` + productMediaColumns + ` FROM product_media
+WHERE product_id IN (SELECT value FROM json_each(?1))
+ORDER BY product_id, position, id
+`
+
+func (q *Queries) ListProductMedia(ctx context.Context, productIds []int64) ([]repository.ProductMedia, error) {
+	ids, err := jsonArray(productIds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.db.QueryContext(ctx, listProductMedia, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductMedia
+	for rows.Next() {
+		var i repository.ProductMedia
+		if err := scanProductMedia(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countProductMedia = `-- name: CountProductMedia :one
+SELECT count(*) FROM product_media
+WHERE product_id = ?1
+`
+
+func (q *Queries) CountProductMedia(ctx context.Context, productID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProductMedia, productID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const updateProductMediaAltText = `-- name: UpdateProductMediaAltText :one
+UPDATE product_media
+SET alt_text = ?1
+WHERE id = ?2 AND product_id = ?3
+RETURNING ` + productMediaColumns
+
+func (q *Queries) UpdateProductMediaAltText(ctx context.Context, arg repository.UpdateProductMediaAltTextParams) (repository.ProductMedia, error) {
+	row := q.db.QueryRowContext(ctx, updateProductMediaAltText, arg.AltText, arg.ID, arg.ProductID)
+	var i repository.ProductMedia
+	err := scanProductMedia(row, &i)
+	return i, pgError(err)
+}
+
+const reorderProductMedia = `-- name: ReorderProductMedia :execrows
+UPDATE product_media
+SET position = (
+  SELECT ids.key FROM json_each(?1) ids
+  WHERE ids.value = product_media.id
+  ORDER BY ids.key
+  LIMIT 1
+)
+WHERE product_id = ?2
+  AND id IN (SELECT value FROM json_each(?1))
+`
+
+// ReorderProductMedia sets each media's position to its index in
+// media_ids.
+func (q *Queries) ReorderProductMedia(ctx context.Context, arg repository.ReorderProductMediaParams) (int64, error) {
+	mediaIDs, err := jsonArray(arg.MediaIds)
+	if err != nil {
+		return 0, err
+	}
+	result, err := q.db.ExecContext(ctx, reorderProductMedia, mediaIDs, arg.ProductID)
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return result.RowsAffected()
+}
+
+const deleteProductMedia = `-- name: DeleteProductMedia :one
+DELETE FROM product_media
+WHERE id = ?1 AND product_id = ?2
+RETURNING ` + productMediaColumns
+
+func (q *Queries) DeleteProductMedia(ctx context.Context, arg repository.DeleteProductMediaParams) (repository.ProductMedia, error) {
+	row := q.db.QueryRowContext(ctx, deleteProductMedia, arg.ID, arg.ProductID)
+	var i repository.ProductMedia
+	err := scanProductMedia(row, &i)
+	return i, pgError(err)
+}
+
+const listPurgedMediaBlobs = `-- name: ListPurgedMediaBlobs :many
+SELECT storage_key FROM purged_media_blobs
+ORDER BY created_at, storage_key
+LIMIT ?1
+`
+
+// ListPurgedMediaBlobs returns queued blob keys, oldest first.
+func (q *Queries) ListPurgedMediaBlobs(ctx context.Context, pageLimit int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgedMediaBlobs, pageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var storageKey string
+		if err := rows.Scan(&storageKey); err != nil {
+			return nil, err
+		}
+		items = append(items, storageKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deletePurgedMediaBlobs = `-- name: DeletePurgedMediaBlobs :execrows
+DELETE FROM purged_media_blobs
+WHERE storage_key IN (SELECT value FROM json_each(?1))
+`
+
+func (q *Queries) DeletePurgedMediaBlobs(ctx context.Context, storageKeys []string) (int64, error) {
+	keys, err := jsonArray(storageKeys)
+	if err != nil {
+		return 0, err
+	}
+	result, err := q.db.ExecContext(ctx, deletePurgedMediaBlobs, keys)
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return result.RowsAffected()
+}
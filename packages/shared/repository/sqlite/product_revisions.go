@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// Revisions are written by the product mutations in products.go.
+
+const productRevisionColumns = "id, product_id, version, action, actor, previous, snapshot, created_at"
+
+func scanProductRevision(row scanner, i *repository.ProductRevision) error {
+	return row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Version,
+		&i.Action,
+		&i.Actor,
+		&i.Previous,
+		&i.Snapshot,
+		timestamp{&i.CreatedAt},
+	)
+}
+
+const listProductRevisions = `-- name: ListProductRevisions :many
+SELECT ` + productRevisionColumns + ` FROM product_revisions
+WHERE product_id = ?1
+  AND (?2 IS NULL OR version < ?2)
+ORDER BY version DESC
+LIMIT ?3
+`
+
+// ListProductRevisions returns the newest revisions first; keyset
+// pagination runs on version.
+func (q *Queries) ListProductRevisions(ctx context.Context, arg repository.ListProductRevisionsParams) ([]repository.ProductRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listProductRevisions, arg.ProductID, arg.BeforeVersion, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductRevision
+	for rows.Next() {
+		var i repository.ProductRevision
+		if err := scanProductRevision(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProductPurges = `-- name: ListProductPurges :many
+SELECT ` + productRevisionColumns + ` FROM product_revisions
+WHERE action = 'purge'
+  AND (created_at, product_id) > (?1, ?2)
+  AND created_at <= ?3
+ORDER BY created_at, product_id
+LIMIT ?4
+`
+
+// ListProductPurges returns the purge revisions after the (created_at,
+// product_id) cursor, oldest first, held back by the settle window like
+// ListProductChanges.
+func (q *Queries) ListProductPurges(ctx context.Context, arg repository.ListProductPurgesParams) ([]repository.ProductRevision, error) {
+	settled := micros(now()) - intervalMicros(arg.Settle)
+	rows, err := q.db.QueryContext(ctx, listProductPurges, micros(arg.AfterCreatedAt), arg.AfterProductID, settled, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductRevision
+	for rows.Next() {
+		var i repository.ProductRevision
+		if err := scanProductRevision(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProductRevisionAsOf = `-- name: GetProductRevisionAsOf :one
+SELECT ` + productRevisionColumns + ` FROM product_revisions
+WHERE product_id = ?1
+  AND created_at <= ?2
+ORDER BY created_at DESC, version DESC
+LIMIT 1
+`
+
+// GetProductRevisionAsOf returns the last revision of a product recorded at
+// or before as_of.
+func (q *Queries) GetProductRevisionAsOf(ctx context.Context, arg repository.GetProductRevisionAsOfParams) (repository.ProductRevision, error) {
+	row := q.db.QueryRowContext(ctx, getProductRevisionAsOf, arg.ProductID, micros(arg.AsOf))
+	var i repository.ProductRevision
+	err := scanProductRevision(row, &i)
+	return i, pgError(err)
+}
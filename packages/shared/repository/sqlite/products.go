@@ -0,0 +1,555 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const productColumns = "id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, deleted_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row scanner, i *repository.Product) error {
+	return row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		timestamp{&i.CreatedAt},
+		timestamp{&i.UpdatedAt},
+		&i.Version,
+		nullTimestamp{&i.DeletedAt},
+	)
+}
+
+func (q *Queries) queryProducts(ctx context.Context, query string, args ...any) ([]repository.Product, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.Product
+	for rows.Next() {
+		var i repository.Product
+		if err := scanProduct(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// snapshot is the JSON document a revision stores: the products row and
+// the product's categories and tags.
+type snapshot struct {
+	repository.Product
+	CategoryIDs []int64  `json:"category_ids"`
+	Tags        []string `json:"tags"`
+}
+
+// snapshotOf reads the categories and tags of p into a snapshot.
+func (q *Queries) snapshotOf(ctx context.Context, p repository.Product) (snapshot, error) {
+	s := snapshot{Product: p, CategoryIDs: []int64{}, Tags: []string{}}
+	links, err := q.ListCategoryLinks(ctx, []int64{p.ID})
+	if err != nil {
+		return snapshot{}, err
+	}
+	for _, l := range links {
+		s.CategoryIDs = append(s.CategoryIDs, l.CategoryID)
+	}
+	tags, err := q.ListProductTags(ctx, []int64{p.ID})
+	if err != nil {
+		return snapshot{}, err
+	}
+	for _, t := range tags {
+		s.Tags = append(s.Tags, t.Tag)
+	}
+	return s, nil
+}
+
+// productEvents are the outbox event types of the revision actions.
+var productEvents = map[string]string{
+	"create":   "product.created",
+	"update":   "product.updated",
+	"delete":   "product.deleted",
+	"undelete": "product.undeleted",
+	"stock":    "product.stock_changed",
+	"purge":    "product.purged",
+}
+
+const insertProductRevision = `
+INSERT INTO product_revisions (product_id, version, action, actor, previous, snapshot, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+const insertOutboxEvent = `
+INSERT INTO outbox (aggregate_type, aggregate_id, aggregate_version, event_type, payload, created_at, next_attempt_at)
+VALUES ('product', ?1, ?2, ?3, ?4, ?5, ?5)
+`
+
+// recordRevision writes the revision of a product mutation and its outbox
+// event, which the CockroachDB queries do in the mutating statement.
+// previous is nil for the first revision. Actions without an event type
+// record the revision alone.
+func (q *Queries) recordRevision(ctx context.Context, action, actor string, previous *snapshot, current snapshot) error {
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	var before []byte
+	if previous != nil {
+		if before, err = json.Marshal(previous); err != nil {
+			return err
+		}
+	}
+	payload, err := json.Marshal(struct {
+		Actor   string          `json:"actor"`
+		Product json.RawMessage `json:"product"`
+	}{actor, snapshot})
+	if err != nil {
+		return err
+	}
+
+	at := micros(current.UpdatedAt)
+	if _, err := q.db.ExecContext(ctx, insertProductRevision,
+		current.ID, current.Version, action, actor, nullJSON(before), string(snapshot), at,
+	); err != nil {
+		return err
+	}
+	eventType, ok := productEvents[action]
+	if !ok {
+		return nil
+	}
+	_, err = q.db.ExecContext(ctx, insertOutboxEvent, current.ID, current.Version, eventType, string(payload), at)
+	return err
+}
+
+// recordStockRevision records the revision of a change to the stock of a
+// product, which leaves its categories and tags as they were.
+func (q *Queries) recordStockRevision(ctx context.Context, actor string, previous, current repository.Product) error {
+	before, err := q.snapshotOf(ctx, previous)
+	if err != nil {
+		return err
+	}
+	after := before
+	after.Product = current
+	return q.recordRevision(ctx, "stock", actor, &before, after)
+}
+
+// orEmpty returns s, or an empty slice when s is nil, so that a snapshot
+// stores [] rather than null.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// nullJSON stores a JSON document as text, and no document as NULL.
+func nullJSON(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+const createProduct = `-- name: CreateProduct :one
+INSERT INTO products (id, name, description, price_minor, currency, stock_quantity, created_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?7)
+RETURNING ` + productColumns
+
+// CreateProduct books initial stock in the ledger as a receipt and records
+// the product's first revision and its product.created event.
+func (q *Queries) CreateProduct(ctx context.Context, arg repository.CreateProductParams) (repository.CreateProductRow, error) {
+	var i repository.Product
+	err := q.atomic(ctx, func(q *Queries) error {
+		row := q.db.QueryRowContext(ctx, createProduct,
+			arg.ID,
+			arg.Name,
+			arg.Description,
+			arg.PriceMinor,
+			arg.Currency,
+			arg.StockQuantity,
+			micros(now()),
+		)
+		if err := scanProduct(row, &i); err != nil {
+			return err
+		}
+		if i.StockQuantity > 0 {
+			if _, err := q.insertStockMovement(ctx, repository.StockMovement{
+				ProductID: i.ID,
+				Delta:     i.StockQuantity,
+				Reason:    "receipt",
+				Note:      text("initial stock"),
+				CreatedAt: i.CreatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		return q.recordRevision(ctx, "create", arg.Actor, nil, snapshot{
+			Product:     i,
+			CategoryIDs: orEmpty(arg.CategoryIds),
+			Tags:        orEmpty(arg.Tags),
+		})
+	})
+	return repository.CreateProductRow(i), pgError(err)
+}
+
+const getProductByID = `-- name: GetProductByID :one
+SELECT ` + productColumns + ` FROM products
+WHERE id = ?1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetProductByID(ctx context.Context, id int64) (repository.Product, error) {
+	row := q.db.QueryRowContext(ctx, getProductByID, id)
+	var i repository.Product
+	err := scanProduct(row, &i)
+	return i, pgError(err)
+}
+
+const getProductByIDWithDeleted = `-- name: GetProductByIDWithDeleted :one
+SELECT ` + productColumns + ` FROM products
+WHERE id = ?1
+`
+
+func (q *Queries) GetProductByIDWithDeleted(ctx context.Context, id int64) (repository.Product, error) {
+	row := q.db.QueryRowContext(ctx, getProductByIDWithDeleted, id)
+	var i repository.Product
+	err := scanProduct(row, &i)
+	return i, pgError(err)
+}
+
+const getProductsByIDs = `-- name: GetProductsByIDs :many
+SELECT ` + productColumns + ` FROM products
+WHERE id IN (SELECT value FROM json_each(?1))
+  AND deleted_at IS NULL
+`
+
+func (q *Queries) GetProductsByIDs(ctx context.Context, ids []int64) ([]repository.Product, error) {
+	idList, err := jsonArray(ids)
+	if err != nil {
+		return nil, err
+	}
+	return q.queryProducts(ctx, getProductsByIDs, idList)
+}
+
+const deleteProduct = `-- name: DeleteProduct :one
+UPDATE products
+SET deleted_at = ?2,
+    updated_at = ?2,
+    version    = version + 1
+WHERE id = ?1
+  AND deleted_at IS NULL
+  AND (?3 IS NULL OR version = ?3)
+RETURNING ` + productColumns
+
+// DeleteProduct soft deletes a product; the row is kept until
+// PurgeDeletedProducts removes it.
+func (q *Queries) DeleteProduct(ctx context.Context, arg repository.DeleteProductParams) (repository.DeleteProductRow, error) {
+	i, err := q.changeProduct(ctx, "delete", arg.Actor, arg.ID, func(q *Queries, i *snapshot) error {
+		row := q.db.QueryRowContext(ctx, deleteProduct, arg.ID, micros(now()), arg.ExpectedVersion)
+		return scanProduct(row, &i.Product)
+	})
+	return repository.DeleteProductRow(i), err
+}
+
+const undeleteProduct = `-- name: UndeleteProduct :one
+UPDATE products
+SET deleted_at = NULL,
+    updated_at = ?2,
+    version    = version + 1
+WHERE id = ?1
+  AND deleted_at IS NOT NULL
+  AND (?3 IS NULL OR version = ?3)
+RETURNING ` + productColumns
+
+func (q *Queries) UndeleteProduct(ctx context.Context, arg repository.UndeleteProductParams) (repository.UndeleteProductRow, error) {
+	i, err := q.changeProduct(ctx, "undelete", arg.Actor, arg.ID, func(q *Queries, i *snapshot) error {
+		row := q.db.QueryRowContext(ctx, undeleteProduct, arg.ID, micros(now()), arg.ExpectedVersion)
+		return scanProduct(row, &i.Product)
+	})
+	return repository.UndeleteProductRow(i), err
+}
+
+const updateProduct = `-- name: UpdateProduct :one
+UPDATE products
+SET
+  name           = CASE WHEN ?2 THEN ?3 ELSE name END,
+  description    = CASE WHEN ?4 THEN ?5 ELSE description END,
+  price_minor    = CASE WHEN ?6 THEN ?7 ELSE price_minor END,
+  currency       = CASE WHEN ?6 THEN ?8 ELSE currency END,
+  stock_quantity = CASE WHEN ?9 THEN ?10 ELSE stock_quantity END,
+  updated_at     = ?11,
+  version        = version + 1
+WHERE id = ?1
+  AND deleted_at IS NULL
+  AND (?12 IS NULL OR version = ?12)
+RETURNING ` + productColumns
+
+// UpdateProduct books stock set through an update in the ledger as a
+// correction. Categories and tags given in arg are the ones the caller sets
+// after the update, and are recorded in its revision.
+func (q *Queries) UpdateProduct(ctx context.Context, arg repository.UpdateProductParams) (repository.UpdateProductRow, error) {
+	i, err := q.changeProduct(ctx, "update", arg.Actor, arg.ID, func(q *Queries, i *snapshot) error {
+		previous := i.Product
+		if arg.CategoryIds != nil {
+			i.CategoryIDs = arg.CategoryIds
+		}
+		if arg.Tags != nil {
+			i.Tags = arg.Tags
+		}
+		row := q.db.QueryRowContext(ctx, updateProduct,
+			arg.ID,
+			arg.SetName,
+			arg.Name,
+			arg.SetDescription,
+			arg.Description,
+			arg.SetPrice,
+			arg.PriceMinor,
+			arg.Currency,
+			arg.SetStockQuantity,
+			arg.StockQuantity,
+			micros(now()),
+			arg.ExpectedVersion,
+		)
+		if err := scanProduct(row, &i.Product); err != nil {
+			return err
+		}
+		if i.StockQuantity == previous.StockQuantity {
+			return nil
+		}
+		_, err := q.insertStockMovement(ctx, repository.StockMovement{
+			ProductID: i.ID,
+			Delta:     i.StockQuantity - previous.StockQuantity,
+			Reason:    "correction",
+			Note:      text("stock set by update"),
+			CreatedAt: i.UpdatedAt,
+		})
+		return err
+	})
+	return repository.UpdateProductRow(i), err
+}
+
+// changeProduct runs a mutation of an existing product and records its
+// revision. change is handed the snapshot of the product as it was and
+// scans it as it is now.
+func (q *Queries) changeProduct(ctx context.Context, action, actor string, id int64, change func(*Queries, *snapshot) error) (repository.Product, error) {
+	var i snapshot
+	err := q.atomic(ctx, func(q *Queries) error {
+		product, err := q.GetProductByIDWithDeleted(ctx, id)
+		if err != nil {
+			return err
+		}
+		previous, err := q.snapshotOf(ctx, product)
+		if err != nil {
+			return err
+		}
+		i = previous
+		if err := change(q, &i); err != nil {
+			return err
+		}
+		return q.recordRevision(ctx, action, actor, &previous, i)
+	})
+	if err != nil {
+		return repository.Product{}, pgError(err)
+	}
+	return i.Product, nil
+}
+
+const listPurgeableProducts = `-- name: PurgeDeletedProducts :execrows
+SELECT ` + productColumns + ` FROM products
+WHERE deleted_at < ?1
+ORDER BY deleted_at
+LIMIT ?2
+`
+
+const purgeProduct = `
+DELETE FROM products
+WHERE id = ?1
+`
+
+const queuePurgedMediaBlobs = `
+INSERT INTO purged_media_blobs (storage_key, created_at)
+SELECT storage_key, ?2 FROM product_media
+WHERE product_id = ?1
+ON CONFLICT (storage_key) DO NOTHING
+`
+
+// PurgeDeletedProducts hard deletes up to batch_size products soft deleted
+// before deleted_before and records a purge revision for each, which
+// WatchProducts reports, and its product.purged event. Their reservations,
+// variants, media, categories and tags go with them; their ledger entries
+// and revisions are kept. The blobs of their media are queued in
+// purged_media_blobs for the gateway to delete.
+func (q *Queries) PurgeDeletedProducts(ctx context.Context, arg repository.PurgeDeletedProductsParams) (int64, error) {
+	var purged int64
+	err := q.atomic(ctx, func(q *Queries) error {
+		products, err := q.queryProducts(ctx, listPurgeableProducts, micros(arg.DeletedBefore), arg.BatchSize)
+		if err != nil {
+			return err
+		}
+		at := now()
+		for _, product := range products {
+			previous, err := q.snapshotOf(ctx, product)
+			if err != nil {
+				return err
+			}
+			if _, err := q.db.ExecContext(ctx, queuePurgedMediaBlobs, previous.ID, micros(at)); err != nil {
+				return err
+			}
+			if _, err := q.db.ExecContext(ctx, purgeProduct, previous.ID); err != nil {
+				return err
+			}
+			current := previous
+			current.UpdatedAt = at
+			current.Version++
+			if err := q.recordRevision(ctx, "purge", "system", &previous, current); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return purged, nil
+}
+
+const listProductNames = `-- name: ListProductNames :many
+SELECT id, name FROM products
+WHERE deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListProductNames(ctx context.Context) ([]repository.ListProductNamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listProductNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ListProductNamesRow
+	for rows.Next() {
+		var i repository.ListProductNamesRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findProductWithStockInfo = `-- name: FindProductWithStockInfo :one
+SELECT
+  p.id                AS product_id,
+  p.name              AS product_name,
+  p.description       AS product_description,
+  p.price_minor       AS product_price_minor,
+  p.currency          AS product_currency,
+  p.stock_quantity    AS product_stock_quantity,
+  p.created_at        AS product_created_at,
+  p.updated_at        AS product_updated_at
+FROM products p
+WHERE p.id = ?1
+`
+
+func (q *Queries) FindProductWithStockInfo(ctx context.Context, id int64) (repository.FindProductWithStockInfoRow, error) {
+	row := q.db.QueryRowContext(ctx, findProductWithStockInfo, id)
+	var i repository.FindProductWithStockInfoRow
+	err := row.Scan(
+		&i.ProductID,
+		&i.ProductName,
+		&i.ProductDescription,
+		&i.ProductPriceMinor,
+		&i.ProductCurrency,
+		&i.ProductStockQuantity,
+		timestamp{&i.ProductCreatedAt},
+		timestamp{&i.ProductUpdatedAt},
+	)
+	return i, pgError(err)
+}
+
+const listProductChanges = `-- name: ListProductChanges :many
+SELECT ` + productColumns + ` FROM products
+WHERE (updated_at, id) > (?1, ?2)
+  AND updated_at <= ?3
+ORDER BY updated_at, id
+LIMIT ?4
+`
+
+// ListProductChanges returns the products changed after the (updated_at, id)
+// cursor, oldest first. Rows newer than the settle window are held back so
+// that a transaction committing late with an earlier updated_at is not
+// skipped.
+func (q *Queries) ListProductChanges(ctx context.Context, arg repository.ListProductChangesParams) ([]repository.Product, error) {
+	settled := micros(now()) - intervalMicros(arg.Settle)
+	return q.queryProducts(ctx, listProductChanges, micros(arg.AfterUpdatedAt), arg.AfterID, settled, arg.PageLimit)
+}
+
+const searchProducts = `-- name: SearchProducts :many
+SELECT id, name, description, price_minor, currency, stock_quantity, created_at, updated_at, version, score
+FROM (
+  SELECT
+    p.*,
+    CASE
+      WHEN lower(p.name) = lower(?1) THEN 2.0
+      WHEN instr(lower(p.name), lower(?1)) > 0 THEN 1.0
+      ELSE 0.0
+    END
+    + CASE WHEN instr(lower(COALESCE(p.description, '')), lower(?1)) > 0 THEN 0.5 ELSE 0.0 END AS score
+  FROM products p
+  WHERE p.deleted_at IS NULL
+) ranked
+WHERE score > 0
+  AND (?2 IS NULL OR (score, id) < (?2, ?3))
+ORDER BY score DESC, id DESC
+LIMIT ?4
+`
+
+// SearchProducts matches the query as a case-insensitive substring of the
+// name or description: SQLite has neither full-text ranking nor trigram
+// similarity built in. An exact name match ranks first, then a name
+// containing the query, then a description containing it. Keyset
+// pagination runs on (score, id).
+func (q *Queries) SearchProducts(ctx context.Context, arg repository.SearchProductsParams) ([]repository.SearchProductsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchProducts, arg.Query, arg.AfterScore, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.SearchProductsRow
+	for rows.Next() {
+		var i repository.SearchProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.PriceMinor,
+			&i.Currency,
+			&i.StockQuantity,
+			timestamp{&i.CreatedAt},
+			timestamp{&i.UpdatedAt},
+			&i.Version,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// ListProducts is assembled like its CockroachDB counterpart in
+// repository/products_list.go, from fixed SQL fragments with every caller
+// supplied value sent as a bind parameter.
+
+var productSortColumns = map[repository.ProductSortField]string{
+	repository.ProductSortByID:        "id",
+	repository.ProductSortByPrice:     "price_minor",
+	repository.ProductSortByName:      "name",
+	repository.ProductSortByCreatedAt: "created_at",
+}
+
+// queryBuilder accumulates WHERE conditions and their bind parameters.
+type queryBuilder struct {
+	conds []string
+	args  []any
+}
+
+// arg registers a bind parameter and returns its placeholder.
+func (b *queryBuilder) arg(v any) string {
+	if t, ok := v.(time.Time); ok {
+		v = micros(t)
+	}
+	b.args = append(b.args, v)
+	return "?" + strconv.Itoa(len(b.args))
+}
+
+func (b *queryBuilder) where(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func buildListProducts(arg repository.ListProductsParams) (string, []any) {
+	var b queryBuilder
+
+	if arg.Currency != "" {
+		b.where("currency = " + b.arg(arg.Currency))
+	}
+	if arg.MinPrice != nil {
+		b.where("price_minor >= " + b.arg(*arg.MinPrice))
+	}
+	if arg.MaxPrice != nil {
+		b.where("price_minor <= " + b.arg(*arg.MaxPrice))
+	}
+	if arg.NamePrefix != "" {
+		// SQLite's LIKE ignores case, so the prefix is compared directly.
+		prefix := b.arg(arg.NamePrefix)
+		b.where("substr(name, 1, length(" + prefix + ")) = " + prefix)
+	}
+	if arg.InStockOnly {
+		b.where("stock_quantity > 0")
+	}
+	if arg.CategoryID != 0 {
+		// A category's subtree is every category whose path extends its own.
+		b.where("id IN (SELECT pc.product_id FROM product_categories pc" +
+			" JOIN categories c ON c.id = pc.category_id" +
+			" JOIN categories root ON root.id = " + b.arg(arg.CategoryID) +
+			" WHERE substr(c.path, 1, length(root.path)) = root.path)")
+	}
+	if arg.Tag != "" {
+		b.where("id IN (SELECT product_id FROM product_tags WHERE tag = " + b.arg(arg.Tag) + ")")
+	}
+	if !arg.ShowDeleted {
+		b.where("deleted_at IS NULL")
+	}
+
+	column, ok := productSortColumns[arg.SortBy]
+	if !ok {
+		column = "id"
+	}
+	direction, cmp := "ASC", ">"
+	if arg.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if arg.After != nil {
+		if column == "id" {
+			b.where("id " + cmp + " " + b.arg(arg.After.ID))
+		} else {
+			b.where("(" + column + ", id) " + cmp + " (" + b.arg(arg.After.Value) + ", " + b.arg(arg.After.ID) + ")")
+		}
+	}
+
+	var sql strings.Builder
+	sql.WriteString("SELECT " + productColumns + " FROM products")
+	if len(b.conds) > 0 {
+		sql.WriteString("\nWHERE " + strings.Join(b.conds, "\n  AND "))
+	}
+	if column == "id" {
+		sql.WriteString("\nORDER BY id " + direction)
+	} else {
+		sql.WriteString("\nORDER BY " + column + " " + direction + ", id " + direction)
+	}
+	sql.WriteString("\nLIMIT " + b.arg(arg.Limit))
+
+	return sql.String(), b.args
+}
+
+func (q *Queries) ListProducts(ctx context.Context, arg repository.ListProductsParams) ([]repository.Product, error) {
+	query, args := buildListProducts(arg)
+	return q.queryProducts(ctx, query, args...)
+}
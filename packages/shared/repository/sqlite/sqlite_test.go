@@ -0,0 +1,452 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository/sqlite"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+// newQueries opens a fresh database file with the schema applied.
+func newQueries(t *testing.T) *sqlite.Queries {
+	t.Helper()
+	lc := fxtest.NewLifecycle(t)
+	db, err := database.NewSQLite(database.Params{
+		Lifecycle: lc,
+		Cfg: &config.Config{DbConfig: config.DbConfig{
+			Driver: database.DriverSQLite,
+			Path:   filepath.Join(t.TempDir(), "test.db"),
+		}},
+		Log: zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatalf("NewSQLite error = %v", err)
+	}
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+	return sqlite.New(db)
+}
+
+func createProduct(t *testing.T, q *sqlite.Queries, id int64, name string, stock int32) repository.CreateProductRow {
+	t.Helper()
+	p, err := q.CreateProduct(context.Background(), repository.CreateProductParams{
+		ID:            id,
+		Name:          name,
+		PriceMinor:    1000,
+		Currency:      "USD",
+		StockQuantity: stock,
+		Actor:         "test",
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct(%d) error = %v", id, err)
+	}
+	return p
+}
+
+func TestProductLifecycle(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+
+	created := createProduct(t, q, 1, "Lamp", 5)
+	if created.Version != 1 || created.StockQuantity != 5 {
+		t.Errorf("created = version %d stock %d, want version 1 stock 5", created.Version, created.StockQuantity)
+	}
+
+	_, err := q.UpdateProduct(ctx, repository.UpdateProductParams{
+		ID:              1,
+		SetName:         true,
+		Name:            "Desk lamp",
+		ExpectedVersion: pgtype.Int8{Int64: 7, Valid: true},
+		Actor:           "test",
+	})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("UpdateProduct with a stale version error = %v, want pgx.ErrNoRows", err)
+	}
+
+	updated, err := q.UpdateProduct(ctx, repository.UpdateProductParams{
+		ID:               1,
+		SetName:          true,
+		Name:             "Desk lamp",
+		SetStockQuantity: true,
+		StockQuantity:    8,
+		ExpectedVersion:  pgtype.Int8{Int64: 1, Valid: true},
+		Actor:            "test",
+	})
+	if err != nil {
+		t.Fatalf("UpdateProduct error = %v", err)
+	}
+	if updated.Name != "Desk lamp" || updated.Version != 2 || updated.StockQuantity != 8 {
+		t.Errorf("updated = %q version %d stock %d, want \"Desk lamp\" version 2 stock 8", updated.Name, updated.Version, updated.StockQuantity)
+	}
+
+	if _, err := q.DeleteProduct(ctx, repository.DeleteProductParams{ID: 1, Actor: "test"}); err != nil {
+		t.Fatalf("DeleteProduct error = %v", err)
+	}
+	if _, err := q.GetProductByID(ctx, 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID after delete error = %v, want pgx.ErrNoRows", err)
+	}
+
+	revisions, err := q.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	var actions []string
+	for _, r := range revisions {
+		actions = append(actions, r.Action)
+	}
+	if len(actions) != 3 || actions[0] != "delete" || actions[1] != "update" || actions[2] != "create" {
+		t.Errorf("revision actions = %v, want [delete update create]", actions)
+	}
+
+	movements, err := q.ListStockMovements(ctx, repository.ListStockMovementsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListStockMovements error = %v", err)
+	}
+	if len(movements) != 2 {
+		t.Errorf("got %d stock movements, want the receipt and the correction", len(movements))
+	}
+
+	events, err := q.ListPendingOutboxEvents(ctx, repository.ListPendingOutboxEventsParams{Now: created.CreatedAt.AddDate(1, 0, 0), BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ListPendingOutboxEvents error = %v", err)
+	}
+	if len(events) != 3 || events[0].EventType != "product.created" || events[2].EventType != "product.deleted" {
+		t.Errorf("got %d pending events, want created, updated and deleted in order", len(events))
+	}
+}
+
+func TestCreateProduct_Violations(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	createProduct(t, q, 1, "Lamp", 0)
+
+	tests := []struct {
+		name           string
+		arg            repository.CreateProductParams
+		wantCode       string
+		wantConstraint string
+	}{
+		{
+			name:           "duplicate id",
+			arg:            repository.CreateProductParams{ID: 1, Name: "Chair", Currency: "USD"},
+			wantCode:       "23505",
+			wantConstraint: "products_pkey",
+		},
+		{
+			name:           "negative price",
+			arg:            repository.CreateProductParams{ID: 2, Name: "Chair", PriceMinor: -1, Currency: "USD"},
+			wantCode:       "23514",
+			wantConstraint: "products_price_minor_check",
+		},
+		{
+			name:     "unknown currency",
+			arg:      repository.CreateProductParams{ID: 3, Name: "Chair", Currency: "XXX"},
+			wantCode: "23503",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := q.CreateProduct(ctx, tt.arg)
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) {
+				t.Fatalf("CreateProduct error = %v, want a *pgconn.PgError", err)
+			}
+			if pgErr.Code != tt.wantCode || pgErr.ConstraintName != tt.wantConstraint {
+				t.Errorf("error = %s %q, want %s %q", pgErr.Code, pgErr.ConstraintName, tt.wantCode, tt.wantConstraint)
+			}
+		})
+	}
+}
+
+func TestReserveStock(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	createProduct(t, q, 1, "Lamp", 5)
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+
+	r, err := q.ReserveStock(ctx, repository.ReserveStockParams{ID: 10, Quantity: 3, Ttl: ttl, ProductID: 1})
+	if err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+	if _, err := q.ReserveStock(ctx, repository.ReserveStockParams{ID: 11, Quantity: 3, Ttl: ttl, ProductID: 1}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("ReserveStock beyond stock error = %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := q.CommitStockReservation(ctx, r.ID); err != nil {
+		t.Fatalf("CommitStockReservation error = %v", err)
+	}
+
+	p, err := q.GetProductByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProductByID error = %v", err)
+	}
+	if p.StockQuantity != 2 {
+		t.Errorf("stock = %d, want 2", p.StockQuantity)
+	}
+	drift, err := q.ReconcileStock(ctx, 10)
+	if err != nil {
+		t.Fatalf("ReconcileStock error = %v", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("ReconcileStock = %v, want no drift", drift)
+	}
+}
+
+func TestRevisions_StockAndTaxonomy(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	if _, err := q.CreateProduct(ctx, repository.CreateProductParams{
+		ID: 1, Name: "Lamp", PriceMinor: 1000, Currency: "USD", StockQuantity: 5, Actor: "test", Tags: []string{"sale"},
+	}); err != nil {
+		t.Fatalf("CreateProduct error = %v", err)
+	}
+	if err := q.AddProductTags(ctx, repository.AddProductTagsParams{ProductID: 1, Tags: []string{"sale"}}); err != nil {
+		t.Fatalf("AddProductTags error = %v", err)
+	}
+
+	if _, err := q.AdjustStock(ctx, repository.AdjustStockParams{ID: 20, ProductID: 1, Delta: 2, Reason: "receipt", Actor: "clerk"}); err != nil {
+		t.Fatalf("AdjustStock error = %v", err)
+	}
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	if _, err := q.ReserveStock(ctx, repository.ReserveStockParams{ID: 10, Quantity: 3, Ttl: ttl, ProductID: 1, Actor: "clerk"}); err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+	if _, err := q.ReleaseStockReservation(ctx, repository.ReleaseStockReservationParams{ID: 10, Actor: "clerk"}); err != nil {
+		t.Fatalf("ReleaseStockReservation error = %v", err)
+	}
+	if _, err := q.UpdateProduct(ctx, repository.UpdateProductParams{ID: 1, Actor: "test", Tags: []string{"clearance"}}); err != nil {
+		t.Fatalf("UpdateProduct error = %v", err)
+	}
+
+	revisions, err := q.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	var actions []string
+	for i, r := range revisions {
+		actions = append(actions, r.Action)
+		if want := int64(len(revisions) - i); r.Version != want {
+			t.Errorf("revisions[%d].Version = %d, want %d", i, r.Version, want)
+		}
+	}
+	if strings.Join(actions, " ") != "update stock stock stock create" {
+		t.Fatalf("revision actions = %v, want [update stock stock stock create]", actions)
+	}
+
+	var before, after struct {
+		StockQuantity int32    `json:"stock_quantity"`
+		Tags          []string `json:"tags"`
+	}
+	if err := json.Unmarshal(revisions[0].Previous, &before); err != nil {
+		t.Fatalf("previous: %v", err)
+	}
+	if err := json.Unmarshal(revisions[0].Snapshot, &after); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if len(before.Tags) != 1 || before.Tags[0] != "sale" || len(after.Tags) != 1 || after.Tags[0] != "clearance" {
+		t.Errorf("update tags = %v -> %v, want [sale] -> [clearance]", before.Tags, after.Tags)
+	}
+	if err := json.Unmarshal(revisions[3].Snapshot, &after); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if after.StockQuantity != 7 || revisions[3].Actor != "clerk" {
+		t.Errorf("adjust revision = stock %d by %q, want 7 by clerk", after.StockQuantity, revisions[3].Actor)
+	}
+
+	events, err := q.ListPendingOutboxEvents(ctx, repository.ListPendingOutboxEventsParams{Now: revisions[0].CreatedAt.AddDate(1, 0, 0), BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ListPendingOutboxEvents error = %v", err)
+	}
+	var types []string
+	for _, e := range events {
+		types = append(types, e.EventType)
+	}
+	if want := "product.created product.stock_changed product.stock_changed product.stock_changed product.updated"; strings.Join(types, " ") != want {
+		t.Errorf("event types = %v, want [%s]", types, want)
+	}
+}
+
+func TestListProducts_Keyset(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	for id, name := range map[int64]string{1: "Lamp", 2: "Ladder", 3: "Chair", 4: "Lantern"} {
+		createProduct(t, q, id, name, 1)
+	}
+
+	var got []int64
+	var after *repository.ProductKeyset
+	for {
+		page, err := q.ListProducts(ctx, repository.ListProductsParams{
+			NamePrefix: "La",
+			SortBy:     repository.ProductSortByName,
+			After:      after,
+			Limit:      2,
+		})
+		if err != nil {
+			t.Fatalf("ListProducts error = %v", err)
+		}
+		for _, p := range page {
+			got = append(got, p.ID)
+		}
+		if len(page) < 2 {
+			break
+		}
+		last := page[len(page)-1]
+		after = &repository.ProductKeyset{ID: last.ID, Value: last.Name}
+	}
+
+	if len(got) != 3 || got[0] != 2 || got[1] != 1 || got[2] != 4 {
+		t.Errorf("ids = %v, want [2 1 4] (Ladder, Lamp, Lantern)", got)
+	}
+}
+
+func TestPurgeDeletedProducts_KeepsHistory(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	created := createProduct(t, q, 1, "Lamp", 5)
+	media, err := q.AddProductMedia(ctx, repository.AddProductMediaParams{
+		ID: 2, ProductID: 1, StorageKey: "products/1/lamp.png", ContentType: "image/png",
+		ChecksumSha256: strings.Repeat("a", 64), SizeBytes: 1, Width: 1, Height: 1, MaxMedia: 20,
+	})
+	if err != nil {
+		t.Fatalf("AddProductMedia error = %v", err)
+	}
+	if _, err := q.DeleteProduct(ctx, repository.DeleteProductParams{ID: 1, Actor: "test"}); err != nil {
+		t.Fatalf("DeleteProduct error = %v", err)
+	}
+
+	n, err := q.PurgeDeletedProducts(ctx, repository.PurgeDeletedProductsParams{
+		DeletedBefore: created.CreatedAt.AddDate(0, 0, 1),
+		BatchSize:     10,
+	})
+	if err != nil {
+		t.Fatalf("PurgeDeletedProducts error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("purged %d products, want 1", n)
+	}
+
+	revisions, err := q.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Action != "purge" || revisions[0].Version != 3 {
+		t.Errorf("got %d revisions after the purge, want purge (version 3), delete and create", len(revisions))
+	}
+	purges, err := q.ListProductPurges(ctx, repository.ListProductPurgesParams{PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductPurges error = %v", err)
+	}
+	if len(purges) != 1 || purges[0].ProductID != 1 {
+		t.Errorf("ListProductPurges = %v, want the purge of product 1", purges)
+	}
+	events, err := q.ListPendingOutboxEvents(ctx, repository.ListPendingOutboxEventsParams{Now: revisions[0].CreatedAt.AddDate(1, 0, 0), BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ListPendingOutboxEvents error = %v", err)
+	}
+	if len(events) != 3 || events[2].EventType != "product.purged" || events[2].AggregateVersion != 3 {
+		t.Errorf("got %d pending events, want created, deleted and purged (version 3)", len(events))
+	}
+	movements, err := q.ListStockMovements(ctx, repository.ListStockMovementsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListStockMovements error = %v", err)
+	}
+	if len(movements) != 1 {
+		t.Errorf("got %d stock movements after the purge, want the receipt", len(movements))
+	}
+
+	blobs, err := q.ListPurgedMediaBlobs(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListPurgedMediaBlobs error = %v", err)
+	}
+	if len(blobs) != 1 || blobs[0] != media.StorageKey {
+		t.Fatalf("ListPurgedMediaBlobs = %v, want [%s]", blobs, media.StorageKey)
+	}
+	if n, err := q.DeletePurgedMediaBlobs(ctx, blobs); err != nil || n != 1 {
+		t.Errorf("DeletePurgedMediaBlobs = %d, %v, want 1, nil", n, err)
+	}
+}
+
+func TestDeleteVariant_KeepsLedger(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	createProduct(t, q, 1, "Lamp", 5)
+	if _, err := q.CreateVariant(ctx, repository.CreateVariantParams{
+		ID: 2, Sku: "LAMP-RED", Options: []byte(`{"color":"red"}`), OptionKey: "color=red", ProductID: 1, MaxVariants: 10,
+	}); err != nil {
+		t.Fatalf("CreateVariant error = %v", err)
+	}
+	// Stock is added before it is taken, so the order matters.
+	for i, delta := range []int32{3, -3} {
+		if _, err := q.AdjustVariantStock(ctx, repository.AdjustVariantStockParams{
+			ID: int64(10 + i), Delta: delta, VariantID: 2, ProductID: 1, Reason: "correction",
+		}); err != nil {
+			t.Fatalf("AdjustVariantStock(%d) error = %v", delta, err)
+		}
+	}
+	if _, err := q.DeleteVariant(ctx, 2); err != nil {
+		t.Fatalf("DeleteVariant error = %v", err)
+	}
+
+	movements, err := q.ListStockMovements(ctx, repository.ListStockMovementsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListStockMovements error = %v", err)
+	}
+	if len(movements) != 3 {
+		t.Fatalf("got %d stock movements after DeleteVariant, want the receipt and both corrections", len(movements))
+	}
+	for _, m := range movements {
+		if m.VariantID.Valid {
+			t.Errorf("movement %d still references variant %d", m.ID, m.VariantID.Int64)
+		}
+	}
+	drift, err := q.ReconcileStock(ctx, 10)
+	if err != nil {
+		t.Fatalf("ReconcileStock error = %v", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("ReconcileStock = %v, want no drift", drift)
+	}
+}
+
+func TestAcquireOutboxLease(t *testing.T) {
+	q := newQueries(t)
+	ctx := context.Background()
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	acquire := func(holder string) int64 {
+		t.Helper()
+		n, err := q.AcquireOutboxLease(ctx, repository.AcquireOutboxLeaseParams{Holder: holder, Ttl: ttl})
+		if err != nil {
+			t.Fatalf("AcquireOutboxLease(%s) error = %v", holder, err)
+		}
+		return n
+	}
+
+	if n := acquire("a"); n != 1 {
+		t.Errorf("a took the free lease: %d rows, want 1", n)
+	}
+	if n := acquire("a"); n != 1 {
+		t.Errorf("a renewed its lease: %d rows, want 1", n)
+	}
+	if n := acquire("b"); n != 0 {
+		t.Errorf("b took a's unexpired lease: %d rows, want 0", n)
+	}
+	if err := q.ReleaseOutboxLease(ctx, "a"); err != nil {
+		t.Fatalf("ReleaseOutboxLease error = %v", err)
+	}
+	if n := acquire("b"); n != 1 {
+		t.Errorf("b took the released lease: %d rows, want 1", n)
+	}
+}
@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const stockMovementColumns = "id, product_id, delta, reason, reference, note, created_at, variant_id"
+
+func scanStockMovement(row scanner, i *repository.StockMovement) error {
+	return row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Delta,
+		&i.Reason,
+		&i.Reference,
+		&i.Note,
+		timestamp{&i.CreatedAt},
+		&i.VariantID,
+	)
+}
+
+const insertStockMovement = `
+INSERT INTO stock_movements (id, product_id, variant_id, delta, reason, reference, note, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+RETURNING ` + stockMovementColumns
+
+// insertStockMovement appends a ledger entry. Its id is generated unless
+// set.
+func (q *Queries) insertStockMovement(ctx context.Context, m repository.StockMovement) (repository.StockMovement, error) {
+	var id any
+	if m.ID != 0 {
+		id = m.ID
+	}
+	row := q.db.QueryRowContext(ctx, insertStockMovement,
+		id,
+		m.ProductID,
+		m.VariantID,
+		m.Delta,
+		m.Reason,
+		m.Reference,
+		m.Note,
+		micros(m.CreatedAt),
+	)
+	var i repository.StockMovement
+	err := scanStockMovement(row, &i)
+	return i, err
+}
+
+const adjustStock = `-- name: AdjustStock :one
+UPDATE products
+SET stock_quantity = stock_quantity + ?1,
+    updated_at     = ?2,
+    version        = version + 1
+WHERE products.id = ?3
+  AND products.deleted_at IS NULL
+  AND stock_quantity + ?1 >= 0
+RETURNING ` + productColumns
+
+// AdjustStock applies a signed stock change and records it in the ledger
+// and as a stock revision. No row is returned when the product is missing
+// or the change would take stock below zero.
+func (q *Queries) AdjustStock(ctx context.Context, arg repository.AdjustStockParams) (repository.AdjustStockRow, error) {
+	var i repository.AdjustStockRow
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := now()
+		previous, err := q.GetProductByID(ctx, arg.ProductID)
+		if err != nil {
+			return err
+		}
+		var current repository.Product
+		if err := scanProduct(q.db.QueryRowContext(ctx, adjustStock, arg.Delta, micros(at), arg.ProductID), &current); err != nil {
+			return err
+		}
+		if err := q.recordStockRevision(ctx, arg.Actor, previous, current); err != nil {
+			return err
+		}
+		i.StockQuantity = current.StockQuantity
+		m, err := q.insertStockMovement(ctx, repository.StockMovement{
+			ID:        arg.ID,
+			ProductID: arg.ProductID,
+			Delta:     arg.Delta,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			Note:      arg.Note,
+			CreatedAt: at,
+		})
+		i.ID, i.ProductID, i.Delta, i.Reason, i.Reference, i.Note, i.CreatedAt = m.ID, m.ProductID, m.Delta, m.Reason, m.Reference, m.Note, m.CreatedAt
+		return err
+	})
+	if err != nil {
+		return repository.AdjustStockRow{}, pgError(err)
+	}
+	return i, nil
+}
+
+const listStockMovements = `-- name: ListStockMovements :many
+SELECT ` + stockMovementColumns + ` FROM stock_movements
+WHERE product_id = ?1
+  AND (?2 IS NULL OR variant_id = ?2)
+  AND (?3 IS NULL OR reason = ?3)
+  AND (?4 IS NULL OR (created_at, id) < (?4, ?5))
+ORDER BY created_at DESC, id DESC
+LIMIT ?6
+`
+
+// ListStockMovements returns the newest entries first; keyset pagination
+// runs on (created_at, id).
+func (q *Queries) ListStockMovements(ctx context.Context, arg repository.ListStockMovementsParams) ([]repository.StockMovement, error) {
+	rows, err := q.db.QueryContext(ctx, listStockMovements,
+		arg.ProductID,
+		arg.VariantID,
+		arg.Reason,
+		nullMicros(arg.BeforeCreatedAt),
+		arg.BeforeID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.StockMovement
+	for rows.Next() {
+		var i repository.StockMovement
+		if err := scanStockMovement(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reconcileStock = `-- name: ReconcileStock :many
+SELECT
+  p.id                          AS product_id,
+  NULL                          AS variant_id,
+  p.stock_quantity,
+  COALESCE(h.quantity, 0)       AS held_quantity,
+  COALESCE(l.quantity, 0)       AS ledger_quantity
+FROM products p
+LEFT JOIN (
+  SELECT product_id, sum(delta) AS quantity
+  FROM stock_movements
+  WHERE variant_id IS NULL
+  GROUP BY product_id
+) l ON l.product_id = p.id
+LEFT JOIN (
+  SELECT product_id, sum(quantity) AS quantity
+  FROM stock_reservations
+  WHERE status = 'pending' AND variant_id IS NULL
+  GROUP BY product_id
+) h ON h.product_id = p.id
+WHERE p.stock_quantity + COALESCE(h.quantity, 0) <> COALESCE(l.quantity, 0)
+UNION ALL
+SELECT
+  v.product_id,
+  v.id,
+  v.stock_quantity,
+  COALESCE(h.quantity, 0),
+  COALESCE(l.quantity, 0)
+FROM product_variants v
+LEFT JOIN (
+  SELECT variant_id, sum(delta) AS quantity
+  FROM stock_movements
+  WHERE variant_id IS NOT NULL
+  GROUP BY variant_id
+) l ON l.variant_id = v.id
+LEFT JOIN (
+  SELECT variant_id, sum(quantity) AS quantity
+  FROM stock_reservations
+  WHERE status = 'pending' AND variant_id IS NOT NULL
+  GROUP BY variant_id
+) h ON h.variant_id = v.id
+WHERE v.stock_quantity + COALESCE(h.quantity, 0) <> COALESCE(l.quantity, 0)
+ORDER BY product_id, variant_id NULLS FIRST
+LIMIT ?1
+`
+
+// ReconcileStock reports products and variants whose stored quantity plus
+// pending holds disagrees with the ledger. Product rows only count entries
+// and holds without a variant.
+func (q *Queries) ReconcileStock(ctx context.Context, maxResults int32) ([]repository.ReconcileStockRow, error) {
+	rows, err := q.db.QueryContext(ctx, reconcileStock, maxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ReconcileStockRow
+	for rows.Next() {
+		var i repository.ReconcileStockRow
+		if err := rows.Scan(
+			&i.ProductID,
+			&i.VariantID,
+			&i.StockQuantity,
+			&i.HeldQuantity,
+			&i.LedgerQuantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
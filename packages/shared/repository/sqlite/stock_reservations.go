@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const stockReservationColumns = "id, product_id, quantity, status, expires_at, created_at, updated_at, variant_id"
+
+func scanStockReservation(row scanner, i *repository.StockReservation) error {
+	return row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Quantity,
+		&i.Status,
+		timestamp{&i.ExpiresAt},
+		timestamp{&i.CreatedAt},
+		timestamp{&i.UpdatedAt},
+		&i.VariantID,
+	)
+}
+
+const insertStockReservation = `
+INSERT INTO stock_reservations (id, product_id, variant_id, quantity, expires_at, created_at, updated_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?6)
+RETURNING ` + stockReservationColumns
+
+const reserveStock = `-- name: ReserveStock :one
+UPDATE products
+SET stock_quantity = stock_quantity - ?1,
+    updated_at     = ?2,
+    version        = version + 1
+WHERE products.id = ?3
+  AND products.deleted_at IS NULL
+  AND stock_quantity >= ?1
+RETURNING ` + productColumns
+
+// ReserveStock decrements stock and records the hold and a stock revision.
+// No row is returned when the product is missing or has insufficient
+// stock.
+func (q *Queries) ReserveStock(ctx context.Context, arg repository.ReserveStockParams) (repository.StockReservation, error) {
+	var i repository.StockReservation
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := micros(now())
+		previous, err := q.GetProductByID(ctx, arg.ProductID)
+		if err != nil {
+			return err
+		}
+		var current repository.Product
+		if err := scanProduct(q.db.QueryRowContext(ctx, reserveStock, arg.Quantity, at, arg.ProductID), &current); err != nil {
+			return err
+		}
+		if err := q.recordStockRevision(ctx, arg.Actor, previous, current); err != nil {
+			return err
+		}
+		row := q.db.QueryRowContext(ctx, insertStockReservation,
+			arg.ID, current.ID, nil, arg.Quantity, at+intervalMicros(arg.Ttl), at,
+		)
+		return scanStockReservation(row, &i)
+	})
+	if err != nil {
+		return repository.StockReservation{}, pgError(err)
+	}
+	return i, nil
+}
+
+const getStockReservation = `-- name: GetStockReservation :one
+SELECT ` + stockReservationColumns + ` FROM stock_reservations
+WHERE id = ?1
+`
+
+func (q *Queries) GetStockReservation(ctx context.Context, id int64) (repository.StockReservation, error) {
+	row := q.db.QueryRowContext(ctx, getStockReservation, id)
+	var i repository.StockReservation
+	err := scanStockReservation(row, &i)
+	return i, pgError(err)
+}
+
+const commitStockReservation = `-- name: CommitStockReservation :one
+UPDATE stock_reservations
+SET status = 'committed', updated_at = ?2
+WHERE stock_reservations.id = ?1
+  AND status = 'pending'
+  AND expires_at > ?2
+RETURNING ` + stockReservationColumns
+
+// CommitStockReservation books the sale in the ledger: the held stock
+// leaves inventory for good.
+func (q *Queries) CommitStockReservation(ctx context.Context, id int64) (repository.CommitStockReservationRow, error) {
+	var i repository.StockReservation
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := now()
+		if err := scanStockReservation(q.db.QueryRowContext(ctx, commitStockReservation, id, micros(at)), &i); err != nil {
+			return err
+		}
+		_, err := q.insertStockMovement(ctx, repository.StockMovement{
+			ProductID: i.ProductID,
+			VariantID: i.VariantID,
+			Delta:     -i.Quantity,
+			Reason:    "sale",
+			Reference: text("reservation:" + strconv.FormatInt(i.ID, 10)),
+			CreatedAt: at,
+		})
+		return err
+	})
+	if err != nil {
+		return repository.CommitStockReservationRow{}, pgError(err)
+	}
+	return repository.CommitStockReservationRow(i), nil
+}
+
+const releaseStockReservation = `-- name: ReleaseStockReservation :one
+UPDATE stock_reservations
+SET status = 'released', updated_at = ?2
+WHERE stock_reservations.id = ?1
+  AND status = 'pending'
+RETURNING ` + stockReservationColumns
+
+const restockProduct = `
+UPDATE products
+SET stock_quantity = stock_quantity + ?2,
+    updated_at     = ?3,
+    version        = version + 1
+WHERE id = ?1
+RETURNING ` + productColumns
+
+const restockVariant = `
+UPDATE product_variants
+SET stock_quantity = stock_quantity + ?2,
+    updated_at     = ?3
+WHERE id = ?1
+`
+
+// restock returns held stock to a product, recording a stock revision by
+// actor, or to the variant it was taken from.
+func (q *Queries) restock(ctx context.Context, actor string, productID int64, variantID pgtype.Int8, quantity int64, at int64) error {
+	if variantID.Valid {
+		_, err := q.db.ExecContext(ctx, restockVariant, variantID.Int64, quantity, at)
+		return err
+	}
+	previous, err := q.GetProductByIDWithDeleted(ctx, productID)
+	if err != nil {
+		return err
+	}
+	var current repository.Product
+	if err := scanProduct(q.db.QueryRowContext(ctx, restockProduct, productID, quantity, at), &current); err != nil {
+		return err
+	}
+	return q.recordStockRevision(ctx, actor, previous, current)
+}
+
+// ReleaseStockReservation returns the held quantity to the product, or to
+// the variant it was taken from, and marks the hold released. Stock
+// returned to the product is recorded as a stock revision.
+func (q *Queries) ReleaseStockReservation(ctx context.Context, arg repository.ReleaseStockReservationParams) (repository.ReleaseStockReservationRow, error) {
+	var i repository.StockReservation
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := micros(now())
+		if err := scanStockReservation(q.db.QueryRowContext(ctx, releaseStockReservation, arg.ID, at), &i); err != nil {
+			return err
+		}
+		return q.restock(ctx, arg.Actor, i.ProductID, i.VariantID, int64(i.Quantity), at)
+	})
+	if err != nil {
+		return repository.ReleaseStockReservationRow{}, pgError(err)
+	}
+	return repository.ReleaseStockReservationRow(i), nil
+}
+
+const expireStockReservations = `
+UPDATE stock_reservations
+SET status = 'expired', updated_at = ?1
+WHERE stock_reservations.id IN (
+  SELECT id FROM stock_reservations
+  WHERE status = 'pending' AND expires_at <= ?1
+  ORDER BY expires_at
+  LIMIT ?2
+)
+RETURNING product_id, variant_id, quantity
+`
+
+// ExpireStockReservations releases up to batch_size overdue holds and
+// returns how many expired. Stock returned to a product is recorded as a
+// stock revision by system.
+func (q *Queries) ExpireStockReservations(ctx context.Context, batchSize int32) (int64, error) {
+	var count int64
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := micros(now())
+		rows, err := q.db.QueryContext(ctx, expireStockReservations, at, batchSize)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		// Holds are returned per product and per variant, so that a
+		// product's version moves once however many of its holds expire.
+		type holder struct {
+			productID int64
+			variantID pgtype.Int8
+		}
+		totals := make(map[holder]int64)
+		for rows.Next() {
+			var h holder
+			var quantity int64
+			if err := rows.Scan(&h.productID, &h.variantID, &quantity); err != nil {
+				return err
+			}
+			if h.variantID.Valid {
+				h.productID = 0
+			}
+			totals[h] += quantity
+			count++
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		holders := make([]holder, 0, len(totals))
+		for h := range totals {
+			holders = append(holders, h)
+		}
+		slices.SortFunc(holders, func(a, b holder) int {
+			if c := cmp.Compare(a.productID, b.productID); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.variantID.Int64, b.variantID.Int64)
+		})
+		for _, h := range holders {
+			if err := q.restock(ctx, "system", h.productID, h.variantID, totals[h], at); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, pgError(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const variantColumns = "id, product_id, sku, options, option_key, price_minor, currency, stock_quantity, created_at, updated_at"
+
+func scanVariant(row scanner, i *repository.ProductVariant) error {
+	return row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Sku,
+		&i.Options,
+		&i.OptionKey,
+		&i.PriceMinor,
+		&i.Currency,
+		&i.StockQuantity,
+		timestamp{&i.CreatedAt},
+		timestamp{&i.UpdatedAt},
+	)
+}
+
+func (q *Queries) queryVariants(ctx context.Context, query string, args ...any) ([]repository.ProductVariant, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []repository.ProductVariant
+	for rows.Next() {
+		var i repository.ProductVariant
+		if err := scanVariant(rows, &i); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createVariant = `-- name: CreateVariant :one
+INSERT INTO product_variants (id, product_id, sku, options, option_key, price_minor, currency, stock_quantity, created_at, updated_at)
+SELECT ?1, products.id, ?2, ?3, ?4, ?5, ?6, ?7, ?10, ?10
+FROM products
+WHERE products.id = ?8
+  AND products.deleted_at IS NULL
+  AND (SELECT count(*) FROM product_variants pv WHERE pv.product_id = ?8) < ?9
+RETURNING ` + variantColumns
+
+// CreateVariant books initial stock in the ledger as a receipt. No row is
+// returned when the product is missing or already has max_variants
+// variants.
+func (q *Queries) CreateVariant(ctx context.Context, arg repository.CreateVariantParams) (repository.CreateVariantRow, error) {
+	var i repository.ProductVariant
+	err := q.atomic(ctx, func(q *Queries) error {
+		row := q.db.QueryRowContext(ctx, createVariant,
+			arg.ID,
+			arg.Sku,
+			nullJSON(arg.Options),
+			arg.OptionKey,
+			arg.PriceMinor,
+			arg.Currency,
+			arg.StockQuantity,
+			arg.ProductID,
+			arg.MaxVariants,
+			micros(now()),
+		)
+		if err := scanVariant(row, &i); err != nil {
+			return err
+		}
+		if i.StockQuantity <= 0 {
+			return nil
+		}
+		_, err := q.insertStockMovement(ctx, repository.StockMovement{
+			ProductID: i.ProductID,
+			VariantID: pgtype.Int8{Int64: i.ID, Valid: true},
+			Delta:     i.StockQuantity,
+			Reason:    "receipt",
+			Note:      text("initial stock"),
+			CreatedAt: i.CreatedAt,
+		})
+		return err
+	})
+	if err != nil {
+		return repository.CreateVariantRow{}, pgError(err)
+	}
+	return repository.CreateVariantRow(i), nil
+}
+
+const getVariant = `-- name: GetVariant :one
+SELECT ` + variantColumns + ` FROM product_variants
+WHERE id = ?1
+`
+
+func (q *Queries) GetVariant(ctx context.Context, id int64) (repository.ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, getVariant, id)
+	var i repository.ProductVariant
+	err := scanVariant(row, &i)
+	return i, pgError(err)
+}
+
+const getVariantBySKU = `-- name: GetVariantBySKU :one
+SELECT ` + variantColumns + ` FROM product_variants
+WHERE sku = ?1
+`
+
+func (q *Queries) GetVariantBySKU(ctx context.Context, sku string) (repository.ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, getVariantBySKU, sku)
+	var i repository.ProductVariant
+	err := scanVariant(row, &i)
+	return i, pgError(err)
+}
+
+const listVariants = `-- name: ListVariants :many
+SELECT ` + variantColumns + ` FROM product_variants
+WHERE product_id = ?1
+ORDER BY id
+`
+
+func (q *Queries) ListVariants(ctx context.Context, productID int64) ([]repository.ProductVariant, error) {
+	return q.queryVariants(ctx, listVariants, productID)
+}
+
+const countVariants = `-- name: CountVariants :one
+SELECT count(*) FROM product_variants
+WHERE product_id = ?1
+`
+
+func (q *Queries) CountVariants(ctx context.Context, productID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countVariants, productID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const updateVariant = `-- name: UpdateVariant :one
+UPDATE product_variants
+SET
+  sku         = CASE WHEN ?1 THEN ?2 ELSE sku END,
+  options     = CASE WHEN ?3 THEN ?4 ELSE options END,
+  option_key  = CASE WHEN ?3 THEN ?5 ELSE option_key END,
+  price_minor = CASE WHEN ?6 THEN ?7 ELSE price_minor END,
+  currency    = CASE WHEN ?6 THEN ?8 ELSE currency END,
+  updated_at  = ?10
+WHERE id = ?9
+RETURNING ` + variantColumns
+
+// UpdateVariant clears the price override when set_price is given a NULL
+// price_minor.
+func (q *Queries) UpdateVariant(ctx context.Context, arg repository.UpdateVariantParams) (repository.ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, updateVariant,
+		arg.SetSku,
+		arg.Sku,
+		arg.SetOptions,
+		nullJSON(arg.Options),
+		arg.OptionKey,
+		arg.SetPrice,
+		arg.PriceMinor,
+		arg.Currency,
+		arg.ID,
+		micros(now()),
+	)
+	var i repository.ProductVariant
+	err := scanVariant(row, &i)
+	return i, pgError(err)
+}
+
+const deleteVariant = `-- name: DeleteVariant :one
+DELETE FROM product_variants
+WHERE product_variants.id = ?1
+  AND stock_quantity = 0
+  AND NOT EXISTS (
+    SELECT 1 FROM stock_reservations r
+    WHERE r.variant_id = ?1 AND r.status = 'pending'
+  )
+RETURNING ` + variantColumns
+
+// DeleteVariant only deletes variants without stock or pending holds.
+func (q *Queries) DeleteVariant(ctx context.Context, id int64) (repository.ProductVariant, error) {
+	row := q.db.QueryRowContext(ctx, deleteVariant, id)
+	var i repository.ProductVariant
+	err := scanVariant(row, &i)
+	return i, pgError(err)
+}
+
+const adjustVariantStock = `-- name: AdjustVariantStock :one
+UPDATE product_variants
+SET stock_quantity = stock_quantity + ?1,
+    updated_at     = ?2
+WHERE product_variants.id = ?3
+  AND product_variants.product_id = ?4
+  AND EXISTS (SELECT 1 FROM products p WHERE p.id = ?4 AND p.deleted_at IS NULL)
+  AND stock_quantity + ?1 >= 0
+RETURNING stock_quantity
+`
+
+// AdjustVariantStock is AdjustStock for a single variant of a product.
+func (q *Queries) AdjustVariantStock(ctx context.Context, arg repository.AdjustVariantStockParams) (repository.AdjustVariantStockRow, error) {
+	var i repository.AdjustVariantStockRow
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := now()
+		if err := q.db.QueryRowContext(ctx, adjustVariantStock, arg.Delta, micros(at), arg.VariantID, arg.ProductID).Scan(&i.StockQuantity); err != nil {
+			return err
+		}
+		m, err := q.insertStockMovement(ctx, repository.StockMovement{
+			ID:        arg.ID,
+			ProductID: arg.ProductID,
+			VariantID: pgtype.Int8{Int64: arg.VariantID, Valid: true},
+			Delta:     arg.Delta,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			Note:      arg.Note,
+			CreatedAt: at,
+		})
+		i.ID, i.ProductID, i.Delta, i.Reason, i.Reference, i.Note, i.CreatedAt, i.VariantID = m.ID, m.ProductID, m.Delta, m.Reason, m.Reference, m.Note, m.CreatedAt, m.VariantID
+		return err
+	})
+	if err != nil {
+		return repository.AdjustVariantStockRow{}, pgError(err)
+	}
+	return i, nil
+}
+
+const reserveVariantStock = `-- name: ReserveVariantStock :one
+UPDATE product_variants
+SET stock_quantity = stock_quantity - ?1,
+    updated_at     = ?2
+WHERE product_variants.id = ?3
+  AND product_variants.product_id = ?4
+  AND EXISTS (SELECT 1 FROM products p WHERE p.id = ?4 AND p.deleted_at IS NULL)
+  AND stock_quantity >= ?1
+RETURNING product_variants.id, product_variants.product_id
+`
+
+// ReserveVariantStock is ReserveStock for a single variant of a product.
+func (q *Queries) ReserveVariantStock(ctx context.Context, arg repository.ReserveVariantStockParams) (repository.StockReservation, error) {
+	var i repository.StockReservation
+	err := q.atomic(ctx, func(q *Queries) error {
+		at := micros(now())
+		var variantID, productID int64
+		if err := q.db.QueryRowContext(ctx, reserveVariantStock, arg.Quantity, at, arg.VariantID, arg.ProductID).Scan(&variantID, &productID); err != nil {
+			return err
+		}
+		row := q.db.QueryRowContext(ctx, insertStockReservation,
+			arg.ID, productID, variantID, arg.Quantity, at+intervalMicros(arg.Ttl), at,
+		)
+		return scanStockReservation(row, &i)
+	})
+	if err != nil {
+		return repository.StockReservation{}, pgError(err)
+	}
+	return i, nil
+}
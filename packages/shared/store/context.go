@@ -0,0 +1,19 @@
+package store
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries tx, a store bound to a
+// transaction that the rest of the request should join.
+func NewContext(ctx context.Context, tx ProductStore) context.Context {
+	return context.WithValue(ctx, contextKey{}, tx)
+}
+
+// FromContext returns the store ctx carries, or s when it carries none.
+func FromContext(ctx context.Context, s ProductStore) ProductStore {
+	if tx, ok := ctx.Value(contextKey{}).(ProductStore); ok {
+		return tx
+	}
+	return s
+}
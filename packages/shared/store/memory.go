@@ -0,0 +1,359 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/money"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// Memory is a ProductStore that keeps its rows in process. It mirrors the
+// SQL queries row for row: the same filters, orderings, ledger entries and
+// revisions and outbox events, pgx.ErrNoRows for misses and
+// *pgconn.PgError for violated constraints.
+//
+// Memory is safe for concurrent use. Statements lock the whole store and
+// transactions hold the lock until they finish, so they run serially.
+type Memory struct {
+	// mu guards state. It is nil in the store handed to an InTx callback,
+	// whose transaction holds the lock already.
+	mu    *sync.RWMutex
+	state *memoryState
+	// now is the statement time. Inside a transaction it is frozen at the
+	// start of the transaction, like now() in SQL.
+	now func() time.Time
+}
+
+var _ ProductStore = (*Memory)(nil)
+
+func NewMemory() *Memory {
+	return &Memory{
+		mu:    &sync.RWMutex{},
+		state: newMemoryState(),
+		now: func() time.Time {
+			// The database keeps microseconds.
+			return time.Now().Truncate(time.Microsecond)
+		},
+	}
+}
+
+// InTx runs fn against a copy of the store and keeps the copy when fn
+// succeeds. fn must only use the store it is passed: m stays locked until
+// the transaction finishes.
+func (m *Memory) InTx(ctx context.Context, fn func(ProductStore) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if m.mu != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+
+	start := m.now()
+	tx := &Memory{state: m.state.clone(), now: func() time.Time { return start }}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	m.state = tx.state
+	return nil
+}
+
+// read runs a query against the store.
+func (m *Memory) read(ctx context.Context, fn func(s *memoryState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.mu != nil {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+	}
+	return fn(m.state)
+}
+
+// write runs a statement against the store. Statements check every
+// constraint before they change anything, so a failed statement leaves
+// the store as it was.
+func (m *Memory) write(ctx context.Context, fn func(s *memoryState, now time.Time) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.mu != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+	return fn(m.state, m.now())
+}
+
+// memoryState holds the rows of every table, keyed by primary key.
+type memoryState struct {
+	products          map[int64]repository.Product
+	revisions         map[int64]repository.ProductRevision
+	categories        map[int64]repository.Category
+	productCategories map[repository.ProductCategory]struct{}
+	productTags       map[repository.ProductTag]struct{}
+	media             map[int64]repository.ProductMedia
+	variants          map[int64]repository.ProductVariant
+	movements         map[int64]repository.StockMovement
+	reservations      map[int64]repository.StockReservation
+	rates             map[rateKey]repository.ExchangeRate
+	purgedBlobs       map[string]repository.PurgedMediaBlob
+	idempotencyKeys   map[idempotencyKey]repository.IdempotencyKey
+	outbox            map[int64]repository.Outbox
+	outboxLeases      map[string]repository.OutboxLease
+	// lastRowID backs the ids the database generates with unique_rowid().
+	lastRowID int64
+}
+
+// firstRowID keeps generated ids far above the ids callers choose, as
+// unique_rowid() does.
+const firstRowID = 1 << 62
+
+type idempotencyKey struct {
+	method, key string
+}
+
+type rateKey struct {
+	base, quote string
+	// effectiveAt is in microseconds since the epoch.
+	effectiveAt int64
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{
+		products:          map[int64]repository.Product{},
+		revisions:         map[int64]repository.ProductRevision{},
+		categories:        map[int64]repository.Category{},
+		productCategories: map[repository.ProductCategory]struct{}{},
+		productTags:       map[repository.ProductTag]struct{}{},
+		media:             map[int64]repository.ProductMedia{},
+		variants:          map[int64]repository.ProductVariant{},
+		movements:         map[int64]repository.StockMovement{},
+		reservations:      map[int64]repository.StockReservation{},
+		rates:             map[rateKey]repository.ExchangeRate{},
+		purgedBlobs:       map[string]repository.PurgedMediaBlob{},
+		idempotencyKeys:   map[idempotencyKey]repository.IdempotencyKey{},
+		outbox:            map[int64]repository.Outbox{},
+		outboxLeases:      map[string]repository.OutboxLease{},
+		lastRowID:         firstRowID,
+	}
+}
+
+// clone copies the tables. Rows are values whose byte slices are never
+// modified in place, so a shallow copy of each map suffices.
+func (s *memoryState) clone() *memoryState {
+	return &memoryState{
+		products:          maps.Clone(s.products),
+		revisions:         maps.Clone(s.revisions),
+		categories:        maps.Clone(s.categories),
+		productCategories: maps.Clone(s.productCategories),
+		productTags:       maps.Clone(s.productTags),
+		media:             maps.Clone(s.media),
+		variants:          maps.Clone(s.variants),
+		movements:         maps.Clone(s.movements),
+		reservations:      maps.Clone(s.reservations),
+		rates:             maps.Clone(s.rates),
+		purgedBlobs:       maps.Clone(s.purgedBlobs),
+		idempotencyKeys:   maps.Clone(s.idempotencyKeys),
+		outbox:            maps.Clone(s.outbox),
+		outboxLeases:      maps.Clone(s.outboxLeases),
+		lastRowID:         s.lastRowID,
+	}
+}
+
+func (s *memoryState) nextRowID() int64 {
+	s.lastRowID++
+	return s.lastRowID
+}
+
+// revisionSnapshot is the JSON document a revision stores: the products
+// row and the product's categories and tags.
+type revisionSnapshot struct {
+	repository.Product
+	CategoryIDs []int64  `json:"category_ids"`
+	Tags        []string `json:"tags"`
+}
+
+// snapshotOf returns p with the categories and tags it has now.
+func (s *memoryState) snapshotOf(p repository.Product) revisionSnapshot {
+	snapshot := revisionSnapshot{Product: p, CategoryIDs: []int64{}, Tags: []string{}}
+	for link := range s.productCategories {
+		if link.ProductID == p.ID {
+			snapshot.CategoryIDs = append(snapshot.CategoryIDs, link.CategoryID)
+		}
+	}
+	for tag := range s.productTags {
+		if tag.ProductID == p.ID {
+			snapshot.Tags = append(snapshot.Tags, tag.Tag)
+		}
+	}
+	slices.Sort(snapshot.CategoryIDs)
+	slices.Sort(snapshot.Tags)
+	return snapshot
+}
+
+// recordChange appends the revision of a change that leaves the categories
+// and tags of a product as they were.
+func (s *memoryState) recordChange(now time.Time, action, actor string, previous, current repository.Product) error {
+	before := s.snapshotOf(previous)
+	after := before
+	after.Product = current
+	return s.recordRevision(now, action, actor, &before, after)
+}
+
+// recordRevision appends a revision of a product and its outbox event.
+// previous is nil for the first revision.
+func (s *memoryState) recordRevision(now time.Time, action, actor string, previous *revisionSnapshot, current revisionSnapshot) error {
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	revision := repository.ProductRevision{
+		ProductID: current.ID,
+		Version:   current.Version,
+		Action:    action,
+		Actor:     actor,
+		Snapshot:  snapshot,
+		CreatedAt: now,
+	}
+	if previous != nil {
+		if revision.Previous, err = json.Marshal(previous); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.revisions {
+		if r.ProductID == revision.ProductID && r.Version == revision.Version {
+			return uniqueViolation("product_revisions", "product_revisions_version_key")
+		}
+	}
+	revision.ID = s.nextRowID()
+	s.revisions[revision.ID] = revision
+	return s.recordEvent(revision)
+}
+
+// recordMovement appends a ledger entry, generating its id unless set.
+func (s *memoryState) recordMovement(m repository.StockMovement) (repository.StockMovement, error) {
+	if m.ID == 0 {
+		m.ID = s.nextRowID()
+	}
+	if _, ok := s.movements[m.ID]; ok {
+		return repository.StockMovement{}, uniqueViolation("stock_movements", "stock_movements_pkey")
+	}
+	if err := checkMovement(m.Delta, m.Reason); err != nil {
+		return repository.StockMovement{}, err
+	}
+	s.movements[m.ID] = m
+	return m, nil
+}
+
+// stockReasons are the reasons stock_movements accepts.
+var stockReasons = map[string]bool{
+	"receipt":    true,
+	"sale":       true,
+	"return":     true,
+	"shrinkage":  true,
+	"correction": true,
+}
+
+func checkMovement(delta int32, reason string) error {
+	if delta == 0 {
+		return checkViolation("stock_movements", "stock_movements_delta_check")
+	}
+	if !stockReasons[reason] {
+		return checkViolation("stock_movements", "stock_movements_reason_check")
+	}
+	return nil
+}
+
+// checkCurrency enforces a foreign key on the currencies table.
+func checkCurrency(code, table, constraint string) error {
+	if _, err := money.LookupCurrency(code); err != nil {
+		return foreignKeyViolation(table, constraint)
+	}
+	return nil
+}
+
+func uniqueViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func foreignKeyViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func checkViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23514",
+		Message:        fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// intervalDuration converts an interval parameter, counting a month as 30
+// days.
+func intervalDuration(i pgtype.Interval) time.Duration {
+	return time.Duration(i.Microseconds)*time.Microsecond +
+		time.Duration(i.Days)*24*time.Hour +
+		time.Duration(i.Months)*30*24*time.Hour
+}
+
+// limited applies a LIMIT to sorted rows.
+func limited[T any](rows []T, limit int32) []T {
+	if limit < 0 {
+		limit = 0
+	}
+	if len(rows) > int(limit) {
+		return rows[:limit]
+	}
+	return rows
+}
+
+// orEmpty returns s, or an empty slice when s is nil, so that a snapshot
+// stores [] like the SQL queries rather than null.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// sortedKeys returns the keys of a table in ascending order.
+func sortedKeys[V any](table map[int64]V) []int64 {
+	return slices.Sorted(maps.Keys(table))
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func text(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: true}
+}
@@ -0,0 +1,297 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// checkCategory enforces the constraints of the categories table on c,
+// which is stored under c.ID.
+func (s *memoryState) checkCategory(c repository.Category) error {
+	if c.Name == "" {
+		return checkViolation("categories", "categories_name_check")
+	}
+	if c.ParentID.Valid {
+		if _, ok := s.categories[c.ParentID.Int64]; !ok {
+			return foreignKeyViolation("categories", "categories_parent_id_fkey")
+		}
+	}
+	for _, other := range s.categories {
+		if other.ID != c.ID && other.Path == c.Path {
+			return uniqueViolation("categories", "categories_path_key")
+		}
+	}
+	return nil
+}
+
+// CreateCategory returns pgx.ErrNoRows when the parent does not exist.
+func (m *Memory) CreateCategory(ctx context.Context, arg repository.CreateCategoryParams) (repository.Category, error) {
+	var created repository.Category
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		path := "/"
+		if arg.ParentID.Valid {
+			parent, ok := s.categories[arg.ParentID.Int64]
+			if !ok {
+				return pgx.ErrNoRows
+			}
+			path = parent.Path
+		}
+		if _, ok := s.categories[arg.ID]; ok {
+			return uniqueViolation("categories", "categories_pkey")
+		}
+
+		c := repository.Category{
+			ID:        arg.ID,
+			ParentID:  arg.ParentID,
+			Name:      arg.Name,
+			Path:      path + strconv.FormatInt(arg.ID, 10) + "/",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.checkCategory(c); err != nil {
+			return err
+		}
+		s.categories[c.ID] = c
+		created = c
+		return nil
+	})
+	return created, err
+}
+
+func (m *Memory) GetCategory(ctx context.Context, id int64) (repository.Category, error) {
+	var c repository.Category
+	err := m.read(ctx, func(s *memoryState) error {
+		var ok bool
+		if c, ok = s.categories[id]; !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return c, err
+}
+
+// GetCategoryForUpdate is GetCategory: a transaction locks the whole store
+// already.
+func (m *Memory) GetCategoryForUpdate(ctx context.Context, id int64) (repository.Category, error) {
+	return m.GetCategory(ctx, id)
+}
+
+// ListCategories returns the children of parent_id, or the roots when it
+// is NULL, in (name, id) order.
+func (m *Memory) ListCategories(ctx context.Context, arg repository.ListCategoriesParams) ([]repository.Category, error) {
+	var items []repository.Category
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, c := range s.categories {
+			if c.ParentID != arg.ParentID {
+				continue
+			}
+			if arg.AfterName.Valid && cmp.Or(strings.Compare(c.Name, arg.AfterName.String), cmp.Compare(c.ID, arg.AfterID)) <= 0 {
+				continue
+			}
+			items = append(items, c)
+		}
+		slices.SortFunc(items, func(a, b repository.Category) int {
+			return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
+
+func (m *Memory) UpdateCategory(ctx context.Context, arg repository.UpdateCategoryParams) (repository.Category, error) {
+	var updated repository.Category
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		c, ok := s.categories[arg.ID]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		c.Name, c.ParentID, c.Path, c.UpdatedAt = arg.Name, arg.ParentID, arg.Path, now
+		if err := s.checkCategory(c); err != nil {
+			return err
+		}
+		s.categories[c.ID] = c
+		updated = c
+		return nil
+	})
+	return updated, err
+}
+
+// MoveCategoryDescendants rewrites the paths below old_path.
+func (m *Memory) MoveCategoryDescendants(ctx context.Context, arg repository.MoveCategoryDescendantsParams) (int64, error) {
+	var moved int64
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		rewritten := map[int64]repository.Category{}
+		for id, c := range s.categories {
+			if strings.HasPrefix(c.Path, arg.OldPath) && c.Path != arg.OldPath {
+				c.Path = arg.NewPath + c.Path[len(arg.OldPath):]
+				c.UpdatedAt = now
+				rewritten[id] = c
+			}
+		}
+		for _, c := range rewritten {
+			for id, other := range s.categories {
+				if _, ok := rewritten[id]; !ok && other.Path == c.Path {
+					return uniqueViolation("categories", "categories_path_key")
+				}
+			}
+		}
+		for id, c := range rewritten {
+			s.categories[id] = c
+		}
+		moved = int64(len(rewritten))
+		return nil
+	})
+	return moved, err
+}
+
+// DeleteCategory only deletes leaf categories and returns pgx.ErrNoRows
+// for others. Product links to the category go with it.
+func (m *Memory) DeleteCategory(ctx context.Context, id int64) (repository.Category, error) {
+	var deleted repository.Category
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		c, ok := s.categories[id]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		for _, child := range s.categories {
+			if child.ParentID.Valid && child.ParentID.Int64 == id {
+				return pgx.ErrNoRows
+			}
+		}
+		delete(s.categories, id)
+		for link := range s.productCategories {
+			if link.CategoryID == id {
+				delete(s.productCategories, link)
+			}
+		}
+		deleted = c
+		return nil
+	})
+	return deleted, err
+}
+
+// AddProductCategories links the product to the categories that exist and
+// returns how many it linked.
+func (m *Memory) AddProductCategories(ctx context.Context, arg repository.AddProductCategoriesParams) (int64, error) {
+	var linked int64
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		wanted := idSet(arg.CategoryIds)
+		var links []repository.ProductCategory
+		for _, id := range sortedKeys(s.categories) {
+			if wanted[id] {
+				links = append(links, repository.ProductCategory{ProductID: arg.ProductID, CategoryID: id})
+			}
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		if _, ok := s.products[arg.ProductID]; !ok {
+			return foreignKeyViolation("product_categories", "product_categories_product_id_fkey")
+		}
+		for _, link := range links {
+			if _, ok := s.productCategories[link]; ok {
+				return uniqueViolation("product_categories", "product_categories_pkey")
+			}
+		}
+		for _, link := range links {
+			s.productCategories[link] = struct{}{}
+		}
+		linked = int64(len(links))
+		return nil
+	})
+	return linked, err
+}
+
+func (m *Memory) ClearProductCategories(ctx context.Context, productID int64) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		for link := range s.productCategories {
+			if link.ProductID == productID {
+				delete(s.productCategories, link)
+			}
+		}
+		return nil
+	})
+}
+
+// ListCategoryLinks returns links in (product_id, category_id) order.
+func (m *Memory) ListCategoryLinks(ctx context.Context, productIds []int64) ([]repository.ProductCategory, error) {
+	var items []repository.ProductCategory
+	err := m.read(ctx, func(s *memoryState) error {
+		wanted := idSet(productIds)
+		for link := range s.productCategories {
+			if wanted[link.ProductID] {
+				items = append(items, link)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ProductCategory) int {
+			return cmp.Or(cmp.Compare(a.ProductID, b.ProductID), cmp.Compare(a.CategoryID, b.CategoryID))
+		})
+		return nil
+	})
+	return items, err
+}
+
+// AddProductTags tags a product. Tags must be lower-cased and, like the
+// rows they become, unique.
+func (m *Memory) AddProductTags(ctx context.Context, arg repository.AddProductTagsParams) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		if len(arg.Tags) == 0 {
+			return nil
+		}
+		if _, ok := s.products[arg.ProductID]; !ok {
+			return foreignKeyViolation("product_tags", "product_tags_product_id_fkey")
+		}
+		added := map[repository.ProductTag]bool{}
+		for _, tag := range arg.Tags {
+			if tag == "" || tag != strings.ToLower(tag) {
+				return checkViolation("product_tags", "product_tags_tag_check")
+			}
+			t := repository.ProductTag{ProductID: arg.ProductID, Tag: tag}
+			if _, ok := s.productTags[t]; ok || added[t] {
+				return uniqueViolation("product_tags", "product_tags_pkey")
+			}
+			added[t] = true
+		}
+		for t := range added {
+			s.productTags[t] = struct{}{}
+		}
+		return nil
+	})
+}
+
+func (m *Memory) ClearProductTags(ctx context.Context, productID int64) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		for t := range s.productTags {
+			if t.ProductID == productID {
+				delete(s.productTags, t)
+			}
+		}
+		return nil
+	})
+}
+
+// ListProductTags returns tags in (product_id, tag) order.
+func (m *Memory) ListProductTags(ctx context.Context, productIds []int64) ([]repository.ProductTag, error) {
+	var items []repository.ProductTag
+	err := m.read(ctx, func(s *memoryState) error {
+		wanted := idSet(productIds)
+		for t := range s.productTags {
+			if wanted[t.ProductID] {
+				items = append(items, t)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ProductTag) int {
+			return cmp.Or(cmp.Compare(a.ProductID, b.ProductID), strings.Compare(a.Tag, b.Tag))
+		})
+		return nil
+	})
+	return items, err
+}
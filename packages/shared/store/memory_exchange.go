@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// GetExchangeRate returns the rate in force now for converting
+// base_currency into quote_currency.
+func (m *Memory) GetExchangeRate(ctx context.Context, arg repository.GetExchangeRateParams) (repository.ExchangeRate, error) {
+	var found repository.ExchangeRate
+	err := m.read(ctx, func(s *memoryState) error {
+		now := m.now()
+		ok := false
+		for key, rate := range s.rates {
+			if key.base != arg.BaseCurrency || key.quote != arg.QuoteCurrency || rate.EffectiveAt.After(now) {
+				continue
+			}
+			if !ok || rate.EffectiveAt.After(found.EffectiveAt) {
+				found, ok = rate, true
+			}
+		}
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return found, err
+}
+
+// UpsertExchangeRate sets the rate of a currency pair from effective_at
+// on, replacing a rate set for the same instant.
+func (m *Memory) UpsertExchangeRate(ctx context.Context, arg repository.UpsertExchangeRateParams) (repository.ExchangeRate, error) {
+	var upserted repository.ExchangeRate
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		if err := checkCurrency(arg.BaseCurrency, "exchange_rates", "exchange_rates_base_currency_fkey"); err != nil {
+			return err
+		}
+		if err := checkCurrency(arg.QuoteCurrency, "exchange_rates", "exchange_rates_quote_currency_fkey"); err != nil {
+			return err
+		}
+		if arg.BaseCurrency == arg.QuoteCurrency {
+			return checkViolation("exchange_rates", "exchange_rates_check")
+		}
+		if !arg.Rate.Valid || arg.Rate.NaN || arg.Rate.Int == nil || arg.Rate.Int.Sign() <= 0 {
+			return checkViolation("exchange_rates", "exchange_rates_rate_check")
+		}
+
+		effectiveAt := arg.EffectiveAt.Truncate(time.Microsecond)
+		key := rateKey{base: arg.BaseCurrency, quote: arg.QuoteCurrency, effectiveAt: effectiveAt.UnixMicro()}
+		upserted = repository.ExchangeRate{
+			BaseCurrency:  arg.BaseCurrency,
+			QuoteCurrency: arg.QuoteCurrency,
+			Rate:          arg.Rate,
+			EffectiveAt:   effectiveAt,
+			UpdatedAt:     now,
+		}
+		s.rates[key] = upserted
+		return nil
+	})
+	return upserted, err
+}
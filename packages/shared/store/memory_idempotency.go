@@ -0,0 +1,79 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// ClaimIdempotencyKey records a new in-flight request. An expired key is
+// reclaimed; a live one is left alone and pgx.ErrNoRows is returned.
+func (m *Memory) ClaimIdempotencyKey(ctx context.Context, arg repository.ClaimIdempotencyKeyParams) (string, error) {
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		key := idempotencyKey{arg.Method, arg.IdempotencyKey}
+		if claimed, ok := s.idempotencyKeys[key]; ok && claimed.ExpiresAt.After(now) {
+			return pgx.ErrNoRows
+		}
+		s.idempotencyKeys[key] = repository.IdempotencyKey{
+			Method:         arg.Method,
+			IdempotencyKey: arg.IdempotencyKey,
+			RequestHash:    arg.RequestHash,
+			CreatedAt:      now,
+			ExpiresAt:      now.Add(intervalDuration(arg.Ttl)),
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return arg.IdempotencyKey, nil
+}
+
+func (m *Memory) GetIdempotencyKey(ctx context.Context, arg repository.GetIdempotencyKeyParams) (repository.IdempotencyKey, error) {
+	var claimed repository.IdempotencyKey
+	err := m.read(ctx, func(s *memoryState) error {
+		var ok bool
+		if claimed, ok = s.idempotencyKeys[idempotencyKey{arg.Method, arg.IdempotencyKey}]; !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (m *Memory) CompleteIdempotencyKey(ctx context.Context, arg repository.CompleteIdempotencyKeyParams) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		key := idempotencyKey{arg.Method, arg.IdempotencyKey}
+		if claimed, ok := s.idempotencyKeys[key]; ok {
+			claimed.Response = arg.Response
+			s.idempotencyKeys[key] = claimed
+		}
+		return nil
+	})
+}
+
+// DeleteExpiredIdempotencyKeys removes up to batch_size expired keys.
+func (m *Memory) DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int32) (int64, error) {
+	var deleted int64
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		var expired []idempotencyKey
+		for key, claimed := range s.idempotencyKeys {
+			if !claimed.ExpiresAt.After(now) {
+				expired = append(expired, key)
+			}
+		}
+		slices.SortFunc(expired, func(a, b idempotencyKey) int {
+			return cmp.Or(cmp.Compare(a.method, b.method), cmp.Compare(a.key, b.key))
+		})
+		for _, key := range limited(expired, batchSize) {
+			delete(s.idempotencyKeys, key)
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
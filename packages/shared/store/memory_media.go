@@ -0,0 +1,174 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// AddProductMedia appends media after the product's existing media. It
+// returns pgx.ErrNoRows when the product is missing or deleted or already
+// has max_media items.
+func (m *Memory) AddProductMedia(ctx context.Context, arg repository.AddProductMediaParams) (repository.ProductMedia, error) {
+	var added repository.ProductMedia
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		if _, err := s.getProduct(arg.ProductID, false); err != nil {
+			return err
+		}
+		var count int64
+		position := int32(0)
+		for _, media := range s.media {
+			if media.ProductID != arg.ProductID {
+				continue
+			}
+			count++
+			position = max(position, media.Position+1)
+		}
+		if count >= arg.MaxMedia {
+			return pgx.ErrNoRows
+		}
+
+		if _, ok := s.media[arg.ID]; ok {
+			return uniqueViolation("product_media", "product_media_pkey")
+		}
+		for _, media := range s.media {
+			if media.ProductID == arg.ProductID && media.ChecksumSha256 == arg.ChecksumSha256 {
+				return uniqueViolation("product_media", "product_media_checksum_key")
+			}
+		}
+
+		added = repository.ProductMedia{
+			ID:             arg.ID,
+			ProductID:      arg.ProductID,
+			Position:       position,
+			StorageKey:     arg.StorageKey,
+			ContentType:    arg.ContentType,
+			ChecksumSha256: arg.ChecksumSha256,
+			SizeBytes:      arg.SizeBytes,
+			Width:          arg.Width,
+			Height:         arg.Height,
+			AltText:        arg.AltText,
+			CreatedAt:      now,
+		}
+		s.media[added.ID] = added
+		return nil
+	})
+	return added, err
+}
+
+func (m *Memory) CountProductMedia(ctx context.Context, productID int64) (int64, error) {
+	var count int64
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, media := range s.media {
+			if media.ProductID == productID {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// ListProductMedia returns media in (product_id, position, id) order.
+func (m *Memory) ListProductMedia(ctx context.Context, productIds []int64) ([]repository.ProductMedia, error) {
+	var items []repository.ProductMedia
+	err := m.read(ctx, func(s *memoryState) error {
+		wanted := idSet(productIds)
+		for _, media := range s.media {
+			if wanted[media.ProductID] {
+				items = append(items, media)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ProductMedia) int {
+			return cmp.Or(cmp.Compare(a.ProductID, b.ProductID), cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+		})
+		return nil
+	})
+	return items, err
+}
+
+func (m *Memory) UpdateProductMediaAltText(ctx context.Context, arg repository.UpdateProductMediaAltTextParams) (repository.ProductMedia, error) {
+	var updated repository.ProductMedia
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		media, ok := s.media[arg.ID]
+		if !ok || media.ProductID != arg.ProductID {
+			return pgx.ErrNoRows
+		}
+		media.AltText = arg.AltText
+		s.media[media.ID] = media
+		updated = media
+		return nil
+	})
+	return updated, err
+}
+
+// ReorderProductMedia sets each media's position to the index of its
+// first occurrence in media_ids and returns how many media it moved.
+func (m *Memory) ReorderProductMedia(ctx context.Context, arg repository.ReorderProductMediaParams) (int64, error) {
+	var moved int64
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		for id, media := range s.media {
+			if media.ProductID != arg.ProductID {
+				continue
+			}
+			if i := slices.Index(arg.MediaIds, id); i >= 0 {
+				media.Position = int32(i)
+				s.media[id] = media
+				moved++
+			}
+		}
+		return nil
+	})
+	return moved, err
+}
+
+func (m *Memory) DeleteProductMedia(ctx context.Context, arg repository.DeleteProductMediaParams) (repository.ProductMedia, error) {
+	var deleted repository.ProductMedia
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		media, ok := s.media[arg.ID]
+		if !ok || media.ProductID != arg.ProductID {
+			return pgx.ErrNoRows
+		}
+		delete(s.media, media.ID)
+		deleted = media
+		return nil
+	})
+	return deleted, err
+}
+
+// ListPurgedMediaBlobs returns the storage keys queued by purges, oldest
+// first.
+func (m *Memory) ListPurgedMediaBlobs(ctx context.Context, pageLimit int32) ([]string, error) {
+	var keys []string
+	err := m.read(ctx, func(s *memoryState) error {
+		blobs := slices.Collect(maps.Values(s.purgedBlobs))
+		slices.SortFunc(blobs, func(a, b repository.PurgedMediaBlob) int {
+			return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.StorageKey, b.StorageKey))
+		})
+		for _, blob := range limited(blobs, pageLimit) {
+			keys = append(keys, blob.StorageKey)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+func (m *Memory) DeletePurgedMediaBlobs(ctx context.Context, storageKeys []string) (int64, error) {
+	var deleted int64
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		for _, key := range storageKeys {
+			if _, ok := s.purgedBlobs[key]; ok {
+				delete(s.purgedBlobs, key)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// productEvents are the outbox event types of the revision actions.
+var productEvents = map[string]string{
+	"create":   "product.created",
+	"update":   "product.updated",
+	"delete":   "product.deleted",
+	"undelete": "product.undeleted",
+	"stock":    "product.stock_changed",
+	"purge":    "product.purged",
+}
+
+// relayLease is the name of the lease AcquireOutboxLease takes.
+const relayLease = "relay"
+
+// recordEvent appends the outbox event of a revision. Actions without an
+// event type record none.
+func (s *memoryState) recordEvent(revision repository.ProductRevision) error {
+	eventType, ok := productEvents[revision.Action]
+	if !ok {
+		return nil
+	}
+	payload, err := json.Marshal(struct {
+		Actor   string          `json:"actor"`
+		Product json.RawMessage `json:"product"`
+	}{revision.Actor, revision.Snapshot})
+	if err != nil {
+		return err
+	}
+	for _, e := range s.outbox {
+		if e.AggregateType == "product" && e.AggregateID == revision.ProductID && e.AggregateVersion == revision.Version {
+			return uniqueViolation("outbox", "outbox_aggregate_version_key")
+		}
+	}
+	event := repository.Outbox{
+		ID:               s.nextRowID(),
+		AggregateType:    "product",
+		AggregateID:      revision.ProductID,
+		AggregateVersion: revision.Version,
+		EventType:        eventType,
+		Payload:          payload,
+		CreatedAt:        revision.CreatedAt,
+		NextAttemptAt:    revision.CreatedAt,
+	}
+	s.outbox[event.ID] = event
+	return nil
+}
+
+// ListPendingOutboxEvents returns the pending events of the aggregates
+// whose oldest pending event is due, in publish order: aggregates by the
+// age of that event, then each aggregate's events by version.
+func (m *Memory) ListPendingOutboxEvents(ctx context.Context, arg repository.ListPendingOutboxEventsParams) ([]repository.Outbox, error) {
+	var items []repository.Outbox
+	err := m.read(ctx, func(s *memoryState) error {
+		type aggregate struct {
+			typ string
+			id  int64
+		}
+		heads := map[aggregate]repository.Outbox{}
+		for _, e := range s.outbox {
+			if e.PublishedAt.Valid {
+				continue
+			}
+			key := aggregate{e.AggregateType, e.AggregateID}
+			if head, ok := heads[key]; !ok || e.AggregateVersion < head.AggregateVersion {
+				heads[key] = e
+			}
+		}
+		for _, e := range s.outbox {
+			head := heads[aggregate{e.AggregateType, e.AggregateID}]
+			if !e.PublishedAt.Valid && !head.NextAttemptAt.After(arg.Now) {
+				items = append(items, e)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.Outbox) int {
+			headA := heads[aggregate{a.AggregateType, a.AggregateID}]
+			headB := heads[aggregate{b.AggregateType, b.AggregateID}]
+			return cmp.Or(
+				headA.CreatedAt.Compare(headB.CreatedAt),
+				cmp.Compare(a.AggregateType, b.AggregateType),
+				cmp.Compare(a.AggregateID, b.AggregateID),
+				cmp.Compare(a.AggregateVersion, b.AggregateVersion),
+			)
+		})
+		items = limited(items, arg.BatchSize)
+		return nil
+	})
+	return items, err
+}
+
+func (m *Memory) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	return m.write(ctx, func(s *memoryState, now time.Time) error {
+		if e, ok := s.outbox[id]; ok {
+			e.PublishedAt.Time, e.PublishedAt.Valid = now, true
+			s.outbox[id] = e
+		}
+		return nil
+	})
+}
+
+func (m *Memory) MarkOutboxEventFailed(ctx context.Context, arg repository.MarkOutboxEventFailedParams) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		if e, ok := s.outbox[arg.ID]; ok {
+			e.Attempts++
+			e.LastError = arg.LastError
+			e.NextAttemptAt = arg.NextAttemptAt
+			s.outbox[arg.ID] = e
+		}
+		return nil
+	})
+}
+
+// GetOutboxBacklog returns the number of pending events and the creation
+// time of the oldest; oldest is now when nothing is pending.
+func (m *Memory) GetOutboxBacklog(ctx context.Context, now time.Time) (repository.GetOutboxBacklogRow, error) {
+	backlog := repository.GetOutboxBacklogRow{Oldest: now}
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, e := range s.outbox {
+			if e.PublishedAt.Valid {
+				continue
+			}
+			if backlog.Pending == 0 || e.CreatedAt.Before(backlog.Oldest) {
+				backlog.Oldest = e.CreatedAt
+			}
+			backlog.Pending++
+		}
+		return nil
+	})
+	return backlog, err
+}
+
+// DeletePublishedOutboxEvents removes up to batch_size events published
+// before published_before, oldest first.
+func (m *Memory) DeletePublishedOutboxEvents(ctx context.Context, arg repository.DeletePublishedOutboxEventsParams) (int64, error) {
+	var deleted int64
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		var published []repository.Outbox
+		for _, e := range s.outbox {
+			if e.PublishedAt.Valid && e.PublishedAt.Time.Before(arg.PublishedBefore) {
+				published = append(published, e)
+			}
+		}
+		slices.SortFunc(published, func(a, b repository.Outbox) int {
+			return cmp.Or(a.PublishedAt.Time.Compare(b.PublishedAt.Time), cmp.Compare(a.ID, b.ID))
+		})
+		for _, e := range limited(published, arg.BatchSize) {
+			delete(s.outbox, e.ID)
+			deleted++
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// AcquireOutboxLease takes the relay lease for holder, or renews it, until
+// ttl from now. No row is affected while another holder's lease is
+// unexpired.
+func (m *Memory) AcquireOutboxLease(ctx context.Context, arg repository.AcquireOutboxLeaseParams) (int64, error) {
+	var acquired int64
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		lease, ok := s.outboxLeases[relayLease]
+		if ok && lease.Holder != arg.Holder && lease.ExpiresAt.After(now) {
+			return nil
+		}
+		s.outboxLeases[relayLease] = repository.OutboxLease{
+			Name:      relayLease,
+			Holder:    arg.Holder,
+			ExpiresAt: now.Add(intervalDuration(arg.Ttl)),
+		}
+		acquired = 1
+		return nil
+	})
+	return acquired, err
+}
+
+// ReleaseOutboxLease gives up the relay lease if holder has it.
+func (m *Memory) ReleaseOutboxLease(ctx context.Context, holder string) error {
+	return m.write(ctx, func(s *memoryState, _ time.Time) error {
+		if lease, ok := s.outboxLeases[relayLease]; ok && lease.Holder == holder {
+			delete(s.outboxLeases, relayLease)
+		}
+		return nil
+	})
+}
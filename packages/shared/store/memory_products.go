@@ -0,0 +1,518 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// checkProduct enforces the constraints of the products table.
+func checkProduct(p repository.Product) error {
+	if p.PriceMinor < 0 {
+		return checkViolation("products", "products_price_minor_check")
+	}
+	if p.StockQuantity < 0 {
+		return checkViolation("products", "products_stock_quantity_check")
+	}
+	return checkCurrency(p.Currency, "products", "products_currency_fkey")
+}
+
+// createProduct inserts a product with its opening ledger entry and first
+// revision.
+func (s *memoryState) createProduct(now time.Time, arg repository.CreateProductParams) (repository.Product, error) {
+	p := repository.Product{
+		ID:            arg.ID,
+		Name:          arg.Name,
+		Description:   arg.Description,
+		PriceMinor:    arg.PriceMinor,
+		Currency:      arg.Currency,
+		StockQuantity: arg.StockQuantity,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+	if _, ok := s.products[p.ID]; ok {
+		return repository.Product{}, uniqueViolation("products", "products_pkey")
+	}
+	if err := checkProduct(p); err != nil {
+		return repository.Product{}, err
+	}
+
+	s.products[p.ID] = p
+	if p.StockQuantity > 0 {
+		if _, err := s.recordMovement(repository.StockMovement{
+			ProductID: p.ID,
+			Delta:     p.StockQuantity,
+			Reason:    "receipt",
+			Note:      text("initial stock"),
+			CreatedAt: now,
+		}); err != nil {
+			return repository.Product{}, err
+		}
+	}
+	snapshot := revisionSnapshot{Product: p, CategoryIDs: orEmpty(arg.CategoryIds), Tags: orEmpty(arg.Tags)}
+	if err := s.recordRevision(now, "create", arg.Actor, nil, snapshot); err != nil {
+		return repository.Product{}, err
+	}
+	return p, nil
+}
+
+func (m *Memory) CreateProduct(ctx context.Context, arg repository.CreateProductParams) (repository.CreateProductRow, error) {
+	var p repository.Product
+	err := m.write(ctx, func(s *memoryState, now time.Time) (err error) {
+		p, err = s.createProduct(now, arg)
+		return err
+	})
+	return repository.CreateProductRow(p), err
+}
+
+// BatchCreateProducts creates the products one by one. Like a pipelined
+// batch, a failed item does not stop the following ones.
+func (m *Memory) BatchCreateProducts(ctx context.Context, arg []repository.BatchCreateProductsParams, f func(int, repository.BatchCreateProductsRow, error)) {
+	for i, a := range arg {
+		row, err := m.CreateProduct(ctx, repository.CreateProductParams(a))
+		if f != nil {
+			f(i, repository.BatchCreateProductsRow(row), err)
+		}
+	}
+}
+
+// getProduct looks up a product, skipping soft deleted ones unless
+// withDeleted is set.
+func (s *memoryState) getProduct(id int64, withDeleted bool) (repository.Product, error) {
+	p, ok := s.products[id]
+	if !ok || (p.DeletedAt.Valid && !withDeleted) {
+		return repository.Product{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+func (m *Memory) GetProductByID(ctx context.Context, id int64) (repository.Product, error) {
+	var p repository.Product
+	err := m.read(ctx, func(s *memoryState) (err error) {
+		p, err = s.getProduct(id, false)
+		return err
+	})
+	return p, err
+}
+
+func (m *Memory) GetProductByIDWithDeleted(ctx context.Context, id int64) (repository.Product, error) {
+	var p repository.Product
+	err := m.read(ctx, func(s *memoryState) (err error) {
+		p, err = s.getProduct(id, true)
+		return err
+	})
+	return p, err
+}
+
+// GetProductsByIDs returns the products in id order, each once.
+func (m *Memory) GetProductsByIDs(ctx context.Context, ids []int64) ([]repository.Product, error) {
+	var items []repository.Product
+	err := m.read(ctx, func(s *memoryState) error {
+		wanted := idSet(ids)
+		for _, id := range sortedKeys(s.products) {
+			if p := s.products[id]; wanted[id] && !p.DeletedAt.Valid {
+				items = append(items, p)
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+func (m *Memory) ListProducts(ctx context.Context, arg repository.ListProductsParams) ([]repository.Product, error) {
+	var items []repository.Product
+	err := m.read(ctx, func(s *memoryState) error {
+		var inCategory map[int64]bool
+		if arg.CategoryID != 0 {
+			inCategory = s.productsInSubtree(arg.CategoryID)
+		}
+
+		for _, p := range s.products {
+			switch {
+			case arg.Currency != "" && p.Currency != arg.Currency,
+				arg.MinPrice != nil && p.PriceMinor < *arg.MinPrice,
+				arg.MaxPrice != nil && p.PriceMinor > *arg.MaxPrice,
+				arg.NamePrefix != "" && !strings.HasPrefix(p.Name, arg.NamePrefix),
+				arg.InStockOnly && p.StockQuantity <= 0,
+				inCategory != nil && !inCategory[p.ID],
+				arg.Tag != "" && !s.hasTag(p.ID, arg.Tag),
+				!arg.ShowDeleted && p.DeletedAt.Valid:
+				continue
+			}
+			if arg.After != nil && compareProductKeys(p, arg.SortBy, arg.After.Value, arg.After.ID, arg.Descending) <= 0 {
+				continue
+			}
+			items = append(items, p)
+		}
+
+		slices.SortFunc(items, func(a, b repository.Product) int {
+			c := compareProducts(a, b, arg.SortBy)
+			if arg.Descending {
+				return -c
+			}
+			return c
+		})
+		items = limited(items, arg.Limit)
+		return nil
+	})
+	return items, err
+}
+
+// compareProducts orders products by the sort column, then id.
+func compareProducts(a, b repository.Product, by repository.ProductSortField) int {
+	var c int
+	switch by {
+	case repository.ProductSortByPrice:
+		c = cmp.Compare(a.PriceMinor, b.PriceMinor)
+	case repository.ProductSortByName:
+		c = strings.Compare(a.Name, b.Name)
+	case repository.ProductSortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	return cmp.Or(c, cmp.Compare(a.ID, b.ID))
+}
+
+// compareProductKeys compares the position of p with a keyset in the
+// direction of the sort, so that a positive result means p comes after it.
+// A keyset value of the wrong type compares like a NULL: nothing follows it.
+func compareProductKeys(p repository.Product, by repository.ProductSortField, value any, id int64, descending bool) int {
+	var c int
+	switch by {
+	case repository.ProductSortByPrice:
+		v, ok := value.(int64)
+		if !ok {
+			return 0
+		}
+		c = cmp.Compare(p.PriceMinor, v)
+	case repository.ProductSortByName:
+		v, ok := value.(string)
+		if !ok {
+			return 0
+		}
+		c = strings.Compare(p.Name, v)
+	case repository.ProductSortByCreatedAt:
+		v, ok := value.(time.Time)
+		if !ok {
+			return 0
+		}
+		c = p.CreatedAt.Compare(v)
+	}
+	c = cmp.Or(c, cmp.Compare(p.ID, id))
+	if descending {
+		return -c
+	}
+	return c
+}
+
+// productsInSubtree returns the products filed under a category or any of
+// its descendants.
+func (s *memoryState) productsInSubtree(categoryID int64) map[int64]bool {
+	products := map[int64]bool{}
+	root, ok := s.categories[categoryID]
+	if !ok {
+		return products
+	}
+	for link := range s.productCategories {
+		if c, ok := s.categories[link.CategoryID]; ok && strings.HasPrefix(c.Path, root.Path) {
+			products[link.ProductID] = true
+		}
+	}
+	return products
+}
+
+func (s *memoryState) hasTag(productID int64, tag string) bool {
+	_, ok := s.productTags[repository.ProductTag{ProductID: productID, Tag: tag}]
+	return ok
+}
+
+// ListProductChanges returns products changed after the (updated_at, id)
+// cursor and before the settle window, oldest first.
+func (m *Memory) ListProductChanges(ctx context.Context, arg repository.ListProductChangesParams) ([]repository.Product, error) {
+	var items []repository.Product
+	err := m.read(ctx, func(s *memoryState) error {
+		settled := m.now().Add(-intervalDuration(arg.Settle))
+		for _, p := range s.products {
+			after := cmp.Or(p.UpdatedAt.Compare(arg.AfterUpdatedAt), cmp.Compare(p.ID, arg.AfterID)) > 0
+			if after && !p.UpdatedAt.After(settled) {
+				items = append(items, p)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.Product) int {
+			return cmp.Or(a.UpdatedAt.Compare(b.UpdatedAt), cmp.Compare(a.ID, b.ID))
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
+
+// CurrentTime returns the statement time.
+func (m *Memory) CurrentTime(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	return m.now(), nil
+}
+
+func (m *Memory) UpdateProduct(ctx context.Context, arg repository.UpdateProductParams) (repository.UpdateProductRow, error) {
+	var updated repository.Product
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		previous, err := s.guardedProduct(arg.ID, arg.ExpectedVersion, false)
+		if err != nil {
+			return err
+		}
+
+		updated = previous
+		if arg.SetName {
+			updated.Name = arg.Name
+		}
+		if arg.SetDescription {
+			updated.Description = arg.Description
+		}
+		if arg.SetPrice {
+			updated.PriceMinor, updated.Currency = arg.PriceMinor, arg.Currency
+		}
+		if arg.SetStockQuantity {
+			updated.StockQuantity = arg.StockQuantity
+		}
+		updated.UpdatedAt = now
+		updated.Version++
+		if err := checkProduct(updated); err != nil {
+			return err
+		}
+
+		s.products[updated.ID] = updated
+		if delta := updated.StockQuantity - previous.StockQuantity; delta != 0 {
+			if _, err := s.recordMovement(repository.StockMovement{
+				ProductID: updated.ID,
+				Delta:     delta,
+				Reason:    "correction",
+				Note:      text("stock set by update"),
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		// The categories and tags given in arg are set by the caller after
+		// the update; the revision records them already.
+		before := s.snapshotOf(previous)
+		after := before
+		after.Product = updated
+		if arg.CategoryIds != nil {
+			after.CategoryIDs = arg.CategoryIds
+		}
+		if arg.Tags != nil {
+			after.Tags = arg.Tags
+		}
+		return s.recordRevision(now, "update", arg.Actor, &before, after)
+	})
+	return repository.UpdateProductRow(updated), err
+}
+
+func (m *Memory) DeleteProduct(ctx context.Context, arg repository.DeleteProductParams) (repository.DeleteProductRow, error) {
+	var deleted repository.Product
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		previous, err := s.guardedProduct(arg.ID, arg.ExpectedVersion, false)
+		if err != nil {
+			return err
+		}
+
+		deleted = previous
+		deleted.DeletedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		deleted.UpdatedAt = now
+		deleted.Version++
+		s.products[deleted.ID] = deleted
+		return s.recordChange(now, "delete", arg.Actor, previous, deleted)
+	})
+	return repository.DeleteProductRow(deleted), err
+}
+
+func (m *Memory) UndeleteProduct(ctx context.Context, arg repository.UndeleteProductParams) (repository.UndeleteProductRow, error) {
+	var restored repository.Product
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		previous, err := s.guardedProduct(arg.ID, arg.ExpectedVersion, true)
+		if err != nil {
+			return err
+		}
+
+		restored = previous
+		restored.DeletedAt = pgtype.Timestamptz{}
+		restored.UpdatedAt = now
+		restored.Version++
+		s.products[restored.ID] = restored
+		return s.recordChange(now, "undelete", arg.Actor, previous, restored)
+	})
+	return repository.UndeleteProductRow(restored), err
+}
+
+// PurgeDeletedProducts hard deletes up to batch_size products soft deleted
+// before deleted_before and records a purge revision for each. Their
+// reservations, variants, media, categories and tags go with them; their
+// ledger entries and revisions are kept. The blobs of their media are
+// queued for the gateway to delete.
+func (m *Memory) PurgeDeletedProducts(ctx context.Context, arg repository.PurgeDeletedProductsParams) (int64, error) {
+	var purged int64
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		var due []repository.Product
+		for _, p := range s.products {
+			if p.DeletedAt.Valid && p.DeletedAt.Time.Before(arg.DeletedBefore) {
+				due = append(due, p)
+			}
+		}
+		slices.SortFunc(due, func(a, b repository.Product) int {
+			return cmp.Or(a.DeletedAt.Time.Compare(b.DeletedAt.Time), cmp.Compare(a.ID, b.ID))
+		})
+
+		for _, p := range limited(due, arg.BatchSize) {
+			previous := s.snapshotOf(p)
+			s.purgeProduct(now, p.ID)
+			current := previous
+			current.UpdatedAt = now
+			current.Version++
+			if err := s.recordRevision(now, "purge", "system", &previous, current); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// purgeProduct deletes a product and the rows that cascade with it.
+func (s *memoryState) purgeProduct(now time.Time, id int64) {
+	for mediaID, media := range s.media {
+		if media.ProductID != id {
+			continue
+		}
+		if _, ok := s.purgedBlobs[media.StorageKey]; !ok {
+			s.purgedBlobs[media.StorageKey] = repository.PurgedMediaBlob{StorageKey: media.StorageKey, CreatedAt: now}
+		}
+		delete(s.media, mediaID)
+	}
+	for reservationID, r := range s.reservations {
+		if r.ProductID == id {
+			delete(s.reservations, reservationID)
+		}
+	}
+	for variantID, v := range s.variants {
+		if v.ProductID != id {
+			continue
+		}
+		delete(s.variants, variantID)
+		for movementID, mv := range s.movements {
+			if mv.VariantID.Valid && mv.VariantID.Int64 == variantID {
+				mv.VariantID = pgtype.Int8{}
+				s.movements[movementID] = mv
+			}
+		}
+	}
+	for link := range s.productCategories {
+		if link.ProductID == id {
+			delete(s.productCategories, link)
+		}
+	}
+	for tag := range s.productTags {
+		if tag.ProductID == id {
+			delete(s.productTags, tag)
+		}
+	}
+	delete(s.products, id)
+}
+
+// ListProductNames returns the names of the products that are not
+// deleted, in id order.
+func (m *Memory) ListProductNames(ctx context.Context) ([]repository.ListProductNamesRow, error) {
+	var items []repository.ListProductNamesRow
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, id := range sortedKeys(s.products) {
+			if p := s.products[id]; !p.DeletedAt.Valid {
+				items = append(items, repository.ListProductNamesRow{ID: p.ID, Name: p.Name})
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// guardedProduct finds the product a version-guarded mutation applies to:
+// one that is deleted or not, as wanted, and at the expected version when
+// one is given.
+func (s *memoryState) guardedProduct(id int64, expectedVersion pgtype.Int8, deleted bool) (repository.Product, error) {
+	p, ok := s.products[id]
+	if !ok || p.DeletedAt.Valid != deleted || (expectedVersion.Valid && p.Version != expectedVersion.Int64) {
+		return repository.Product{}, pgx.ErrNoRows
+	}
+	return p, nil
+}
+
+// GetProductRevisionAsOf returns the last revision recorded at or before
+// as_of.
+func (m *Memory) GetProductRevisionAsOf(ctx context.Context, arg repository.GetProductRevisionAsOfParams) (repository.ProductRevision, error) {
+	var found repository.ProductRevision
+	err := m.read(ctx, func(s *memoryState) error {
+		ok := false
+		for _, r := range s.revisions {
+			if r.ProductID != arg.ProductID || r.CreatedAt.After(arg.AsOf) {
+				continue
+			}
+			if !ok || cmp.Or(r.CreatedAt.Compare(found.CreatedAt), cmp.Compare(r.Version, found.Version)) > 0 {
+				found, ok = r, true
+			}
+		}
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return found, err
+}
+
+// ListProductPurges returns purge revisions after the (created_at,
+// product_id) cursor and before the settle window, oldest first.
+func (m *Memory) ListProductPurges(ctx context.Context, arg repository.ListProductPurgesParams) ([]repository.ProductRevision, error) {
+	var items []repository.ProductRevision
+	err := m.read(ctx, func(s *memoryState) error {
+		settled := m.now().Add(-intervalDuration(arg.Settle))
+		for _, r := range s.revisions {
+			after := cmp.Or(r.CreatedAt.Compare(arg.AfterCreatedAt), cmp.Compare(r.ProductID, arg.AfterProductID)) > 0
+			if r.Action == "purge" && after && !r.CreatedAt.After(settled) {
+				items = append(items, r)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ProductRevision) int {
+			return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ProductID, b.ProductID))
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
+
+// ListProductRevisions returns revisions newest first.
+func (m *Memory) ListProductRevisions(ctx context.Context, arg repository.ListProductRevisionsParams) ([]repository.ProductRevision, error) {
+	var items []repository.ProductRevision
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, r := range s.revisions {
+			if r.ProductID == arg.ProductID && (!arg.BeforeVersion.Valid || r.Version < arg.BeforeVersion.Int64) {
+				items = append(items, r)
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ProductRevision) int {
+			return cmp.Compare(b.Version, a.Version)
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
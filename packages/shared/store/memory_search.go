@@ -0,0 +1,149 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+const (
+	// similarityThreshold is pg_trgm's default threshold for the %
+	// operator.
+	similarityThreshold = 0.3
+	// textMatchRank stands in for ts_rank, which is about this for a
+	// document that contains each query term once.
+	textMatchRank = 0.0607927
+)
+
+// stopWords are the most common English stop words, which full-text search
+// leaves out of a query.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// SearchProducts ranks products like the SQL query: a full-text match of
+// every query term in the name or description, plus the trigram
+// similarity of the name. Full-text matching only folds case and plural
+// endings where the database stems words with its English dictionary, so
+// scores differ from the database's, but not their ordering rules.
+func (m *Memory) SearchProducts(ctx context.Context, arg repository.SearchProductsParams) ([]repository.SearchProductsRow, error) {
+	var items []repository.SearchProductsRow
+	err := m.read(ctx, func(s *memoryState) error {
+		terms := searchTerms(arg.Query)
+		for _, p := range s.products {
+			if p.DeletedAt.Valid {
+				continue
+			}
+			matched := len(terms) > 0 && containsTerms(p.Name+" "+p.Description.String, terms)
+			similar := similarity(p.Name, arg.Query)
+			if !matched && similar < similarityThreshold {
+				continue
+			}
+
+			score := similar
+			if matched {
+				score += textMatchRank
+			}
+			if arg.AfterScore.Valid && cmp.Or(cmp.Compare(score, arg.AfterScore.Float64), cmp.Compare(p.ID, arg.AfterID)) >= 0 {
+				continue
+			}
+			items = append(items, repository.SearchProductsRow{
+				ID:            p.ID,
+				Name:          p.Name,
+				Description:   p.Description,
+				PriceMinor:    p.PriceMinor,
+				Currency:      p.Currency,
+				StockQuantity: p.StockQuantity,
+				CreatedAt:     p.CreatedAt,
+				UpdatedAt:     p.UpdatedAt,
+				Version:       p.Version,
+				Score:         score,
+			})
+		}
+		slices.SortFunc(items, func(a, b repository.SearchProductsRow) int {
+			return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.ID, a.ID))
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
+
+// words splits text into lower-cased runs of letters and digits.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stem folds plural endings.
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "es"):
+		return word[:len(word)-2]
+	case len(word) > 2 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// searchTerms are the stemmed words of a query without stop words.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, w := range words(query) {
+		if !stopWords[w] {
+			terms = append(terms, stem(w))
+		}
+	}
+	return terms
+}
+
+func containsTerms(document string, terms []string) bool {
+	stems := map[string]bool{}
+	for _, w := range words(document) {
+		stems[stem(w)] = true
+	}
+	for _, t := range terms {
+		if !stems[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// trigrams returns the trigrams pg_trgm extracts from text: each word is
+// padded with two spaces in front and one behind.
+func trigrams(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range words(text) {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// similarity is pg_trgm's similarity(): the share of trigrams two strings
+// have in common.
+func similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
@@ -0,0 +1,437 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+func variantID(id int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: id, Valid: true}
+}
+
+// AdjustStock applies a signed stock change and records it in the ledger
+// and as a stock revision. It returns pgx.ErrNoRows when the product is missing or deleted or the
+// change would take stock below zero.
+func (m *Memory) AdjustStock(ctx context.Context, arg repository.AdjustStockParams) (repository.AdjustStockRow, error) {
+	var row repository.AdjustStockRow
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		p, err := s.getProduct(arg.ProductID, false)
+		if err != nil {
+			return err
+		}
+		if p.StockQuantity+arg.Delta < 0 {
+			return pgx.ErrNoRows
+		}
+		mv, err := s.recordMovement(repository.StockMovement{
+			ID:        arg.ID,
+			ProductID: p.ID,
+			Delta:     arg.Delta,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			Note:      arg.Note,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		previous := p
+		p.StockQuantity += arg.Delta
+		p.UpdatedAt = now
+		p.Version++
+		s.products[p.ID] = p
+		if err := s.recordChange(now, "stock", arg.Actor, previous, p); err != nil {
+			return err
+		}
+
+		row = repository.AdjustStockRow{
+			ID:            mv.ID,
+			ProductID:     mv.ProductID,
+			Delta:         mv.Delta,
+			Reason:        mv.Reason,
+			Reference:     mv.Reference,
+			Note:          mv.Note,
+			CreatedAt:     mv.CreatedAt,
+			StockQuantity: p.StockQuantity,
+		}
+		return nil
+	})
+	return row, err
+}
+
+// variantOf returns a variant of a product that is not deleted.
+func (s *memoryState) variantOf(productID, id int64) (repository.ProductVariant, error) {
+	v, ok := s.variants[id]
+	if !ok || v.ProductID != productID {
+		return repository.ProductVariant{}, pgx.ErrNoRows
+	}
+	if _, err := s.getProduct(productID, false); err != nil {
+		return repository.ProductVariant{}, err
+	}
+	return v, nil
+}
+
+// AdjustVariantStock is AdjustStock for a single variant of a product.
+func (m *Memory) AdjustVariantStock(ctx context.Context, arg repository.AdjustVariantStockParams) (repository.AdjustVariantStockRow, error) {
+	var row repository.AdjustVariantStockRow
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		v, err := s.variantOf(arg.ProductID, arg.VariantID)
+		if err != nil {
+			return err
+		}
+		if v.StockQuantity+arg.Delta < 0 {
+			return pgx.ErrNoRows
+		}
+		mv, err := s.recordMovement(repository.StockMovement{
+			ID:        arg.ID,
+			ProductID: v.ProductID,
+			VariantID: variantID(v.ID),
+			Delta:     arg.Delta,
+			Reason:    arg.Reason,
+			Reference: arg.Reference,
+			Note:      arg.Note,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		v.StockQuantity += arg.Delta
+		v.UpdatedAt = now
+		s.variants[v.ID] = v
+
+		row = repository.AdjustVariantStockRow{
+			ID:            mv.ID,
+			ProductID:     mv.ProductID,
+			Delta:         mv.Delta,
+			Reason:        mv.Reason,
+			Reference:     mv.Reference,
+			Note:          mv.Note,
+			CreatedAt:     mv.CreatedAt,
+			VariantID:     mv.VariantID,
+			StockQuantity: v.StockQuantity,
+		}
+		return nil
+	})
+	return row, err
+}
+
+// ListStockMovements returns a product's ledger newest first, paging on
+// (created_at, id).
+func (m *Memory) ListStockMovements(ctx context.Context, arg repository.ListStockMovementsParams) ([]repository.StockMovement, error) {
+	var items []repository.StockMovement
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, mv := range s.movements {
+			if mv.ProductID != arg.ProductID {
+				continue
+			}
+			if arg.VariantID.Valid && mv.VariantID != arg.VariantID {
+				continue
+			}
+			if arg.Reason.Valid && mv.Reason != arg.Reason.String {
+				continue
+			}
+			if arg.BeforeCreatedAt.Valid && compareMovement(mv, arg.BeforeCreatedAt.Time, arg.BeforeID) >= 0 {
+				continue
+			}
+			items = append(items, mv)
+		}
+		slices.SortFunc(items, func(a, b repository.StockMovement) int {
+			return compareMovement(b, a.CreatedAt, a.ID)
+		})
+		items = limited(items, arg.PageLimit)
+		return nil
+	})
+	return items, err
+}
+
+func compareMovement(mv repository.StockMovement, createdAt time.Time, id int64) int {
+	return cmp.Or(mv.CreatedAt.Compare(createdAt), cmp.Compare(mv.ID, id))
+}
+
+// ReconcileStock reports products and variants whose stored quantity plus
+// pending holds disagrees with the ledger, in (product_id, variant_id)
+// order with product rows first.
+func (m *Memory) ReconcileStock(ctx context.Context, maxResults int32) ([]repository.ReconcileStockRow, error) {
+	var items []repository.ReconcileStockRow
+	err := m.read(ctx, func(s *memoryState) error {
+		type totals struct{ ledger, held int64 }
+		products := map[int64]*totals{}
+		variants := map[int64]*totals{}
+		of := func(table map[int64]*totals, id int64) *totals {
+			if table[id] == nil {
+				table[id] = &totals{}
+			}
+			return table[id]
+		}
+		for _, mv := range s.movements {
+			if mv.VariantID.Valid {
+				of(variants, mv.VariantID.Int64).ledger += int64(mv.Delta)
+			} else {
+				of(products, mv.ProductID).ledger += int64(mv.Delta)
+			}
+		}
+		for _, r := range s.reservations {
+			if r.Status != "pending" {
+				continue
+			}
+			if r.VariantID.Valid {
+				of(variants, r.VariantID.Int64).held += int64(r.Quantity)
+			} else {
+				of(products, r.ProductID).held += int64(r.Quantity)
+			}
+		}
+
+		for _, p := range s.products {
+			t := of(products, p.ID)
+			if int64(p.StockQuantity)+t.held != t.ledger {
+				items = append(items, repository.ReconcileStockRow{
+					ProductID:      p.ID,
+					StockQuantity:  p.StockQuantity,
+					HeldQuantity:   t.held,
+					LedgerQuantity: t.ledger,
+				})
+			}
+		}
+		for _, v := range s.variants {
+			t := of(variants, v.ID)
+			if int64(v.StockQuantity)+t.held != t.ledger {
+				items = append(items, repository.ReconcileStockRow{
+					ProductID:      v.ProductID,
+					VariantID:      variantID(v.ID),
+					StockQuantity:  v.StockQuantity,
+					HeldQuantity:   t.held,
+					LedgerQuantity: t.ledger,
+				})
+			}
+		}
+		slices.SortFunc(items, func(a, b repository.ReconcileStockRow) int {
+			return cmp.Or(
+				cmp.Compare(a.ProductID, b.ProductID),
+				compareBool(a.VariantID.Valid, b.VariantID.Valid),
+				cmp.Compare(a.VariantID.Int64, b.VariantID.Int64),
+			)
+		})
+		items = limited(items, maxResults)
+		return nil
+	})
+	return items, err
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// insertReservation records a pending hold that expires ttl from now.
+func (s *memoryState) insertReservation(now time.Time, r repository.StockReservation, ttl pgtype.Interval) (repository.StockReservation, error) {
+	if _, ok := s.reservations[r.ID]; ok {
+		return repository.StockReservation{}, uniqueViolation("stock_reservations", "stock_reservations_pkey")
+	}
+	if r.Quantity <= 0 {
+		return repository.StockReservation{}, checkViolation("stock_reservations", "stock_reservations_quantity_check")
+	}
+	r.Status = "pending"
+	r.ExpiresAt = now.Add(intervalDuration(ttl))
+	r.CreatedAt, r.UpdatedAt = now, now
+	s.reservations[r.ID] = r
+	return r, nil
+}
+
+// ReserveStock decrements stock and records the hold and a stock revision.
+// It returns
+// pgx.ErrNoRows when the product is missing or deleted or has insufficient
+// stock.
+func (m *Memory) ReserveStock(ctx context.Context, arg repository.ReserveStockParams) (repository.StockReservation, error) {
+	var reserved repository.StockReservation
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		p, err := s.getProduct(arg.ProductID, false)
+		if err != nil {
+			return err
+		}
+		if p.StockQuantity < arg.Quantity {
+			return pgx.ErrNoRows
+		}
+		reserved, err = s.insertReservation(now, repository.StockReservation{
+			ID:        arg.ID,
+			ProductID: p.ID,
+			Quantity:  arg.Quantity,
+		}, arg.Ttl)
+		if err != nil {
+			return err
+		}
+		previous := p
+		p.StockQuantity -= arg.Quantity
+		p.UpdatedAt = now
+		p.Version++
+		s.products[p.ID] = p
+		return s.recordChange(now, "stock", arg.Actor, previous, p)
+	})
+	return reserved, err
+}
+
+// ReserveVariantStock is ReserveStock for a single variant of a product.
+func (m *Memory) ReserveVariantStock(ctx context.Context, arg repository.ReserveVariantStockParams) (repository.StockReservation, error) {
+	var reserved repository.StockReservation
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		v, err := s.variantOf(arg.ProductID, arg.VariantID)
+		if err != nil {
+			return err
+		}
+		if v.StockQuantity < arg.Quantity {
+			return pgx.ErrNoRows
+		}
+		reserved, err = s.insertReservation(now, repository.StockReservation{
+			ID:        arg.ID,
+			ProductID: v.ProductID,
+			VariantID: variantID(v.ID),
+			Quantity:  arg.Quantity,
+		}, arg.Ttl)
+		if err != nil {
+			return err
+		}
+		v.StockQuantity -= arg.Quantity
+		v.UpdatedAt = now
+		s.variants[v.ID] = v
+		return nil
+	})
+	return reserved, err
+}
+
+func (m *Memory) GetStockReservation(ctx context.Context, id int64) (repository.StockReservation, error) {
+	var r repository.StockReservation
+	err := m.read(ctx, func(s *memoryState) error {
+		var ok bool
+		if r, ok = s.reservations[id]; !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return r, err
+}
+
+// CommitStockReservation turns a pending, unexpired hold into a sale in
+// the ledger. It returns pgx.ErrNoRows for any other reservation.
+func (m *Memory) CommitStockReservation(ctx context.Context, id int64) (repository.CommitStockReservationRow, error) {
+	var committed repository.StockReservation
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		r, ok := s.reservations[id]
+		if !ok || r.Status != "pending" || !r.ExpiresAt.After(now) {
+			return pgx.ErrNoRows
+		}
+		if _, err := s.recordMovement(repository.StockMovement{
+			ProductID: r.ProductID,
+			VariantID: r.VariantID,
+			Delta:     -r.Quantity,
+			Reason:    "sale",
+			Reference: text("reservation:" + strconv.FormatInt(r.ID, 10)),
+			CreatedAt: now,
+		}); err != nil {
+			return err
+		}
+		r.Status, r.UpdatedAt = "committed", now
+		s.reservations[r.ID] = r
+		committed = r
+		return nil
+	})
+	return repository.CommitStockReservationRow(committed), err
+}
+
+// ReleaseStockReservation returns the stock of a pending hold to its
+// product, recording a stock revision, or to its variant. It returns
+// pgx.ErrNoRows for any other reservation.
+func (m *Memory) ReleaseStockReservation(ctx context.Context, arg repository.ReleaseStockReservationParams) (repository.ReleaseStockReservationRow, error) {
+	var released repository.StockReservation
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		r, ok := s.reservations[arg.ID]
+		if !ok || r.Status != "pending" {
+			return pgx.ErrNoRows
+		}
+		r.Status, r.UpdatedAt = "released", now
+		s.reservations[r.ID] = r
+
+		if r.VariantID.Valid {
+			if v, ok := s.variants[r.VariantID.Int64]; ok {
+				v.StockQuantity += r.Quantity
+				v.UpdatedAt = now
+				s.variants[v.ID] = v
+			}
+		} else if p, ok := s.products[r.ProductID]; ok {
+			previous := p
+			p.StockQuantity += r.Quantity
+			p.UpdatedAt = now
+			p.Version++
+			s.products[p.ID] = p
+			if err := s.recordChange(now, "stock", arg.Actor, previous, p); err != nil {
+				return err
+			}
+		}
+		released = r
+		return nil
+	})
+	return repository.ReleaseStockReservationRow(released), err
+}
+
+// ExpireStockReservations expires up to batch_size pending holds whose
+// expiry has passed and returns their stock, recording one stock revision
+// per product. It returns the number of holds expired.
+func (m *Memory) ExpireStockReservations(ctx context.Context, batchSize int32) (int64, error) {
+	var expired int64
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		var due []repository.StockReservation
+		for _, r := range s.reservations {
+			if r.Status == "pending" && !r.ExpiresAt.After(now) {
+				due = append(due, r)
+			}
+		}
+		slices.SortFunc(due, func(a, b repository.StockReservation) int {
+			return cmp.Or(a.ExpiresAt.Compare(b.ExpiresAt), cmp.Compare(a.ID, b.ID))
+		})
+
+		products := map[int64]int32{}
+		for _, r := range limited(due, batchSize) {
+			r.Status, r.UpdatedAt = "expired", now
+			s.reservations[r.ID] = r
+			expired++
+			if !r.VariantID.Valid {
+				products[r.ProductID] += r.Quantity
+				continue
+			}
+			if v, ok := s.variants[r.VariantID.Int64]; ok {
+				v.StockQuantity += r.Quantity
+				v.UpdatedAt = now
+				s.variants[v.ID] = v
+			}
+		}
+		for _, id := range sortedKeys(products) {
+			p, ok := s.products[id]
+			if !ok {
+				continue
+			}
+			previous := p
+			p.StockQuantity += products[id]
+			p.UpdatedAt = now
+			p.Version++
+			s.products[p.ID] = p
+			if err := s.recordChange(now, "stock", "system", previous, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return expired, nil
+}
@@ -0,0 +1,525 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func createProduct(t *testing.T, s ProductStore, id int64, name string, price int64) repository.CreateProductRow {
+	t.Helper()
+	row, err := s.CreateProduct(context.Background(), repository.CreateProductParams{
+		ID:            id,
+		Name:          name,
+		PriceMinor:    price,
+		Currency:      "USD",
+		StockQuantity: 5,
+		Actor:         "test",
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct(%d) error = %v", id, err)
+	}
+	return row
+}
+
+func constraintName(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+	return pgErr.ConstraintName
+}
+
+func TestMemory_CreateAndGetProduct(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	created := createProduct(t, m, 1, "Widget", 999)
+	if created.Version != 1 {
+		t.Errorf("Version = %d, want 1", created.Version)
+	}
+
+	got, err := m.GetProductByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProductByID error = %v", err)
+	}
+	if got.Name != "Widget" || got.PriceMinor != 999 || got.StockQuantity != 5 {
+		t.Errorf("GetProductByID = %+v", got)
+	}
+
+	if _, err := m.GetProductByID(ctx, 2); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID(missing) error = %v, want pgx.ErrNoRows", err)
+	}
+
+	_, err = m.CreateProduct(ctx, repository.CreateProductParams{ID: 1, Name: "Again", PriceMinor: 1, Currency: "USD"})
+	if got := constraintName(err); got != "products_pkey" {
+		t.Errorf("duplicate CreateProduct constraint = %q (err %v), want products_pkey", got, err)
+	}
+
+	movements, err := m.ListStockMovements(ctx, repository.ListStockMovementsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListStockMovements error = %v", err)
+	}
+	if len(movements) != 1 || movements[0].Reason != "receipt" || movements[0].Delta != 5 {
+		t.Errorf("ListStockMovements = %+v, want one receipt of 5", movements)
+	}
+}
+
+func TestMemory_UpdateProductChecksVersion(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+
+	_, err := m.UpdateProduct(ctx, repository.UpdateProductParams{
+		ID:              1,
+		SetName:         true,
+		Name:            "Gadget",
+		ExpectedVersion: pgtype.Int8{Int64: 2, Valid: true},
+	})
+	if !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("stale UpdateProduct error = %v, want pgx.ErrNoRows", err)
+	}
+
+	updated, err := m.UpdateProduct(ctx, repository.UpdateProductParams{
+		ID:              1,
+		SetName:         true,
+		Name:            "Gadget",
+		ExpectedVersion: pgtype.Int8{Int64: 1, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("UpdateProduct error = %v", err)
+	}
+	if updated.Name != "Gadget" || updated.Version != 2 {
+		t.Errorf("UpdateProduct = %+v, want name Gadget at version 2", updated)
+	}
+
+	revisions, err := m.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Version != 2 || revisions[0].Action != "update" {
+		t.Errorf("ListProductRevisions = %+v, want update then create", revisions)
+	}
+}
+
+func TestMemory_ListProductsKeyset(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Charlie", 300)
+	createProduct(t, m, 2, "Alpha", 100)
+	createProduct(t, m, 3, "Bravo", 200)
+	createProduct(t, m, 4, "Delta", 200)
+
+	page, err := m.ListProducts(ctx, repository.ListProductsParams{
+		SortBy: repository.ProductSortByPrice,
+		Limit:  2,
+	})
+	if err != nil {
+		t.Fatalf("ListProducts error = %v", err)
+	}
+	if ids := productIDs(page); !equalIDs(ids, []int64{2, 3}) {
+		t.Fatalf("first page = %v, want [2 3]", ids)
+	}
+
+	last := page[len(page)-1]
+	page, err = m.ListProducts(ctx, repository.ListProductsParams{
+		SortBy: repository.ProductSortByPrice,
+		After:  &repository.ProductKeyset{ID: last.ID, Value: last.PriceMinor},
+		Limit:  2,
+	})
+	if err != nil {
+		t.Fatalf("ListProducts error = %v", err)
+	}
+	if ids := productIDs(page); !equalIDs(ids, []int64{4, 1}) {
+		t.Errorf("second page = %v, want [4 1]", ids)
+	}
+}
+
+func productIDs(products []repository.Product) []int64 {
+	ids := make([]int64, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemory_VariantConstraints(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Shirt", 2500)
+
+	variant := repository.CreateVariantParams{
+		ID:          10,
+		ProductID:   1,
+		Sku:         "SHIRT-S",
+		Options:     []byte(`{"size":"S"}`),
+		OptionKey:   "size=S",
+		MaxVariants: 2,
+	}
+	if _, err := m.CreateVariant(ctx, variant); err != nil {
+		t.Fatalf("CreateVariant error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(*repository.CreateVariantParams)
+		constraint string
+	}{
+		{"duplicate sku", func(p *repository.CreateVariantParams) { p.OptionKey = "size=M" }, "product_variants_sku_key"},
+		{"duplicate options", func(p *repository.CreateVariantParams) { p.Sku = "SHIRT-M" }, "product_variants_options_key"},
+		{"price without currency", func(p *repository.CreateVariantParams) {
+			p.Sku, p.OptionKey = "SHIRT-M", "size=M"
+			p.PriceMinor = pgtype.Int8{Int64: 100, Valid: true}
+		}, "product_variants_price_check"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := variant
+			params.ID = 11
+			tt.mutate(&params)
+			_, err := m.CreateVariant(ctx, params)
+			if got := constraintName(err); got != tt.constraint {
+				t.Errorf("CreateVariant constraint = %q (err %v), want %q", got, err, tt.constraint)
+			}
+		})
+	}
+
+	variant.ID, variant.Sku, variant.OptionKey = 11, "SHIRT-M", "size=M"
+	if _, err := m.CreateVariant(ctx, variant); err != nil {
+		t.Fatalf("CreateVariant error = %v", err)
+	}
+	variant.ID, variant.Sku, variant.OptionKey = 12, "SHIRT-L", "size=L"
+	if _, err := m.CreateVariant(ctx, variant); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("CreateVariant past max_variants error = %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func TestMemory_Reservations(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	if _, err := m.ReserveStock(ctx, repository.ReserveStockParams{ID: 20, ProductID: 1, Quantity: 6, Ttl: ttl}); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("ReserveStock beyond stock error = %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := m.ReserveStock(ctx, repository.ReserveStockParams{ID: 20, ProductID: 1, Quantity: 2, Ttl: ttl}); err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+	if _, err := m.ReserveStock(ctx, repository.ReserveStockParams{ID: 21, ProductID: 1, Quantity: 1, Ttl: ttl}); err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+
+	if _, err := m.CommitStockReservation(ctx, 20); err != nil {
+		t.Fatalf("CommitStockReservation error = %v", err)
+	}
+	if _, err := m.CommitStockReservation(ctx, 20); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second CommitStockReservation error = %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := m.ReleaseStockReservation(ctx, repository.ReleaseStockReservationParams{ID: 21}); err != nil {
+		t.Fatalf("ReleaseStockReservation error = %v", err)
+	}
+
+	p, err := m.GetProductByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProductByID error = %v", err)
+	}
+	if p.StockQuantity != 3 {
+		t.Errorf("StockQuantity = %d, want 3", p.StockQuantity)
+	}
+	drift, err := m.ReconcileStock(ctx, 10)
+	if err != nil {
+		t.Fatalf("ReconcileStock error = %v", err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("ReconcileStock = %+v, want no drift", drift)
+	}
+}
+
+func TestMemory_StockRevisions(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+	if err := m.AddProductTags(ctx, repository.AddProductTagsParams{ProductID: 1, Tags: []string{"sale"}}); err != nil {
+		t.Fatalf("AddProductTags error = %v", err)
+	}
+
+	if _, err := m.AdjustStock(ctx, repository.AdjustStockParams{ID: 30, ProductID: 1, Delta: -1, Reason: "shrinkage", Actor: "clerk"}); err != nil {
+		t.Fatalf("AdjustStock error = %v", err)
+	}
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	if _, err := m.ReserveStock(ctx, repository.ReserveStockParams{ID: 20, ProductID: 1, Quantity: 2, Ttl: ttl, Actor: "clerk"}); err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+	if _, err := m.ReleaseStockReservation(ctx, repository.ReleaseStockReservationParams{ID: 20, Actor: "clerk"}); err != nil {
+		t.Fatalf("ReleaseStockReservation error = %v", err)
+	}
+
+	revisions, err := m.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	if len(revisions) != 4 {
+		t.Fatalf("got %d revisions, want create and three stock changes", len(revisions))
+	}
+	for i, r := range revisions[:3] {
+		if r.Action != "stock" || r.Actor != "clerk" || r.Version != int64(4-i) {
+			t.Errorf("revisions[%d] = %s by %s at version %d, want stock by clerk at version %d", i, r.Action, r.Actor, r.Version, 4-i)
+		}
+	}
+
+	var snapshot revisionSnapshot
+	if err := json.Unmarshal(revisions[0].Snapshot, &snapshot); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if snapshot.StockQuantity != 4 || len(snapshot.Tags) != 1 || snapshot.Tags[0] != "sale" {
+		t.Errorf("snapshot = stock %d tags %v, want stock 4 tags [sale]", snapshot.StockQuantity, snapshot.Tags)
+	}
+}
+
+func TestMemory_InTx(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	boom := errors.New("boom")
+
+	err := m.InTx(ctx, func(tx ProductStore) error {
+		createProduct(t, tx, 1, "Widget", 999)
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("InTx error = %v, want %v", err, boom)
+	}
+	if _, err := m.GetProductByID(ctx, 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID after rollback error = %v, want pgx.ErrNoRows", err)
+	}
+
+	err = m.InTx(ctx, func(tx ProductStore) error {
+		createProduct(t, tx, 1, "Widget", 999)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+	if _, err := m.GetProductByID(ctx, 1); err != nil {
+		t.Errorf("GetProductByID after commit error = %v", err)
+	}
+}
+
+func TestMemory_ConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.AdjustStock(ctx, repository.AdjustStockParams{
+				ID:        int64(100 + i),
+				ProductID: 1,
+				Delta:     1,
+				Reason:    "receipt",
+			})
+			if err != nil {
+				t.Errorf("AdjustStock error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	p, err := m.GetProductByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProductByID error = %v", err)
+	}
+	if p.StockQuantity != 25 || p.Version != 21 {
+		t.Errorf("product = stock %d version %d, want stock 25 version 21", p.StockQuantity, p.Version)
+	}
+}
+
+func TestMemory_OutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+	if _, err := m.AdjustStock(ctx, repository.AdjustStockParams{ID: 30, ProductID: 1, Delta: -1, Reason: "shrinkage", Actor: "clerk"}); err != nil {
+		t.Fatalf("AdjustStock error = %v", err)
+	}
+
+	events, err := m.ListPendingOutboxEvents(ctx, repository.ListPendingOutboxEventsParams{Now: m.now(), BatchSize: 10})
+	if err != nil {
+		t.Fatalf("ListPendingOutboxEvents error = %v", err)
+	}
+	if len(events) != 2 || events[0].EventType != "product.created" || events[1].EventType != "product.stock_changed" {
+		t.Fatalf("ListPendingOutboxEvents = %+v, want product.created then product.stock_changed", events)
+	}
+	if events[1].AggregateID != 1 || events[1].AggregateVersion != 2 {
+		t.Errorf("stock event = product %d version %d, want product 1 version 2", events[1].AggregateID, events[1].AggregateVersion)
+	}
+
+	if err := m.MarkOutboxEventPublished(ctx, events[0].ID); err != nil {
+		t.Fatalf("MarkOutboxEventPublished error = %v", err)
+	}
+	backlog, err := m.GetOutboxBacklog(ctx, m.now())
+	if err != nil {
+		t.Fatalf("GetOutboxBacklog error = %v", err)
+	}
+	if backlog.Pending != 1 {
+		t.Errorf("Pending = %d, want 1", backlog.Pending)
+	}
+
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	if n, err := m.AcquireOutboxLease(ctx, repository.AcquireOutboxLeaseParams{Holder: "a", Ttl: ttl}); err != nil || n != 1 {
+		t.Fatalf("AcquireOutboxLease(a) = %d, %v, want 1", n, err)
+	}
+	if n, err := m.AcquireOutboxLease(ctx, repository.AcquireOutboxLeaseParams{Holder: "b", Ttl: ttl}); err != nil || n != 0 {
+		t.Errorf("AcquireOutboxLease(b) while a holds it = %d, %v, want 0", n, err)
+	}
+}
+
+func TestMemory_PurgeDeletedProducts(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+	createProduct(t, m, 2, "Gadget", 999)
+	if _, err := m.DeleteProduct(ctx, repository.DeleteProductParams{ID: 1, Actor: "test"}); err != nil {
+		t.Fatalf("DeleteProduct error = %v", err)
+	}
+
+	later := m.now().Add(time.Hour)
+	m.now = func() time.Time { return later }
+	purged, err := m.PurgeDeletedProducts(ctx, repository.PurgeDeletedProductsParams{DeletedBefore: later, BatchSize: 10})
+	if err != nil {
+		t.Fatalf("PurgeDeletedProducts error = %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("purged = %d, want 1", purged)
+	}
+	if _, err := m.GetProductByID(ctx, 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID(purged) error = %v, want pgx.ErrNoRows", err)
+	}
+	if _, err := m.GetProductByID(ctx, 2); err != nil {
+		t.Errorf("GetProductByID(live) error = %v", err)
+	}
+
+	revisions, err := m.ListProductRevisions(ctx, repository.ListProductRevisionsParams{ProductID: 1, PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListProductRevisions error = %v", err)
+	}
+	if len(revisions) == 0 || revisions[0].Action != "purge" || revisions[0].Version != 3 {
+		t.Errorf("latest revision = %+v, want purge at version 3", revisions)
+	}
+}
+
+func TestMemory_ExpireStockReservations(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	createProduct(t, m, 1, "Widget", 999)
+	ttl := pgtype.Interval{Microseconds: 60_000_000, Valid: true}
+	if _, err := m.ReserveStock(ctx, repository.ReserveStockParams{ID: 20, ProductID: 1, Quantity: 2, Ttl: ttl}); err != nil {
+		t.Fatalf("ReserveStock error = %v", err)
+	}
+
+	if n, err := m.ExpireStockReservations(ctx, 10); err != nil || n != 0 {
+		t.Fatalf("ExpireStockReservations before expiry = %d, %v, want 0", n, err)
+	}
+	later := m.now().Add(2 * time.Minute)
+	m.now = func() time.Time { return later }
+	if n, err := m.ExpireStockReservations(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("ExpireStockReservations = %d, %v, want 1", n, err)
+	}
+
+	r, err := m.GetStockReservation(ctx, 20)
+	if err != nil {
+		t.Fatalf("GetStockReservation error = %v", err)
+	}
+	if r.Status != "expired" {
+		t.Errorf("Status = %q, want expired", r.Status)
+	}
+	p, err := m.GetProductByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProductByID error = %v", err)
+	}
+	if p.StockQuantity != 5 || p.Version != 3 {
+		t.Errorf("product = stock %d version %d, want stock 5 version 3", p.StockQuantity, p.Version)
+	}
+}
+
+func TestMemory_IdempotencyKeys(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	claim := repository.ClaimIdempotencyKeyParams{
+		Method:         "/product.v1.ProductService/CreateProduct",
+		IdempotencyKey: "k1",
+		RequestHash:    []byte("hash"),
+		Ttl:            pgtype.Interval{Microseconds: 60_000_000, Valid: true},
+	}
+	if _, err := m.ClaimIdempotencyKey(ctx, claim); err != nil {
+		t.Fatalf("ClaimIdempotencyKey error = %v", err)
+	}
+	if _, err := m.ClaimIdempotencyKey(ctx, claim); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("second ClaimIdempotencyKey error = %v, want pgx.ErrNoRows", err)
+	}
+
+	if err := m.CompleteIdempotencyKey(ctx, repository.CompleteIdempotencyKeyParams{Method: claim.Method, IdempotencyKey: "k1", Response: []byte("done")}); err != nil {
+		t.Fatalf("CompleteIdempotencyKey error = %v", err)
+	}
+	stored, err := m.GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{Method: claim.Method, IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("GetIdempotencyKey error = %v", err)
+	}
+	if string(stored.Response) != "done" {
+		t.Errorf("Response = %q, want \"done\"", stored.Response)
+	}
+
+	later := m.now().Add(2 * time.Minute)
+	m.now = func() time.Time { return later }
+	if n, err := m.DeleteExpiredIdempotencyKeys(ctx, 10); err != nil || n != 1 {
+		t.Errorf("DeleteExpiredIdempotencyKeys = %d, %v, want 1", n, err)
+	}
+}
+
+func TestInMemory_RunsWithoutDatabase(t *testing.T) {
+	var s ProductStore
+	app := fxtest.New(t,
+		// The configured CockroachDB is unreachable: it must never be
+		// dialled.
+		fx.Supply(&config.Config{DbConfig: config.DbConfig{Host: "db.invalid"}}),
+		fx.Supply(zap.NewNop(), prometheus.NewRegistry()),
+		database.Module,
+		Module,
+		InMemory(),
+		fx.Populate(&s),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	if _, ok := s.(*Memory); !ok {
+		t.Errorf("ProductStore = %T, want *Memory", s)
+	}
+}
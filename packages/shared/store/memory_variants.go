@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// checkVariant enforces the constraints of the product_variants table on
+// v, which is stored under v.ID.
+func (s *memoryState) checkVariant(v repository.ProductVariant) error {
+	if v.StockQuantity < 0 {
+		return checkViolation("product_variants", "product_variants_stock_quantity_check")
+	}
+	if v.PriceMinor.Valid != v.Currency.Valid {
+		return checkViolation("product_variants", "product_variants_price_check")
+	}
+	if v.PriceMinor.Valid && v.PriceMinor.Int64 <= 0 {
+		return checkViolation("product_variants", "product_variants_price_minor_check")
+	}
+	if v.Currency.Valid {
+		if err := checkCurrency(v.Currency.String, "product_variants", "product_variants_currency_fkey"); err != nil {
+			return err
+		}
+	}
+	for _, other := range s.variants {
+		if other.ID == v.ID {
+			continue
+		}
+		if other.Sku == v.Sku {
+			return uniqueViolation("product_variants", "product_variants_sku_key")
+		}
+		if other.ProductID == v.ProductID && other.OptionKey == v.OptionKey {
+			return uniqueViolation("product_variants", "product_variants_options_key")
+		}
+	}
+	return nil
+}
+
+// CreateVariant books initial stock in the ledger as a receipt. It returns
+// pgx.ErrNoRows when the product is missing or deleted or already has
+// max_variants variants.
+func (m *Memory) CreateVariant(ctx context.Context, arg repository.CreateVariantParams) (repository.CreateVariantRow, error) {
+	var created repository.ProductVariant
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		if _, err := s.getProduct(arg.ProductID, false); err != nil {
+			return err
+		}
+		var count int64
+		for _, v := range s.variants {
+			if v.ProductID == arg.ProductID {
+				count++
+			}
+		}
+		if count >= arg.MaxVariants {
+			return pgx.ErrNoRows
+		}
+
+		v := repository.ProductVariant{
+			ID:            arg.ID,
+			ProductID:     arg.ProductID,
+			Sku:           arg.Sku,
+			Options:       arg.Options,
+			OptionKey:     arg.OptionKey,
+			PriceMinor:    arg.PriceMinor,
+			Currency:      arg.Currency,
+			StockQuantity: arg.StockQuantity,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if _, ok := s.variants[v.ID]; ok {
+			return uniqueViolation("product_variants", "product_variants_pkey")
+		}
+		if err := s.checkVariant(v); err != nil {
+			return err
+		}
+
+		s.variants[v.ID] = v
+		if v.StockQuantity > 0 {
+			if _, err := s.recordMovement(repository.StockMovement{
+				ProductID: v.ProductID,
+				VariantID: variantID(v.ID),
+				Delta:     v.StockQuantity,
+				Reason:    "receipt",
+				Note:      text("initial stock"),
+				CreatedAt: now,
+			}); err != nil {
+				return err
+			}
+		}
+		created = v
+		return nil
+	})
+	return repository.CreateVariantRow(created), err
+}
+
+func (m *Memory) GetVariant(ctx context.Context, id int64) (repository.ProductVariant, error) {
+	var v repository.ProductVariant
+	err := m.read(ctx, func(s *memoryState) error {
+		var ok bool
+		if v, ok = s.variants[id]; !ok {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+	return v, err
+}
+
+func (m *Memory) GetVariantBySKU(ctx context.Context, sku string) (repository.ProductVariant, error) {
+	var found repository.ProductVariant
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, v := range s.variants {
+			if v.Sku == sku {
+				found = v
+				return nil
+			}
+		}
+		return pgx.ErrNoRows
+	})
+	return found, err
+}
+
+// ListVariants returns the variants of a product in id order.
+func (m *Memory) ListVariants(ctx context.Context, productID int64) ([]repository.ProductVariant, error) {
+	var items []repository.ProductVariant
+	err := m.read(ctx, func(s *memoryState) error {
+		for _, id := range sortedKeys(s.variants) {
+			if v := s.variants[id]; v.ProductID == productID {
+				items = append(items, v)
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// UpdateVariant clears the price override when set_price comes with a NULL
+// price_minor.
+func (m *Memory) UpdateVariant(ctx context.Context, arg repository.UpdateVariantParams) (repository.ProductVariant, error) {
+	var updated repository.ProductVariant
+	err := m.write(ctx, func(s *memoryState, now time.Time) error {
+		v, ok := s.variants[arg.ID]
+		if !ok {
+			return pgx.ErrNoRows
+		}
+		if arg.SetSku {
+			v.Sku = arg.Sku
+		}
+		if arg.SetOptions {
+			v.Options, v.OptionKey = arg.Options, arg.OptionKey
+		}
+		if arg.SetPrice {
+			v.PriceMinor, v.Currency = arg.PriceMinor, arg.Currency
+		}
+		v.UpdatedAt = now
+		if err := s.checkVariant(v); err != nil {
+			return err
+		}
+		s.variants[v.ID] = v
+		updated = v
+		return nil
+	})
+	return updated, err
+}
+
+// DeleteVariant only deletes variants without stock or pending holds and
+// returns pgx.ErrNoRows for others. The variant's ledger entries and holds
+// are kept without it.
+func (m *Memory) DeleteVariant(ctx context.Context, id int64) (repository.ProductVariant, error) {
+	var deleted repository.ProductVariant
+	err := m.write(ctx, func(s *memoryState, _ time.Time) error {
+		v, ok := s.variants[id]
+		if !ok || v.StockQuantity != 0 {
+			return pgx.ErrNoRows
+		}
+		for _, r := range s.reservations {
+			if r.VariantID.Valid && r.VariantID.Int64 == id && r.Status == "pending" {
+				return pgx.ErrNoRows
+			}
+		}
+
+		delete(s.variants, id)
+		for movementID, mv := range s.movements {
+			if mv.VariantID.Valid && mv.VariantID.Int64 == id {
+				mv.VariantID = pgtype.Int8{}
+				s.movements[movementID] = mv
+			}
+		}
+		for reservationID, r := range s.reservations {
+			if r.VariantID.Valid && r.VariantID.Int64 == id {
+				r.VariantID = pgtype.Int8{}
+				s.reservations[reservationID] = r
+			}
+		}
+		deleted = v
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// TxBeginner starts the transactions InTx runs in. Both *pgxpool.Pool and
+// pgx.Tx implement it; the latter through a savepoint.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// SQL is the ProductStore backed by the sqlc queries.
+type SQL struct {
+	*repository.Queries
+	db TxBeginner
+	// txs, when set, runs the top level transactions of InTx and retries
+	// their serialization failures.
+	txs *database.Transactor
+}
+
+var _ ProductStore = (*SQL)(nil)
+
+func NewSQL(db TxBeginner, queries *repository.Queries) *SQL {
+	return &SQL{Queries: queries, db: db}
+}
+
+// WithTransactor runs the top level transactions of InTx on txs, which
+// retries their serialization failures.
+func (s *SQL) WithTransactor(txs *database.Transactor) *SQL {
+	s.txs = txs
+	return s
+}
+
+// InTx retries a transaction CockroachDB aborts with a serialization
+// failure when the store runs on a Transactor. fn may then run more than
+// once. A failure is recognized whatever error fn returns, so fn may turn
+// the errors of its queries into status errors; nested calls of InTx run
+// in savepoints and leave the retry to the outermost one.
+func (s *SQL) InTx(ctx context.Context, fn func(ProductStore) error) error {
+	if s.txs != nil {
+		return s.txs.RunTx(ctx, func(tx pgx.Tx) error {
+			conn := newTxConn(tx)
+			return conn.result(fn(&SQL{Queries: s.Queries.WithTx(conn), db: conn}))
+		})
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		// A no-op once the transaction has been committed.
+		_ = tx.Rollback(ctx)
+	}()
+
+	// A savepoint of an enclosing InTx is a *txConn already.
+	conn, ok := tx.(*txConn)
+	if !ok {
+		conn = newTxConn(tx)
+	}
+	if err := fn(&SQL{Queries: s.Queries.WithTx(conn), db: conn}); err != nil {
+		return conn.result(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// BatchCreateProducts pipelines the inserts in one round trip. It shadows
+// the sqlc method, whose batch results are specific to pgx.
+func (s *SQL) BatchCreateProducts(ctx context.Context, arg []repository.BatchCreateProductsParams, f func(int, repository.BatchCreateProductsRow, error)) {
+	s.Queries.BatchCreateProducts(ctx, arg).QueryRow(f)
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+)
+
+// txConn is the transaction of an SQL store handed to an InTx callback. It
+// remembers the serialization failure of any of its statements, those of
+// nested savepoints included, so that InTx retries the transaction even
+// when the callback reported the failure as an error of its own.
+type txConn struct {
+	pgx.Tx
+	// failure is shared with the savepoints begun on the transaction.
+	failure *error
+}
+
+func newTxConn(tx pgx.Tx) *txConn {
+	return &txConn{Tx: tx, failure: new(error)}
+}
+
+func (c *txConn) record(err error) {
+	if *c.failure == nil && database.IsSerializationFailure(err) {
+		*c.failure = err
+	}
+}
+
+// result returns err, or the serialization failure it stands for.
+func (c *txConn) result(err error) error {
+	if err == nil || *c.failure == nil || database.IsSerializationFailure(err) {
+		return err
+	}
+	return errors.Join(err, *c.failure)
+}
+
+func (c *txConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := c.Tx.Begin(ctx)
+	c.record(err)
+	if err != nil {
+		return nil, err
+	}
+	return &txConn{Tx: tx, failure: c.failure}, nil
+}
+
+func (c *txConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := c.Tx.Exec(ctx, sql, args...)
+	c.record(err)
+	return tag, err
+}
+
+func (c *txConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := c.Tx.Query(ctx, sql, args...)
+	c.record(err)
+	if err != nil {
+		return rows, err
+	}
+	return txRows{Rows: rows, conn: c}, nil
+}
+
+func (c *txConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return txRow{Row: c.Tx.QueryRow(ctx, sql, args...), conn: c}
+}
+
+func (c *txConn) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return txBatch{BatchResults: c.Tx.SendBatch(ctx, b), conn: c}
+}
+
+type txRows struct {
+	pgx.Rows
+	conn *txConn
+}
+
+func (r txRows) Err() error {
+	err := r.Rows.Err()
+	r.conn.record(err)
+	return err
+}
+
+type txRow struct {
+	pgx.Row
+	conn *txConn
+}
+
+func (r txRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.conn.record(err)
+	return err
+}
+
+type txBatch struct {
+	pgx.BatchResults
+	conn *txConn
+}
+
+func (b txBatch) QueryRow() pgx.Row {
+	return txRow{Row: b.BatchResults.QueryRow(), conn: b.conn}
+}
+
+func (b txBatch) Close() error {
+	err := b.BatchResults.Close()
+	b.conn.record(err)
+	return err
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+)
+
+// fakeConn begins fakeTxs whose statements fail with a serialization
+// failure while failures remain.
+type fakeConn struct {
+	failures int
+	commits  int
+}
+
+func (c *fakeConn) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{conn: c}, nil
+}
+
+type fakeTx struct {
+	pgx.Tx
+	conn *fakeConn
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{conn: tx.conn}, nil
+}
+
+func (tx *fakeTx) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	if tx.conn.failures > 0 {
+		tx.conn.failures--
+		return pgconn.CommandTag{}, &pgconn.PgError{Code: "40001"}
+	}
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	tx.conn.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback(context.Context) error { return nil }
+
+func TestSQL_InTxRetriesFailureReportedByNestedTx(t *testing.T) {
+	ctx := context.Background()
+	conn := &fakeConn{failures: 1}
+	txs := database.NewTransactor(conn, repository.New(nil), config.TxRetryConfig{
+		MaxRetries: 3,
+		MinBackoff: time.Microsecond,
+		MaxBackoff: time.Microsecond,
+	}, prometheus.NewRegistry())
+	s := NewSQL(conn, repository.New(nil)).WithTransactor(txs)
+
+	attempts := 0
+	err := s.InTx(ctx, func(tx ProductStore) error {
+		attempts++
+		return tx.InTx(ctx, func(nested ProductStore) error {
+			if err := nested.MarkOutboxEventPublished(ctx, 1); err != nil {
+				// Handlers report failures in errors of their own.
+				return errors.New("failed to mark the event published")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("ran %d attempts, want 2", attempts)
+	}
+	// The savepoint and the transaction of the second attempt.
+	if conn.commits != 2 {
+		t.Errorf("committed %d times, want 2", conn.commits)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository/sqlite"
+)
+
+// SQLite is the ProductStore backed by the SQLite queries of package
+// repository/sqlite.
+type SQLite struct {
+	*sqlite.Queries
+	db *sql.DB
+	// tx is set on the store InTx hands to fn.
+	tx *sql.Tx
+}
+
+var _ ProductStore = (*SQLite)(nil)
+
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{Queries: sqlite.New(db), db: db}
+}
+
+// InTx runs fn in a transaction on the database's only connection (see
+// database.NewSQLite), which the transaction holds until it ends. fn must
+// therefore run every query through the store it is given: a query through
+// s, or any other store or Querier on the same database, waits for that
+// connection and deadlocks.
+func (s *SQLite) InTx(ctx context.Context, fn func(ProductStore) error) error {
+	if s.tx != nil {
+		return s.inSavepoint(ctx, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		// A no-op once the transaction has been committed.
+		_ = tx.Rollback()
+	}()
+
+	if err := fn(&SQLite{Queries: s.Queries.WithTx(tx), tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// inSavepoint nests InTx in the current transaction, as pgx.Tx.Begin does.
+func (s *SQLite) inSavepoint(ctx context.Context, fn func(ProductStore) error) error {
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT store_tx"); err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(s); err != nil {
+		_, _ = s.tx.ExecContext(ctx, "ROLLBACK TO store_tx")
+		_, _ = s.tx.ExecContext(ctx, "RELEASE store_tx")
+		return err
+	}
+	if _, err := s.tx.ExecContext(ctx, "RELEASE store_tx"); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// BatchCreateProducts creates the products one at a time; an embedded
+// database has no round trips to save.
+func (s *SQLite) BatchCreateProducts(ctx context.Context, arg []repository.BatchCreateProductsParams, f func(int, repository.BatchCreateProductsRow, error)) {
+	for i, a := range arg {
+		row, err := s.CreateProduct(ctx, repository.CreateProductParams(a))
+		f(i, repository.BatchCreateProductsRow(row), err)
+	}
+}
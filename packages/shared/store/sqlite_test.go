@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/config"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func newSQLite(t *testing.T) *SQLite {
+	t.Helper()
+	lc := fxtest.NewLifecycle(t)
+	db, err := database.NewSQLite(database.Params{
+		Lifecycle: lc,
+		Cfg:       &config.Config{DbConfig: config.DbConfig{Path: filepath.Join(t.TempDir(), "test.db")}},
+		Log:       zap.NewNop(),
+	})
+	if err != nil {
+		t.Fatalf("NewSQLite error = %v", err)
+	}
+	lc.RequireStart()
+	t.Cleanup(lc.RequireStop)
+	return NewSQLite(db)
+}
+
+func TestSQLite_InTx(t *testing.T) {
+	ctx := context.Background()
+	s := newSQLite(t)
+	boom := errors.New("boom")
+
+	err := s.InTx(ctx, func(tx ProductStore) error {
+		createProduct(t, tx, 1, "Widget", 999)
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("InTx error = %v, want %v", err, boom)
+	}
+	if _, err := s.GetProductByID(ctx, 1); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID after rollback error = %v, want pgx.ErrNoRows", err)
+	}
+
+	err = s.InTx(ctx, func(tx ProductStore) error {
+		createProduct(t, tx, 1, "Widget", 999)
+		// A nested InTx rolls back on its own.
+		err := tx.InTx(ctx, func(tx ProductStore) error {
+			createProduct(t, tx, 2, "Gadget", 999)
+			return boom
+		})
+		if !errors.Is(err, boom) {
+			t.Errorf("nested InTx error = %v, want %v", err, boom)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx error = %v", err)
+	}
+	if _, err := s.GetProductByID(ctx, 1); err != nil {
+		t.Errorf("GetProductByID after commit error = %v", err)
+	}
+	if _, err := s.GetProductByID(ctx, 2); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("GetProductByID of the nested rollback error = %v, want pgx.ErrNoRows", err)
+	}
+}
+
+func TestSQLite_BatchCreateProducts(t *testing.T) {
+	ctx := context.Background()
+	s := newSQLite(t)
+	createProduct(t, s, 1, "Widget", 999)
+
+	var errs []error
+	s.BatchCreateProducts(ctx, []repository.BatchCreateProductsParams{
+		{ID: 2, Name: "Gadget", PriceMinor: 100, Currency: "USD", Actor: "test"},
+		{ID: 1, Name: "Widget", PriceMinor: 100, Currency: "USD", Actor: "test"},
+	}, func(_ int, _ repository.BatchCreateProductsRow, err error) {
+		errs = append(errs, err)
+	})
+
+	if len(errs) != 2 || errs[0] != nil || constraintName(errs[1]) != "products_pkey" {
+		t.Errorf("batch errors = %v, want nil then products_pkey", errs)
+	}
+}
+
+func TestModule_SelectsSQLite(t *testing.T) {
+	var s ProductStore
+	app := fxtest.New(t,
+		fx.Supply(
+			&config.Config{DbConfig: config.DbConfig{
+				Driver: database.DriverSQLite,
+				Path:   filepath.Join(t.TempDir(), "test.db"),
+			}},
+			zap.NewNop(),
+			prometheus.NewRegistry(),
+		),
+		database.Module,
+		Module,
+		fx.Populate(&s),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	if _, ok := s.(*SQLite); !ok {
+		t.Errorf("ProductStore = %T, want *SQLite", s)
+	}
+}
@@ -0,0 +1,153 @@
+// Package store defines the ProductStore the product service reads and
+// writes through. SQL runs the sqlc queries in package repository against
+// CockroachDB, SQLite runs their SQLite port in package repository/sqlite,
+// and Memory keeps everything in process for tests and demos.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/database"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/repository"
+	"go.uber.org/fx"
+)
+
+// ProductStore is the persistence the product service depends on. Methods
+// follow the sqlc queries of the same name: a :one query that matches no
+// row fails with pgx.ErrNoRows, and constraint violations fail with a
+// *pgconn.PgError carrying the SQLSTATE and constraint name.
+type ProductStore interface {
+	// InTx runs fn with a store bound to a new transaction and commits it
+	// when fn succeeds. The error of fn is returned unchanged. fn must
+	// query through that store only; on SQLite, a query outside the
+	// transaction deadlocks.
+	InTx(ctx context.Context, fn func(ProductStore) error) error
+
+	// Products
+	CreateProduct(ctx context.Context, arg repository.CreateProductParams) (repository.CreateProductRow, error)
+	// BatchCreateProducts creates every product of arg and reports the
+	// result of each to f in order. Run it with InTx so that a batch is
+	// created all-or-nothing.
+	BatchCreateProducts(ctx context.Context, arg []repository.BatchCreateProductsParams, f func(int, repository.BatchCreateProductsRow, error))
+	GetProductByID(ctx context.Context, id int64) (repository.Product, error)
+	GetProductByIDWithDeleted(ctx context.Context, id int64) (repository.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []int64) ([]repository.Product, error)
+	ListProducts(ctx context.Context, arg repository.ListProductsParams) ([]repository.Product, error)
+	ListProductChanges(ctx context.Context, arg repository.ListProductChangesParams) ([]repository.Product, error)
+	CurrentTime(ctx context.Context) (time.Time, error)
+	SearchProducts(ctx context.Context, arg repository.SearchProductsParams) ([]repository.SearchProductsRow, error)
+	UpdateProduct(ctx context.Context, arg repository.UpdateProductParams) (repository.UpdateProductRow, error)
+	DeleteProduct(ctx context.Context, arg repository.DeleteProductParams) (repository.DeleteProductRow, error)
+	UndeleteProduct(ctx context.Context, arg repository.UndeleteProductParams) (repository.UndeleteProductRow, error)
+	PurgeDeletedProducts(ctx context.Context, arg repository.PurgeDeletedProductsParams) (int64, error)
+	ListProductNames(ctx context.Context) ([]repository.ListProductNamesRow, error)
+
+	// Revisions
+	GetProductRevisionAsOf(ctx context.Context, arg repository.GetProductRevisionAsOfParams) (repository.ProductRevision, error)
+	ListProductRevisions(ctx context.Context, arg repository.ListProductRevisionsParams) ([]repository.ProductRevision, error)
+	ListProductPurges(ctx context.Context, arg repository.ListProductPurgesParams) ([]repository.ProductRevision, error)
+
+	// Categories and tags
+	CreateCategory(ctx context.Context, arg repository.CreateCategoryParams) (repository.Category, error)
+	GetCategory(ctx context.Context, id int64) (repository.Category, error)
+	GetCategoryForUpdate(ctx context.Context, id int64) (repository.Category, error)
+	ListCategories(ctx context.Context, arg repository.ListCategoriesParams) ([]repository.Category, error)
+	UpdateCategory(ctx context.Context, arg repository.UpdateCategoryParams) (repository.Category, error)
+	MoveCategoryDescendants(ctx context.Context, arg repository.MoveCategoryDescendantsParams) (int64, error)
+	DeleteCategory(ctx context.Context, id int64) (repository.Category, error)
+	AddProductCategories(ctx context.Context, arg repository.AddProductCategoriesParams) (int64, error)
+	ClearProductCategories(ctx context.Context, productID int64) error
+	ListCategoryLinks(ctx context.Context, productIds []int64) ([]repository.ProductCategory, error)
+	AddProductTags(ctx context.Context, arg repository.AddProductTagsParams) error
+	ClearProductTags(ctx context.Context, productID int64) error
+	ListProductTags(ctx context.Context, productIds []int64) ([]repository.ProductTag, error)
+
+	// Media
+	AddProductMedia(ctx context.Context, arg repository.AddProductMediaParams) (repository.ProductMedia, error)
+	CountProductMedia(ctx context.Context, productID int64) (int64, error)
+	ListProductMedia(ctx context.Context, productIds []int64) ([]repository.ProductMedia, error)
+	UpdateProductMediaAltText(ctx context.Context, arg repository.UpdateProductMediaAltTextParams) (repository.ProductMedia, error)
+	ReorderProductMedia(ctx context.Context, arg repository.ReorderProductMediaParams) (int64, error)
+	DeleteProductMedia(ctx context.Context, arg repository.DeleteProductMediaParams) (repository.ProductMedia, error)
+	ListPurgedMediaBlobs(ctx context.Context, pageLimit int32) ([]string, error)
+	DeletePurgedMediaBlobs(ctx context.Context, storageKeys []string) (int64, error)
+
+	// Variants
+	CreateVariant(ctx context.Context, arg repository.CreateVariantParams) (repository.CreateVariantRow, error)
+	GetVariant(ctx context.Context, id int64) (repository.ProductVariant, error)
+	GetVariantBySKU(ctx context.Context, sku string) (repository.ProductVariant, error)
+	ListVariants(ctx context.Context, productID int64) ([]repository.ProductVariant, error)
+	UpdateVariant(ctx context.Context, arg repository.UpdateVariantParams) (repository.ProductVariant, error)
+	DeleteVariant(ctx context.Context, id int64) (repository.ProductVariant, error)
+
+	// Inventory
+	AdjustStock(ctx context.Context, arg repository.AdjustStockParams) (repository.AdjustStockRow, error)
+	AdjustVariantStock(ctx context.Context, arg repository.AdjustVariantStockParams) (repository.AdjustVariantStockRow, error)
+	ListStockMovements(ctx context.Context, arg repository.ListStockMovementsParams) ([]repository.StockMovement, error)
+	ReconcileStock(ctx context.Context, maxResults int32) ([]repository.ReconcileStockRow, error)
+	ReserveStock(ctx context.Context, arg repository.ReserveStockParams) (repository.StockReservation, error)
+	ReserveVariantStock(ctx context.Context, arg repository.ReserveVariantStockParams) (repository.StockReservation, error)
+	GetStockReservation(ctx context.Context, id int64) (repository.StockReservation, error)
+	CommitStockReservation(ctx context.Context, id int64) (repository.CommitStockReservationRow, error)
+	ReleaseStockReservation(ctx context.Context, arg repository.ReleaseStockReservationParams) (repository.ReleaseStockReservationRow, error)
+	ExpireStockReservations(ctx context.Context, batchSize int32) (int64, error)
+
+	// Exchange rates
+	GetExchangeRate(ctx context.Context, arg repository.GetExchangeRateParams) (repository.ExchangeRate, error)
+	UpsertExchangeRate(ctx context.Context, arg repository.UpsertExchangeRateParams) (repository.ExchangeRate, error)
+
+	// Idempotency keys
+	ClaimIdempotencyKey(ctx context.Context, arg repository.ClaimIdempotencyKeyParams) (string, error)
+	GetIdempotencyKey(ctx context.Context, arg repository.GetIdempotencyKeyParams) (repository.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, arg repository.CompleteIdempotencyKeyParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, batchSize int32) (int64, error)
+
+	OutboxStore
+}
+
+// OutboxStore is the part of ProductStore the outbox relay depends on. The
+// events themselves are written by the product mutations above.
+type OutboxStore interface {
+	ListPendingOutboxEvents(ctx context.Context, arg repository.ListPendingOutboxEventsParams) ([]repository.Outbox, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MarkOutboxEventFailed(ctx context.Context, arg repository.MarkOutboxEventFailedParams) error
+	GetOutboxBacklog(ctx context.Context, now time.Time) (repository.GetOutboxBacklogRow, error)
+	DeletePublishedOutboxEvents(ctx context.Context, arg repository.DeletePublishedOutboxEventsParams) (int64, error)
+	AcquireOutboxLease(ctx context.Context, arg repository.AcquireOutboxLeaseParams) (int64, error)
+	ReleaseOutboxLease(ctx context.Context, holder string) error
+}
+
+// Module provides the ProductStore on top of the connection the database
+// module opened.
+var Module = fx.Module("store",
+	fx.Provide(New),
+)
+
+type Params struct {
+	fx.In
+
+	Pool       *pgxpool.Pool
+	SQLite     *sql.DB
+	Transactor *database.Transactor
+}
+
+// New returns the SQLite store when the database module opened a SQLite
+// database, and the SQL store on the CockroachDB pool otherwise. The SQL
+// store retries serialization failures through the Transactor.
+func New(p Params) ProductStore {
+	if p.SQLite != nil {
+		return NewSQLite(p.SQLite)
+	}
+	return NewSQL(p.Pool, repository.New(p.Pool)).WithTransactor(p.Transactor)
+}
+
+// InMemory replaces the ProductStore with a new, empty Memory store. The
+// handler, its idempotency keys, the outbox relay and the background jobs
+// all run on it, so the database module never connects and a whole
+// service runs without a database server. Use it in tests and demos.
+func InMemory() fx.Option {
+	return fx.Replace(fx.Annotate(NewMemory(), fx.As(new(ProductStore))))
+}
@@ -0,0 +1,284 @@
+// Package validation enforces the validate.v1 field rules declared on the
+// messages in proto/. Requests are checked as a whole and every broken rule
+// is reported, not just the first.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	validatev1 "github.com/yaninyzwitty/go-fx-v1/gen/validate/v1"
+	"github.com/yaninyzwitty/go-fx-v1/packages/shared/apierror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Violation is a field that breaks one of its rules.
+type Violation struct {
+	// Field is the path to the field, e.g. "requests[1].price.currency_code".
+	Field       string
+	Description string
+}
+
+// Error lists the violations of a message. It converts to an
+// INVALID_ARGUMENT status carrying a BadRequest detail and an ErrorInfo
+// with reason VALIDATION_FAILED.
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + " " + v.Description
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+// GRPCStatus lets status.FromError and the gRPC server convert the error.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, e.Error())
+	br := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	info := &errdetails.ErrorInfo{Reason: apierror.ReasonValidationFailed, Domain: apierror.Domain}
+	if detailed, err := st.WithDetails(br, info); err == nil {
+		return detailed
+	}
+	return st
+}
+
+// Invalid returns an *Error for a single field that breaks a rule the
+// field rules cannot express, such as one that depends on other fields or
+// on stored data.
+func Invalid(field, format string, args ...any) error {
+	return &Error{Violations: []Violation{{Field: field, Description: fmt.Sprintf(format, args...)}}}
+}
+
+// Nested adds prefix to the field of every violation of err, for errors
+// about a message found at prefix, e.g. "requests[1]". Other errors are
+// returned unchanged.
+func Nested(prefix string, err error) error {
+	var verr *Error
+	if !errors.As(err, &verr) {
+		return err
+	}
+	nested := &Error{Violations: make([]Violation, len(verr.Violations))}
+	for i, v := range verr.Violations {
+		nested.Violations[i] = Violation{Field: prefix + "." + v.Field, Description: v.Description}
+	}
+	return nested
+}
+
+// Validate checks msg and the messages set in it against their field
+// rules. It returns nil or an *Error listing every violation.
+func Validate(msg proto.Message) error {
+	var v validator
+	v.message(msg.ProtoReflect(), "", nil)
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &Error{Violations: v.violations}
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// message checks the fields of m. overrides are the rules the field
+// holding m declares for them, which take the place of their own.
+func (v *validator) message(m protoreflect.Message, prefix string, overrides map[string]*validatev1.FieldRules) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		rules := fieldRules(fd)
+		if r, ok := overrides[string(fd.Name())]; ok {
+			rules = r
+		}
+
+		switch {
+		case fd.IsMap():
+			continue
+		case fd.IsList():
+			v.list(fd, m.Get(fd).List(), path, rules)
+		default:
+			if !m.Has(fd) {
+				if rules.GetRequired() {
+					v.add(path, "is required")
+				}
+				continue
+			}
+			v.value(fd, m.Get(fd), path, rules)
+		}
+	}
+}
+
+func (v *validator) list(fd protoreflect.FieldDescriptor, list protoreflect.List, path string, rules *validatev1.FieldRules) {
+	if list.Len() == 0 && rules.GetRequired() {
+		v.add(path, "is required")
+		return
+	}
+	if r := rules.GetRepeated(); r != nil {
+		n := uint64(list.Len())
+		if r.GetMinItems() > 0 && n < r.GetMinItems() {
+			v.add(path, "must have at least %d items", r.GetMinItems())
+		}
+		if r.GetMaxItems() > 0 && n > r.GetMaxItems() {
+			v.add(path, "must have at most %d items", r.GetMaxItems())
+		}
+	}
+	items := rules.GetRepeated().GetItems()
+	for i := 0; i < list.Len(); i++ {
+		item := list.Get(i)
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if isZero(fd, item) {
+			if items.GetRequired() {
+				v.add(itemPath, "is required")
+			}
+			continue
+		}
+		v.value(fd, item, itemPath, items)
+	}
+}
+
+// value checks a set, non-zero value against its rules and descends into
+// messages.
+func (v *validator) value(fd protoreflect.FieldDescriptor, val protoreflect.Value, path string, rules *validatev1.FieldRules) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v.string(val.String(), path, rules.GetString_())
+	case protoreflect.EnumKind:
+		if rules.GetEnum().GetDefinedOnly() && fd.Enum().Values().ByNumber(val.Enum()) == nil {
+			v.add(path, "must be one of the defined values")
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if rules.GetMoney().GetPositive() && !positiveMoney(val.Message()) {
+			v.add(path, "must be greater than 0")
+		}
+		v.message(val.Message(), path, rules.GetMessage().GetFields())
+	default:
+		if n, ok := toInt64(fd.Kind(), val); ok {
+			v.int(n, path, rules.GetInt())
+		}
+	}
+}
+
+func (v *validator) string(s, path string, r *validatev1.StringRules) {
+	if r == nil {
+		return
+	}
+	if r.GetMinLen() > 0 || r.GetMaxLen() > 0 {
+		n := uint64(utf8.RuneCountInString(s))
+		if n < r.GetMinLen() {
+			v.add(path, "must be at least %d characters", r.GetMinLen())
+		}
+		if r.GetMaxLen() > 0 && n > r.GetMaxLen() {
+			v.add(path, "must be at most %d characters", r.GetMaxLen())
+		}
+	}
+	if r.GetMaxBytes() > 0 && uint64(len(s)) > r.GetMaxBytes() {
+		v.add(path, "must be at most %d bytes", r.GetMaxBytes())
+	}
+	if r.GetPattern() != "" && !pattern(r.GetPattern()).MatchString(s) {
+		v.add(path, "must match %s", r.GetPattern())
+	}
+	if r.GetNotBlank() && strings.TrimSpace(s) == "" {
+		v.add(path, "must not be blank")
+	}
+}
+
+func (v *validator) int(n int64, path string, r *validatev1.IntRules) {
+	if r == nil {
+		return
+	}
+	if r.Gt != nil && n <= r.GetGt() {
+		v.add(path, "must be greater than %d", r.GetGt())
+	}
+	if r.Gte != nil && n < r.GetGte() {
+		v.add(path, "must be at least %d", r.GetGte())
+	}
+	if r.Lt != nil && n >= r.GetLt() {
+		v.add(path, "must be less than %d", r.GetLt())
+	}
+	if r.Lte != nil && n > r.GetLte() {
+		v.add(path, "must be at most %d", r.GetLte())
+	}
+}
+
+// fieldRules returns the rules declared on a field, or nil.
+func fieldRules(fd protoreflect.FieldDescriptor) *validatev1.FieldRules {
+	opts := fd.Options()
+	if opts == nil || !proto.HasExtension(opts, validatev1.E_Field) {
+		return nil
+	}
+	return proto.GetExtension(opts, validatev1.E_Field).(*validatev1.FieldRules)
+}
+
+func isZero(fd protoreflect.FieldDescriptor, val protoreflect.Value) bool {
+	if fd.Message() != nil {
+		return !val.Message().IsValid()
+	}
+	return val.Equal(fd.Default())
+}
+
+// toInt64 reads any integer kind, saturating unsigned values that do not
+// fit.
+func toInt64(kind protoreflect.Kind, val protoreflect.Value) (int64, bool) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return val.Int(), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if u := val.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+		return math.MaxInt64, true
+	}
+	return 0, false
+}
+
+// positiveMoney reports whether a Money-like message holds an amount above
+// zero.
+func positiveMoney(m protoreflect.Message) bool {
+	fields := m.Descriptor().Fields()
+	units, nanos := fields.ByName("units"), fields.ByName("nanos")
+	if units == nil || nanos == nil {
+		return false
+	}
+	u, n := m.Get(units).Int(), m.Get(nanos).Int()
+	return u > 0 || (u == 0 && n > 0)
+}
+
+var patterns sync.Map // string -> *regexp.Regexp
+
+// pattern compiles rule patterns once. They come from the generated
+// descriptors, so a bad one is a programming error.
+func pattern(expr string) *regexp.Regexp {
+	if re, ok := patterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(expr)
+	patterns.Store(expr, re)
+	return re
+}
@@ -0,0 +1,205 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	productsv1 "github.com/yaninyzwitty/go-fx-v1/gen/products/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func violations(t *testing.T, msg proto.Message) []Violation {
+	t.Helper()
+
+	err := Validate(msg)
+	if err == nil {
+		return nil
+	}
+	var verr *Error
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate returned %T, want *Error", err)
+	}
+	return verr.Violations
+}
+
+func TestValidate_ValidRequest(t *testing.T) {
+	req := &productsv1.CreateProductRequest{
+		Name:  "Widget",
+		Price: &productsv1.Money{CurrencyCode: "USD", Units: 9, Nanos: 990_000_000},
+		Tags:  []string{"tools"},
+	}
+	if err := Validate(req); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestValidate_ReportsEveryViolation(t *testing.T) {
+	req := &productsv1.CreateProductRequest{
+		Description: strings.Repeat("é", 5001),
+		Price:       &productsv1.Money{CurrencyCode: "usd", Units: -1},
+		Tags:        []string{"ok", strings.Repeat("x", 65)},
+	}
+
+	want := []Violation{
+		{"name", "is required"},
+		{"description", "must be at most 5000 characters"},
+		{"price", "must be greater than 0"},
+		{"price.currency_code", "must match ^[A-Z]{3}$"},
+		{"tags[1]", "must be at most 64 bytes"},
+	}
+	if got := violations(t, req); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestValidate_NestedAndRepeatedMessages(t *testing.T) {
+	req := &productsv1.BatchCreateProductsRequest{
+		Requests: []*productsv1.CreateProductRequest{
+			{Name: "Widget", Price: &productsv1.Money{CurrencyCode: "USD", Units: 1}},
+			{Name: "Gadget", Price: &productsv1.Money{Units: 1}},
+		},
+	}
+
+	want := []Violation{{"requests[1].price.currency_code", "is required"}}
+	if got := violations(t, req); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+
+	req.Requests = make([]*productsv1.CreateProductRequest, 101)
+	for i := range req.Requests {
+		req.Requests[i] = &productsv1.CreateProductRequest{Name: "Widget", Price: &productsv1.Money{CurrencyCode: "USD", Units: 1}}
+	}
+	want = []Violation{{"requests", "must have at most 100 items"}}
+	if got := violations(t, req); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestValidate_SharedMessageRules(t *testing.T) {
+	product := &productsv1.Product{
+		Name:  strings.Repeat("x", 201),
+		Price: &productsv1.Money{CurrencyCode: "USD", Units: -1},
+		Tags:  []string{strings.Repeat("x", 65)},
+	}
+	// A Product is only constrained where a request carries it.
+	if err := Validate(product); err != nil {
+		t.Errorf("Validate(product) = %v, want nil", err)
+	}
+
+	want := []Violation{
+		{"product.id", "is required"},
+		{"product.name", "must be at most 200 characters"},
+		{"product.price", "must be greater than 0"},
+		{"product.tags[0]", "must be at most 64 bytes"},
+	}
+	if got := violations(t, &productsv1.UpdateProductRequest{Product: product}); !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+}
+
+func TestValidate_Integers(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want []Violation
+	}{
+		{"page size in bounds", &productsv1.ListProductsRequest{PageSize: 100}, nil},
+		{"page size too large", &productsv1.ListProductsRequest{PageSize: 101}, []Violation{{"page_size", "must be at most 100"}}},
+		{"missing id", &productsv1.GetProductRequest{}, []Violation{{"id", "is required"}}},
+		{"negative id", &productsv1.GetProductRequest{Id: -4}, []Violation{{"id", "must be greater than 0"}}},
+		{"undefined enum", &productsv1.AdjustStockRequest{ProductId: 1, Delta: 1, Reason: 42}, []Violation{{"reason", "must be one of the defined values"}}},
+		{"missing enum", &productsv1.AdjustStockRequest{ProductId: 1, Delta: 1}, []Violation{{"reason", "is required"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := violations(t, tt.msg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError_GRPCStatus(t *testing.T) {
+	err := Validate(&productsv1.CreateProductRequest{})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument", st.Code())
+	}
+	if want := "invalid request: name is required; price is required"; st.Message() != want {
+		t.Errorf("message = %q, want %q", st.Message(), want)
+	}
+	details := st.Details()
+	if len(details) != 2 {
+		t.Fatalf("got %d details, want 2", len(details))
+	}
+	if info, ok := details[1].(*errdetails.ErrorInfo); !ok || info.GetReason() != "VALIDATION_FAILED" {
+		t.Errorf("details[1] = %v, want an ErrorInfo with reason VALIDATION_FAILED", details[1])
+	}
+	br, ok := details[0].(*errdetails.BadRequest)
+	if !ok || len(br.GetFieldViolations()) != 2 {
+		t.Fatalf("details = %v, want a BadRequest with 2 violations", details)
+	}
+	if br.GetFieldViolations()[1].GetField() != "price" {
+		t.Errorf("second violation is on %q, want price", br.GetFieldViolations()[1].GetField())
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	err := Invalid("page_token", "is malformed")
+
+	if got, want := err.Error(), "invalid request: page_token is malformed"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument", st.Code())
+	}
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(br.GetFieldViolations()) != 1 || br.GetFieldViolations()[0].GetField() != "page_token" {
+		t.Errorf("details = %v, want a BadRequest naming page_token", st.Details())
+	}
+}
+
+func TestNested(t *testing.T) {
+	err := Nested("requests[1]", Invalid("price", "must be greater than 0"))
+
+	if got, want := err.Error(), "invalid request: requests[1].price must be greater than 0"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	plain := errors.New("boom")
+	if got := Nested("requests[1]", plain); got != plain {
+		t.Errorf("Nested changed a plain error to %v", got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	called := false
+	handler := func(context.Context, any) (any, error) {
+		called = true
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), &productsv1.DeleteProductRequest{Id: -1}, &grpc.UnaryServerInfo{}, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument", status.Code(err))
+	}
+	if called {
+		t.Error("handler ran for an invalid request")
+	}
+
+	if _, err := interceptor(context.Background(), &productsv1.DeleteProductRequest{Id: 1}, &grpc.UnaryServerInfo{}, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	if !called {
+		t.Error("handler did not run for a valid request")
+	}
+}